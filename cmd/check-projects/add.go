@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/vcs"
+)
+
+var (
+	addRoot           string
+	addCreateCategory bool
+)
+
+func newAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add [path] --category NAME",
+		Short: "Register a project, or a root-based category with --root, in the config",
+		Example: `  check-projects add ~/src/newthing --category personal
+  check-projects add ~/src/newthing --category sandbox --create-category
+  check-projects add --root ~/newarea --category newarea`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runAdd,
+	}
+
+	cmd.Flags().StringVar(&addRoot, "root", "", "Create a category scanning this root directory")
+	cmd.Flags().BoolVar(&addCreateCategory, "create-category", false, "Create the category if it does not exist")
+
+	return cmd
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	if category == "" {
+		return fmt.Errorf("--category is required")
+	}
+	if (addRoot == "") == (len(args) == 0) {
+		return fmt.Errorf("provide either a project path or --root")
+	}
+	cmd.SilenceUsage = true
+
+	cfg, err := config.LoadConfig(configPaths)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if addRoot != "" {
+		return addRootCategory(cfg, addRoot)
+	}
+	return addProject(cfg, args[0])
+}
+
+// addProject appends a repository path to an explicit category
+func addProject(cfg *config.Config, path string) error {
+	absPath, err := filepath.Abs(config.ExpandPath(path))
+	if err != nil {
+		return fmt.Errorf("invalid path '%s': %w", path, err)
+	}
+	if !vcs.IsRepository(absPath) {
+		return fmt.Errorf("'%s' is not a git or hg repository", absPath)
+	}
+
+	// Nothing to do if a category already includes this path
+	for _, cat := range cfg.Categories {
+		if cat.Covers(absPath) {
+			fmt.Printf("✔ '%s' is already included in category '%s'\n", absPath, cat.Name)
+			return nil
+		}
+	}
+
+	cat := cfg.FindCategory(category)
+	switch {
+	case cat == nil && !addCreateCategory:
+		return fmt.Errorf("category '%s' not found in config (use --create-category to create it)", category)
+	case cat == nil:
+		cfg.Categories = append(cfg.Categories, config.Category{Name: category})
+		cat = &cfg.Categories[len(cfg.Categories)-1]
+	case cat.Root != "" && len(cat.Projects) == 0:
+		return fmt.Errorf("category '%s' scans %s, projects can only be added to categories listing explicit projects", cat.Name, cat.Root)
+	}
+
+	entry := config.ContractPath(absPath)
+	cat.Projects = append(cat.Projects, entry)
+
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	savedMessage("✅ Added '%s' to category '%s' in %s\n", entry, cat.Name, cfg.SourceOf(cat))
+	return nil
+}
+
+// addRootCategory creates a new category scanning root
+func addRootCategory(cfg *config.Config, root string) error {
+	absRoot, err := filepath.Abs(config.ExpandPath(root))
+	if err != nil {
+		return fmt.Errorf("invalid root '%s': %w", root, err)
+	}
+	info, err := os.Stat(absRoot)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("'%s' is not a directory", absRoot)
+	}
+
+	if cat := cfg.FindCategory(category); cat != nil {
+		if cat.Root != "" && filepath.Clean(cat.GetRootPath()) == absRoot {
+			fmt.Printf("✔ Category '%s' already scans '%s'\n", cat.Name, absRoot)
+			return nil
+		}
+		return fmt.Errorf("category '%s' already exists", category)
+	}
+
+	entry := config.ContractPath(absRoot)
+	cfg.Categories = append(cfg.Categories, config.Category{Name: category, Root: entry})
+
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	savedMessage("✅ Added category '%s' scanning '%s' in %s\n", category, entry, cfg.ConfigPath)
+	return nil
+}
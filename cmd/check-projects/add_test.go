@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// applyTwice runs a config mutation twice on the fixture tree and checks that the
+// first run changes the config, keeping its comments, and that the second one
+// leaves its bytes as they were. It returns what the second run printed.
+func applyTwice(t *testing.T, run func(tree string) error) string {
+	t.Helper()
+	tree := fixtureTree(t)
+	t.Cleanup(func() { category, addRoot, addCreateCategory, ignorePattern = "", "", false, "" })
+	read := func() []byte {
+		data, err := os.ReadFile(configPaths[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	original := read()
+
+	var err error
+	captureStdout(t, func() { err = run(tree) })
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	first := read()
+	if bytes.Equal(first, original) {
+		t.Fatal("the first run does not change the config")
+	}
+	if !strings.Contains(string(first), "# Projects of the dry-run fixture\n") {
+		t.Errorf("the first run lost the comments:\n%s", first)
+	}
+
+	out := captureStdout(t, func() { err = run(tree) })
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if second := read(); !bytes.Equal(second, first) {
+		t.Errorf("the second run changed the config:\n%s\nthen:\n%s", first, second)
+	}
+	return out
+}
+
+func TestAddIsIdempotent(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(tree string) error
+		want string // Printed by the second run
+	}{
+		{"project", func(tree string) error {
+			category = "explicit"
+			return runAdd(&cobra.Command{}, []string{filepath.Join(tree, "new", "tool")})
+		}, "is already included in category 'explicit'"},
+		{"project in a new category", func(tree string) error {
+			category, addCreateCategory = "tools", true
+			return runAdd(&cobra.Command{}, []string{filepath.Join(tree, "new", "tool")})
+		}, "is already included in category 'tools'"},
+		{"root", func(tree string) error {
+			category, addRoot = "new", filepath.Join(tree, "new")
+			return runAdd(&cobra.Command{}, nil)
+		}, "Category 'new' already scans"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if out := applyTwice(t, tt.run); !strings.Contains(out, tt.want) {
+				t.Errorf("%q missing from the second run:\n%s", tt.want, out)
+			}
+		})
+	}
+}
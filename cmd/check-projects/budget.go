@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// exitBudgetExceeded is the exit status of a run whose --max-duration ran out
+// before every repository was checked
+const exitBudgetExceeded = 3
+
+var maxDuration time.Duration
+
+func addBudgetFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&maxDuration, "max-duration", 0, "Finish within this time (e.g. 20s), reporting the repositories left unchecked with … and exiting with 3")
+}
+
+// withBudget bounds ctx by --max-duration. Its end is told apart from an
+// interruption by budgetExceeded.
+func withBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	if maxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, maxDuration)
+}
+
+// scanContext bounds the discovery of projects to half of the budget left in ctx,
+// keeping the other half for the checks
+func scanContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || maxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/2)
+}
+
+// budgetExceeded reports whether ctx ended because --max-duration ran out
+// (an interruption cancels it instead)
+func budgetExceeded(ctx context.Context) bool {
+	return maxDuration > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// markUnchecked gives the unchecked status to the projects the budget left without
+// a result (checks are not started when they would not complete in time), and
+// returns how many
+func markUnchecked(projects []scanner.Project, results []reporter.ProjectResult) int {
+	unchecked := 0
+	for i, proj := range projects {
+		if results[i].Status != nil {
+			continue
+		}
+		results[i] = reporter.ProjectResult{
+			Name:          proj.Name,
+			Path:          proj.Path,
+			Status:        git.NewUncheckedStatus(),
+			Category:      proj.Category,
+			IsSymlink:     proj.IsSymlink,
+			SymlinkTarget: proj.SymlinkTarget,
+			VCS:           proj.VCS(),
+		}
+		unchecked++
+	}
+	return unchecked
+}
+
+// printUnchecked says how many of total repositories the budget left unchecked
+func printUnchecked(unchecked, total int) {
+	if unchecked > 0 {
+		fmt.Fprintf(os.Stderr, "⚠ time budget exceeded: %d of %d repositories not checked\n", unchecked, total)
+	}
+}
+
+// truncated reports whether the budget ran out: repositories were left unchecked,
+// or the deadline passed after the checks
+func truncated(ctx context.Context, results []reporter.ProjectResult) bool {
+	if budgetExceeded(ctx) {
+		return true
+	}
+	for _, result := range results {
+		if result.Status.Type == git.StatusUnchecked {
+			return true
+		}
+	}
+	return false
+}
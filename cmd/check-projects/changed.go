@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/history"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+var changedOnly bool
+
+// changedOnlyPath returns where the results of the previous --changed-only run are
+// kept. Each selection of projects (config files, --category, --root, --project,
+// --stdin) has its own, so that runs of different selections do not compare.
+func changedOnlyPath(cfg *config.Config, fromStdin bool) (string, error) {
+	dir, err := history.StateDir()
+	if err != nil {
+		return "", err
+	}
+	selection := fmt.Sprintf("%s|%s|%s|%s|%t", strings.Join(cfg.Files(), ","), category, scanRoot, projectName, fromStdin)
+	sum := sha256.Sum256([]byte(selection))
+	return filepath.Join(dir, "changed-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// onlyChanged keeps the projects that changed since the results recorded at path
+// (or that appeared), then records the current results there. It returns the projects
+// that disappeared since, and first when there was nothing to compare with, in which
+// case every project is kept.
+func onlyChanged(path string, projects []scanner.Project, results []reporter.ProjectResult) ([]scanner.Project, []reporter.ProjectResult, []history.Change, bool, error) {
+	current := history.NewSnapshot(results, time.Now())
+	// An unreadable previous run is replaced, rather than failing every run from now on
+	previous, err := history.Load(path)
+	first := err != nil
+	if err := history.Write(path, current); err != nil {
+		return nil, nil, nil, false, err
+	}
+	if first {
+		return projects, results, nil, true, nil
+	}
+
+	changed := make(map[string]bool)
+	var disappeared []history.Change
+	for _, change := range history.Diff(previous, current) {
+		if change.Kind == history.ChangeDisappeared {
+			disappeared = append(disappeared, change)
+		} else {
+			changed[change.Path] = true
+		}
+	}
+	projects, results = keepResults(projects, results, func(result reporter.ProjectResult) bool {
+		return changed[result.Path]
+	})
+	return projects, results, disappeared, false, nil
+}
+
+// printDisappeared lists the projects that were part of the previous run but no longer are
+func printDisappeared(disappeared []history.Change) {
+	if len(disappeared) == 0 {
+		return
+	}
+	red := color.New(color.FgRed).SprintFunc()
+	fmt.Fprintln(logOut, "\nDisappeared since the previous run:")
+	for _, change := range disappeared {
+		fmt.Fprintf(logOut, "  %s %s/%s\n", red("-"), change.Before.Category, change.Before.Name)
+	}
+}
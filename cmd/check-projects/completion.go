@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+)
+
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate the autocompletion script for your shell",
+		Long: `Generate the autocompletion script for check-projects for the specified shell.
+
+Examples:
+  check-projects completion bash > /etc/bash_completion.d/check-projects
+  check-projects completion zsh > "${fpath[1]}/_check-projects"
+  check-projects completion fish > ~/.config/fish/completions/check-projects.fish
+  check-projects completion powershell | Out-String | Invoke-Expression`,
+		Args:                  cobra.ExactArgs(1),
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(os.Stdout)
+			default:
+				return fmt.Errorf("unsupported shell '%s' (valid: bash, zsh, fish, powershell)", args[0])
+			}
+		},
+	}
+}
+
+// completionConfig loads the config for completion. Cobra parses the flags of the
+// completed command line a second time, which repeats every --config path.
+func completionConfig() (*config.Config, error) {
+	var paths []string
+	for _, path := range configPaths {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return config.LoadConfig(paths)
+}
+
+// completeCategories offers the category names from the config.
+// It only reads the config and returns no completions when none can be loaded.
+func completeCategories(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := completionConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, cat := range cfg.Categories {
+		if strings.HasPrefix(cat.Name, toComplete) {
+			names = append(names, cat.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProjects offers the explicitly listed projects from the config.
+// Root-based categories are not scanned to keep completion instant.
+func completeProjects(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := completionConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, cat := range cfg.Categories {
+		if category != "" && cat.Name != category {
+			continue
+		}
+		for _, projectPath := range cat.Projects {
+			paths := []string{config.ExpandPath(projectPath)}
+			if config.IsGlob(projectPath) {
+				paths, _ = config.GlobProjects(projectPath)
+			}
+			for _, path := range paths {
+				name := filepath.Base(path)
+				if strings.HasPrefix(name, toComplete) {
+					names = append(names, fmt.Sprintf("%s\t%s", name, cat.Name))
+				}
+			}
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// completionFixture lists projects explicitly, by glob, and under a root that
+// completion does not scan
+const completionFixture = `categories:
+  - name: dev
+    projects:
+      - {{tree}}/dev/app
+      - {{tree}}/dev/lib
+  - name: work
+    projects:
+      - {{tree}}/work/*
+  - name: scanned
+    root: {{tree}}/scanned
+`
+
+// complete runs check-projects __complete with args, as the shell scripts do,
+// and returns the completions it printed
+func complete(t *testing.T, args ...string) []string {
+	t.Helper()
+	t.Cleanup(func() { configPaths, category = nil, "" })
+
+	var out bytes.Buffer
+	cmd := newRootCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(append([]string{"__complete"}, args...))
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// One completion per line, then the directive such as :4
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if directive := lines[len(lines)-1]; !strings.HasPrefix(directive, ":") {
+		t.Fatalf("no directive in %q", out.String())
+	}
+	return lines[:len(lines)-1]
+}
+
+func TestComplete(t *testing.T) {
+	tree := t.TempDir()
+	for _, dir := range []string{"dev/app", "dev/lib", "work/api", "work/web", "scanned/tool"} {
+		if err := os.MkdirAll(filepath.Join(tree, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(tree, "check-projects.yml")
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(completionFixture, "{{tree}}", tree)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(tree, "missing.yml")
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"categories", []string{"--config", path, "--category", ""}, []string{"dev", "work", "scanned"}},
+		{"categories by prefix", []string{"--config", path, "--category", "w"}, []string{"work"}},
+		{"categories of a subcommand", []string{"list", "--config", path, "--category", "s"}, []string{"scanned"}},
+		{"projects", []string{"--config", path, "--project", ""}, []string{"app\tdev", "lib\tdev", "api\twork", "web\twork"}},
+		{"projects by prefix", []string{"--config", path, "--project", "a"}, []string{"app\tdev", "api\twork"}},
+		{"projects of a category", []string{"--config", path, "--category", "work", "--project", ""}, []string{"api\twork", "web\twork"}},
+		{"open argument", []string{"open", "--config", path, "l"}, []string{"lib\tdev"}},
+		{"open takes one argument", []string{"open", "--config", path, "lib", ""}, nil},
+		{"no match", []string{"--config", path, "--project", "z"}, nil},
+		{"no config", []string{"--config", missing, "--category", ""}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := complete(t, tt.args...)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/uralys/check-projects/internal/config"
+)
+
+// saveConfig writes the modified configuration, or with --dry-run only prints
+// the changes it would make as a diff. Every command changing the config goes through it.
+func saveConfig(cfg *config.Config) error {
+	if !dryRunFlag {
+		return config.SaveConfig(cfg)
+	}
+
+	changes, err := config.PendingChanges(cfg)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("Dry run: the config would not change")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Print(change.UnifiedDiff())
+	}
+	fmt.Println("Dry run: nothing was written")
+	return nil
+}
+
+// savedMessage prints a confirmation once the config was saved, not in dry runs
+func savedMessage(format string, args ...interface{}) {
+	if !dryRunFlag {
+		fmt.Printf(format, args...)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// deadEntries returns the entries of projects lists that listed no repository during
+// the scan (moved or deleted projects, typos), leaving out those marked with
+// config.KeepMarker as prune does
+func deadEntries(cfg *config.Config, warnings []scanner.Warning) []scanner.Warning {
+	kept, err := config.KeptEntries(cfg)
+	if err != nil {
+		kept = nil // Report every entry rather than none
+	}
+	var dead []scanner.Warning
+	for _, warning := range warnings {
+		if warning.Dead && !kept[warning.Category][warning.Path] {
+			dead = append(dead, warning)
+		}
+	}
+	return dead
+}
+
+// printDeadEntries lists the dead entries of the config after the report
+func printDeadEntries(dead []scanner.Warning) {
+	if len(dead) == 0 {
+		return
+	}
+	yellow := color.New(color.FgYellow).SprintFunc()
+	fmt.Fprintf(logOut, "\n%s\n", yellow(fmt.Sprintf("⚠ Config entries listing no repository (%d):", len(dead))))
+	for _, warning := range dead {
+		fmt.Fprintf(logOut, "  %s\n", yellow(warning.String()))
+	}
+}
+
+// handleDeadEntries asks whether to remove the dead entries from the config (all,
+// one by one, or skip). Nothing is asked when the config is filtered, since it
+// cannot be saved.
+func handleDeadEntries(p prompter, cfg *config.Config, dead []scanner.Warning) error {
+	if cfg.IsFiltered || len(dead) == 0 {
+		return nil
+	}
+
+	answer, err := p.Ask("\033[38;5;208mRemove them from config?\033[0m [a]ll, [c]hoose individually, [s]kip \033[92m(a/c/S):\033[0m ", "s")
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var removed []staleEntry
+	switch strings.ToLower(answer) {
+	case "a", "all", "y", "yes":
+		for _, warning := range dead {
+			removed = append(removed, staleEntry{warning.Category, staleProject, warning.Path, warning.Message})
+		}
+	case "c", "choose":
+		for _, warning := range dead {
+			answer, err := p.Ask(fmt.Sprintf("  %s: remove? [y]es, [N]o: ", warning.Path), "n")
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes") {
+				removed = append(removed, staleEntry{warning.Category, staleProject, warning.Path, warning.Message})
+			}
+		}
+	default:
+		fmt.Printf("Skipped.\n")
+		return nil
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	removeStaleEntries(cfg, removed)
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+	savedMessage("✅ Removed %d entr%s from %s\n", len(removed), pluralY(len(removed)), strings.Join(cfg.Files(), ", "))
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/history"
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+func newDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff [<older> <newer>]",
+		Short: "Show what changed between two snapshots (the latest two by default)",
+		Long: `Compare two snapshots written by --snapshot and list the projects that became
+dirty or clean, whose status, ahead/behind counts or branches behind their remote
+changed, that appeared or that disappeared.
+
+Snapshots are read from ~/.local/share/check-projects/history ($XDG_DATA_HOME is
+honored), and can be given by file name or path.
+
+Examples:
+  check-projects --snapshot          # Check and record a snapshot (e.g. daily from cron)
+  check-projects diff                # What changed between the latest two snapshots
+  check-projects diff 20261016T090000.000Z 20261017T090000.000Z
+  check-projects diff --output json`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 && len(args) != 2 {
+				return fmt.Errorf("expected no snapshot or two (older and newer), got %d", len(args))
+			}
+			return nil
+		},
+		RunE: runDiff,
+	}
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if outputFmt != reporter.FormatText && outputFmt != reporter.FormatJSON {
+		return fmt.Errorf("diff supports --output %s or %s", reporter.FormatText, reporter.FormatJSON)
+	}
+	cmd.SilenceUsage = true
+
+	dir, err := history.Dir()
+	if err != nil {
+		return err
+	}
+	olderPath, newerPath, err := diffSnapshotPaths(dir, args)
+	if err != nil {
+		return err
+	}
+	older, err := history.Load(olderPath)
+	if err != nil {
+		return err
+	}
+	newer, err := history.Load(newerPath)
+	if err != nil {
+		return err
+	}
+
+	var changes []history.Change
+	for _, change := range history.Diff(older, newer) {
+		if category == "" || change.Project().Category == category {
+			changes = append(changes, change)
+		}
+	}
+
+	if outputFmt == reporter.FormatJSON {
+		data, err := json.MarshalIndent(struct {
+			From    string           `json:"from"`
+			To      string           `json:"to"`
+			Changes []history.Change `json:"changes"`
+		}{olderPath, newerPath, append([]history.Change{}, changes...)}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printDiff(older, newer, changes)
+	return nil
+}
+
+// saveSnapshot records results in the history, keeping the configured number of snapshots
+func saveSnapshot(cfg *config.Config, results []reporter.ProjectResult) error {
+	dir, err := history.Dir()
+	if err != nil {
+		return err
+	}
+	_, err = history.Save(dir, history.NewSnapshot(results, time.Now()), cfg.HistoryKeep)
+	return err
+}
+
+// diffSnapshotPaths resolves the snapshots to compare: the given ones, or the latest two
+func diffSnapshotPaths(dir string, args []string) (string, string, error) {
+	if len(args) == 2 {
+		older, err := snapshotPath(dir, args[0])
+		if err != nil {
+			return "", "", err
+		}
+		newer, err := snapshotPath(dir, args[1])
+		return older, newer, err
+	}
+
+	paths, err := history.List(dir)
+	if err != nil {
+		return "", "", err
+	}
+	if len(paths) < 2 {
+		return "", "", fmt.Errorf("%d snapshot(s) in %s, two are needed: run check-projects --snapshot", len(paths), dir)
+	}
+	return paths[len(paths)-2], paths[len(paths)-1], nil
+}
+
+// snapshotPath finds a snapshot given as a path, or as a file name (.json optional) in dir
+func snapshotPath(dir, arg string) (string, error) {
+	candidates := []string{arg, filepath.Join(dir, arg), filepath.Join(dir, arg+".json")}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("snapshot '%s' not found (looked in %s)", arg, dir)
+}
+
+// printDiff lists the changes, one line per project
+func printDiff(older, newer history.Snapshot, changes []history.Change) {
+	const layout = "2006-01-02 15:04"
+	fmt.Printf("Changes from %s to %s:\n", older.CreatedAt.Local().Format(layout), newer.CreatedAt.Local().Format(layout))
+	if len(changes) == 0 {
+		fmt.Println("  No changes")
+		return
+	}
+
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	for _, change := range changes {
+		project := change.Project()
+		name := project.Category + "/" + project.Name
+		switch change.Kind {
+		case history.ChangeDirty:
+			fmt.Printf("  %s %s: became dirty (%s)\n", red("✱"), name, change.After.Message)
+		case history.ChangeClean:
+			fmt.Printf("  %s %s: became clean\n", green("✔"), name)
+		case history.ChangeStatus:
+			fmt.Printf("  %s %s: %s → %s (%s)\n", yellow("~"), name, change.Before.Status, change.After.Status, change.After.Message)
+		case history.ChangeAheadBehind:
+			fmt.Printf("  %s %s: %s\n", yellow("↕"), name, aheadBehindChange(*change.Before, *change.After))
+		case history.ChangeBranches:
+			fmt.Printf("  %s %s: %d branch(es) behind their remote (was %d)\n", yellow("↓"), name, change.After.BehindBranches, change.Before.BehindBranches)
+		case history.ChangeAppeared:
+			fmt.Printf("  %s %s: appeared (%s)\n", green("+"), name, change.After.Message)
+		case history.ChangeDisappeared:
+			fmt.Printf("  %s %s: disappeared\n", red("-"), name)
+		}
+	}
+}
+
+// aheadBehindChange describes the counts that changed, e.g. "ahead 0 → 2, behind 1 → 0"
+func aheadBehindChange(before, after history.SnapshotProject) string {
+	var parts []string
+	if before.Ahead != after.Ahead {
+		parts = append(parts, fmt.Sprintf("ahead %d → %d", before.Ahead, after.Ahead))
+	}
+	if before.Behind != after.Behind {
+		parts = append(parts, fmt.Sprintf("behind %d → %d", before.Behind, after.Behind))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata with the current output")
+
+// golden compares got with the content of testdata/name, or rewrites it with -update
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s (run go test -update if the change is intended):\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestDiffGolden(t *testing.T) {
+	noColor, local := color.NoColor, time.Local
+	color.NoColor, time.Local = true, time.UTC
+	t.Cleanup(func() {
+		color.NoColor, time.Local = noColor, local
+		outputFmt, category = reporter.FormatText, ""
+	})
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	// The snapshots change every project in a different way, one of them in each category
+	args := []string{filepath.Join("testdata", "diff", "older.json"), filepath.Join("testdata", "diff", "newer.json")}
+	tests := []struct {
+		name     string
+		format   string
+		category string
+	}{
+		{"text", reporter.FormatText, ""},
+		{"json", reporter.FormatJSON, ""},
+		{"category", reporter.FormatText, "personal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFmt, category = tt.format, tt.category
+			var err error
+			out := captureStdout(t, func() {
+				err = runDiff(&cobra.Command{}, args)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, filepath.Join("diff", tt.name+".golden"), out)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/gittest"
+)
+
+// fixtureConfig lists a scanned category ignoring a pattern that matches nothing
+// and an explicit one holding a missing project, both pruned
+const fixtureConfig = `# Projects of the dry-run fixture
+categories:
+  - name: dev
+    root: {{tree}}/dev
+    ignore:
+      - gone-*  # Matches nothing
+  - name: explicit
+    projects:
+      - {{tree}}/dev/lib
+      - {{tree}}/missing
+`
+
+// fixtureTree creates repositories and a config file in a temporary directory,
+// selects that config and returns the directory
+func fixtureTree(t *testing.T) string {
+	t.Helper()
+	tree := t.TempDir()
+	for _, repo := range []string{"dev/app", "dev/lib", "new/tool"} {
+		path := filepath.Join(tree, repo)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command("git", "init", "--quiet")
+		cmd.Dir = path
+		cmd.Env = gittest.Env()
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git init: %v: %s", err, out)
+		}
+	}
+	path := filepath.Join(tree, "check-projects.yml")
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(fixtureConfig, "{{tree}}", tree)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	configPaths = []string{path}
+	t.Cleanup(func() { configPaths = nil })
+	return tree
+}
+
+// fileState is what a snapshot records of each file
+type fileState struct {
+	mode    fs.FileMode
+	content string
+	modTime int64
+}
+
+// snapshot records every file and directory under dir
+func snapshot(t *testing.T, dir string) map[string]fileState {
+	t.Helper()
+	files := make(map[string]fileState)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		state := fileState{mode: info.Mode()}
+		if d.Type().IsRegular() {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			state.content = string(content)
+			state.modTime = info.ModTime().UnixNano()
+		}
+		files[path] = state
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestDryRunChangesNothing(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, tree string) error
+	}{
+		{"add project", func(t *testing.T, tree string) error {
+			category = "explicit"
+			return runAdd(&cobra.Command{}, []string{filepath.Join(tree, "new", "tool")})
+		}},
+		{"add root", func(t *testing.T, tree string) error {
+			category, addRoot = "new", filepath.Join(tree, "new")
+			return runAdd(&cobra.Command{}, nil)
+		}},
+		{"ignore project", func(t *testing.T, tree string) error {
+			return runIgnore(&cobra.Command{}, []string{"app"})
+		}},
+		{"ignore pattern", func(t *testing.T, tree string) error {
+			category, ignorePattern = "dev", "old-*"
+			return runIgnore(&cobra.Command{}, nil)
+		}},
+		{"unignore", func(t *testing.T, tree string) error {
+			return runUnignore(&cobra.Command{}, []string{"gone-*"})
+		}},
+		{"prune", func(t *testing.T, tree string) error {
+			return runPrune(&cobra.Command{}, newLinePrompter(strings.NewReader("y\n"), &bytes.Buffer{}), false)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := fixtureTree(t)
+			t.Cleanup(func() { category, addRoot, ignorePattern = "", "", "" })
+			configPath := configPaths[0]
+			original, err := os.ReadFile(configPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dryRunFlag = true
+			before := snapshot(t, tree)
+			err = tt.run(t, tree)
+			dryRunFlag = false
+			if err != nil {
+				t.Fatalf("dry run: %v", err)
+			}
+			if after := snapshot(t, tree); !reflect.DeepEqual(before, after) {
+				t.Errorf("the dry run changed the tree:\nbefore %v\nafter  %v", before, after)
+			}
+
+			// Without --dry-run, the same command does change the config
+			if err := tt.run(t, tree); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			saved, err := os.ReadFile(configPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(saved, original) {
+				t.Error("the command does not change the config: the dry run proves nothing")
+			}
+		})
+	}
+}
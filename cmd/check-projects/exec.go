@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/checker"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// execResult is the outcome of running the command in one project
+type execResult struct {
+	Project scanner.Project
+	Err     error
+}
+
+func newExecCmd() *cobra.Command {
+	var dirtyOnly bool
+	var jobs int
+
+	cmd := &cobra.Command{
+		Use:   "exec [flags] -- <command> [args...]",
+		Short: "Run a command in every repository",
+		Long: `Run a command in the directory of every selected repository.
+
+Each output line is prefixed with [category/project]. With more than one job, the
+output of a repository is printed as a block once its command finishes, so that
+repositories never interleave.
+
+The environment of the command also contains CHECK_PROJECT_NAME, CHECK_PROJECT_PATH
+and CHECK_PROJECT_CATEGORY.
+
+Examples:
+  check-projects exec -- git gc
+  check-projects exec --dirty-only -- git status --short
+  check-projects exec --category work --jobs 4 -- make test
+  check-projects exec -- sh -c 'echo "$CHECK_PROJECT_CATEGORY: $(git log -1 --format=%cr)"'`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExec(cmd, args, dirtyOnly, jobs)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dirtyOnly, "dirty-only", false, "Only run in repositories with uncommitted or untracked changes")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 1, "Number of repositories to run the command in at once")
+	return cmd
+}
+
+func runExec(cmd *cobra.Command, args []string, dirtyOnly bool, jobs int) error {
+	cmd.SilenceUsage = true
+	if jobs < 1 {
+		return fmt.Errorf("--jobs must be at least 1")
+	}
+
+	cfg, err := config.LoadConfig(configPaths)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := filterCategory(cfg, category); err != nil {
+		return err
+	}
+
+	projects, err := scanner.NewScanner(cfg).ScanAll(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to scan projects: %w", err)
+	}
+	projects = withRepository(projects)
+
+	if dirtyOnly {
+		projects = dirtyProjects(context.Background(), projects, cfg.FetchConcurrency, time.Duration(cfg.GitTimeout))
+	}
+	if len(projects) == 0 {
+		fmt.Println("No repository selected")
+		return nil
+	}
+
+	results := make([]execResult, len(projects))
+	var outMu sync.Mutex
+	checker.ForEach(projects, jobs, func(idx int, proj scanner.Project) {
+		prefix := "[" + proj.Category + "/" + proj.Name + "] "
+
+		// A single job streams its output; parallel jobs print each repository as a block
+		if jobs == 1 {
+			out := newPrefixWriter(os.Stdout, prefix)
+			results[idx] = execResult{Project: proj, Err: runInProject(proj, args, out)}
+			out.Flush()
+			return
+		}
+
+		var buf bytes.Buffer
+		out := newPrefixWriter(&buf, prefix)
+		results[idx] = execResult{Project: proj, Err: runInProject(proj, args, out)}
+		out.Flush()
+
+		outMu.Lock()
+		_, _ = os.Stdout.Write(buf.Bytes())
+		outMu.Unlock()
+	})
+
+	return printExecSummary(cmd, results)
+}
+
+// runInProject runs args in the project's directory, sending stdout and stderr to out
+func runInProject(project scanner.Project, args []string, out io.Writer) error {
+	c := exec.Command(args[0], args[1:]...)
+	c.Dir = project.Path
+	c.Stdout = out
+	c.Stderr = out
+	c.Env = append(os.Environ(),
+		"CHECK_PROJECT_NAME="+project.Name,
+		"CHECK_PROJECT_PATH="+project.Path,
+		"CHECK_PROJECT_CATEGORY="+project.Category,
+	)
+	return c.Run()
+}
+
+// dirtyProjects keeps the projects whose working tree has changes
+func dirtyProjects(ctx context.Context, projects []scanner.Project, concurrency int, timeout time.Duration) []scanner.Project {
+	dirty := make([]bool, len(projects))
+	checker.ForEach(projects, concurrency, func(idx int, proj scanner.Project) {
+		opCtx, cancel := checker.WithTimeout(ctx, timeout)
+		defer cancel()
+		dirty[idx], _ = proj.Repository.IsDirty(opCtx)
+	})
+
+	var kept []scanner.Project
+	for i, project := range projects {
+		if dirty[i] {
+			kept = append(kept, project)
+		}
+	}
+	return kept
+}
+
+func printExecSummary(cmd *cobra.Command, results []execResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	fmt.Printf("\n%d succeeded, %d failed\n", len(results)-failed, failed)
+	if failed == 0 {
+		return nil
+	}
+
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		fmt.Printf("❌ %s/%s: %v\n", result.Project.Category, result.Project.Name, result.Err)
+	}
+	return exitWithCode(cmd, 1)
+}
+
+// prefixWriter prefixes every line written to it.
+// Flush must be called to write a trailing line without newline.
+type prefixWriter struct {
+	w       io.Writer
+	prefix  []byte
+	pending []byte
+}
+
+func newPrefixWriter(w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: []byte(prefix)}
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.pending = append(p.pending, data...)
+	for {
+		i := bytes.IndexByte(p.pending, '\n')
+		if i < 0 {
+			break
+		}
+		if err := p.writeLine(p.pending[:i+1]); err != nil {
+			return len(data), err
+		}
+		p.pending = p.pending[i+1:]
+	}
+	return len(data), nil
+}
+
+// Flush writes any incomplete last line, terminated with a newline
+func (p *prefixWriter) Flush() {
+	if len(p.pending) == 0 {
+		return
+	}
+	_ = p.writeLine(append(p.pending, '\n'))
+	p.pending = nil
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	if _, err := p.w.Write(p.prefix); err != nil {
+		return err
+	}
+	_, err := p.w.Write(line)
+	return err
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/gittest"
+)
+
+// useProjects saves a config listing the repositories in category dev and selects it
+func useProjects(t *testing.T, repos ...*gittest.Repo) {
+	t.Helper()
+	config := "categories:\n  - name: dev\n    projects:\n"
+	for _, r := range repos {
+		config += "      - " + r.Path + "\n"
+	}
+	path := filepath.Join(t.TempDir(), "check-projects.yml")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configPaths = []string{path}
+	t.Cleanup(func() { configPaths = nil })
+}
+
+func TestPrefixWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		flush  bool
+		want   string
+	}{
+		{"one line", []string{"hello\n"}, false, "> hello\n"},
+		{"lines in one write", []string{"one\ntwo\n"}, false, "> one\n> two\n"},
+		{"line across writes", []string{"he", "ll", "o\n"}, false, "> hello\n"},
+		{"lines across writes", []string{"one\ntw", "o\nthr", "ee\n"}, false, "> one\n> two\n> three\n"},
+		{"empty lines", []string{"\n", "\n"}, false, "> \n> \n"},
+		{"empty writes", []string{"", "one", "", "\n"}, false, "> one\n"},
+		{"incomplete line, not flushed", []string{"one\ntwo"}, false, "> one\n"},
+		{"incomplete line, flushed", []string{"one\ntwo"}, true, "> one\n> two\n"},
+		{"flushed after a full line", []string{"one\n"}, true, "> one\n"},
+		{"carriage returns kept", []string{"one\r\n"}, false, "> one\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := newPrefixWriter(&out, "> ")
+			for _, data := range tt.writes {
+				if n, err := w.Write([]byte(data)); n != len(data) || err != nil {
+					t.Fatalf("wrote %d of %d bytes: %v", n, len(data), err)
+				}
+			}
+			if tt.flush {
+				w.Flush()
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecTallies(t *testing.T) {
+	for _, jobs := range []int{1, 3} {
+		t.Run(map[int]string{1: "streamed", 3: "parallel"}[jobs], func(t *testing.T) {
+			repos := []*gittest.Repo{
+				gittest.NewRepo(t).Commit("README.md"),
+				gittest.NewRepo(t).Commit("README.md").WriteFile("fail", ""),
+				gittest.NewRepo(t).Commit("README.md"),
+			}
+			useProjects(t, repos...)
+
+			// Prints a full line then an incomplete one, and fails where the fail file exists
+			var err error
+			out := captureStdout(t, func() {
+				err = runExec(&cobra.Command{}, []string{"sh", "-c", "echo start; printf end; test ! -e fail"}, false, jobs)
+			})
+			var exitErr *exitCodeError
+			if !errors.As(err, &exitErr) || exitErr.code != 1 {
+				t.Errorf("got %v, want exit status 1", err)
+			}
+			if !strings.Contains(out, "\n2 succeeded, 1 failed\n") {
+				t.Errorf("wrong tally:\n%s", out)
+			}
+			if failed := "❌ dev/" + filepath.Base(repos[1].Path) + ": exit status 1"; !strings.Contains(out, failed) {
+				t.Errorf("%q missing:\n%s", failed, out)
+			}
+
+			// Each repository prints its two lines together, prefixed
+			for _, r := range repos {
+				prefix := "[dev/" + filepath.Base(r.Path) + "] "
+				if block := prefix + "start\n" + prefix + "end\n"; !strings.Contains(out, block) {
+					t.Errorf("%q missing:\n%s", block, out)
+				}
+			}
+		})
+	}
+}
+
+func TestExecSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		errs   []error
+		tally  string
+		failed bool
+	}{
+		{"none", nil, "0 succeeded, 0 failed", false},
+		{"all succeeded", []error{nil, nil}, "2 succeeded, 0 failed", false},
+		{"all failed", []error{errors.New("exit status 2"), errors.New("exit status 1")}, "0 succeeded, 2 failed", true},
+		{"mixed", []error{nil, errors.New("exit status 1"), nil}, "2 succeeded, 1 failed", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make([]execResult, len(tt.errs))
+			for i, err := range tt.errs {
+				results[i] = execResult{Err: err}
+				results[i].Project.Category, results[i].Project.Name = "dev", string(rune('a'+i))
+			}
+			var err error
+			out := captureStdout(t, func() { err = printExecSummary(&cobra.Command{}, results) })
+			if !strings.Contains(out, tt.tally+"\n") {
+				t.Errorf("got %q, want %q", out, tt.tally)
+			}
+			if (err != nil) != tt.failed {
+				t.Errorf("got %v, want failure %v", err, tt.failed)
+			}
+			failures := 0
+			for _, err := range tt.errs {
+				if err != nil {
+					failures++
+				}
+			}
+			if got := strings.Count(out, "❌ dev/"); got != failures {
+				t.Errorf("%d failures listed, want %d:\n%s", got, failures, out)
+			}
+		})
+	}
+}
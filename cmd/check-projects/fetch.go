@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/checker"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// fetchResult is the outcome of fetching one project
+type fetchResult struct {
+	Project  scanner.Project
+	NoRemote bool
+	Refs     git.FetchResult // Refs the fetch changed
+	Err      error
+	Auth     *git.RemoteError // Remote that refused the credentials, when that is why it failed
+}
+
+func newFetchCmd() *cobra.Command {
+	var flags git.FetchOptions
+
+	cmd := &cobra.Command{
+		Use:   "fetch",
+		Short: "Fetch the remotes of every repository, without checking their status",
+		Long: `Run git fetch in every configured repository concurrently.
+
+Working trees are never changed: only remote-tracking branches are updated, so that
+the next status run compares against fresh remote state. Handy in a cron job.
+
+Examples:
+  check-projects fetch                   # Fetch everything
+  check-projects fetch --category work   # Only one category
+  check-projects fetch --prune           # Also drop branches deleted on the remote
+  check-projects fetch --all-remotes     # Every remote, not only the default one
+  check-projects fetch --remote upstream # Only the upstream remote
+
+The fetch_prune, fetch_all_remotes and fetch_remote config keys set the defaults.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFetch(cmd, flags)
+		},
+	}
+
+	cmd.Flags().BoolVar(&flags.Prune, "prune", false, "Remove remote-tracking branches that no longer exist on the remote")
+	cmd.Flags().BoolVar(&flags.AllRemotes, "all-remotes", false, "Fetch every remote rather than the default one")
+	cmd.Flags().StringVar(&flags.Remote, "remote", "", "Fetch this remote rather than the default one")
+	cmd.MarkFlagsMutuallyExclusive("all-remotes", "remote")
+	return cmd
+}
+
+func runFetch(cmd *cobra.Command, flags git.FetchOptions) error {
+	cmd.SilenceUsage = true
+
+	cfg, err := config.LoadConfig(configPaths)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := filterCategory(cfg, category); err != nil {
+		return err
+	}
+
+	// Command line flags override config
+	opts := fetchOptions(cfg)
+	opts.Prune = opts.Prune || flags.Prune
+	if flags.AllRemotes || flags.Remote != "" {
+		opts.AllRemotes, opts.Remote = flags.AllRemotes, flags.Remote
+	}
+
+	projects, err := scanner.NewScanner(cfg).ScanAll(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to scan projects: %w", err)
+	}
+	projects = withRepository(projects)
+
+	counter := newFetchCounter(os.Stderr, isTerminal(os.Stderr), len(projects))
+	counter.render()
+
+	timeout := time.Duration(cfg.GitTimeout)
+	var fetched, noRemote, failed int
+	var refs git.FetchResult
+	var authNames []string
+	var authRemotes []*git.RemoteError
+	checker.ForEach(projects, cfg.FetchConcurrency, func(_ int, proj scanner.Project) {
+		result := fetchProject(context.Background(), proj, timeout, opts, fetchRetry(cfg))
+
+		counter.mu.Lock()
+		defer counter.mu.Unlock()
+		switch {
+		case result.Auth != nil:
+			// Listed by host once done
+			failed++
+			authNames = append(authNames, proj.Category+"/"+proj.Name)
+			authRemotes = append(authRemotes, result.Auth)
+		case result.Err != nil:
+			failed++
+			counter.printLine(fmt.Sprintf("❌ %s/%s: %s", proj.Category, proj.Name, fetchErrorSummary(result.Err)))
+		case result.NoRemote:
+			noRemote++
+		default:
+			fetched++
+			refs.Updated += result.Refs.Updated
+			refs.Pruned += result.Refs.Pruned
+		}
+		counter.done++
+		counter.render()
+	})
+	counter.clear()
+
+	summary := fmt.Sprintf("%d fetched", fetched)
+	if fetched > 0 {
+		summary += fmt.Sprintf(" (%s)", refs)
+	}
+	summary += fmt.Sprintf(", %d failed", failed)
+	if noRemote > 0 {
+		summary += fmt.Sprintf(", %d without remote", noRemote)
+	}
+	for _, group := range reporter.GroupAuthFailures(authNames, authRemotes) {
+		slices.Sort(group.Projects) // Listed as they completed
+		fmt.Printf("🔒 %s\n   %s\n", group.Line(), strings.Join(group.Projects, ", "))
+	}
+	fmt.Println(summary)
+
+	if failed > 0 {
+		return exitWithCode(cmd, 1)
+	}
+	return nil
+}
+
+// fetchRetry returns how failed fetches are retried, as configured
+func fetchRetry(cfg *config.Config) checker.Retry {
+	return checker.Retry{Retries: cfg.FetchRetries, Backoff: time.Duration(cfg.FetchBackoff)}
+}
+
+// fetchOptions returns what fetches update, as configured
+func fetchOptions(cfg *config.Config) git.FetchOptions {
+	return git.FetchOptions{Prune: cfg.FetchPrune, AllRemotes: cfg.FetchAllRemotes, Remote: cfg.FetchRemote}
+}
+
+// fetchProject fetches the remotes of a project selected by opts, retrying transient failures;
+// repositories without remotes are skipped
+func fetchProject(ctx context.Context, project scanner.Project, timeout time.Duration, opts git.FetchOptions, retry checker.Retry) fetchResult {
+	result := fetchResult{Project: project}
+
+	remoteCtx, cancel := checker.WithTimeout(ctx, timeout)
+	remotes, err := project.Repository.Remotes(remoteCtx)
+	cancel()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if len(remotes) == 0 {
+		result.NoRemote = true
+		return result
+	}
+
+	result.Refs, result.Err = checker.FetchWithRetry(ctx, project, timeout, opts, retry)
+	result.Auth = checker.AuthFailure(ctx, project, timeout, result.Err)
+	return result
+}
+
+// fetchErrorSummary keeps the most telling line of a git fetch error
+// (git prints hints after the "fatal:" line)
+func fetchErrorSummary(err error) string {
+	lines := strings.Split(err.Error(), "\n")
+	for _, line := range lines {
+		if i := strings.Index(line, "fatal: "); i >= 0 {
+			return strings.TrimSpace(line[i+len("fatal: "):])
+		}
+	}
+	return strings.TrimSpace(lines[0])
+}
+
+// fetchCounter renders an in-place "Fetching n/total" line on terminals,
+// keeping it below the per-repository messages printed meanwhile
+type fetchCounter struct {
+	mu    sync.Mutex
+	out   io.Writer
+	tty   bool
+	total int
+	done  int
+}
+
+func newFetchCounter(out io.Writer, tty bool, total int) *fetchCounter {
+	return &fetchCounter{out: out, tty: tty, total: total}
+}
+
+func (c *fetchCounter) render() {
+	if c.tty {
+		fmt.Fprintf(c.out, "\r\033[KFetching %d/%d…", c.done, c.total)
+	}
+}
+
+// printLine prints a message above the counter line
+func (c *fetchCounter) printLine(line string) {
+	c.clear()
+	fmt.Println(line)
+}
+
+func (c *fetchCounter) clear() {
+	if c.tty {
+		fmt.Fprint(c.out, "\r\033[K")
+	}
+}
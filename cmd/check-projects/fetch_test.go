@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/gittest"
+)
+
+// fetchFixtures build repositories in the states fetch tells apart
+var fetchFixtures = map[string]func(t *testing.T) *gittest.Repo{
+	"up to date": func(t *testing.T) *gittest.Repo {
+		return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll()
+	},
+	"remote ahead": func(t *testing.T) *gittest.Repo {
+		r := gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Commit()
+		r.Git("push", "--quiet", r.Remote, "HEAD:"+gittest.DefaultBranch) // By URL: origin/main is not updated
+		return r
+	},
+	"no remote": func(t *testing.T) *gittest.Repo {
+		return gittest.NewRepo(t).Commit("README.md")
+	},
+	"missing remote": func(t *testing.T) *gittest.Repo {
+		r := gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll()
+		r.Git("remote", "set-url", "origin", filepath.Join(t.TempDir(), "missing.git"))
+		return r
+	},
+}
+
+func TestFetchTallies(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	tests := []struct {
+		name     string
+		fixtures []string
+		want     string
+		fails    bool
+	}{
+		{"up to date", []string{"up to date"}, "1 fetched (already up to date), 0 failed", false},
+		{"updated", []string{"up to date", "remote ahead", "remote ahead"}, "3 fetched (2 ref(s) updated), 0 failed", false},
+		{"without remote", []string{"no remote"}, "0 fetched, 0 failed, 1 without remote", false},
+		{"mixed", []string{"remote ahead", "no remote", "missing remote", "missing remote"}, "1 fetched (1 ref(s) updated), 2 failed, 1 without remote", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := make([]*gittest.Repo, len(tt.fixtures))
+			for i, name := range tt.fixtures {
+				repos[i] = fetchFixtures[name](t)
+			}
+			useProjects(t, repos...)
+
+			var err error
+			out := captureStdout(t, func() { err = runFetch(&cobra.Command{}, git.FetchOptions{}) })
+			var exitErr *exitCodeError
+			if tt.fails != errors.As(err, &exitErr) || (!tt.fails && err != nil) {
+				t.Errorf("got %v, want failure %v", err, tt.fails)
+			}
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			if got := lines[len(lines)-1]; got != tt.want {
+				t.Errorf("got %q, want %q\n%s", got, tt.want, out)
+			}
+			if listed := strings.Count(out, "❌ dev/repo: ") > 0; listed != tt.fails {
+				t.Errorf("failures listed: %v, want %v\n%s", listed, tt.fails, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+func newGenDocsCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "gen-docs <directory>",
+		Short: "Generate man pages and markdown reference for all commands",
+		Long: `Generate the CLI reference for check-projects and all its subcommands.
+
+Examples:
+  check-projects gen-docs ./man                  # man(1) pages
+  check-projects gen-docs ./docs/cli --format markdown`,
+		Args:   cobra.ExactArgs(1),
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return genDocs(cmd.Root(), format, args[0])
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "man", "Documentation format: man|markdown")
+	return cmd
+}
+
+// genDocs writes the reference for root and its subcommands into dir
+func genDocs(root *cobra.Command, format, dir string) error {
+	if format != "man" && format != "markdown" {
+		return fmt.Errorf("unsupported format '%s' (valid: man, markdown)", format)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	// Generated files must not contain terminal colors or build-specific dates
+	root.Long = plainLongDescription()
+	root.DisableAutoGenTag = true
+
+	var err error
+	switch format {
+	case "man":
+		err = doc.GenManTree(root, &doc.GenManHeader{
+			Title:   "CHECK-PROJECTS",
+			Section: "1",
+			Source:  "check-projects " + Version,
+			Manual:  "check-projects manual",
+		}, dir)
+	case "markdown":
+		err = doc.GenMarkdownTree(root, dir)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate %s docs: %w", format, err)
+	}
+
+	fmt.Printf("✅ Generated %s docs in %s\n", format, dir)
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// documented returns the commands gen-docs writes a page for: root and every
+// available subcommand
+func documented(cmd *cobra.Command) []*cobra.Command {
+	commands := []*cobra.Command{cmd}
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() && !sub.IsAdditionalHelpTopicCommand() {
+			commands = append(commands, documented(sub)...)
+		}
+	}
+	return commands
+}
+
+func TestGenDocsListsEveryFlag(t *testing.T) {
+	tests := []struct {
+		format string
+		page   func(cmd *cobra.Command) string // File name of the page of cmd
+		flag   func(flag *pflag.Flag) string   // How the page shows flag
+		short  func(flag *pflag.Flag) string   // How the page shows its shorthand
+	}{
+		{"markdown",
+			func(cmd *cobra.Command) string { return strings.ReplaceAll(cmd.CommandPath(), " ", "_") + ".md" },
+			func(flag *pflag.Flag) string { return "--" + flag.Name },
+			func(flag *pflag.Flag) string { return "-" + flag.Shorthand + ", --" + flag.Name },
+		},
+		{"man",
+			func(cmd *cobra.Command) string { return strings.ReplaceAll(cmd.CommandPath(), " ", "-") + ".1" },
+			func(flag *pflag.Flag) string { return `\fB--` + flag.Name + `\fP` },
+			func(flag *pflag.Flag) string { return `\fB-` + flag.Shorthand + `\fP, \fB--` + flag.Name + `\fP` },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			root := newRootCmd()
+			dir := t.TempDir()
+			if err := genDocs(root, tt.format, dir); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, cmd := range documented(root) {
+				data, err := os.ReadFile(filepath.Join(dir, tt.page(cmd)))
+				if err != nil {
+					t.Errorf("no page for %s: %v", cmd.CommandPath(), err)
+					continue
+				}
+				page := string(data)
+				check := func(flag *pflag.Flag) {
+					if flag.Hidden {
+						return
+					}
+					want := tt.flag(flag)
+					if flag.Shorthand != "" {
+						want = tt.short(flag)
+					}
+					if !strings.Contains(page, want) {
+						t.Errorf("%s: %s missing", tt.page(cmd), want)
+					}
+				}
+				cmd.LocalFlags().VisitAll(check)
+				cmd.InheritedFlags().VisitAll(check)
+			}
+		})
+	}
+}
+
+func TestGenDocsRejectsUnknownFormat(t *testing.T) {
+	if err := genDocs(newRootCmd(), "html", t.TempDir()); err == nil {
+		t.Error("accepted the html format")
+	}
+}
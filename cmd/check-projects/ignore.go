@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+var ignorePattern string
+
+func newIgnoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ignore [name-or-path]",
+		Short: "Add a project or pattern to a category's ignore list",
+		Example: `  check-projects ignore acme-api
+  check-projects ignore acme-api --category work
+  check-projects ignore --pattern 'foo-*' --category work`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runIgnore,
+	}
+	cmd.Flags().StringVar(&ignorePattern, "pattern", "", "Ignore pattern to add instead of a single project (requires --category)")
+	return cmd
+}
+
+func newUnignoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unignore <name-or-pattern>",
+		Short: "Remove a project or pattern from the ignore lists",
+		Example: `  check-projects unignore acme-api
+  check-projects unignore 'foo-*' --category work`,
+		Args: cobra.ExactArgs(1),
+		RunE: runUnignore,
+	}
+}
+
+func runIgnore(cmd *cobra.Command, args []string) error {
+	if (ignorePattern == "") == (len(args) == 0) {
+		return fmt.Errorf("provide either a project name or --pattern")
+	}
+	if ignorePattern != "" && category == "" {
+		return fmt.Errorf("--pattern requires --category")
+	}
+	if ignorePattern != "" {
+		if err := scanner.ValidatePattern(ignorePattern); err != nil {
+			return err
+		}
+	}
+	cmd.SilenceUsage = true
+
+	cfg, err := config.LoadConfig(configPaths)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	categoryName, entry := category, ignorePattern
+	if entry == "" {
+		project, err := resolveConfigProject(cfg, args[0])
+		if err != nil {
+			return err
+		}
+		if project.IgnoredBy != "" {
+			fmt.Printf("✔ '%s' is already ignored by '%s' in category '%s'\n", project.Name, project.IgnoredBy, project.Category)
+			return nil
+		}
+		categoryName, entry = project.Category, project.Name
+	}
+
+	cat := cfg.FindCategory(categoryName)
+	if cat == nil {
+		return fmt.Errorf("category '%s' not found in config", categoryName)
+	}
+	for _, existing := range cat.Ignore {
+		if existing == entry {
+			fmt.Printf("✔ '%s' is already in the ignore list of category '%s'\n", entry, cat.Name)
+			return nil
+		}
+	}
+
+	cat.Ignore = append(cat.Ignore, entry)
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	printIgnoreDiff(cat.Name, []string{entry}, nil)
+	savedMessage("✅ Saved %s\n", cfg.SourceOf(cat))
+	return nil
+}
+
+func runUnignore(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	cfg, err := config.LoadConfig(configPaths)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if category != "" && cfg.FindCategory(category) == nil {
+		return fmt.Errorf("category '%s' not found in config", category)
+	}
+
+	// Remove literal entries first, then fall back to the pattern ignoring the named project
+	removed := removeIgnoreEntry(cfg, category, args[0])
+	if removed == 0 && config.IsGlob(args[0]) {
+		fmt.Printf("✔ '%s' is not in any ignore list\n", args[0])
+		return nil
+	}
+	if removed == 0 {
+		project, err := resolveConfigProject(cfg, args[0])
+		if err != nil {
+			return err
+		}
+		if project.IgnoredBy == "" {
+			fmt.Printf("✔ '%s' is not ignored\n", project.Name)
+			return nil
+		}
+		removed = removeIgnoreEntry(cfg, project.Category, project.IgnoredBy)
+	}
+
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+	savedMessage("✅ Saved %s\n", strings.Join(cfg.Files(), ", "))
+	return nil
+}
+
+// removeIgnoreEntry removes entry from the ignore lists (of one category when
+// categoryName is set), printing the changes, and returns how many were removed
+func removeIgnoreEntry(cfg *config.Config, categoryName, entry string) int {
+	removed := 0
+	for i := range cfg.Categories {
+		cat := &cfg.Categories[i]
+		if categoryName != "" && cat.Name != categoryName {
+			continue
+		}
+
+		var kept []string
+		for _, existing := range cat.Ignore {
+			if existing != entry {
+				kept = append(kept, existing)
+			}
+		}
+		if len(kept) != len(cat.Ignore) {
+			removed += len(cat.Ignore) - len(kept)
+			cat.Ignore = kept
+			printIgnoreDiff(cat.Name, nil, []string{entry})
+		}
+	}
+	return removed
+}
+
+// resolveConfigProject resolves a project name among every project of the config,
+// including ignored ones, restricted to --category when set
+func resolveConfigProject(cfg *config.Config, name string) (scanner.Project, error) {
+	// Scan a filtered copy so cfg itself stays saveable
+	scanCfg := *cfg
+	if err := filterCategory(&scanCfg, category); err != nil {
+		return scanner.Project{}, err
+	}
+
+	s := scanner.NewScanner(&scanCfg)
+	s.IncludeIgnored = true
+	projects, err := s.ScanAll(context.Background())
+	if err != nil {
+		return scanner.Project{}, fmt.Errorf("failed to scan projects: %w", err)
+	}
+
+	return scanner.ResolveProject(projects, name)
+}
+
+// printIgnoreDiff shows added and removed ignore entries of a category
+func printIgnoreDiff(categoryName string, added, removed []string) {
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	fmt.Printf("category '%s' ignore:\n", categoryName)
+	for _, entry := range removed {
+		fmt.Println(red("  - " + entry))
+	}
+	for _, entry := range added {
+		fmt.Println(green("  + " + entry))
+	}
+}
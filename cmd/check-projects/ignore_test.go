@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestIgnoreIsIdempotent(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(tree string) error
+		want string // Printed by the second run
+	}{
+		{"ignore project", func(tree string) error {
+			return runIgnore(&cobra.Command{}, []string{"app"})
+		}, "'app' is already ignored by 'app' in category 'dev'"},
+		{"ignore pattern", func(tree string) error {
+			category, ignorePattern = "dev", "old-*"
+			return runIgnore(&cobra.Command{}, nil)
+		}, "'old-*' is already in the ignore list of category 'dev'"},
+		{"unignore pattern", func(tree string) error {
+			return runUnignore(&cobra.Command{}, []string{"gone-*"})
+		}, "'gone-*' is not in any ignore list"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if out := applyTwice(t, tt.run); !strings.Contains(out, tt.want) {
+				t.Errorf("%q missing from the second run:\n%s", tt.want, out)
+			}
+		})
+	}
+}
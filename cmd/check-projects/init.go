@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/schema"
+)
+
+var initForce bool
+
+func newInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init [root]",
+		Short: "Create a config file with a category scanning a root directory",
+		Long: `Create ~/check-projects.yml (or the first --config file) with the default options
+and one category scanning root (default: the current directory), named after it.
+
+The file starts with a yaml-language-server hint, so that editors complete and
+check it against the published schema (see check-projects schema config).`,
+		Example: `  check-projects init
+  check-projects init ~/dev
+  check-projects init ~/dev --config ./check-projects.yml --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := "."
+			if len(args) == 1 {
+				root = args[0]
+			}
+			cmd.SilenceUsage = true
+
+			path, err := initPath()
+			if err != nil {
+				return err
+			}
+			return runInit(path, root)
+		},
+	}
+
+	cmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing config file")
+
+	return cmd
+}
+
+// initPath returns where init writes the config: the first --config file, or ~/check-projects.yml
+func initPath() (string, error) {
+	if len(configPaths) > 0 {
+		return configPaths[0], nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the home directory: %w", err)
+	}
+	return filepath.Join(home, "check-projects.yml"), nil
+}
+
+// runInit writes to path a config whose single category scans root, or with --dry-run prints it
+func runInit(path, root string) error {
+	absRoot, err := filepath.Abs(config.ExpandPath(root))
+	if err != nil {
+		return fmt.Errorf("invalid root '%s': %w", root, err)
+	}
+	if info, err := os.Stat(absRoot); err != nil || !info.IsDir() {
+		return fmt.Errorf("'%s' is not a directory", absRoot)
+	}
+	if _, err := os.Stat(path); err == nil && !initForce {
+		return fmt.Errorf("%s already exists (use --force to overwrite it)", path)
+	}
+
+	category := config.Category{Name: filepath.Base(absRoot), Root: config.ContractPath(absRoot)}
+	data, err := config.Template(schema.URL(schema.NameConfig), []config.Category{category})
+	if err != nil {
+		return err
+	}
+
+	if dryRunFlag {
+		fmt.Print(string(data))
+		fmt.Printf("Dry run: %s was not written\n", path)
+		return nil
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+	fmt.Printf("✔ Created %s with category '%s' scanning %s\n", path, category.Name, category.Root)
+	return nil
+}
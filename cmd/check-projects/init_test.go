@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/schema"
+)
+
+func TestRunInit(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(t.TempDir(), "check-projects.yml")
+
+	if err := runInit(path, root); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hint := "# yaml-language-server: $schema=" + schema.URL(schema.NameConfig) + "\n"; !strings.HasPrefix(string(data), hint) {
+		t.Errorf("no schema hint on the first line:\n%s", data)
+	}
+
+	cfg, err := config.LoadConfig([]string{path})
+	if err != nil {
+		t.Fatalf("the created config does not load: %v", err)
+	}
+	if len(cfg.Categories) != 1 || cfg.Categories[0].Name != filepath.Base(root) || config.ExpandPath(cfg.Categories[0].Root) != root {
+		t.Errorf("categories %+v, want one scanning %s", cfg.Categories, root)
+	}
+
+	// An existing file is only overwritten with --force
+	if err := runInit(path, root); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("overwrote an existing config: %v", err)
+	}
+	initForce = true
+	t.Cleanup(func() { initForce = false })
+	if err := runInit(path, root); err != nil {
+		t.Errorf("--force: %v", err)
+	}
+}
+
+func TestRunInitDryRun(t *testing.T) {
+	dryRunFlag = true
+	t.Cleanup(func() { dryRunFlag = false })
+
+	path := filepath.Join(t.TempDir(), "check-projects.yml")
+	if err := runInit(path, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote %s", path)
+	}
+}
+
+func TestRunInitRejectsMissingRoot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "check-projects.yml")
+	if err := runInit(path, filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("accepted a missing root")
+	}
+}
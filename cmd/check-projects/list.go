@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// inventoryCategory is a category in the list --output json document
+type inventoryCategory struct {
+	Name     string             `json:"name"`
+	Root     string             `json:"root,omitempty"`
+	Projects []inventoryProject `json:"projects"`
+}
+
+// inventoryProject is a project in the list --output json document
+type inventoryProject struct {
+	Name          string `json:"name"`
+	Path          string `json:"path"`
+	Origin        string `json:"origin"`
+	IgnoredBy     string `json:"ignored_by,omitempty"`
+	SymlinkTarget string `json:"symlink_target,omitempty"`
+}
+
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the projects discovered from the config, without checking their status",
+		Args:  cobra.NoArgs,
+		RunE:  runList,
+	}
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	if outputFmt != reporter.FormatText && outputFmt != reporter.FormatJSON {
+		return fmt.Errorf("list supports --output %s or %s", reporter.FormatText, reporter.FormatJSON)
+	}
+
+	cfg, err := config.LoadConfig(configPaths)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := filterCategory(cfg, category); err != nil {
+		return err
+	}
+
+	s := scanner.NewScanner(cfg)
+	s.IncludeIgnored = true
+	projects, err := s.ScanAll(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to scan projects: %w", err)
+	}
+
+	inventory := buildInventory(cfg, projects)
+
+	if outputFmt == reporter.FormatJSON {
+		data, err := json.MarshalIndent(struct {
+			Categories []inventoryCategory `json:"categories"`
+			Total      int                 `json:"total"`
+		}{inventory, len(projects)}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printInventory(inventory, len(projects))
+	return nil
+}
+
+// buildInventory groups scanned projects under their categories, in config order
+func buildInventory(cfg *config.Config, projects []scanner.Project) []inventoryCategory {
+	inventory := make([]inventoryCategory, 0, len(cfg.Categories))
+	for _, cat := range cfg.Categories {
+		entry := inventoryCategory{Name: cat.Name, Projects: []inventoryProject{}}
+		if cat.Root != "" && len(cat.Projects) == 0 {
+			entry.Root = cat.GetRootPath()
+		}
+
+		for _, project := range projects {
+			if project.Category != cat.Name {
+				continue
+			}
+			entry.Projects = append(entry.Projects, inventoryProject{
+				Name:          project.Name,
+				Path:          project.Path,
+				Origin:        project.Origin,
+				IgnoredBy:     project.IgnoredBy,
+				SymlinkTarget: project.SymlinkTarget,
+			})
+		}
+
+		inventory = append(inventory, entry)
+	}
+	return inventory
+}
+
+func printInventory(inventory []inventoryCategory, total int) {
+	bold := color.New(color.Bold, color.Underline).SprintFunc()
+	faint := color.New(color.Faint).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	ignoredCount := 0
+	for _, cat := range inventory {
+		origin := "explicit"
+		if cat.Root != "" {
+			origin = "scanned from " + cat.Root
+		}
+		fmt.Printf("%s %s\n", bold(cat.Name), faint("("+origin+")"))
+
+		if len(cat.Projects) == 0 {
+			fmt.Println(faint("  no projects found"))
+		}
+
+		for _, project := range cat.Projects {
+			line := fmt.Sprintf("  %s  %s", project.Name, faint(project.Path))
+			if project.SymlinkTarget != "" {
+				line += faint(" -> " + project.SymlinkTarget)
+			}
+			if project.IgnoredBy != "" {
+				ignoredCount++
+				line += " " + yellow(fmt.Sprintf("[ignored by '%s']", project.IgnoredBy))
+			}
+			fmt.Println(line)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%d project(s), %d ignored\n", total, ignoredCount)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+func TestListIsReadOnly(t *testing.T) {
+	for _, format := range []string{reporter.FormatText, reporter.FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			fixtureTree(t)
+			outputFmt = format
+			t.Cleanup(func() { outputFmt = reporter.FormatText })
+			original, err := os.ReadFile(configPaths[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var outputs []string
+			for i := 0; i < 2; i++ {
+				var err error
+				outputs = append(outputs, captureStdout(t, func() {
+					err = runList(&cobra.Command{}, nil)
+				}))
+				if err != nil {
+					t.Fatal(err)
+				}
+				saved, err := os.ReadFile(configPaths[0])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(saved, original) {
+					t.Fatalf("run %d changed the config:\n%s", i+1, saved)
+				}
+			}
+			if !strings.Contains(outputs[0], "app") {
+				t.Errorf("the fixture projects are not listed:\n%s", outputs[0])
+			}
+			if outputs[0] != outputs[1] {
+				t.Errorf("the second run lists other projects:\n%s\nthen:\n%s", outputs[0], outputs[1])
+			}
+		})
+	}
+}
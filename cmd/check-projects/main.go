@@ -1,55 +1,192 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"sync"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/uralys/check-projects/internal/config"
 	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/history"
+	"github.com/uralys/check-projects/internal/notify"
 	"github.com/uralys/check-projects/internal/reporter"
 	"github.com/uralys/check-projects/internal/scanner"
 	"github.com/uralys/check-projects/internal/tui"
 	"github.com/uralys/check-projects/internal/updater"
+	"github.com/uralys/check-projects/internal/webhook"
+	"github.com/uralys/check-projects/pkg/checkprojects"
 )
 
 var (
-	configPath  string
-	verbose     bool
-	category    string
-	useTUI      bool
-	fetchFlag   bool
-	updateFlag  bool
+	configPaths   []string
+	verbose       bool
+	category      string
+	projectName   string
+	useTUI        bool
+	fetchFlag     bool
+	updateFlag    bool
+	noUpdateChk   bool
+	outputFmt     string
+	exitCode      bool
+	readStdin     bool
+	watchInterval time.Duration
+	watchFlag     bool // --watch was given, with or without an interval
+	sinceFlag     string
+	activeOnly    bool
+	staleOnly     bool
+	scanRoot      string
+	gitTimeout    time.Duration
+	dryRunFlag    bool
+	sortFlag      string
+	groupByFlag   string
+	extraIgnore   []string
+	maxDepthFlag  int
+	rescanFlag    bool
+	summaryFlag   bool
+	summaryFmt    string
+	jobsFlag      int
+	notifyFlag    bool
+	notifyAlways  bool
+	webhookURLs   []string
+	snapshotFlag  bool
+	outputFile    string
+	checkRemotes  bool
+	sizesFlag     bool
+	debugFlag     bool
+
+	// logOut receives human chatter (progress, prompts, notices).
+	// It is switched to stderr when a machine-readable output is selected.
+	logOut io.Writer = os.Stdout
+
+	// updateNoticeTimeout bounds how long the report waits for the update check
+	updateNoticeTimeout = 300 * time.Millisecond
+
+	// checkUpdates starts the background update check of a version
+	checkUpdates = updater.CheckForUpdatesAsync
 
 	// Version information (set by ldflags during build)
 	Version   = "dev"
+	Commit    = "unknown"
 	BuildTime = "unknown"
 )
 
 func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// newRootCmd returns the check-projects command, with its flags and subcommands
+func newRootCmd() *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use:   "check-projects",
+		Use:   "check-projects [-]",
 		Short: "Check git status of multiple projects",
 		Long:  buildLongDescription(),
+		Args:  rootArgs,
 		RunE:  run,
+
+		// Errors are printed once by main()
+		SilenceErrors: true,
 	}
 
-	rootCmd.Flags().StringVarP(&configPath, "config", "c", "", "Config file path (default: ./check-projects.yml or ~/check-projects.yml)")
+	rootCmd.PersistentFlags().StringArrayVarP(&configPaths, "config", "c", nil, "Config file path, repeat to merge several files (default: ./check-projects.yml or ~/check-projects.yml)")
+	rootCmd.PersistentFlags().StringVar(&category, "category", "", "Only check projects in this category")
+	rootCmd.PersistentFlags().BoolVar(&dryRunFlag, "dry-run", false, "Print the changes to the config file as a diff instead of saving them")
+	rootCmd.PersistentFlags().StringVarP(&outputFmt, "output", "o", reporter.FormatText, "Output format: "+strings.Join(reporter.Formats, "|"))
+	rootCmd.Flags().StringVar(&outputFile, "output-file", "", "Write the --output report to this file (replaced atomically) instead of stdout")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show all projects including clean ones")
-	rootCmd.Flags().StringVar(&category, "category", "", "Only check projects in this category")
+	rootCmd.Flags().StringVarP(&projectName, "project", "p", "", "Only check this project (name or path) and show its full detail")
 	rootCmd.Flags().BoolVar(&useTUI, "tui", false, "Use interactive TUI mode")
 	rootCmd.Flags().BoolVarP(&fetchFlag, "fetch", "f", false, "Fetch from remote before checking status")
 	rootCmd.Flags().BoolVar(&updateFlag, "update", false, "Check for updates and install if available")
-	rootCmd.Version = fmt.Sprintf("%s (built: %s)", Version, BuildTime)
+	rootCmd.Flags().IntVarP(&jobsFlag, "jobs", "j", 0, "Check at most N repositories at once (default: adapted to each disk's latency)")
+	rootCmd.Flags().BoolVar(&debugFlag, "debug", false, "Print debug messages, such as concurrency changes, on stderr")
+	rootCmd.Flags().DurationVar(&gitTimeout, "timeout", 0, "Limit for each git operation per repository, e.g. 30s (default: git_timeout from config, or none)")
+	rootCmd.Flags().BoolVar(&exitCode, "exit-code", false, "Exit with 1 when projects need attention, 2 when a project errored")
+	rootCmd.Flags().BoolVar(&readStdin, "stdin", false, "Check the repository paths read from stdin (one per line) instead of the config categories; same as '-'")
+	rootCmd.Flags().DurationVar(&watchInterval, "watch", 0, "Re-check projects as they change on disk until interrupted, marking changed projects with Δ; with an interval (e.g. --watch=5m), also re-check all of them that often")
+	rootCmd.Flags().Lookup("watch").NoOptDefVal = "0s"
+	rootCmd.Flags().StringVar(&sinceFlag, "since", "", "Mark projects with commits since a duration ago (7d, 36h) or a date (2024-05-01)")
+	rootCmd.Flags().BoolVar(&activeOnly, "active-only", false, "Only report projects with commits since --since")
+	rootCmd.Flags().BoolVar(&staleOnly, "stale-only", false, "Only report stale projects: clean, without commits for longer than stale_after")
+	rootCmd.Flags().StringVar(&groupByFlag, "group-by", "", "Sections of the report: "+strings.Join(reporter.Groupings, "|")+" (default: display.group_by from config, or category)")
+	rootCmd.Flags().StringVar(&sortFlag, "sort", "", "Order projects within categories: "+strings.Join(reporter.SortOrders, "|")+" (default: display.sort from config, or config)")
+	rootCmd.Flags().BoolVar(&rescanFlag, "rescan", false, "Scan every root rather than starting from the projects found by the previous run")
+	rootCmd.Flags().IntVar(&maxDepthFlag, "max-depth", 0, "Directory levels below each root the scan descends, for this run only (default: max_depth from config, or unlimited)")
+	rootCmd.Flags().StringArrayVar(&extraIgnore, "ignore-pattern", nil, "Also ignore projects matching this pattern in every category, for this run only (repeatable)")
+	rootCmd.Flags().BoolVar(&summaryFlag, "summary", false, "Print a single line of counts (repos: 12 ✔9 ✱2 ↓1) and exit like --exit-code")
+	rootCmd.Flags().StringVar(&summaryFmt, "summary-format", "", "Go template for the --summary line, with .Total .Clean .Changes .Behind .Stale .NoUpstream .NoRemote .Unreachable .AuthRequired .Errors .Unchecked")
+	rootCmd.Flags().BoolVar(&notifyFlag, "notify", false, "Send a desktop notification when projects need attention")
+	rootCmd.Flags().BoolVar(&notifyAlways, "notify-always", false, "Send a desktop notification after every run, clean ones included")
+	rootCmd.Flags().StringArrayVar(&webhookURLs, "webhook", nil, "Also POST the JSON report to this URL after the run (repeatable)")
+	rootCmd.Flags().BoolVar(&changedOnly, "changed-only", false, "Only report projects whose status changed since the previous --changed-only run, print nothing when none did (for cron)")
+	rootCmd.Flags().BoolVar(&snapshotFlag, "snapshot", false, "Record the results in the history, to compare runs with 'check-projects diff'")
+	rootCmd.Flags().BoolVar(&checkRemotes, "check-remotes", false, "Flag repositories whose remote cannot be reached (deleted or moved), results are cached")
+	rootCmd.Flags().BoolVar(&sizesFlag, "sizes", false, "Measure the disk usage of each project and list the largest ones (cached)")
+	addPromptFlags(rootCmd)
+	addBudgetFlags(rootCmd)
+	rootCmd.Flags().StringVar(&scanRoot, "root", "", "Scan this directory for repositories instead of the config categories")
+	rootCmd.Flags().BoolVar(&noUpdateChk, "no-update-check", false, "Skip the background check for a newer release")
+	rootCmd.Flags().StringVar(&cpuProfile, "cpuprofile", "", "Write a CPU profile to this file")
+	rootCmd.Flags().StringVar(&memProfile, "memprofile", "", "Write a heap profile to this file")
+	rootCmd.Flags().StringVar(&traceFile, "trace", "", "Write an execution trace to this file")
+	for _, name := range []string{"cpuprofile", "memprofile", "trace"} {
+		_ = rootCmd.Flags().MarkHidden(name)
+	}
+	_ = rootCmd.RegisterFlagCompletionFunc("category", completeCategories)
+	_ = rootCmd.RegisterFlagCompletionFunc("project", completeProjects)
+	rootCmd.Version = Version
+	rootCmd.SetVersionTemplate(versionTemplate())
+
+	rootCmd.AddCommand(newCompletionCmd())
+	rootCmd.AddCommand(newInitCmd())
+	rootCmd.AddCommand(newListCmd())
+	rootCmd.AddCommand(newStatusCmd())
+	rootCmd.AddCommand(newOpenCmd())
+	rootCmd.AddCommand(newDiffCmd())
+	rootCmd.AddCommand(newStatsCmd())
+	rootCmd.AddCommand(newAddCmd())
+	rootCmd.AddCommand(newIgnoreCmd())
+	rootCmd.AddCommand(newUnignoreCmd())
+	rootCmd.AddCommand(newPruneCmd())
+	rootCmd.AddCommand(newPullCmd())
+	rootCmd.AddCommand(newFetchCmd())
+	rootCmd.AddCommand(newExecCmd())
+	rootCmd.AddCommand(newSchemaCmd())
+	rootCmd.AddCommand(newVersionCmd())
+	rootCmd.AddCommand(newGenDocsCmd())
 
 	// Customize help template with colors
 	rootCmd.SetUsageTemplate(getColoredUsageTemplate())
+	return rootCmd
+}
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
+// exitCodeError carries a process exit code out of a command without printing anything
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// exitWithCode silences cobra's error output and returns an error that exits with code
+func exitWithCode(cmd *cobra.Command, code int) error {
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return &exitCodeError{code: code}
 }
 
 func getColoredUsageTemplate() string {
@@ -102,251 +239,709 @@ func buildLongDescription() string {
 
 	// Note: Update check moved to run() to avoid blocking startup
 
+	description += "\n\n" + purple + "Configuration:" + reset + "\n" + configHelp
+	description += "\n\n" + purple + "Examples:" + reset + "\n" + examplesHelp
+	description += "\n\n" + purple + "Profiling:" + reset + "\n" + profilingHelp
+
 	return description
 }
 
+// plainLongDescription is the Long help without colors or build details, for generated docs
+func plainLongDescription() string {
+	description := "A tool to quickly check the git status of all your projects organized by categories."
+	description += "\n\nConfiguration:\n" + configHelp
+	description += "\n\nExamples:\n" + examplesHelp
+	return description
+}
+
+const configHelp = `  Read from --config, ./check-projects.yml or ~/check-projects.yml (first found).
+
+  categories:                 # list of categories, checked in order
+    - name: work              # category name (used by --category)
+      root: ~/work            # scan this directory recursively for repositories
+      ignore:                 # names or patterns skipped in this category
+        - legacy-*
+    - name: personal
+      projects:               # or list repositories explicitly
+        - ~/src/dotfiles
+  display:
+    hide_clean: true          # hide clean projects unless --verbose
+    hide_ignored: true        # hide ignored projects
+  use_tui_by_default: false   # same as always passing --tui
+  fetch: false                # same as always passing --fetch
+  fetch_concurrency: 10       # parallel fetches
+  git_timeout: 30s            # limit per git operation (same as --timeout)`
+
+const examplesHelp = `  check-projects                          # report projects needing attention
+  check-projects -v                       # include clean projects
+  check-projects --category work --fetch  # fetch, then check one category
+  check-projects -p dotfiles              # full detail for a single project
+  check-projects -o json --exit-code      # machine-readable report for scripts
+  check-projects --tui                    # interactive mode
+  check-projects add ~/src/new --category personal
+  check-projects ignore legacy-api`
+
 func run(cmd *cobra.Command, args []string) error {
+	// Flags are parsed: errors past this point are not usage mistakes
+	cmd.SilenceUsage = true
+	watchFlag = cmd.Flags().Changed("watch")
+
 	// Handle --update flag: blocking check + install prompt
 	if updateFlag {
 		return updater.CheckForUpdates(Version)
 	}
 
+	// Shell prompts read the last recorded run and return at once
+	if promptFlag || promptRefresh {
+		return runPrompt()
+	}
+	if noNewline {
+		return fmt.Errorf("--no-newline requires --prompt")
+	}
+
+	// Validate output format before doing any work
+	if err := reporter.ValidateFormat(outputFmt); err != nil {
+		return err
+	}
+	machineOutput := reporter.IsMachineFormat(outputFmt)
+	if outputFile != "" && !machineOutput {
+		return fmt.Errorf("--output-file requires a machine-readable --output (%s)", strings.Join(reporter.Formats[1:], ", "))
+	}
+	if machineOutput {
+		if useTUI {
+			return fmt.Errorf("--tui cannot be combined with --output %s", outputFmt)
+		}
+		logOut = os.Stderr
+	}
+
+	// A --summary line is the only output, handled like machine-readable ones
+	if summaryFmt != "" && !summaryFlag {
+		return fmt.Errorf("--summary-format requires --summary")
+	}
+	if summaryFlag {
+		switch {
+		case machineOutput:
+			return fmt.Errorf("--summary cannot be combined with --output %s", outputFmt)
+		case useTUI:
+			return fmt.Errorf("--summary cannot be combined with --tui")
+		case projectName != "":
+			return fmt.Errorf("--summary cannot be combined with --project")
+		case watchFlag:
+			return fmt.Errorf("--summary cannot be combined with --watch")
+		}
+		if summaryFmt != "" {
+			if _, err := reporter.ParseSummaryFormat(summaryFmt); err != nil {
+				return err
+			}
+		}
+		machineOutput = true
+		logOut = os.Stderr
+	}
+
+	// Repository paths piped on stdin replace the config categories
+	fromStdin := readStdin || len(args) == 1
+	if fromStdin {
+		if useTUI {
+			return fmt.Errorf("--tui cannot be combined with --stdin")
+		}
+		if category != "" {
+			return fmt.Errorf("--category cannot be combined with --stdin")
+		}
+		if scanRoot != "" {
+			return fmt.Errorf("--root cannot be combined with --stdin")
+		}
+	}
+	if scanRoot != "" && category != "" {
+		return fmt.Errorf("--category cannot be combined with --root")
+	}
+	if sinceFlag != "" {
+		if _, err := config.ParseSince(sinceFlag, time.Now()); err != nil {
+			return err
+		}
+	} else if activeOnly {
+		return fmt.Errorf("--active-only requires --since")
+	}
+	if jobsFlag < 0 {
+		return fmt.Errorf("--jobs must be positive")
+	}
+	if maxDepthFlag < 0 {
+		return fmt.Errorf("--max-depth must be positive")
+	}
+	if sortFlag != "" {
+		if err := reporter.ValidateSort(sortFlag); err != nil {
+			return err
+		}
+	}
+	if err := reporter.ValidateGroupBy(groupByFlag); err != nil {
+		return err
+	}
+	for _, pattern := range extraIgnore {
+		if err := scanner.ValidatePattern(pattern); err != nil {
+			return err
+		}
+	}
+	if notifyFlag || notifyAlways {
+		switch {
+		case useTUI:
+			return fmt.Errorf("--notify cannot be combined with --tui")
+		case watchFlag:
+			return fmt.Errorf("--notify cannot be combined with --watch")
+		}
+	}
+	if len(webhookURLs) > 0 && useTUI {
+		return fmt.Errorf("--webhook cannot be combined with --tui")
+	}
+	if snapshotFlag {
+		switch {
+		case useTUI:
+			return fmt.Errorf("--snapshot cannot be combined with --tui")
+		case watchFlag:
+			return fmt.Errorf("--snapshot cannot be combined with --watch")
+		}
+	}
+	if changedOnly {
+		switch {
+		case useTUI:
+			return fmt.Errorf("--changed-only cannot be combined with --tui")
+		case watchFlag:
+			return fmt.Errorf("--changed-only cannot be combined with --watch")
+		case summaryFlag:
+			return fmt.Errorf("--changed-only cannot be combined with --summary")
+		}
+	}
+	if maxDuration > 0 {
+		switch {
+		case useTUI:
+			return fmt.Errorf("--max-duration cannot be combined with --tui")
+		case watchFlag:
+			return fmt.Errorf("--max-duration cannot be combined with --watch")
+		}
+	}
+	if watchFlag {
+		if watchInterval < 0 {
+			return fmt.Errorf("--watch interval cannot be negative, got %s", watchInterval)
+		}
+		if exitCode {
+			return fmt.Errorf("--watch cannot be combined with --exit-code")
+		}
+	}
+
 	// Check for updates in background (truly non-blocking)
-	updateCh := updater.CheckForUpdatesAsync(Version)
+	var updateCh <-chan *updater.UpdateResult
+	if !noUpdateChk {
+		updateCh = checkUpdates(Version)
+	}
 
-	// Load configuration
-	cfg, err := config.LoadConfig(configPath)
+	// Load configuration (optional with --stdin and --root, where only display options are used)
+	cfg, err := config.LoadConfig(configPaths)
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		noConfig := errors.Is(err, config.ErrNoConfig)
+		switch {
+		case noConfig && (fromStdin || scanRoot != ""):
+			cfg = config.DefaultConfig()
+		case noConfig && isTerminal(os.Stdin) && isTerminal(os.Stdout):
+			// First run in a terminal: have a look around instead of failing
+			cfg = config.DefaultConfig()
+			scanRoot = "."
+			fmt.Fprintln(logOut, "No config found — scanning . ; run 'check-projects init' to create one")
+		default:
+			return fmt.Errorf("failed to load config: %w", err)
+		}
 	}
 
-	// Filter by category if specified
-	if category != "" {
-		var filteredCategories []config.Category
-		for _, cat := range cfg.Categories {
-			if cat.Name == category {
-				filteredCategories = append(filteredCategories, cat)
-			}
+	if scanRoot != "" {
+		if err := useScanRoot(cfg, scanRoot); err != nil {
+			return err
 		}
-		if len(filteredCategories) == 0 {
-			return fmt.Errorf("category '%s' not found in config", category)
+	}
+
+	if fromStdin {
+		stdinCat, err := readStdinCategory(os.Stdin, os.Stderr)
+		if err != nil {
+			return err
 		}
-		cfg.Categories = filteredCategories
-		cfg.IsFiltered = true // Mark as filtered to prevent saving
+		cfg.Categories = []config.Category{stdinCat}
+		cfg.IsFiltered = true // Never save the synthetic category
+		cfg.UseTUIByDefault = false
 	}
 
+	// Filter by category if specified
+	if err := filterCategory(cfg, category); err != nil {
+		return err
+	}
+
+	hooks := runWebhooks(cfg)
+	if err := validateWebhooks(hooks); err != nil {
+		return err
+	}
+	cfg.ExtraIgnore = extraIgnore
+	cfg.MaxDepthOverride = maxDepthFlag
+
 	// Determine if we should use TUI mode
-	// Command line flag overrides config
-	shouldUseTUI := useTUI || cfg.UseTUIByDefault
+	// Command line flag overrides config, machine-readable output disables it
+	shouldUseTUI := (useTUI || cfg.UseTUIByDefault) && !machineOutput
 
 	// Determine if we should fetch
 	// Command line flag overrides config
 	shouldFetch := fetchFlag || cfg.Fetch
 
-	// Use TUI mode if enabled
-	if shouldUseTUI {
-		return tui.Run(cfg, Version)
+	// Project ordering
+	// Command line flag overrides config
+	if sortFlag != "" {
+		cfg.Display.Sort = sortFlag
+	} else if err := reporter.ValidateSort(cfg.Display.Sort); err != nil {
+		return fmt.Errorf("display.sort in config: %w", err)
+	}
+	if groupByFlag != "" {
+		cfg.Display.GroupBy = groupByFlag
+	} else if err := reporter.ValidateGroupBy(cfg.Display.GroupBy); err != nil {
+		return fmt.Errorf("display.group_by in config: %w", err)
+	}
+	if err := config.ValidateBare(cfg.Display.Bare); err != nil {
+		return fmt.Errorf("display.bare in config: %w", err)
+	}
+	if err := config.ValidateScanSort(cfg.Scan.Sort); err != nil {
+		return fmt.Errorf("scan.sort in config: %w", err)
+	}
+	if err := config.Patterns(cfg.Scan.SkipDirs).Validate(); err != nil {
+		return fmt.Errorf("scan.skip_dirs in config: %w", err)
+	}
+	for _, category := range cfg.Categories {
+		if err := category.ExpectedEmail.Validate(); err != nil {
+			return fmt.Errorf("expected_email of category '%s' in config: %w", category.Name, err)
+		}
+		if err := config.Patterns(category.SkipDirs).Validate(); err != nil {
+			return fmt.Errorf("skip_dirs of category '%s' in config: %w", category.Name, err)
+		}
 	}
 
-	// Scan for projects
-	fmt.Println("Processing projects...")
-	s := scanner.NewScanner(cfg)
-	projects, err := s.ScanAll()
+	// Per-repository limits for git operations
+	// Command line flag overrides config
+	opts := checkprojects.Options{
+		Timeout:          time.Duration(cfg.GitTimeout),
+		Concurrency:      jobsFlag,
+		Fetch:            shouldFetch,
+		FetchRetry:       fetchRetry(cfg),
+		FetchOptions:     fetchOptions(cfg),
+		LastCommits:      sinceFlag != "" || cfg.Display.Sort == reporter.SortAge,
+		DiffStats:        verbose && outputFmt == reporter.FormatText,
+		StaleAfter:       staleAfterOption(cfg),
+		LocalBranches:    localBranchesOption(cfg),
+		OffDefaultBranch: offDefaultBranchOption(cfg),
+		MergedBranches:   mergedBranchesOption(cfg),
+		CompareRemotes:   compareRemotesOption(cfg),
+		UnpushedTags:     unpushedTagsOption(cfg),
+		ExpectedEmail:    expectedEmailOption(cfg),
+	}
+	if staleOnly && opts.StaleAfter == nil {
+		return fmt.Errorf("--stale-only requires stale_after in config")
+	}
+	if gitTimeout > 0 {
+		opts.Timeout = gitTimeout
+	}
+	// Fetching is bound by the network rather than the disk
+	if shouldFetch && jobsFlag == 0 {
+		opts.Concurrency = cfg.FetchConcurrency
+	}
+	if debugFlag {
+		opts.Logf = func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, "debug: "+format+"\n", args...)
+		}
+	}
+	// Ctrl+C cancels the running git commands (a second one exits immediately)
+	ctx, stopInterrupt := interruptContext()
+	defer stopInterrupt()
+
+	// Profile the scan and checks when requested (--cpuprofile, --memprofile, --trace)
+	stopProfiling, err := startProfiling()
 	if err != nil {
-		return fmt.Errorf("failed to scan projects: %w", err)
-	}
-
-	// Fetch from remote if enabled
-	if shouldFetch {
-		fetchProjects(projects, cfg.FetchConcurrency)
-	}
-
-	// Check git status for each project concurrently
-	results := make([]reporter.ProjectResult, len(projects))
-	var wg sync.WaitGroup
-	sem := make(chan struct{}, 10) // Limit concurrency to 10
-
-	for i, project := range projects {
-		wg.Add(1)
-		go func(idx int, proj scanner.Project) {
-			defer wg.Done()
-			sem <- struct{}{}        // Acquire semaphore
-			defer func() { <-sem }() // Release semaphore
-
-			if proj.Repository == nil {
-				results[idx] = reporter.ProjectResult{
-					Name:          proj.Name,
-					Status:        &git.Status{Type: git.StatusBrokenSymlink, Symbol: "🔗 ✗"},
-					Category:      proj.Category,
-					IsSymlink:     proj.IsSymlink,
-					SymlinkTarget: proj.SymlinkTarget,
-				}
-				return
-			}
+		return err
+	}
+	defer stopProfiling()
 
-			status, err := proj.Repository.GetStatus()
-			if err != nil {
-				// Handle error by marking as error status
-				status = &git.Status{
-					Type:    git.StatusError,
-					Message: err.Error(),
-					Symbol:  "❌",
-				}
-			}
+	// Use TUI mode if enabled (not for a single project, which has its own detail view)
+	if shouldUseTUI && projectName == "" {
+		return tui.Run(ctx, cfg, Version, opts, rescanFlag, watchFlag, watchInterval)
+	}
 
-			results[idx] = reporter.ProjectResult{
-				Name:          proj.Name,
-				Status:        status,
-				Category:      proj.Category,
-				IsSymlink:     proj.IsSymlink,
-				SymlinkTarget: proj.SymlinkTarget,
-			}
-		}(i, project)
+	// Re-check as projects change until interrupted
+	if watchFlag {
+		return runWatch(ctx, cfg, opts, hooks, machineOutput, updateCh)
+	}
+
+	// --max-duration bounds the scan and the checks; its end is not an interruption
+	ctx, cancelBudget := withBudget(ctx)
+	defer cancelBudget()
+
+	// Print the categories of the text report as soon as their projects are checked
+	var stream *reporter.Streamer
+	if canStream(cfg, machineOutput) {
+		stream = reporter.NewReporter(cfg, verbose).NewStreamer(cfg.Display.Sort)
+	}
+	projects, results, dead, err := checkProjects(ctx, cfg, opts, machineOutput, stream)
+	if err != nil {
+		return err
 	}
 
-	wg.Wait()
+	// Only report what changed since the previous run, recording this one for the next
+	allResults := results
+	var disappeared []history.Change
+	if changedOnly && !truncated(ctx, results) && ctx.Err() == nil {
+		path, err := changedOnlyPath(cfg, fromStdin)
+		if err != nil {
+			return err
+		}
+		var first bool
+		projects, results, disappeared, first, err = onlyChanged(path, projects, results)
+		if err != nil {
+			return err
+		}
+		if first {
+			fmt.Fprintln(logOut, "No previous run to compare with: reporting every project")
+		} else if len(results) == 0 && len(disappeared) == 0 {
+			// Nothing at all is printed, so that cron sends no mail
+			recordRun(cfg, allResults)
+			return nil
+		}
+	}
 
-	// Generate report first (show all categories)
-	rep := reporter.NewReporter(cfg, verbose)
-	rep.Report(results)
+	if stream != nil {
+		stream.Finish(reporter.SortResults(results, cfg.Display.Sort))
+		printTimeoutSummary(results, opts.Timeout)
+	} else if err := reportResults(cfg, results, opts.Timeout, machineOutput); err != nil {
+		return err
+	}
+	printDisappeared(disappeared)
+	if !summaryFlag {
+		printDeadEntries(dead)
+	}
+
+	// Report what completed before an interruption or the end of the budget, and nothing more
+	if truncated(ctx, results) {
+		return exitWithCode(cmd, exitBudgetExceeded)
+	}
+	if ctx.Err() != nil {
+		return exitWithCode(cmd, 130)
+	}
+	if notifyFlag || notifyAlways {
+		notifyResults(notify.New(), results, notifyAlways)
+	}
+	deliverWebhooks(ctx, webhook.NewSender(), hooks, results)
+	recordRun(cfg, allResults)
+	// Prompts below read stdin: let Ctrl+C terminate them as usual. Stopping cancels
+	// ctx, which the upstream setup below must outlive.
+	stopInterrupt()
+	ctx = context.WithoutCancel(ctx)
 
 	// Handle repositories without upstream after the report
-	if err := handleNoUpstream(cfg, projects, results); err != nil {
-		return err
+	// (interactive, text output only, and stdin is not available for answers with --stdin)
+	if !machineOutput && !fromStdin {
+		prompt := newLinePrompter(os.Stdin, os.Stdout)
+		if err := handleNoUpstream(ctx, prompt, cfg, projects, results, opts); err != nil {
+			return err
+		}
+		if !summaryFlag {
+			if err := handleDeadEntries(prompt, cfg, dead); err != nil {
+				return err
+			}
+		}
 	}
 
-	// Check if update is available (non-blocking read)
-	select {
-	case result := <-updateCh:
-		updater.PrintUpdateNotice(result)
-	default:
-		// Update check still in progress, skip notification
+	// Check if update is available, giving the background check a short grace period
+	updater.PrintUpdateNotice(logOut, updater.WaitForUpdate(updateCh, updateNoticeTimeout))
+
+	// Reflect repository state in the exit code when requested
+	if exitCode || summaryFlag {
+		if code := reporter.ExitCode(results); code != reporter.ExitClean {
+			return exitWithCode(cmd, code)
+		}
 	}
 
 	return nil
 }
 
-func fetchProjects(projects []scanner.Project, concurrency int) {
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	sem := make(chan struct{}, concurrency)
-
-	total := len(projects)
-	completed := 0
-
-	printProgress := func() {
-		barWidth := 20
-		progress := float64(completed) / float64(total)
-		filled := int(progress * float64(barWidth))
-
-		bar := ""
-		for i := 0; i < barWidth; i++ {
-			if i < filled {
-				bar += "█"
-			} else {
-				bar += "░"
+// checkProjects discovers the configured projects (narrowed by --project) and checks
+// them as set by opts, with an in-place progress line on terminals. Results are passed
+// to stream when set. It also returns the entries of projects lists that listed no
+// repository, to report after the results.
+func checkProjects(ctx context.Context, cfg *config.Config, opts checkprojects.Options, machineOutput bool, stream *reporter.Streamer) ([]scanner.Project, []reporter.ProjectResult, []scanner.Warning, error) {
+	// The progress line is rendered on stderr so it never mixes with the report
+	prog := newProgress(logOut, false)
+	switch {
+	case summaryFlag:
+		prog = newProgress(io.Discard, false) // The summary line is the only output
+	case changedOnly && !isTerminal(os.Stdout):
+		prog = newProgress(io.Discard, false) // Runs without changes print nothing
+	case !machineOutput && isTerminal(os.Stdout):
+		prog = newProgress(os.Stderr, true)
+	}
+	prog.scanning()
+	scanCtx, cancelScan := scanContext(ctx)
+	defer cancelScan()
+	s := scanner.NewCachedScanner(cfg, rescanFlag)
+	s.Unversioned = true
+	projects, err := s.ScanAll(scanCtx)
+	if err != nil && scanCtx.Err() == nil {
+		return nil, nil, nil, fmt.Errorf("failed to scan projects: %w", err)
+	}
+	warnings := s.Warnings
+	scanCut := budgetExceeded(scanCtx)
+
+	// Narrow down to a single project if requested, scanning the cached roots again
+	// for a project that appeared since
+	var rescan <-chan rescanResult
+	if projectName != "" {
+		project, err := scanner.ResolveProject(projects, projectName)
+		if err != nil && s.FromCache {
+			if projects, warnings, err = s.Revalidate(scanCtx); err == nil {
+				project, err = scanner.ResolveProject(projects, projectName)
 			}
 		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		projects = []scanner.Project{project}
+	} else if s.FromCache {
+		rescan = revalidate(scanCtx, s)
+	}
 
-		fmt.Printf("\rFetching [%s] %d/%d projects", bar, completed, total)
+	// Fetch (if enabled) and check each project concurrently, as results stream in
+	if stream != nil {
+		stream.Before = prog.clear
+	}
+	projects, results, rescanned := checkAll(ctx, projects, rescan, opts, prog, stream)
+	if rescanned != nil {
+		warnings = rescanned
+	}
+	// Unless interrupted, what was not checked is left out by the budget
+	unchecked := 0
+	if ctx.Err() == nil || budgetExceeded(ctx) {
+		unchecked = markUnchecked(projects, results)
 	}
 
-	printProgress()
+	// Flag dead or moved remotes (network-heavy, hence opt-in)
+	// Command line flag overrides config
+	if (checkRemotes || cfg.CheckRemotes) && ctx.Err() == nil {
+		prog.checkingRemotes()
+		markUnreachableRemotes(ctx, cfg, projects, results)
+	}
+	if sizesFlag && ctx.Err() == nil {
+		prog.measuringSizes()
+		measureSizes(ctx, projects, results)
+	}
 
-	for _, project := range projects {
-		wg.Add(1)
-		go func(proj scanner.Project) {
-			defer wg.Done()
-			sem <- struct{}{}        // Acquire semaphore
-			defer func() { <-sem }() // Release semaphore
+	// Mark recently active projects (relative cutoffs move with each --watch iteration)
+	if sinceFlag != "" {
+		cutoff, err := config.ParseSince(sinceFlag, time.Now())
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		markActive(results, cutoff)
+		if activeOnly {
+			projects, results = onlyActive(projects, results)
+		}
+	}
+	if staleOnly {
+		projects, results = onlyStale(projects, results)
+	}
+	prog.clear()
 
-			if proj.Repository != nil {
-				_ = proj.Repository.Fetch()
-			}
+	// Directories that could not be read hide projects: always say so (dead entries are listed after the report)
+	dead := deadEntries(cfg, warnings)
+	for _, warning := range warnings {
+		if warning.Dead {
+			continue
+		}
+		if verbose || warning.Denied {
+			fmt.Fprintf(logOut, "⚠ %s\n", warning)
+		}
+	}
+
+	if scanCut {
+		fmt.Fprintf(os.Stderr, "⚠ time budget: scanning stopped at half of --max-duration, some projects may be missing\n")
+	}
 
-			mu.Lock()
-			completed++
-			printProgress()
-			mu.Unlock()
-		}(project)
+	// Say how many projects the budget left unchecked, or keep what completed before an interruption
+	printUnchecked(unchecked, len(results))
+	if ctx.Err() != nil && !budgetExceeded(ctx) {
+		total := len(projects)
+		projects, results = checkedOnly(projects, results)
+		fmt.Fprintf(os.Stderr, "⚠ Interrupted — %d/%d checked\n", len(results), total)
 	}
 
-	wg.Wait()
-	fmt.Println() // New line after progress bar completes
+	return projects, results, dead, nil
 }
 
-func handleNoUpstream(cfg *config.Config, projects []scanner.Project, results []reporter.ProjectResult) error {
-	for i, result := range results {
-		if result.Status.Type == git.StatusNoUpstream {
-			branchName := "unknown"
-			if branch, err := projects[i].Repository.GetCurrentBranch(); err == nil {
-				branchName = branch
-			}
-			fmt.Printf("\n🧚🏻‍♀️ Repository '%s' has no upstream configured for branch '\033[95m%s\033[0m'.\n", result.Name, branchName)
-			fmt.Printf("\033[38;5;208mSet upstream tracking locally?\033[0m \033[92m(Y/n):\033[0m ")
+// recordRun keeps the results for later commands: --prompt (full runs only) and
+// the history (--snapshot)
+func recordRun(cfg *config.Config, results []reporter.ProjectResult) {
+	if isFullRun(cfg) {
+		savePromptState(results)
+	}
+	if snapshotFlag {
+		if err := saveSnapshot(cfg, results); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠ Failed to save the snapshot: %v\n", err)
+		}
+	}
+}
 
-			var response string
-			if _, err := fmt.Scanln(&response); err != nil {
-				// Enter pressed without input - default to yes
-				response = "y"
-			}
+// checkedOnly keeps the projects whose status was checked, with their results at the same indexes
+func checkedOnly(projects []scanner.Project, results []reporter.ProjectResult) ([]scanner.Project, []reporter.ProjectResult) {
+	return keepResults(projects, results, func(result reporter.ProjectResult) bool {
+		return result.Status != nil
+	})
+}
 
-			if response == "n" || response == "N" {
-				continue
-			}
+// canStream reports whether the report can be printed as results come in: the text
+// report of categories, no result depending on the others or on checks run after them
+func canStream(cfg *config.Config, machineOutput bool) bool {
+	return outputFmt == reporter.FormatText && !machineOutput && outputFile == "" &&
+		!summaryFlag && projectName == "" && !changedOnly &&
+		sinceFlag == "" && !staleOnly && !checkRemotes && !cfg.CheckRemotes && !sizesFlag &&
+		(cfg.Display.GroupBy == "" || cfg.Display.GroupBy == reporter.GroupByCategory)
+}
 
-			// Try to set upstream locally
-			if err := projects[i].Repository.SetUpstream(); err != nil {
-				// Failed - prompt user to ignore
-				fmt.Printf("❌ Failed to set upstream: %v\n", err)
-				fmt.Printf("Ignore this project? (y/n): ")
-
-				var response string
-				if _, err := fmt.Scanln(&response); err != nil {
-					continue
-				}
-
-				if response == "y" || response == "Y" {
-					// Check if config is filtered (--category used)
-					if cfg.IsFiltered {
-						fmt.Printf("⚠ Cannot ignore project when using --category flag.\n")
-						fmt.Printf("   Run without --category to ignore projects.\n")
-						continue
-					}
-
-					// Add to ignored list of the project's category
-					projectName := result.Name
-					categoryName := result.Category
-
-					// Find the category and add to its ignore list
-					for j := range cfg.Categories {
-						if cfg.Categories[j].Name == categoryName {
-							cfg.Categories[j].Ignore = append(cfg.Categories[j].Ignore, projectName)
-							break
-						}
-					}
-
-					// Save config
-					if err := config.SaveConfig(cfg); err != nil {
-						fmt.Printf("❌ Failed to save config: %v\n", err)
-						continue
-					}
-
-					fmt.Printf("✅ Project '%s' added to ignore list in category '%s' in %s\n", projectName, categoryName, cfg.ConfigPath)
-					results[i].Status.Type = git.StatusIgnored
-				} else {
-					fmt.Printf("Skipped.\n")
-				}
-			} else {
-				// Success - re-check status
-				newStatus, err := projects[i].Repository.GetStatus()
-				if err != nil {
-					return fmt.Errorf("failed to get updated status: %w", err)
-				}
-				results[i].Status = newStatus
-				fmt.Printf("✅ Upstream configured \033[92msuccessfully\033[0m\n")
-			}
+// reportResults prints the report in the selected output format
+func reportResults(cfg *config.Config, results []reporter.ProjectResult, timeout time.Duration, machineOutput bool) error {
+	if summaryFlag {
+		return printSummary(results)
+	}
+
+	if activeOnly && len(results) == 0 && !machineOutput {
+		fmt.Printf("No project has commits since %s\n", sinceFlag)
+		return nil
+	}
+	if staleOnly && len(results) == 0 && !machineOutput {
+		fmt.Println("No project is stale")
+		return nil
+	}
+
+	var out io.Writer = os.Stdout
+	var buf bytes.Buffer
+	if outputFile != "" {
+		out = &buf
+	}
+
+	var rep reporter.ResultReporter
+	if projectName != "" && !machineOutput {
+		rep = reporter.NewDetailReporter(out)
+	} else {
+		var err error
+		// Changes to clean are changes too
+		rep, err = reporter.New(outputFmt, cfg, verbose || changedOnly, out)
+		if err != nil {
+			return err
 		}
 	}
+	rep.Report(reporter.SortResults(results, cfg.Display.Sort))
+	printTimeoutSummary(results, timeout)
+
+	if outputFile != "" {
+		return writeFileAtomic(outputFile, buf.Bytes())
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data through a temporary file renamed over it,
+// so that readers (such as node_exporter's textfile collector) never see a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
 	return nil
 }
+
+// useScanRoot replaces the categories of cfg with a single one scanning root
+func useScanRoot(cfg *config.Config, root string) error {
+	abs, err := filepath.Abs(config.ExpandPath(root))
+	if err != nil {
+		return fmt.Errorf("invalid root %s: %w", root, err)
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return fmt.Errorf("root %s is not a directory", root)
+	}
+
+	cfg.Categories = []config.Category{{Name: filepath.Base(abs), Root: abs}}
+	cfg.IsFiltered = true // Never save the synthetic category
+	return nil
+}
+
+// filterCategory keeps only the named category in cfg (no-op when name is empty)
+func filterCategory(cfg *config.Config, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	var filteredCategories []config.Category
+	for _, cat := range cfg.Categories {
+		if cat.Name == name {
+			filteredCategories = append(filteredCategories, cat)
+		}
+	}
+	if len(filteredCategories) == 0 {
+		return fmt.Errorf("category '%s' not found in config", name)
+	}
+	cfg.Categories = filteredCategories
+	cfg.IsFiltered = true // Mark as filtered to prevent saving
+	return nil
+}
+
+// printTimeoutSummary reports repositories whose git operations timed out
+func printTimeoutSummary(results []reporter.ProjectResult, limit time.Duration) {
+	timedOut := 0
+	for _, result := range results {
+		if result.Status.Type == git.StatusTimeout {
+			timedOut++
+		}
+	}
+	if timedOut == 0 {
+		return
+	}
+
+	fmt.Fprintf(logOut, "\n⌛ %d repositor%s timed out after %s, raise the limit with --timeout or git_timeout\n",
+		timedOut, pluralY(timedOut), limit)
+}
+
+// printSummary prints the --summary line, using --summary-format when set
+func printSummary(results []reporter.ProjectResult) error {
+	summary := reporter.Summarize(results)
+	if summaryFmt == "" {
+		fmt.Println(summary.Line())
+		return nil
+	}
+
+	tmpl, err := reporter.ParseSummaryFormat(summaryFmt)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(os.Stdout, summary); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
+
+// pluralY returns the suffix for "repository" given a count
+func pluralY(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uralys/check-projects/internal/notify"
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+// notifiedNames is the number of projects named in a notification
+const notifiedNames = 3
+
+// notifyResults sends a desktop notification when projects need attention
+// (or for every run with always), warning on logOut when it cannot be shown
+func notifyResults(n notify.Notifier, results []reporter.ProjectResult, always bool) {
+	message, ok := notificationMessage(results, always)
+	if !ok {
+		return
+	}
+	if err := n.Notify("check-projects", message); err != nil {
+		fmt.Fprintf(logOut, "⚠ Failed to send the notification: %v\n", err)
+	}
+}
+
+// notificationMessage summarizes the results needing attention, e.g.
+// "4 repos need attention — work/api, oss/tool, work/web, …".
+// Without such results, it reports false unless always is set.
+func notificationMessage(results []reporter.ProjectResult, always bool) (string, bool) {
+	var names []string
+	for _, result := range results {
+		if !reporter.IsClean(result) {
+			names = append(names, result.Category+"/"+result.Name)
+		}
+	}
+
+	switch {
+	case len(names) == 0 && !always:
+		return "", false
+	case len(names) == 0:
+		return fmt.Sprintf("No repo needs attention (%d checked)", len(results)), true
+	}
+
+	shown := names[:min(len(names), notifiedNames)]
+	list := strings.Join(shown, ", ")
+	if len(names) > len(shown) {
+		list += ", …"
+	}
+	if len(names) == 1 {
+		return fmt.Sprintf("1 repo needs attention — %s", list), true
+	}
+	return fmt.Sprintf("%d repos need attention — %s", len(names), list), true
+}
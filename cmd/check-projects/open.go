@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/checker"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+func newOpenCmd() *cobra.Command {
+	var web bool
+
+	cmd := &cobra.Command{
+		Use:   "open <name-or-path>",
+		Short: "Print the path of a project, or open its remote in a browser with --web",
+		Long: `Resolve a project by name like --project does and print its absolute path,
+and nothing else, so that it can be used from scripts and shell aliases.
+
+Examples:
+  cd "$(check-projects open acme-api)"
+  check-projects open acme-api --category work   # Disambiguate between categories
+  check-projects open acme-api --web             # Open the repository page`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeProjects(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runOpen(cmd, args[0], web)
+		},
+	}
+
+	cmd.Flags().BoolVar(&web, "web", false, "Open the web page of the repository remote (origin, or the first one)")
+	return cmd
+}
+
+func runOpen(cmd *cobra.Command, name string, web bool) error {
+	cmd.SilenceUsage = true
+
+	cfg, err := config.LoadConfig(configPaths)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := filterCategory(cfg, category); err != nil {
+		return err
+	}
+
+	projects, err := scanner.NewScanner(cfg).ScanAll(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to scan projects: %w", err)
+	}
+	project, err := scanner.ResolveProject(projects, name)
+	if err != nil {
+		return err
+	}
+
+	if !web {
+		fmt.Println(project.Path)
+		return nil
+	}
+
+	if project.Repository == nil {
+		return fmt.Errorf("%s is a broken symlink", project.Name)
+	}
+	repo, ok := project.Repository.(*git.Repository)
+	if !ok {
+		return fmt.Errorf("%s is a %s repository: --web only supports git remotes", project.Name, project.VCS())
+	}
+	webURL, err := projectWebURL(repo, time.Duration(cfg.GitTimeout))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Opening %s\n", webURL)
+	return openBrowser(webURL)
+}
+
+// projectWebURL returns the web page of the origin remote, or of the first remote
+func projectWebURL(repo *git.Repository, timeout time.Duration) (string, error) {
+	ctx, cancel := checker.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	remotes, err := repo.Remotes(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(remotes) == 0 {
+		return "", fmt.Errorf("%s has no remote", repo.Name)
+	}
+	remote := remotes[0]
+	if slices.Contains(remotes, "origin") {
+		remote = "origin"
+	}
+
+	remoteURL, err := repo.RemoteURL(ctx, remote)
+	if err != nil {
+		return "", err
+	}
+	return git.WebURL(remoteURL)
+}
+
+// openBrowser opens url with the default browser of the platform
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open a browser: %w", err)
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Profiling flags (hidden, listed in the full help only)
+var (
+	cpuProfile string
+	memProfile string
+	traceFile  string
+)
+
+const profilingHelp = `  --cpuprofile FILE   write a CPU profile of the scan and checks (go tool pprof)
+  --memprofile FILE   write a heap profile once the checks are done
+  --trace FILE        write an execution trace (go tool trace)`
+
+// startProfiling starts the CPU profile and execution trace requested by the flags.
+// The returned function stops them and writes the heap profile; it does nothing
+// when no flag is set.
+func startProfiling() (stop func(), err error) {
+	var stops []func()
+	stop = func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err != nil {
+			return nil, fmt.Errorf("--cpuprofile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("--cpuprofile: %w", err)
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			closeProfile(f, "--cpuprofile")
+		})
+	}
+
+	if traceFile != "" {
+		f, err := os.Create(traceFile)
+		if err != nil {
+			stop()
+			return nil, fmt.Errorf("--trace: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			stop()
+			return nil, fmt.Errorf("--trace: %w", err)
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			closeProfile(f, "--trace")
+		})
+	}
+
+	if memProfile != "" {
+		stops = append(stops, writeMemProfile)
+	}
+
+	return stop, nil
+}
+
+// writeMemProfile writes the heap profile to --memprofile, after a GC so that it is up to date
+func writeMemProfile() {
+	f, err := os.Create(memProfile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ --memprofile: %v\n", err)
+		return
+	}
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ --memprofile: %v\n", err)
+	}
+	closeProfile(f, "--memprofile")
+}
+
+func closeProfile(f *os.File, flag string) {
+	if err := f.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ %s: %v\n", flag, err)
+	}
+}
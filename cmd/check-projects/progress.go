@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mattn/go-isatty"
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// isTerminal reports whether f is an interactive terminal
+var isTerminal = func(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// progress renders an in-place counter of checked repositories.
+// Outside a terminal it only prints a single static line.
+type progress struct {
+	out      io.Writer
+	tty      bool
+	fetching bool
+	total    int
+	done     int
+	dirty    int
+}
+
+func newProgress(out io.Writer, tty bool) *progress {
+	return &progress{out: out, tty: tty}
+}
+
+// scanning announces the discovery phase
+func (p *progress) scanning() {
+	if p.tty {
+		fmt.Fprint(p.out, "\r\033[KScanning projects…")
+		return
+	}
+	fmt.Fprintln(p.out, "Processing projects...")
+}
+
+// start sets the number of repositories about to be checked (and fetched first)
+func (p *progress) start(total int, fetching bool) {
+	p.total = total
+	p.fetching = fetching
+	p.render()
+}
+
+// more adds repositories to check, found once checks started
+func (p *progress) more(n int) {
+	p.total += n
+	p.render()
+}
+
+// add records one checked repository
+func (p *progress) add(status *git.Status) {
+	p.done++
+	if status.Type == git.StatusUnsync {
+		p.dirty++
+	}
+	p.render()
+}
+
+func (p *progress) render() {
+	if !p.tty {
+		return
+	}
+	action := "Checking"
+	if p.fetching {
+		action = "Fetching and checking"
+	}
+	fmt.Fprintf(p.out, "\r\033[K%s repositories %d/%d (%d dirty so far)…", action, p.done, p.total, p.dirty)
+}
+
+// checkingRemotes announces the remote reachability check (--check-remotes)
+func (p *progress) checkingRemotes() {
+	if p.tty {
+		fmt.Fprint(p.out, "\r\033[KChecking remotes…")
+	}
+}
+
+// measuringSizes announces the disk usage measurement (--sizes)
+func (p *progress) measuringSizes() {
+	if p.tty {
+		fmt.Fprint(p.out, "\r\033[KMeasuring sizes…")
+	}
+}
+
+// clear removes the in-place line before the report is printed
+func (p *progress) clear() {
+	if p.tty {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+}
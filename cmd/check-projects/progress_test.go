@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uralys/check-projects/internal/gittest"
+)
+
+// captureStderr returns what f prints on stderr
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	f()
+	w.Close()
+	return <-out
+}
+
+// runWithTerminal runs check-projects on two repositories as if stdout were a
+// terminal or not, and returns what it printed on stdout and stderr
+func runWithTerminal(t *testing.T, tty bool, args ...string) (string, string) {
+	t.Helper()
+	for _, dir := range []string{"XDG_CACHE_HOME", "XDG_STATE_HOME", "XDG_DATA_HOME"} {
+		t.Setenv(dir, t.TempDir())
+	}
+	clean := gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll()
+	dirty := gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Modify("README.md")
+	path := filepath.Join(t.TempDir(), "check-projects.yml")
+	config := "categories:\n  - name: dev\n    projects:\n      - " + clean.Path + "\n      - " + dirty.Path + "\n"
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	terminal := isTerminal
+	isTerminal = func(*os.File) bool { return tty }
+	t.Cleanup(func() { isTerminal, logOut = terminal, os.Stdout })
+
+	cmd := newRootCmd()
+	cmd.SetArgs(append([]string{"--config", path, "--no-update-check"}, args...))
+	var stdout string
+	var err error
+	stderr := captureStderr(t, func() {
+		stdout = captureStdout(t, func() {
+			logOut = os.Stdout
+			err = cmd.Execute()
+		})
+	})
+	var exitErr *exitCodeError // --summary exits with 1 for the dirty repository
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatal(err)
+	}
+	return stdout, stderr
+}
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		tty      bool
+		args     []string
+		progress bool
+	}{
+		{"terminal", true, nil, true},
+		{"not a terminal", false, nil, false},
+		{"not a terminal, verbose", false, []string{"--verbose"}, false},
+		{"terminal, json", true, []string{"--output", "json"}, false},
+		{"terminal, summary", true, []string{"--summary"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := runWithTerminal(t, tt.tty, tt.args...)
+			for name, out := range map[string]string{"stdout": stdout, "stderr": stderr} {
+				counter := strings.Contains(out, "repositories 2/2") || strings.Contains(out, "\r")
+				if name == "stderr" && tt.progress {
+					if !counter {
+						t.Errorf("no progress on the terminal:\n%q", out)
+					}
+					continue
+				}
+				if counter {
+					t.Errorf("progress printed on %s:\n%q", name, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/prompt"
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+var (
+	promptFlag    bool
+	promptRefresh bool
+	noNewline     bool
+)
+
+// refreshLockAge is how long a background refresh is assumed to still be running,
+// so that prompts drawn meanwhile do not start another one
+const refreshLockAge = 10 * time.Minute
+
+// runPrompt handles --prompt and --prompt-refresh. The token comes from the state
+// recorded by the last full run: nothing is scanned and git is never run.
+// With both flags, the refresh only starts once half of prompt_max_age has passed,
+// so that the token is renewed before it turns into !.
+func runPrompt() error {
+	if promptFlag && (outputFmt != reporter.FormatText || useTUI || summaryFlag || watchFlag || projectName != "" || category != "") {
+		return fmt.Errorf("--prompt cannot be combined with other report options")
+	}
+
+	path, err := prompt.DefaultPath()
+	if err != nil {
+		return err
+	}
+
+	// Settings only: a missing or broken config falls back to the default age
+	maxAge := prompt.DefaultMaxAge
+	if cfg, err := config.LoadConfig(configPaths); err == nil && cfg.PromptMaxAge > 0 {
+		maxAge = time.Duration(cfg.PromptMaxAge)
+	}
+
+	now := time.Now()
+	state, ok := prompt.Load(path)
+	if promptRefresh && (!promptFlag || !ok || state.Stale(maxAge/2, now)) {
+		if err := startRefresh(); err != nil && !promptFlag {
+			return err
+		}
+	}
+	if !promptFlag {
+		return nil
+	}
+
+	fmt.Print(prompt.Token(state, ok, maxAge, now))
+	if !noNewline {
+		fmt.Println()
+	}
+	return nil
+}
+
+// isFullRun reports whether this run checks every configured project, without
+// narrowing the report, so that its outcome stands for all of them in prompts
+func isFullRun(cfg *config.Config) bool {
+	return !cfg.IsFiltered && projectName == "" && !activeOnly && !staleOnly && len(extraIgnore) == 0 && maxDepthFlag == 0
+}
+
+// savePromptState records the outcome of a full run for --prompt, then releases
+// the lock of the background refresh that may have started it.
+// Failures are ignored: prompts then show ! once the previous state gets old.
+func savePromptState(results []reporter.ProjectResult) {
+	path, err := prompt.DefaultPath()
+	if err != nil {
+		return
+	}
+	_ = prompt.Save(path, prompt.NewState(results, time.Now()))
+	_ = os.Remove(refreshLockPath(path))
+}
+
+func refreshLockPath(statePath string) string {
+	return filepath.Join(filepath.Dir(statePath), "prompt-refresh.lock")
+}
+
+// startRefresh runs a full check in a detached process, which records its outcome
+// for --prompt. It does nothing while a previous refresh is still running.
+func startRefresh() error {
+	path, err := prompt.DefaultPath()
+	if err != nil {
+		return err
+	}
+	lock := refreshLockPath(path)
+	if err := os.MkdirAll(filepath.Dir(lock), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		info, statErr := os.Stat(lock)
+		if statErr != nil || time.Since(info.ModTime()) < refreshLockAge {
+			return nil // Already refreshing
+		}
+		// Left behind by a refresh that failed: take it over
+		_ = os.Remove(lock)
+		f, err = os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start the refresh: %w", err)
+	}
+	f.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		_ = os.Remove(lock)
+		return fmt.Errorf("failed to start the refresh: %w", err)
+	}
+	args := []string{"--summary", "--no-update-check"}
+	for _, configPath := range configPaths {
+		args = append(args, "--config", configPath)
+	}
+	// Output goes nowhere (nil standard streams are the null device)
+	refresh := exec.Command(exe, args...)
+	detach(refresh)
+	if err := refresh.Start(); err != nil {
+		_ = os.Remove(lock)
+		return fmt.Errorf("failed to start the refresh: %w", err)
+	}
+	return refresh.Process.Release()
+}
+
+// addPromptFlags registers the shell prompt flags on the root command
+func addPromptFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&promptFlag, "prompt", false, "Print ✔, ✱N (projects needing attention) or ! (no recent full run) from the last full run, instantly, for shell prompts")
+	cmd.Flags().BoolVar(&promptRefresh, "prompt-refresh", false, "Run a full check in the background to update --prompt (with --prompt: only when getting old)")
+	cmd.Flags().BoolVar(&noNewline, "no-newline", false, "Do not end the --prompt token with a newline")
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/vcs"
+)
+
+// Kinds of config entries checked by prune
+const (
+	staleProject = "project"
+	staleIgnore  = "ignore"
+)
+
+// staleEntry is a config entry that no longer points to anything
+type staleEntry struct {
+	Category string
+	Kind     string // staleProject or staleIgnore
+	Value    string
+	Reason   string
+}
+
+func newPruneCmd() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove config entries pointing to missing repositories or ignoring nothing",
+		Long: `Find the stale entries of the config and remove them after confirmation:
+explicit project paths that are missing or no longer git repositories, and ignore
+patterns that match none of the projects of their category.
+
+Entries whose trailing comment contains "` + config.KeepMarker + `" are never reported:
+
+  ignore:
+    - "*-wip"  # ` + config.KeepMarker + `
+
+Examples:
+  check-projects prune --dry-run         # Show the stale entries and the config diff
+  check-projects prune --category work   # Only one category
+  check-projects prune --yes             # Remove without asking`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPrune(cmd, newLinePrompter(os.Stdin, os.Stdout), yes)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Remove the stale entries without asking")
+	return cmd
+}
+
+func runPrune(cmd *cobra.Command, p prompter, yes bool) error {
+	cmd.SilenceUsage = true
+
+	cfg, err := config.LoadConfig(configPaths)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if category != "" && cfg.FindCategory(category) == nil {
+		return fmt.Errorf("category '%s' not found in config", category)
+	}
+
+	stale, err := findStaleEntries(cfg, category)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		fmt.Println("✔ No stale entries")
+		return nil
+	}
+	printStaleEntries(stale)
+
+	if !dryRunFlag && !yes {
+		answer, err := p.Ask(fmt.Sprintf("Remove %d stale entries? [y/N]: ", len(stale)), "n")
+		if err != nil || !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			fmt.Println("Nothing removed")
+			return nil
+		}
+	}
+
+	removeStaleEntries(cfg, stale)
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+	savedMessage("✅ Removed %d stale entries from %s\n", len(stale), strings.Join(cfg.Files(), ", "))
+	return nil
+}
+
+// findStaleEntries returns the stale entries of every category (or only categoryName),
+// in config order, leaving out those marked with config.KeepMarker
+func findStaleEntries(cfg *config.Config, categoryName string) ([]staleEntry, error) {
+	kept, err := config.KeptEntries(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Discover every project, ignored ones included, to match the patterns against
+	s := scanner.NewScanner(cfg)
+	s.IncludeIgnored = true
+	projects, err := s.ScanAll(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan projects: %w", err)
+	}
+	names := make(map[string][]string)
+	for _, proj := range projects {
+		names[proj.Category] = append(names[proj.Category], proj.Name)
+	}
+
+	var stale []staleEntry
+	for _, cat := range cfg.Categories {
+		if categoryName != "" && cat.Name != categoryName {
+			continue
+		}
+
+		for _, projectPath := range cat.Projects {
+			if kept[cat.Name][projectPath] {
+				continue
+			}
+			if config.IsGlob(projectPath) {
+				if !matchesRepository(projectPath) {
+					stale = append(stale, staleEntry{cat.Name, staleProject, projectPath, "matches no repository"})
+				}
+				continue
+			}
+			path := config.ExpandPath(projectPath)
+			if _, err := os.Stat(path); err != nil {
+				stale = append(stale, staleEntry{cat.Name, staleProject, projectPath, "missing"})
+			} else if !vcs.IsRepository(path) {
+				stale = append(stale, staleEntry{cat.Name, staleProject, projectPath, "not a repository"})
+			}
+		}
+
+		// Patterns of a root that cannot be read tell nothing
+		if len(cat.Projects) == 0 && cat.Root != "" {
+			if _, err := os.Stat(cat.GetRootPath()); err != nil {
+				continue
+			}
+		}
+		for _, pattern := range cat.Ignore {
+			if kept[cat.Name][pattern] || matchesAny(names[cat.Name], pattern) {
+				continue
+			}
+			stale = append(stale, staleEntry{cat.Name, staleIgnore, pattern, "matches no project"})
+		}
+	}
+	return stale, nil
+}
+
+func matchesAny(names []string, pattern string) bool {
+	for _, name := range names {
+		if scanner.MatchesPattern(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// printStaleEntries shows the stale entries grouped by category
+func printStaleEntries(stale []staleEntry) {
+	red := color.New(color.FgRed).SprintFunc()
+
+	current := ""
+	for _, entry := range stale {
+		if entry.Category != current {
+			current = entry.Category
+			fmt.Printf("category '%s':\n", current)
+		}
+		fmt.Printf("  %s %s: %s\n", red("- "+entry.Kind), entry.Value, entry.Reason)
+	}
+}
+
+// removeStaleEntries drops the stale entries from cfg
+func removeStaleEntries(cfg *config.Config, stale []staleEntry) {
+	for _, entry := range stale {
+		cat := cfg.FindCategory(entry.Category)
+		if cat == nil {
+			continue
+		}
+		switch entry.Kind {
+		case staleProject:
+			cat.Projects = without(cat.Projects, entry.Value)
+		case staleIgnore:
+			cat.Ignore = without(cat.Ignore, entry.Value)
+		}
+	}
+}
+
+// without returns values with every occurrence of value removed
+func without(values []string, value string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// matchesRepository reports whether a pattern of a projects list matches a repository
+func matchesRepository(pattern string) bool {
+	matches, _ := config.GlobProjects(pattern)
+	return slices.ContainsFunc(matches, vcs.IsRepository)
+}
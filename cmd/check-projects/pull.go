@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/checker"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/vcs"
+)
+
+// pullOutcome describes what pull did with one repository
+type pullOutcome int
+
+const (
+	pullUpToDate pullOutcome = iota
+	pullPulled
+	pullWouldPull // --dry-run
+	pullSkippedDirty
+	pullSkippedDiverged
+	pullSkippedNoUpstream
+	pullSkippedUnsupported // Not a git repository
+	pullFailed
+)
+
+// pullResult is the outcome of pulling one project
+type pullResult struct {
+	Project scanner.Project
+	Outcome pullOutcome
+	Behind  int
+	Err     error
+}
+
+func newPullCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Fetch and fast-forward every clean repository that is behind its upstream",
+		Long: `Fetch every repository, then fast-forward the current branch of those that are
+strictly behind their upstream and have a clean working tree.
+
+Repositories with local changes or diverged from their upstream are never touched.
+
+Examples:
+  check-projects pull                   # Fast-forward everything that is behind
+  check-projects pull --category work   # Only one category
+  check-projects pull --dry-run         # Show what would be pulled`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPull(cmd, dryRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Fetch and list what would be pulled without changing any working tree")
+	return cmd
+}
+
+func runPull(cmd *cobra.Command, dryRun bool) error {
+	cmd.SilenceUsage = true
+
+	cfg, err := config.LoadConfig(configPaths)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := filterCategory(cfg, category); err != nil {
+		return err
+	}
+
+	projects, err := scanner.NewScanner(cfg).ScanAll(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to scan projects: %w", err)
+	}
+	projects = withRepository(projects)
+
+	results := make(chan pullResult, len(projects))
+	go func() {
+		checker.ForEach(projects, cfg.FetchConcurrency, func(_ int, proj scanner.Project) {
+			results <- pullProject(context.Background(), proj, time.Duration(cfg.GitTimeout), fetchOptions(cfg), fetchRetry(cfg), dryRun)
+		})
+		close(results)
+	}()
+
+	counts := make(map[pullOutcome]int)
+	for result := range results {
+		counts[result.Outcome]++
+		printPullResult(result)
+	}
+
+	printPullSummary(counts, dryRun)
+
+	if counts[pullFailed] > 0 {
+		return exitWithCode(cmd, 1)
+	}
+	return nil
+}
+
+// pullProject fetches a project and fast-forwards it when it is safe to do so
+func pullProject(ctx context.Context, project scanner.Project, timeout time.Duration, fetchOpts git.FetchOptions, retry checker.Retry, dryRun bool) pullResult {
+	result := pullResult{Project: project}
+
+	repo, ok := project.Repository.(*git.Repository)
+	if !ok {
+		result.Outcome = pullSkippedUnsupported
+		return result
+	}
+
+	if _, err := checker.FetchWithRetry(ctx, project, timeout, fetchOpts, retry); err != nil {
+		result.Outcome = pullFailed
+		result.Err = err
+		return result
+	}
+
+	opCtx, cancel := checker.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ahead, behind, err := repo.AheadBehind(opCtx)
+	if err != nil {
+		var timeoutErr *git.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			result.Outcome = pullFailed
+			result.Err = err
+			return result
+		}
+		result.Outcome = pullSkippedNoUpstream
+		return result
+	}
+	result.Behind = behind
+
+	switch {
+	case behind == 0:
+		result.Outcome = pullUpToDate
+		return result
+	case ahead > 0:
+		result.Outcome = pullSkippedDiverged
+		return result
+	}
+
+	dirty, err := repo.IsDirty(opCtx)
+	if err != nil {
+		result.Outcome = pullFailed
+		result.Err = err
+		return result
+	}
+	if dirty {
+		result.Outcome = pullSkippedDirty
+		return result
+	}
+
+	if dryRun {
+		result.Outcome = pullWouldPull
+		return result
+	}
+
+	if err := repo.FastForward(opCtx); err != nil {
+		result.Outcome = pullFailed
+		result.Err = err
+		return result
+	}
+	result.Outcome = pullPulled
+	return result
+}
+
+func printPullResult(result pullResult) {
+	name := result.Project.Category + "/" + result.Project.Name
+
+	switch result.Outcome {
+	case pullPulled:
+		fmt.Printf("\033[92m⬇\033[0m %s: pulled %d commit(s)\n", name, result.Behind)
+	case pullWouldPull:
+		fmt.Printf("↓ %s: would pull %d commit(s)\n", name, result.Behind)
+	case pullSkippedDirty:
+		fmt.Printf("\033[93m*\033[0m %s: skipped, %d commit(s) behind but has local changes\n", name, result.Behind)
+	case pullSkippedDiverged:
+		fmt.Printf("\033[93m⬆⬆\033[0m %s: skipped, diverged from upstream\n", name)
+	case pullSkippedUnsupported:
+		if result.Project.VCS() == vcs.KindGit {
+			fmt.Printf("   %s: skipped, pull needs the git binary (go-git backend)\n", name)
+			break
+		}
+		fmt.Printf("   %s: skipped, pull is only supported for git repositories (%s)\n", name, result.Project.VCS())
+	case pullFailed:
+		fmt.Printf("❌ %s: %v\n", name, result.Err)
+	}
+}
+
+func printPullSummary(counts map[pullOutcome]int, dryRun bool) {
+	var parts []string
+	if dryRun {
+		parts = append(parts, fmt.Sprintf("%d to pull", counts[pullWouldPull]))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d pulled", counts[pullPulled]))
+	}
+	parts = append(parts,
+		fmt.Sprintf("%d up to date", counts[pullUpToDate]),
+		fmt.Sprintf("%d skipped (dirty)", counts[pullSkippedDirty]),
+		fmt.Sprintf("%d skipped (diverged)", counts[pullSkippedDiverged]),
+	)
+	if n := counts[pullSkippedNoUpstream]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d without upstream", n))
+	}
+	if n := counts[pullSkippedUnsupported]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d not git", n))
+	}
+	parts = append(parts, fmt.Sprintf("%d failed", counts[pullFailed]))
+
+	fmt.Printf("\n%s\n", strings.Join(parts, ", "))
+}
+
+// withRepository drops projects without a repository (broken symlinks)
+func withRepository(projects []scanner.Project) []scanner.Project {
+	var kept []scanner.Project
+	for _, project := range projects {
+		if project.Repository != nil {
+			kept = append(kept, project)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/gittest"
+)
+
+func TestPullTallies(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	tests := []struct {
+		name     string
+		fixtures []string
+		dryRun   bool
+		want     string
+		fails    bool
+	}{
+		{"up to date", []string{"clean"}, false,
+			"0 pulled, 1 up to date, 0 skipped (dirty), 0 skipped (diverged), 0 failed", false},
+		{"pulled", []string{"clean", "behind", "behind"}, false,
+			"2 pulled, 1 up to date, 0 skipped (dirty), 0 skipped (diverged), 0 failed", false},
+		{"skipped", []string{"dirty behind", "diverged", "no upstream"}, false,
+			"0 pulled, 0 up to date, 1 skipped (dirty), 1 skipped (diverged), 1 without upstream, 0 failed", false},
+		{"mixed", []string{"clean", "behind", "dirty behind", "diverged", "no upstream", "missing remote"}, false,
+			"1 pulled, 1 up to date, 1 skipped (dirty), 1 skipped (diverged), 1 without upstream, 1 failed", true},
+		{"dry run", []string{"clean", "behind", "dirty behind", "missing remote"}, true,
+			"1 to pull, 1 up to date, 1 skipped (dirty), 0 skipped (diverged), 1 failed", true},
+	}
+	fixtures := map[string]func(t *testing.T) *gittest.Repo{
+		"clean":          fetchFixtures["up to date"],
+		"missing remote": fetchFixtures["missing remote"],
+		"behind": func(t *testing.T) *gittest.Repo {
+			return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Behind(1)
+		},
+		"dirty behind": func(t *testing.T) *gittest.Repo {
+			return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Behind(1).Modify("README.md")
+		},
+		"diverged": func(t *testing.T) *gittest.Repo {
+			return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Diverged()
+		},
+		"no upstream": func(t *testing.T) *gittest.Repo {
+			return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Branch("topic").Commit()
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := make([]*gittest.Repo, len(tt.fixtures))
+			for i, name := range tt.fixtures {
+				repos[i] = fixtures[name](t)
+			}
+			useProjects(t, repos...)
+
+			var err error
+			out := captureStdout(t, func() { err = runPull(&cobra.Command{}, tt.dryRun) })
+			var exitErr *exitCodeError
+			if tt.fails != errors.As(err, &exitErr) || (!tt.fails && err != nil) {
+				t.Errorf("got %v, want failure %v", err, tt.fails)
+			}
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			if got := lines[len(lines)-1]; got != tt.want {
+				t.Errorf("got %q, want %q\n%s", got, tt.want, out)
+			}
+
+			// Only the clean repositories behind are fast-forwarded, not on dry runs
+			for i, name := range tt.fixtures {
+				if name != "behind" && name != "dirty behind" && name != "diverged" {
+					continue
+				}
+				head, upstream := repos[i].Git("rev-parse", "HEAD"), repos[i].Git("rev-parse", "@{upstream}")
+				if pulled := name == "behind" && !tt.dryRun; (head == upstream) != pulled {
+					t.Errorf("%s: HEAD %s, upstream %s, want pulled %v", name, head, upstream, pulled)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/remotes"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// markUnreachableRemotes checks the remote of every project and replaces the status
+// of those that cannot be reached, or refuse the credentials. Ignored projects and those that could not be
+// checked keep their status.
+func markUnreachableRemotes(ctx context.Context, cfg *config.Config, projects []scanner.Project, results []reporter.ProjectResult) {
+	unreachable := remotes.Check(ctx, projects, remotes.Options{
+		Concurrency: cfg.FetchConcurrency,
+		CacheFor:    time.Duration(cfg.RemoteCache),
+		Allowlist:   cfg.RemoteAllowlist,
+	})
+	for idx, remoteErr := range unreachable {
+		status := results[idx].Status
+		switch {
+		case status == nil:
+			continue
+		case status.Type == git.StatusIgnored, status.Type == git.StatusError, status.Type == git.StatusTimeout,
+			status.Type == git.StatusPermission, status.Type == git.StatusUnchecked:
+			continue
+		}
+		results[idx].Status = git.NewRemoteStatus(remoteErr, status)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/pkg/checkprojects"
+)
+
+// rescanResult is what scanning the roots read from the cache again found
+type rescanResult struct {
+	projects []scanner.Project
+	warnings []scanner.Warning
+	err      error
+}
+
+// revalidate scans the roots read from the cache again in the background, sending
+// what it found once done
+func revalidate(ctx context.Context, s *scanner.Scanner) <-chan rescanResult {
+	rescan := make(chan rescanResult, 1)
+	go func() {
+		projects, warnings, err := s.Revalidate(ctx)
+		rescan <- rescanResult{projects, warnings, err}
+	}()
+	return rescan
+}
+
+// checkAll checks projects concurrently, passing each result to stream when set.
+// Meanwhile, with rescan, the roots read from the scan cache are scanned again: the
+// projects that appeared are checked as well, and those gone dropped. It returns the
+// projects, with their results at the same indexes (a nil status for those left
+// unchecked), and the warnings of the rescan (nil without one).
+func checkAll(ctx context.Context, projects []scanner.Project, rescan <-chan rescanResult, opts checkprojects.Options, prog *progress, stream *reporter.Streamer) ([]scanner.Project, []reporter.ProjectResult, []scanner.Warning) {
+	type key struct{ category, path string }
+	checked := make(map[key]reporter.ProjectResult, len(projects))
+	record := func(result checkprojects.Result) {
+		checked[key{result.Project.Category, result.Project.Path}] = projectResult(result)
+		prog.add(result.Status)
+		if stream != nil {
+			stream.Add(projectResult(result))
+		}
+	}
+	expect(stream, projects, rescan == nil)
+
+	var warnings []scanner.Warning
+	prog.start(len(projects), opts.Fetch)
+	listed := checkprojects.Stream(ctx, projects, opts)
+	var appeared <-chan checkprojects.Result
+	for listed != nil || appeared != nil || rescan != nil {
+		select {
+		case result, ok := <-listed:
+			if !ok {
+				listed = nil
+				continue
+			}
+			record(result)
+		case result, ok := <-appeared:
+			if !ok {
+				appeared = nil
+				continue
+			}
+			record(result)
+		case fresh := <-rescan:
+			rescan = nil
+			if fresh.err == nil {
+				known := make(map[key]bool, len(projects))
+				for _, project := range projects {
+					known[key{project.Category, project.Path}] = true
+				}
+				var added []scanner.Project
+				for _, project := range fresh.projects {
+					if !known[key{project.Category, project.Path}] {
+						added = append(added, project)
+					}
+				}
+				projects, warnings = fresh.projects, fresh.warnings
+				prog.more(len(added))
+				appeared = checkprojects.Stream(ctx, added, opts)
+			}
+			expect(stream, projects, true)
+		}
+		// Once interrupted, the rescan is not waited for
+		if ctx.Err() != nil && listed == nil && appeared == nil {
+			break
+		}
+	}
+
+	results := make([]reporter.ProjectResult, len(projects))
+	for i, project := range projects {
+		results[i] = checked[key{project.Category, project.Path}]
+	}
+	return projects, results, warnings
+}
+
+// expect tells stream, when set, which projects the report lists
+func expect(stream *reporter.Streamer, projects []scanner.Project, final bool) {
+	if stream == nil {
+		return
+	}
+	expected := make([]reporter.ProjectResult, len(projects))
+	for i, project := range projects {
+		expected[i] = reporter.ProjectResult{Category: project.Category, Path: project.Path}
+	}
+	stream.Expect(expected, final)
+}
+
+// projectResult is the result of checking a project, as reported
+func projectResult(result checkprojects.Result) reporter.ProjectResult {
+	proj := result.Project
+	return reporter.ProjectResult{
+		Name:          proj.Name,
+		Path:          proj.Path,
+		Status:        result.Status,
+		Category:      proj.Category,
+		IsSymlink:     proj.IsSymlink,
+		SymlinkTarget: proj.SymlinkTarget,
+		VCS:           proj.VCS(),
+		LastCommit:    result.LastCommit,
+	}
+}
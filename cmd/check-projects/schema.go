@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/schema"
+)
+
+func newSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema <" + strings.Join(schema.Names, "|") + ">",
+		Short: "Print the JSON Schema of the config file or of the JSON report",
+		Long: fmt.Sprintf(`Print the JSON Schema (draft-07) of the configuration file or of the document
+written by --output json, generated from the code of this version.
+
+The schemas are also published at %s<name>.json.
+Editors using the YAML language server pick the config schema up from a first line:
+
+  # yaml-language-server: $schema=%s
+
+Examples:
+  check-projects schema config > check-projects.schema.json
+  check-projects schema report`, schema.BaseURL, schema.URL(schema.NameConfig)),
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: schema.Names,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, ok := schema.Get(args[0])
+			if !ok {
+				return fmt.Errorf("unknown schema '%s' (valid: %s)", args[0], strings.Join(schema.Names, ", "))
+			}
+			cmd.SilenceUsage = true
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			return encoder.Encode(s)
+		},
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// markActive flags the results whose HEAD was committed after cutoff.
+// Repositories without commits (or broken symlinks) are left unmarked.
+func markActive(results []reporter.ProjectResult, cutoff time.Time) {
+	for i := range results {
+		results[i].Active = results[i].LastCommit.After(cutoff)
+	}
+}
+
+// onlyActive keeps the active projects, with their results at the same indexes
+func onlyActive(projects []scanner.Project, results []reporter.ProjectResult) ([]scanner.Project, []reporter.ProjectResult) {
+	return keepResults(projects, results, func(result reporter.ProjectResult) bool {
+		return result.Active
+	})
+}
+
+// onlyStale keeps the stale projects, with their results at the same indexes
+func onlyStale(projects []scanner.Project, results []reporter.ProjectResult) ([]scanner.Project, []reporter.ProjectResult) {
+	return keepResults(projects, results, func(result reporter.ProjectResult) bool {
+		return result.Status != nil && result.Status.Type == git.StatusStale
+	})
+}
+
+// keepResults keeps the projects whose result matches keep, with their results at the same indexes
+func keepResults(projects []scanner.Project, results []reporter.ProjectResult, keep func(reporter.ProjectResult) bool) ([]scanner.Project, []reporter.ProjectResult) {
+	var keptProjects []scanner.Project
+	var keptResults []reporter.ProjectResult
+	for i, result := range results {
+		if keep(result) {
+			keptProjects = append(keptProjects, projects[i])
+			keptResults = append(keptResults, result)
+		}
+	}
+	return keptProjects, keptResults
+}
+
+// staleAfterOption returns the per-project stale_after threshold of cfg,
+// or nil when no threshold is configured
+func staleAfterOption(cfg *config.Config) func(scanner.Project) time.Duration {
+	if !cfg.HasStaleAfter() {
+		return nil
+	}
+	return func(project scanner.Project) time.Duration {
+		return cfg.StaleAfterFor(project.Category)
+	}
+}
+
+// localBranchesOption tells whether to report the local-only branches of a project,
+// following the local_branches setting of its category
+func localBranchesOption(cfg *config.Config) func(scanner.Project) bool {
+	return func(project scanner.Project) bool {
+		return cfg.LocalBranchesFor(project.Category)
+	}
+}
+
+// mergedBranchesOption tells whether to list the merged branches of a project,
+// following the merged_branches setting of its category
+func mergedBranchesOption(cfg *config.Config) func(scanner.Project) bool {
+	return func(project scanner.Project) bool {
+		return cfg.MergedBranchesFor(project.Category)
+	}
+}
+
+// offDefaultBranchOption tells whether to flag a project that is not on its default
+// branch, following the default_branch setting of its category
+func offDefaultBranchOption(cfg *config.Config) func(scanner.Project) bool {
+	return func(project scanner.Project) bool {
+		return cfg.DefaultBranchFor(project.Category)
+	}
+}
+
+// unpushedTagsOption tells whether to look for the unpushed tags of a project,
+// following the unpushed_tags setting of its category
+func unpushedTagsOption(cfg *config.Config) func(scanner.Project) bool {
+	return func(project scanner.Project) bool {
+		return cfg.UnpushedTagsFor(project.Category)
+	}
+}
+
+// expectedEmailOption returns the patterns the user.email of each project must
+// match, following the expected_email setting of its category
+func expectedEmailOption(cfg *config.Config) func(scanner.Project) config.Patterns {
+	return func(project scanner.Project) config.Patterns {
+		return cfg.ExpectedEmailFor(project.Category)
+	}
+}
+
+// compareRemotesOption returns the remotes each project is compared with, following
+// compare_remotes globally and per category
+func compareRemotesOption(cfg *config.Config) func(scanner.Project) []string {
+	return func(project scanner.Project) []string {
+		return cfg.CompareRemotesFor(project.Category)
+	}
+}
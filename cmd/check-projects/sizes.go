@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+
+	"github.com/uralys/check-projects/internal/checker"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/sizes"
+)
+
+// measureSizes sets the disk usage of every project (--sizes), measuring those that
+// changed since the last run. Broken symlinks and unreadable projects are left at 0.
+func measureSizes(ctx context.Context, projects []scanner.Project, results []reporter.ProjectResult) {
+	cachePath, _ := sizes.DefaultCachePath()
+	cache := sizes.LoadCache(cachePath)
+
+	checker.ForEach(projects, jobsFlag, func(idx int, proj scanner.Project) {
+		if proj.Repository == nil || ctx.Err() != nil {
+			return
+		}
+		size, err := cache.Measure(ctx, proj.Path)
+		if err != nil {
+			return
+		}
+		results[idx].SizeBytes = size.Bytes
+		results[idx].GitSizeBytes = size.GitBytes
+	})
+
+	_ = cache.Save()
+}
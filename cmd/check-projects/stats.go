@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/history"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/stats"
+	"github.com/uralys/check-projects/pkg/checkprojects"
+)
+
+func newStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Check every project and print an overview of repository hygiene",
+		Long: `Check every project and print aggregate tables instead of the per-project report:
+counts by status per category, the ten dirtiest repositories, the oldest
+uncommitted change, and the totals of the latest snapshots (--snapshot) with
+how they moved since.
+
+The dirtiest repositories are ranked by a score weighing unmerged files the most,
+then changed files, commits behind the upstream, and the age of stale projects.
+
+Examples:
+  check-projects stats
+  check-projects stats --category work
+  check-projects stats --output json`,
+		Args: cobra.NoArgs,
+		RunE: runStats,
+	}
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if outputFmt != reporter.FormatText && outputFmt != reporter.FormatJSON {
+		return fmt.Errorf("stats supports --output %s or %s", reporter.FormatText, reporter.FormatJSON)
+	}
+	cmd.SilenceUsage = true
+	machineOutput := outputFmt == reporter.FormatJSON
+	if machineOutput {
+		logOut = os.Stderr
+	}
+
+	cfg, err := config.LoadConfig(configPaths)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := filterCategory(cfg, category); err != nil {
+		return err
+	}
+
+	// Last commits give the age of stale projects
+	opts := checkprojects.Options{
+		Timeout:          time.Duration(cfg.GitTimeout),
+		LastCommits:      true,
+		StaleAfter:       staleAfterOption(cfg),
+		LocalBranches:    localBranchesOption(cfg),
+		OffDefaultBranch: offDefaultBranchOption(cfg),
+		MergedBranches:   mergedBranchesOption(cfg),
+		CompareRemotes:   compareRemotesOption(cfg),
+		UnpushedTags:     unpushedTagsOption(cfg),
+		ExpectedEmail:    expectedEmailOption(cfg),
+	}
+	ctx, stopInterrupt := interruptContext()
+	defer stopInterrupt()
+
+	_, results, _, err := checkProjects(ctx, cfg, opts, machineOutput, nil)
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return exitWithCode(cmd, 130)
+	}
+
+	snapshots, err := loadRecentSnapshots(stats.HistoryCount)
+	if err != nil {
+		return err
+	}
+	report := stats.Build(results, snapshots, time.Now())
+
+	if machineOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	stats.Render(os.Stdout, report)
+	return nil
+}
+
+// loadRecentSnapshots reads the latest count snapshots of the history, oldest first
+func loadRecentSnapshots(count int) ([]history.Snapshot, error) {
+	dir, err := history.Dir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := history.List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []history.Snapshot
+	for _, path := range paths[max(len(paths)-count, 0):] {
+		snapshot, err := history.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
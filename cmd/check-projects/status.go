@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/pkg/checkprojects"
+)
+
+// statusCategory is the synthetic category holding the paths given to status
+const statusCategory = "status"
+
+func newStatusCmd() *cobra.Command {
+	var withExitCode bool
+
+	cmd := &cobra.Command{
+		Use:   "status <path>...",
+		Short: "Check the given repositories, whether or not they are in the config",
+		Long: `Check the repositories at the given paths and print the full detail of each one.
+
+No config file is needed: when one exists, only git_timeout is read from it.
+Paths that are not git repositories are reported on stderr, and the command then
+exits with 2 after checking the others.
+
+Examples:
+  check-projects status ~/tmp/experiment /srv/deploy/app
+  check-projects status . --output json
+  check-projects status . --exit-code    # Exit with 1 on changes, 2 on errors`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStatus(cmd, args, withExitCode)
+		},
+	}
+
+	cmd.Flags().BoolVar(&withExitCode, "exit-code", false, "Exit with 1 when a repository needs attention, 2 when one errored")
+	return cmd
+}
+
+func runStatus(cmd *cobra.Command, paths []string, withExitCode bool) error {
+	if category != "" {
+		return fmt.Errorf("--category cannot be combined with status")
+	}
+	if err := reporter.ValidateFormat(outputFmt); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	cfg, err := config.LoadConfig(configPaths)
+	if errors.Is(err, config.ErrNoConfig) {
+		cfg = config.DefaultConfig()
+	} else if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cat, invalid := statusPathsCategory(paths)
+	cfg.Categories = []config.Category{cat}
+	cfg.IsFiltered = true // Never save the synthetic category
+
+	projects, _, err := checkprojects.Discover(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("failed to scan projects: %w", err)
+	}
+
+	opts := checkprojects.Options{
+		Timeout:          time.Duration(cfg.GitTimeout),
+		StaleAfter:       staleAfterOption(cfg),
+		LocalBranches:    localBranchesOption(cfg),
+		OffDefaultBranch: offDefaultBranchOption(cfg),
+		MergedBranches:   mergedBranchesOption(cfg),
+		CompareRemotes:   compareRemotesOption(cfg),
+		UnpushedTags:     unpushedTagsOption(cfg),
+		ExpectedEmail:    expectedEmailOption(cfg),
+	}
+	checked, _ := checkprojects.Check(context.Background(), projects, opts)
+	results := make([]reporter.ProjectResult, len(checked))
+	for i, result := range checked {
+		proj := result.Project
+		results[i] = reporter.ProjectResult{
+			Name:          proj.Name,
+			Path:          proj.Path,
+			Status:        result.Status,
+			Category:      proj.Category,
+			IsSymlink:     proj.IsSymlink,
+			SymlinkTarget: proj.SymlinkTarget,
+			VCS:           proj.VCS(),
+		}
+	}
+
+	if len(results) > 0 {
+		var rep reporter.ResultReporter = reporter.NewDetailReporter(os.Stdout)
+		if reporter.IsMachineFormat(outputFmt) {
+			rep, _ = reporter.New(outputFmt, cfg, true, os.Stdout)
+		}
+		rep.Report(results)
+	}
+
+	if invalid > 0 {
+		return exitWithCode(cmd, reporter.ExitErrors)
+	}
+	if withExitCode {
+		if code := reporter.ExitCode(results); code != reporter.ExitClean {
+			return exitWithCode(cmd, code)
+		}
+	}
+	return nil
+}
+
+// statusPathsCategory builds the category checking the given paths, reporting
+// those that are not git repositories on stderr and returning how many there were
+func statusPathsCategory(paths []string) (config.Category, int) {
+	cat := config.Category{Name: statusCategory}
+	seen := make(map[string]bool)
+	invalid := 0
+
+	for _, arg := range paths {
+		path, err := repositoryPath(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", arg, err)
+			invalid++
+			continue
+		}
+		if !seen[path] {
+			seen[path] = true
+			cat.Projects = append(cat.Projects, path)
+		}
+	}
+
+	return cat, invalid
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/vcs"
+)
+
+// stdinCategory is the synthetic category holding paths read with --stdin
+const stdinCategory = "stdin"
+
+// rootArgs accepts no positional argument, or "-" as a shorthand for --stdin
+func rootArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if len(args) == 1 && args[0] == "-" {
+		return nil
+	}
+	// The interval of --watch is optional, hence only taken with an equal sign
+	if _, err := time.ParseDuration(args[0]); err == nil && cmd.Flags().Changed("watch") {
+		return fmt.Errorf("unexpected argument %q: set the interval with --watch=%s", args[0], args[0])
+	}
+	msg := fmt.Sprintf("unknown command %q for %q", args[0], cmd.CommandPath())
+	if suggestions := cmd.SuggestionsFor(args[0]); len(suggestions) > 0 {
+		msg += "\n\nDid you mean this?\n\t" + strings.Join(suggestions, "\n\t")
+	}
+	return errors.New(msg)
+}
+
+// readStdinCategory reads newline-separated repository paths from r into a single category.
+// Blank lines and duplicates are dropped; paths that are not git repositories are
+// reported on warn and skipped.
+func readStdinCategory(r io.Reader, warn io.Writer) (config.Category, error) {
+	category := config.Category{Name: stdinCategory}
+	seen := make(map[string]bool)
+
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" {
+			continue
+		}
+
+		path, err := repositoryPath(line)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		if err != nil {
+			fmt.Fprintf(warn, "⚠ Skipping %s: %v\n", line, err)
+			continue
+		}
+		category.Projects = append(category.Projects, path)
+	}
+	if err := lines.Err(); err != nil {
+		return category, fmt.Errorf("failed to read paths from stdin: %w", err)
+	}
+
+	return category, nil
+}
+
+// repositoryPath returns the absolute path of a repository given on the command line,
+// with an error when it is not a git or hg repository
+func repositoryPath(arg string) (string, error) {
+	path := config.ExpandPath(arg)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if !vcs.IsRepository(path) {
+		return path, errors.New("not a git or hg repository")
+	}
+	return path, nil
+}
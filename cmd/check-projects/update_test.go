@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/gittest"
+	"github.com/uralys/check-projects/internal/updater"
+)
+
+// runWithUpdateCheck runs check-projects on a clean repository with the given update
+// check, and returns what it printed and how long it took
+func runWithUpdateCheck(t *testing.T, check func(version string) <-chan *updater.UpdateResult, args ...string) (string, time.Duration) {
+	t.Helper()
+	for _, dir := range []string{"XDG_CACHE_HOME", "XDG_STATE_HOME", "XDG_DATA_HOME"} {
+		t.Setenv(dir, t.TempDir())
+	}
+	r := gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll()
+	path := filepath.Join(t.TempDir(), "check-projects.yml")
+	if err := os.WriteFile(path, []byte("categories:\n  - name: dev\n    projects:\n      - "+r.Path+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checkUpdates, updateNoticeTimeout = check, 100*time.Millisecond
+	t.Cleanup(func() {
+		checkUpdates, updateNoticeTimeout, logOut = updater.CheckForUpdatesAsync, 300*time.Millisecond, os.Stdout
+	})
+
+	cmd := newRootCmd()
+	cmd.SetArgs(append([]string{"--config", path}, args...))
+	start := time.Now()
+	var err error
+	out := captureStdout(t, func() {
+		logOut = os.Stdout // Notices and report in the order they are printed
+		err = cmd.Execute()
+	})
+	took := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out, took
+}
+
+func TestUpdateCheckPastTimeout(t *testing.T) {
+	blocked := make(chan *updater.UpdateResult) // Never answers
+	out, took := runWithUpdateCheck(t, func(string) <-chan *updater.UpdateResult { return blocked })
+
+	if took > 5*time.Second {
+		t.Errorf("took %s waiting for the update check", took)
+	}
+	if strings.Contains(out, "New version available") {
+		t.Errorf("notice printed without any result:\n%s", out)
+	}
+}
+
+func TestUpdateCheckNoticeAfterReport(t *testing.T) {
+	out, _ := runWithUpdateCheck(t, func(version string) <-chan *updater.UpdateResult {
+		ch := make(chan *updater.UpdateResult, 1)
+		ch <- &updater.UpdateResult{Available: true, CurrentVersion: version, LatestVersion: "9.9.9"}
+		return ch
+	})
+
+	notice := strings.Index(out, "New version available")
+	if notice < 0 {
+		t.Fatalf("no notice:\n%s", out)
+	}
+	if report := strings.Index(out, "All projects are clean"); report < 0 || report > notice {
+		t.Errorf("notice not after the report:\n%s", out)
+	}
+}
+
+func TestNoUpdateCheck(t *testing.T) {
+	called := false
+	out, _ := runWithUpdateCheck(t, func(string) <-chan *updater.UpdateResult {
+		called = true
+		return nil
+	}, "--no-update-check")
+
+	if called {
+		t.Error("checked for updates with --no-update-check")
+	}
+	if strings.Contains(out, "New version available") {
+		t.Errorf("notice printed:\n%s", out)
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/uralys/check-projects/internal/checker"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/vcs"
+)
+
+// prompter asks the user questions in the interactive flows
+type prompter interface {
+	// Ask prints question and returns the trimmed answer, or def for an empty answer.
+	// It returns io.EOF when no answer can be read anymore.
+	Ask(question, def string) (string, error)
+}
+
+// linePrompter reads one answer per line
+type linePrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newLinePrompter(in io.Reader, out io.Writer) *linePrompter {
+	return &linePrompter{in: bufio.NewReader(in), out: out}
+}
+
+func (p *linePrompter) Ask(question, def string) (string, error) {
+	fmt.Fprint(p.out, question)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		fmt.Fprintln(p.out)
+		return "", io.EOF
+	}
+	answer := strings.TrimSpace(line)
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// upstreamAction is what to do with a repository without upstream
+type upstreamAction int
+
+const (
+	upstreamSkip upstreamAction = iota
+	upstreamSet
+	upstreamPush
+	upstreamIgnore
+)
+
+// handleNoUpstream lists the repositories without upstream, asks once what to do with
+// them (all, one by one, ignore or skip), then applies the choices and prints the outcome.
+// Branches the remote does not have cannot track anything yet: for each of them, it
+// then asks whether to push it and set its upstream, ignore it or skip it.
+// Results are updated in place. Branches whose upstream was deleted on the remote
+// still have one configured: they are only reported, since pushing them again
+// would bring back a merged branch. Repositories without any remote come first,
+// only offered to be ignored (see handleNoRemote). Repositories given an upstream are
+// checked again with opts.
+func handleNoUpstream(ctx context.Context, p prompter, cfg *config.Config, projects []scanner.Project, results []reporter.ProjectResult, opts checker.Options) error {
+	handleNoRemote(ctx, p, cfg, projects, results, opts)
+
+	var pending []int
+	branches := make(map[int]string)
+	for i, result := range results {
+		// Upstreams can only be set up for git repositories
+		if _, ok := projects[i].Repository.(vcs.UpstreamSetter); result.Status.Type != git.StatusNoUpstream || !ok {
+			continue
+		}
+		// A detached HEAD has no branch to track anything
+		if result.Status.DetachedAt != "" {
+			continue
+		}
+		pending = append(pending, i)
+		branches[i] = "unknown"
+		if branch, err := projects[i].Repository.GetCurrentBranch(ctx); err == nil {
+			branches[i] = branch
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\n🧚🏻‍♀️ %d repositor%s without upstream:\n", len(pending), pluralY(len(pending)))
+	for n, i := range pending {
+		fmt.Printf("  %d. %s (\033[95m%s\033[0m)\n", n+1, results[i].Name, branches[i])
+	}
+
+	choices, keys := ", [i]gnore all in config", "A/c/i/s"
+	if cfg.IsFiltered {
+		choices, keys = "", "A/c/s"
+	}
+	answer, err := p.Ask(fmt.Sprintf("\033[38;5;208mSet upstream tracking locally?\033[0m [a]ll, [c]hoose individually%s, [s]kip \033[92m(%s):\033[0m ", choices, keys), "a")
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	actions := make(map[int]upstreamAction)
+	switch strings.ToLower(answer) {
+	case "a", "all", "y", "yes":
+		for _, i := range pending {
+			actions[i] = upstreamSet
+		}
+	case "i", "ignore":
+		if cfg.IsFiltered {
+			fmt.Printf("⚠ Cannot ignore projects when the config is filtered (--category, --root or --stdin).\n")
+			return nil
+		}
+		for _, i := range pending {
+			actions[i] = upstreamIgnore
+		}
+	case "c", "choose":
+	choose:
+		for _, i := range pending {
+			question := fmt.Sprintf("  %s (%s): set upstream? [Y]es, [n]o", results[i].Name, branches[i])
+			if !cfg.IsFiltered {
+				question += ", [i]gnore"
+			}
+			answer, err := p.Ask(question+": ", "y")
+			if errors.Is(err, io.EOF) {
+				break choose
+			}
+			switch strings.ToLower(answer) {
+			case "y", "yes":
+				actions[i] = upstreamSet
+			case "i", "ignore":
+				if !cfg.IsFiltered {
+					actions[i] = upstreamIgnore
+				}
+			}
+		}
+	default:
+		fmt.Printf("Skipped.\n")
+		return nil
+	}
+
+	notOnRemote := applyUpstreamActions(ctx, cfg, projects, results, pending, actions, opts)
+	if len(notOnRemote) == 0 {
+		return nil
+	}
+
+	var unpushed []int
+	actions = make(map[int]upstreamAction)
+	fmt.Println()
+ask:
+	for _, i := range pending {
+		notPushed, ok := notOnRemote[i]
+		if !ok {
+			continue
+		}
+		unpushed = append(unpushed, i)
+		question := fmt.Sprintf("  %s: %s. [p]ush and set upstream", results[i].Name, notPushed)
+		keys := "p/i/S"
+		if cfg.IsFiltered {
+			keys = "p/S"
+		} else {
+			question += ", [i]gnore"
+		}
+		answer, err := p.Ask(fmt.Sprintf("%s, [s]kip \033[92m(%s):\033[0m ", question, keys), "s")
+		if errors.Is(err, io.EOF) {
+			break ask
+		}
+		switch strings.ToLower(answer) {
+		case "p", "push":
+			actions[i] = upstreamPush
+		case "i", "ignore":
+			if !cfg.IsFiltered {
+				actions[i] = upstreamIgnore
+			}
+		}
+	}
+	applyUpstreamActions(ctx, cfg, projects, results, unpushed, actions, opts)
+	return nil
+}
+
+// handleNoRemote lists the repositories without any remote, which nothing can be set
+// to track, and asks whether to ignore them in the config (all, one by one, or skip).
+// Nothing is asked when the config is filtered, since it cannot be saved.
+func handleNoRemote(ctx context.Context, p prompter, cfg *config.Config, projects []scanner.Project, results []reporter.ProjectResult, opts checker.Options) {
+	if cfg.IsFiltered {
+		return
+	}
+	var pending []int
+	for i, result := range results {
+		if result.Status.Type == git.StatusNoRemote {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	fmt.Printf("\n🧚🏻‍♀️ %d repositor%s without remote:\n", len(pending), pluralY(len(pending)))
+	for n, i := range pending {
+		fmt.Printf("  %d. %s\n", n+1, results[i].Name)
+	}
+
+	answer, err := p.Ask("\033[38;5;208mIgnore them in config?\033[0m [a]ll, [c]hoose individually, [s]kip \033[92m(a/c/S):\033[0m ", "s")
+	if errors.Is(err, io.EOF) {
+		return
+	}
+
+	actions := make(map[int]upstreamAction)
+	switch strings.ToLower(answer) {
+	case "a", "all", "y", "yes", "i", "ignore":
+		for _, i := range pending {
+			actions[i] = upstreamIgnore
+		}
+	case "c", "choose":
+	choose:
+		for _, i := range pending {
+			answer, err := p.Ask(fmt.Sprintf("  %s: ignore? [y]es, [N]o: ", results[i].Name), "n")
+			if errors.Is(err, io.EOF) {
+				break choose
+			}
+			if strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes") {
+				actions[i] = upstreamIgnore
+			}
+		}
+	default:
+		fmt.Printf("Skipped.\n")
+		return
+	}
+
+	applyUpstreamActions(ctx, cfg, projects, results, pending, actions, opts)
+}
+
+// applyUpstreamActions sets upstreams, pushes branches and ignores projects as chosen,
+// then prints one line per repository. Repositories given an upstream are checked
+// again like any other (local-only branches, staleness...). It returns the repositories
+// whose branch could not track anything since the remote does not have it.
+func applyUpstreamActions(ctx context.Context, cfg *config.Config, projects []scanner.Project, results []reporter.ProjectResult, pending []int, actions map[int]upstreamAction, opts checker.Options) map[int]*git.BranchNotOnRemoteError {
+	var lines []string
+	var ignored []int
+	notOnRemote := make(map[int]*git.BranchNotOnRemoteError)
+
+	for _, i := range pending {
+		name := results[i].Name
+		switch actions[i] {
+		case upstreamSet:
+			opCtx, cancel := checker.WithTimeout(ctx, opts.Timeout)
+			err := projects[i].Repository.(vcs.UpstreamSetter).SetUpstream(opCtx)
+			cancel()
+			var notPushed *git.BranchNotOnRemoteError
+			if errors.As(err, &notPushed) {
+				notOnRemote[i] = notPushed
+				lines = append(lines, fmt.Sprintf("⚠ %s: %v", name, err))
+				continue
+			}
+			if err != nil {
+				lines = append(lines, fmt.Sprintf("❌ %s: failed to set upstream: %v", name, err))
+				continue
+			}
+			results[i].Status = checker.Recheck(ctx, projects[i], results[i].LastCommit, opts)
+			lines = append(lines, fmt.Sprintf("✅ %s: upstream configured", name))
+		case upstreamPush:
+			opCtx, cancel := checker.WithTimeout(ctx, opts.Timeout)
+			_, err := projects[i].Repository.(vcs.UpstreamSetter).Push(opCtx, true)
+			cancel()
+			if err != nil {
+				lines = append(lines, fmt.Sprintf("❌ %s: failed to push: %v", name, err))
+				continue
+			}
+			results[i].Status = checker.Recheck(ctx, projects[i], results[i].LastCommit, opts)
+			lines = append(lines, fmt.Sprintf("✅ %s: pushed, upstream configured", name))
+		case upstreamIgnore:
+			if category := cfg.FindCategory(results[i].Category); category != nil {
+				category.Ignore = append(category.Ignore, name)
+				ignored = append(ignored, i)
+			}
+		default:
+			lines = append(lines, fmt.Sprintf("   %s: skipped", name))
+		}
+	}
+
+	// Save the config once for all ignored projects
+	if len(ignored) > 0 {
+		if err := saveConfig(cfg); err != nil {
+			lines = append(lines, fmt.Sprintf("❌ Failed to save config: %v", err))
+		} else if !dryRunFlag {
+			for _, i := range ignored {
+				results[i].Status.Type = git.StatusIgnored
+				lines = append(lines, fmt.Sprintf("✅ %s: added to the ignore list of '%s' in %s", results[i].Name, results[i].Category, cfg.SourceOf(cfg.FindCategory(results[i].Category))))
+			}
+		}
+	}
+
+	fmt.Println()
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return notOnRemote
+}
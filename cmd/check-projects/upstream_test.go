@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uralys/check-projects/internal/checker"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/gittest"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// scriptedPrompter answers the questions in order, then fails with io.EOF as if
+// stdin were closed (Ctrl+D). An empty answer takes the default.
+type scriptedPrompter struct {
+	answers   []string
+	questions []string
+}
+
+func (p *scriptedPrompter) Ask(question, def string) (string, error) {
+	p.questions = append(p.questions, question)
+	if len(p.answers) == 0 {
+		return "", io.EOF
+	}
+	answer := p.answers[0]
+	p.answers = p.answers[1:]
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// captureStdout returns what f prints on stdout
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	f()
+	w.Close()
+	return <-out
+}
+
+// onTopic returns a repository on a topic branch without upstream, which the
+// remote has when pushed
+func onTopic(t *testing.T, pushed bool) *gittest.Repo {
+	t.Helper()
+	r := gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Branch("topic").Commit()
+	if pushed {
+		r.Git("push", "--quiet", "origin", "topic")
+	}
+	return r
+}
+
+// upstreamFixture returns the projects of the repositories, their results as
+// checked, and a config listing them in a saved file
+func upstreamFixture(t *testing.T, repos []*gittest.Repo) ([]scanner.Project, []reporter.ProjectResult, *config.Config) {
+	t.Helper()
+	var paths []string
+	projects := make([]scanner.Project, len(repos))
+	results := make([]reporter.ProjectResult, len(repos))
+	for i, r := range repos {
+		name := fmt.Sprintf("p%d", i)
+		projects[i] = scanner.Project{Name: name, Path: r.Path, Category: "dev", Repository: r.Repository()}
+		results[i] = reporter.ProjectResult{Name: name, Path: r.Path, Category: "dev", Status: checker.Status(context.Background(), projects[i], 0)}
+		paths = append(paths, "      - "+r.Path)
+	}
+
+	path := filepath.Join(t.TempDir(), "check-projects.yml")
+	content := "categories:\n  - name: dev\n    projects:\n" + strings.Join(paths, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig([]string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return projects, results, cfg
+}
+
+func TestHandleNoUpstream(t *testing.T) {
+	tests := []struct {
+		name    string
+		pushed  []bool // One repository each, its branch on the remote or not
+		answers []string
+		want    []git.StatusType
+		output  []string // Lines printed at the end
+		ignored []string // Projects saved to the ignore list
+	}{
+		{"all", []bool{true, true}, []string{"a"},
+			[]git.StatusType{git.StatusSync, git.StatusSync}, []string{"✅ p0: upstream configured", "✅ p1: upstream configured"}, nil},
+		{"all by default", []bool{true}, []string{""},
+			[]git.StatusType{git.StatusSync}, []string{"✅ p0: upstream configured"}, nil},
+		{"choose yes and no", []bool{true, true}, []string{"c", "y", "n"},
+			[]git.StatusType{git.StatusSync, git.StatusNoUpstream}, []string{"✅ p0: upstream configured", "   p1: skipped"}, nil},
+		{"choose then quit", []bool{true, true}, []string{"c", "y"},
+			[]git.StatusType{git.StatusSync, git.StatusNoUpstream}, []string{"✅ p0: upstream configured", "   p1: skipped"}, nil},
+		{"choose ignore", []bool{true, true}, []string{"c", "i", ""},
+			[]git.StatusType{git.StatusIgnored, git.StatusSync}, []string{"✅ p1: upstream configured", "✅ p0: added to the ignore list of 'dev'"}, []string{"p0"}},
+		{"ignore all", []bool{true, true}, []string{"i"},
+			[]git.StatusType{git.StatusIgnored, git.StatusIgnored}, []string{"✅ p0: added to the ignore list", "✅ p1: added to the ignore list"}, []string{"p0", "p1"}},
+		{"skip", []bool{true}, []string{"s"},
+			[]git.StatusType{git.StatusNoUpstream}, []string{"Skipped."}, nil},
+		{"quit", []bool{true}, nil,
+			[]git.StatusType{git.StatusNoUpstream}, nil, nil},
+		{"push branch not on remote", []bool{false}, []string{"a", "p"},
+			[]git.StatusType{git.StatusSync}, []string{"⚠ p0: ", "✅ p0: pushed, upstream configured"}, nil},
+		{"skip branch not on remote", []bool{false}, []string{"a", ""},
+			[]git.StatusType{git.StatusNoUpstream}, []string{"⚠ p0: ", "   p0: skipped"}, nil},
+		{"ignore branch not on remote", []bool{false}, []string{"a", "i"},
+			[]git.StatusType{git.StatusIgnored}, []string{"✅ p0: added to the ignore list"}, []string{"p0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := make([]*gittest.Repo, len(tt.pushed))
+			for i, pushed := range tt.pushed {
+				repos[i] = onTopic(t, pushed)
+			}
+			projects, results, cfg := upstreamFixture(t, repos)
+
+			p := &scriptedPrompter{answers: tt.answers}
+			var err error
+			out := captureStdout(t, func() {
+				err = handleNoUpstream(context.Background(), p, cfg, projects, results, checker.Options{})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(p.answers) > 0 {
+				t.Errorf("answers left unasked: %q", p.answers)
+			}
+			for i, want := range tt.want {
+				if got := results[i].Status.Type; got != want {
+					t.Errorf("p%d: %s (%s), want %s", i, got, results[i].Status.Message, want)
+				}
+			}
+			for _, line := range tt.output {
+				if !strings.Contains(out, line) {
+					t.Errorf("%q missing from the output:\n%s", line, out)
+				}
+			}
+
+			saved, err := config.LoadConfig(cfg.Files())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := saved.FindCategory("dev").Ignore; strings.Join(got, ",") != strings.Join(tt.ignored, ",") {
+				t.Errorf("ignore list %q, want %q", got, tt.ignored)
+			}
+		})
+	}
+}
+
+func TestHandleNoUpstreamReportsFailures(t *testing.T) {
+	r := onTopic(t, true)
+	r.Git("remote", "set-url", "origin", filepath.Join(t.TempDir(), "missing.git"))
+	projects, results, cfg := upstreamFixture(t, []*gittest.Repo{r})
+
+	out := captureStdout(t, func() {
+		_ = handleNoUpstream(context.Background(), &scriptedPrompter{answers: []string{"a"}}, cfg, projects, results, checker.Options{})
+	})
+	if !strings.Contains(out, "❌ p0: failed to set upstream") {
+		t.Errorf("no failure reported:\n%s", out)
+	}
+	if results[0].Status.Type != git.StatusNoUpstream {
+		t.Errorf("got %s after a failure, want %s", results[0].Status.Type, git.StatusNoUpstream)
+	}
+}
+
+func TestHandleNoUpstreamFilteredConfig(t *testing.T) {
+	projects, results, cfg := upstreamFixture(t, []*gittest.Repo{onTopic(t, true)})
+	cfg.IsFiltered = true
+
+	p := &scriptedPrompter{answers: []string{"i"}}
+	out := captureStdout(t, func() {
+		_ = handleNoUpstream(context.Background(), p, cfg, projects, results, checker.Options{})
+	})
+	if strings.Contains(p.questions[0], "[i]gnore") {
+		t.Errorf("ignoring offered with a filtered config: %q", p.questions[0])
+	}
+	if !strings.Contains(out, "Cannot ignore projects") || results[0].Status.Type != git.StatusNoUpstream {
+		t.Errorf("got %s:\n%s", results[0].Status.Type, out)
+	}
+}
+
+func TestHandleNoUpstreamKeepsCheckDetails(t *testing.T) {
+	tests := []struct {
+		name    string
+		pushed  bool
+		answers []string
+	}{
+		{"upstream set", true, []string{"a"}},
+		{"pushed", false, []string{"a", "p"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := onTopic(t, tt.pushed).Branch("spike").Commit().Checkout("topic")
+			projects, results, cfg := upstreamFixture(t, []*gittest.Repo{r})
+
+			opts := checker.Options{LocalBranches: func(scanner.Project) bool { return true }}
+			captureStdout(t, func() {
+				_ = handleNoUpstream(context.Background(), &scriptedPrompter{answers: tt.answers}, cfg, projects, results, opts)
+			})
+
+			status := results[0].Status
+			if status.Type != git.StatusSync || len(status.LocalOnlyBranches) != 1 || status.LocalOnlyBranches[0].Branch != "spike" {
+				t.Errorf("got %s with local-only branches %+v, want the spike branch", status.Type, status.LocalOnlyBranches)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/uralys/check-projects/internal/updater"
+)
+
+// versionInfo describes this build, for bug reports
+type versionInfo struct {
+	Version       string `json:"version"`
+	Commit        string `json:"commit"`
+	BuildTime     string `json:"build_time"`
+	GoVersion     string `json:"go_version"`
+	Platform      string `json:"platform"`
+	LatestVersion string `json:"latest_version,omitempty"` // From the last update check, if any
+	Outdated      bool   `json:"outdated"`
+}
+
+func newVersionCmd() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print version and build information",
+		Long: `Print the version, commit, build time, Go version and platform of this build.
+
+The latest release is taken from the last background update check; no network call is made.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			info := currentVersionInfo()
+			if asJSON {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(info)
+			}
+			printVersionInfo(os.Stdout, info)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print as JSON")
+	return cmd
+}
+
+// currentVersionInfo gathers build details and the cached latest release
+func currentVersionInfo() versionInfo {
+	info := versionInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if latest, _, ok := updater.CachedLatestVersion(); ok {
+		info.LatestVersion = latest
+		current := strings.TrimPrefix(Version, "v")
+		info.Outdated = current != "dev" && current != latest
+	}
+
+	return info
+}
+
+func printVersionInfo(w io.Writer, info versionInfo) {
+	fmt.Fprintf(w, "check-projects %s\n", info.Version)
+	fmt.Fprintf(w, "  commit:     %s\n", info.Commit)
+	fmt.Fprintf(w, "  built:      %s\n", info.BuildTime)
+	fmt.Fprintf(w, "  go:         %s\n", info.GoVersion)
+	fmt.Fprintf(w, "  platform:   %s\n", info.Platform)
+	if info.Outdated {
+		fmt.Fprintf(w, "  ⚠ %s is available, run check-projects --update\n", info.LatestVersion)
+	}
+}
+
+// versionTemplate renders --version with the same details as the version command
+func versionTemplate() string {
+	var b strings.Builder
+	printVersionInfo(&b, currentVersionInfo())
+	return b.String()
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/updater"
+	"github.com/uralys/check-projects/internal/watcher"
+	"github.com/uralys/check-projects/internal/webhook"
+	"github.com/uralys/check-projects/pkg/checkprojects"
+)
+
+// watchState remembers the status of every project at the previous iteration, keyed by path
+type watchState map[string]string
+
+// statusKey summarizes what a change of status means for the report
+func statusKey(status *git.Status) string {
+	return fmt.Sprintf("%s|%s|%s|%d", status.Type, status.Symbol, status.Branch, len(status.BehindBranches))
+}
+
+// markChanges flags the results whose status differs from prev and returns the new state.
+// Projects that were not part of the previous iteration are not flagged.
+func markChanges(prev watchState, results []reporter.ProjectResult) watchState {
+	next := make(watchState, len(results))
+	for i := range results {
+		key := statusKey(results[i].Status)
+		before, seen := prev[results[i].Path]
+		results[i].Changed = seen && before != key
+		next[results[i].Path] = key
+	}
+	return next
+}
+
+// watchLoop runs iterate immediately, then on every tick (with a nil change) and every
+// change seen on disk, until ctx is done. An iteration interrupted by ctx is dropped
+// without calling render.
+func watchLoop(ctx context.Context, ticks <-chan time.Time, changes <-chan watcher.Change,
+	iterate func(context.Context, *watcher.Change) ([]reporter.ProjectResult, error),
+	render func([]reporter.ProjectResult) error) error {
+	var state watchState
+	var change *watcher.Change
+	for {
+		results, err := iterate(ctx, change)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		state = markChanges(state, results)
+		if err := render(results); err != nil {
+			return err
+		}
+
+		change = nil
+		for change == nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticks:
+				change = &watcher.Change{Rescan: true}
+			case next, ok := <-changes:
+				if !ok {
+					changes = nil // The watcher stopped: only ticks are left
+					continue
+				}
+				change = &next
+			}
+		}
+	}
+}
+
+// runWatch re-checks and reports the projects as they change on disk until interrupted,
+// and all of them every watchInterval when set. A directory appearing under a root
+// (e.g. a new clone) scans the projects again; other changes only re-check the projects
+// they belong to. On terminals (text output) the screen is cleared between reports,
+// otherwise reports are separated by a timestamped delimiter.
+func runWatch(ctx context.Context, cfg *config.Config, opts checkprojects.Options, hooks []config.Webhook, machineOutput bool, updateCh <-chan *updater.UpdateResult) error {
+	w, err := watcher.New(watcher.DefaultDebounce)
+	if err != nil {
+		return fmt.Errorf("failed to watch the projects: %w", err)
+	}
+	defer w.Close()
+
+	var ticks <-chan time.Time
+	if watchInterval > 0 {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	clearScreen := !machineOutput && isTerminal(os.Stdout)
+	first := true
+	sender := webhook.NewSender()
+
+	var projects []scanner.Project       // Of the last full check
+	var results []reporter.ProjectResult // Of the last iteration
+	var dead []scanner.Warning           // Of the last full check
+	var unwatched error                  // Paths that could not be watched
+	iterate := func(ctx context.Context, change *watcher.Change) ([]reporter.ProjectResult, error) {
+		// --since and --stale-only pick the projects to report out of all of them
+		if change != nil && !change.Rescan && sinceFlag == "" && !staleOnly {
+			results = recheckProjects(ctx, cfg, opts, projects, results, change.Projects)
+			return results, nil
+		}
+
+		var err error
+		projects, results, dead, err = checkProjects(ctx, cfg, opts, machineOutput, nil)
+		if err == nil {
+			unwatched = w.Set(cfg, projects)
+		}
+		return results, err
+	}
+
+	render := func(results []reporter.ProjectResult) error {
+		now := time.Now().Format("2006-01-02 15:04:05")
+		if clearScreen {
+			fmt.Print("\033[H\033[2J")
+			status := fmt.Sprintf("Watching %d paths", w.Paths())
+			if watchInterval > 0 {
+				status = fmt.Sprintf("Every %s · watching %d paths", watchInterval, w.Paths())
+			}
+			fmt.Fprintf(logOut, "%s · last check %s · Ctrl+C to stop\n\n", status, now)
+		} else {
+			fmt.Fprintf(logOut, "--- %s ---\n", now)
+		}
+		if unwatched != nil {
+			fmt.Fprintf(logOut, "⚠ %v: they are only checked again with --watch=<interval>\n", unwatched)
+		}
+
+		if err := reportResults(cfg, results, opts.Timeout, machineOutput); err != nil {
+			return err
+		}
+		printDeadEntries(dead)
+		deliverWebhooks(ctx, sender, hooks, results)
+		if isFullRun(cfg) {
+			savePromptState(results)
+		}
+
+		if first {
+			first = false
+			updater.PrintUpdateNotice(logOut, updater.WaitForUpdate(updateCh, updateNoticeTimeout))
+		}
+		return nil
+	}
+
+	return watchLoop(ctx, ticks, w.Changes(ctx), iterate, render)
+}
+
+// recheckProjects checks again the projects at paths, out of those of the last full
+// check, and returns a copy of results with their new status. Nothing is fetched, as
+// fetching writes to the repositories and would be seen as another change.
+func recheckProjects(ctx context.Context, cfg *config.Config, opts checkprojects.Options, projects []scanner.Project, results []reporter.ProjectResult, paths []string) []reporter.ProjectResult {
+	var indexes []int
+	var subset []scanner.Project
+	for i, project := range projects {
+		if slices.Contains(paths, project.Path) {
+			indexes = append(indexes, i)
+			subset = append(subset, project)
+		}
+	}
+
+	opts.Fetch = false
+	checked, _ := checkprojects.Check(ctx, subset, opts)
+	rechecked := make([]reporter.ProjectResult, len(subset))
+	for j, result := range checked {
+		rechecked[j] = results[indexes[j]]
+		if result.Status != nil {
+			rechecked[j].Status = result.Status
+			rechecked[j].LastCommit = result.LastCommit
+		}
+	}
+	if (checkRemotes || cfg.CheckRemotes) && ctx.Err() == nil {
+		markUnreachableRemotes(ctx, cfg, subset, rechecked)
+	}
+	if sizesFlag && ctx.Err() == nil {
+		measureSizes(ctx, subset, rechecked)
+	}
+
+	next := slices.Clone(results)
+	for j, i := range indexes {
+		next[i] = rechecked[j]
+	}
+	return next
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/watcher"
+)
+
+// result builds the result of the project at path with a status of type statusType
+func result(path string, statusType git.StatusType, symbol string) reporter.ProjectResult {
+	return reporter.ProjectResult{Name: path, Path: path, Status: &git.Status{Type: statusType, Symbol: symbol, Branch: "main"}}
+}
+
+// changed returns the paths of the results flagged as changed
+func changed(results []reporter.ProjectResult) []string {
+	var paths []string
+	for _, r := range results {
+		if r.Changed {
+			paths = append(paths, r.Path)
+		}
+	}
+	return paths
+}
+
+func TestMarkChanges(t *testing.T) {
+	first := []reporter.ProjectResult{
+		result("/a", git.StatusSync, "✔"),
+		result("/b", git.StatusUnsync, "* M"),
+		result("/c", git.StatusSync, "✔"),
+	}
+	state := markChanges(nil, first)
+	if got := changed(first); got != nil {
+		t.Errorf("first iteration flagged %v", got)
+	}
+
+	second := []reporter.ProjectResult{
+		result("/a", git.StatusUnsync, "✱ ✚"), // New status
+		result("/b", git.StatusUnsync, "* M"), // Same status
+		result("/c", git.StatusSync, "✔"),
+		result("/d", git.StatusUnsync, "⬆"), // New project
+	}
+	second[2].Status.BehindBranches = []git.BranchTracking{{Branch: "topic", Message: "behind by 1 commit(s)"}}
+	state = markChanges(state, second)
+	if got, want := changed(second), []string{"/a", "/c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("flagged %v, want %v", got, want)
+	}
+
+	// Back to the same statuses: nothing changed since the previous iteration
+	third := []reporter.ProjectResult{
+		result("/a", git.StatusUnsync, "✱ ✚"),
+		result("/d", git.StatusUnsync, "⬆"),
+	}
+	third[0].Changed = true // Left over from an earlier render
+	markChanges(state, third)
+	if got := changed(third); got != nil {
+		t.Errorf("flagged %v, want none", got)
+	}
+}
+
+func TestMarkChangesBranch(t *testing.T) {
+	state := markChanges(nil, []reporter.ProjectResult{result("/a", git.StatusSync, "✔")})
+	next := []reporter.ProjectResult{result("/a", git.StatusSync, "✔")}
+	next[0].Status.Branch = "topic"
+	markChanges(state, next)
+	if !next[0].Changed {
+		t.Error("a checkout of another branch was not flagged")
+	}
+}
+
+// loop runs watchLoop in the background with the ticks and changes of the test, and
+// reports every iteration: the change it was given and the results rendered
+type loop struct {
+	ticks   chan time.Time
+	changes chan watcher.Change
+	calls   chan *watcher.Change
+	renders chan []reporter.ProjectResult
+	cancel  context.CancelFunc
+	done    chan error
+}
+
+// startLoop runs watchLoop, iterate returning the results of each call (from 1)
+func startLoop(t *testing.T, iterate func(call int) ([]reporter.ProjectResult, error)) *loop {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &loop{
+		ticks:   make(chan time.Time),
+		changes: make(chan watcher.Change),
+		calls:   make(chan *watcher.Change, 10),
+		renders: make(chan []reporter.ProjectResult, 10),
+		cancel:  cancel,
+		done:    make(chan error, 1),
+	}
+	call := 0
+	go func() {
+		l.done <- watchLoop(ctx, l.ticks, l.changes,
+			func(ctx context.Context, change *watcher.Change) ([]reporter.ProjectResult, error) {
+				l.calls <- change
+				call++
+				return iterate(call)
+			},
+			func(results []reporter.ProjectResult) error {
+				l.renders <- results
+				return nil
+			})
+	}()
+	t.Cleanup(cancel)
+	return l
+}
+
+// next returns the change given to the next iteration and what it rendered
+func (l *loop) next(t *testing.T) (*watcher.Change, []reporter.ProjectResult) {
+	t.Helper()
+	var change *watcher.Change
+	select {
+	case change = <-l.calls:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no iteration")
+	}
+	select {
+	case results := <-l.renders:
+		return change, results
+	case <-time.After(5 * time.Second):
+		t.Fatal("no render")
+	}
+	return nil, nil
+}
+
+// stop cancels the loop and returns its error
+func (l *loop) stop(t *testing.T) error {
+	t.Helper()
+	l.cancel()
+	select {
+	case err := <-l.done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("the loop did not stop")
+	}
+	return nil
+}
+
+func TestWatchLoop(t *testing.T) {
+	statuses := []git.StatusType{git.StatusSync, git.StatusSync, git.StatusUnsync, git.StatusUnsync}
+	l := startLoop(t, func(call int) ([]reporter.ProjectResult, error) {
+		return []reporter.ProjectResult{result("/a", statuses[(call-1)%len(statuses)], "")}, nil
+	})
+
+	// Right away, a full check
+	if change, results := l.next(t); change != nil || results[0].Changed {
+		t.Fatalf("first iteration: change %+v, changed %v", change, results[0].Changed)
+	}
+
+	// On a tick, a full check too
+	l.ticks <- time.Now()
+	if change, results := l.next(t); change == nil || !change.Rescan || results[0].Changed {
+		t.Fatalf("tick: change %+v, changed %v", change, results[0].Changed)
+	}
+
+	// On a change seen on disk, the projects it names, flagged when their status changed
+	l.changes <- watcher.Change{Projects: []string{"/a"}}
+	change, results := l.next(t)
+	if change == nil || change.Rescan || !reflect.DeepEqual(change.Projects, []string{"/a"}) {
+		t.Fatalf("change: got %+v", change)
+	}
+	if !results[0].Changed {
+		t.Error("change: the new status was not flagged")
+	}
+
+	l.changes <- watcher.Change{Rescan: true}
+	if change, results := l.next(t); change == nil || !change.Rescan || results[0].Changed {
+		t.Fatalf("rescan: change %+v, changed %v", change, results[0].Changed)
+	}
+
+	if err := l.stop(t); err != nil {
+		t.Errorf("stopped with %v", err)
+	}
+}
+
+func TestWatchLoopWithoutWatcher(t *testing.T) {
+	l := startLoop(t, func(int) ([]reporter.ProjectResult, error) { return nil, nil })
+	l.next(t)
+
+	close(l.changes) // The watcher stopped: ticks still re-check
+	l.ticks <- time.Now()
+	if change, _ := l.next(t); change == nil || !change.Rescan {
+		t.Fatalf("tick after the watcher stopped: change %+v", change)
+	}
+	if err := l.stop(t); err != nil {
+		t.Errorf("stopped with %v", err)
+	}
+}
+
+func TestWatchLoopErrors(t *testing.T) {
+	failure := errors.New("config gone")
+
+	err := watchLoop(context.Background(), nil, nil,
+		func(context.Context, *watcher.Change) ([]reporter.ProjectResult, error) { return nil, failure },
+		func([]reporter.ProjectResult) error { t.Error("rendered a failed iteration"); return nil })
+	if !errors.Is(err, failure) {
+		t.Errorf("iterate failure: got %v", err)
+	}
+
+	err = watchLoop(context.Background(), nil, nil,
+		func(context.Context, *watcher.Change) ([]reporter.ProjectResult, error) { return nil, nil },
+		func([]reporter.ProjectResult) error { return failure })
+	if !errors.Is(err, failure) {
+		t.Errorf("render failure: got %v", err)
+	}
+}
+
+func TestWatchLoopInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	err := watchLoop(ctx, nil, nil,
+		func(context.Context, *watcher.Change) ([]reporter.ProjectResult, error) {
+			cancel() // Ctrl+C during the check
+			return nil, context.Canceled
+		},
+		func([]reporter.ProjectResult) error { t.Error("rendered an interrupted iteration"); return nil })
+	if err != nil {
+		t.Errorf("got %v, want nil once interrupted", err)
+	}
+}
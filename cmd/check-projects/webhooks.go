@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/webhook"
+)
+
+// runWebhooks returns the webhooks of the config followed by the --webhook ones
+func runWebhooks(cfg *config.Config) []config.Webhook {
+	hooks := append([]config.Webhook(nil), cfg.Webhooks...)
+	for _, url := range webhookURLs {
+		hooks = append(hooks, config.Webhook{URL: url})
+	}
+	return hooks
+}
+
+// validateWebhooks checks every webhook before anything is checked
+func validateWebhooks(hooks []config.Webhook) error {
+	for _, hook := range hooks {
+		if err := webhook.Validate(hook); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliverWebhooks posts the JSON report of results to the webhooks whose only_on
+// condition holds, concurrently. Failures are reported on stderr and never change
+// the exit code.
+func deliverWebhooks(ctx context.Context, s *webhook.Sender, hooks []config.Webhook, results []reporter.ProjectResult) {
+	if len(hooks) == 0 {
+		return
+	}
+	body, err := json.Marshal(reporter.NewJSONReport(results))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠ Failed to encode the webhook report: %v\n", err)
+		return
+	}
+	dirty := reporter.ExitCode(results) != reporter.ExitClean
+
+	// Changes are detected on the state of the projects, leaving out the --watch marks
+	unmarked := append([]reporter.ProjectResult(nil), results...)
+	for i := range unmarked {
+		unmarked[i].Changed = false
+	}
+	state, _ := json.Marshal(reporter.NewJSONReport(unmarked))
+
+	var wg sync.WaitGroup
+	delivered := make([]bool, len(hooks))
+	for i, hook := range hooks {
+		switch hook.OnlyOn {
+		case config.WebhookDirty:
+			if !dirty {
+				continue
+			}
+		case config.WebhookChange:
+			if !webhook.Changed(hook.URL, state) {
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, hook config.Webhook) {
+			defer wg.Done()
+			if err := s.Send(ctx, hook, body); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠ Webhook to %s failed: %v\n", webhook.Host(hook.URL), err)
+				return
+			}
+			delivered[i] = true
+		}(i, hook)
+	}
+	wg.Wait()
+
+	// Recorded once every delivery is done, as they share the state file
+	for i, hook := range hooks {
+		if delivered[i] && hook.OnlyOn == config.WebhookChange {
+			webhook.Remember(hook.URL, state)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/webhook"
+)
+
+func TestDeliverWebhooksOnlyOn(t *testing.T) {
+	clean := []reporter.ProjectResult{result("/a", git.StatusSync, "✔")}
+	dirty := []reporter.ProjectResult{result("/a", git.StatusUnsync, "* M")}
+
+	tests := []struct {
+		name   string
+		onlyOn string
+		runs   [][]reporter.ProjectResult
+		want   int32 // Requests received over all runs
+	}{
+		{"always", config.WebhookAlways, [][]reporter.ProjectResult{clean, clean}, 2},
+		{"default", "", [][]reporter.ProjectResult{clean}, 1},
+		{"dirty, clean results", config.WebhookDirty, [][]reporter.ProjectResult{clean, clean}, 0},
+		{"dirty, dirty results", config.WebhookDirty, [][]reporter.ProjectResult{dirty, clean}, 1},
+		{"change, same results", config.WebhookChange, [][]reporter.ProjectResult{clean, clean, clean}, 1},
+		{"change, new results", config.WebhookChange, [][]reporter.ProjectResult{clean, dirty, dirty, clean}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir()) // Where the state of only_on: change is kept
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+			}))
+			defer server.Close()
+
+			hooks := []config.Webhook{{URL: server.URL, OnlyOn: tt.onlyOn}}
+			for _, results := range tt.runs {
+				deliverWebhooks(context.Background(), webhook.NewSender(), hooks, results)
+			}
+			if got := requests.Load(); got != tt.want {
+				t.Errorf("%d requests, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliverWebhooksChangeIgnoresWatchMarks(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	hooks := []config.Webhook{{URL: server.URL, OnlyOn: config.WebhookChange}}
+	results := []reporter.ProjectResult{result("/a", git.StatusSync, "✔")}
+	deliverWebhooks(context.Background(), webhook.NewSender(), hooks, results)
+	results[0].Changed = true
+	deliverWebhooks(context.Background(), webhook.NewSender(), hooks, results)
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("%d requests, want 1: only the Δ mark changed", got)
+	}
+}
@@ -0,0 +1,116 @@
+package checker
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// Adaptive concurrency: each storage device starts with one worker per CPU, halves
+// its workers while checks are slow and adds them back one by one once they are fast
+const (
+	minDeviceWorkers = 2
+	slowCheck        = 500 * time.Millisecond // Median duration of a saturated device
+	fastCheck        = 100 * time.Millisecond // Median duration of a device with room to spare
+	latencySamples   = 5                      // Checks between two adjustments
+)
+
+// deviceLimiter bounds the checks running at once on a storage device
+type deviceLimiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	limit    int
+	maxLimit int
+	running  int
+	samples  []time.Duration
+}
+
+func newDeviceLimiter(start, maxLimit int) *deviceLimiter {
+	d := &deviceLimiter{limit: start, maxLimit: maxLimit}
+	d.cond = sync.NewCond(&d.mu)
+	return d
+}
+
+// acquire waits for a free worker
+func (d *deviceLimiter) acquire() {
+	d.mu.Lock()
+	for d.running >= d.limit {
+		d.cond.Wait()
+	}
+	d.running++
+	d.mu.Unlock()
+}
+
+// release frees a worker and records how long its check took, adapting the limit
+// every latencySamples checks. When the limit changed, it returns the previous and new ones.
+func (d *deviceLimiter) release(took time.Duration) (previous, limit int, median time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	defer d.cond.Broadcast()
+
+	d.running--
+	d.samples = append(d.samples, took)
+	if len(d.samples) < latencySamples {
+		return 0, d.limit, 0
+	}
+
+	median = medianDuration(d.samples)
+	d.samples = d.samples[:0]
+	switch {
+	case median > slowCheck && d.limit > minDeviceWorkers:
+		previous, d.limit = d.limit, max(d.limit/2, minDeviceWorkers)
+	case median < fastCheck && d.limit < d.maxLimit:
+		previous, d.limit = d.limit, d.limit+1
+	}
+	return previous, d.limit, median
+}
+
+func medianDuration(samples []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+// forEachAdaptive calls fn for every project like ForEach, with the number of calls
+// running at once adapted to the latency of the device holding each project
+func forEachAdaptive(projects []scanner.Project, logf func(format string, args ...interface{}), fn func(index int, project scanner.Project)) {
+	start := runtime.NumCPU()
+	var mu sync.Mutex
+	limiters := make(map[string]*deviceLimiter)
+	limiterFor := func(device string) *deviceLimiter {
+		mu.Lock()
+		defer mu.Unlock()
+		if d, ok := limiters[device]; ok {
+			return d
+		}
+		d := newDeviceLimiter(max(start, minDeviceWorkers), 4*max(start, minDeviceWorkers))
+		limiters[device] = d
+		logf("device %s: starting with %d workers", device, d.limit)
+		return d
+	}
+
+	var wg sync.WaitGroup
+	for i, project := range projects {
+		wg.Add(1)
+		go func(idx int, proj scanner.Project) {
+			defer wg.Done()
+			device := deviceOf(proj.Path)
+			if device == "" {
+				device = "unknown"
+			}
+			limiter := limiterFor(device)
+
+			limiter.acquire()
+			began := time.Now()
+			fn(idx, proj)
+			if previous, limit, median := limiter.release(time.Since(began)); previous != 0 {
+				logf("device %s: %d workers → %d (median check %s)", device, previous, limit, median.Round(time.Millisecond))
+			}
+		}(i, project)
+	}
+
+	wg.Wait()
+}
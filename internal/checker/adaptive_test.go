@@ -0,0 +1,205 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// simulate runs rounds of checks on a device limiter: each round starts as many checks
+// as the limit allows, all taking latency(checks running at once), and returns the
+// limit after each round
+func simulate(d *deviceLimiter, rounds int, latency func(running int) time.Duration) []int {
+	limits := make([]int, rounds)
+	for round := range limits {
+		running := d.limit
+		for i := 0; i < running; i++ {
+			d.acquire()
+		}
+		for i := 0; i < running; i++ {
+			d.release(latency(running))
+		}
+		limits[round] = d.limit
+	}
+	return limits
+}
+
+func TestDeviceLimiterFastDevice(t *testing.T) {
+	d := newDeviceLimiter(4, 16)
+	limits := simulate(d, 50, func(int) time.Duration { return 10 * time.Millisecond })
+
+	for i := 1; i < len(limits); i++ {
+		if limits[i] < limits[i-1] {
+			t.Fatalf("limit went down on a fast device: %v", limits)
+		}
+	}
+	if got := limits[len(limits)-1]; got != 16 {
+		t.Errorf("limit %d, want the maximum of 16: %v", got, limits)
+	}
+}
+
+func TestDeviceLimiterSaturatedDevice(t *testing.T) {
+	d := newDeviceLimiter(16, 64)
+	limits := simulate(d, 20, func(int) time.Duration { return time.Second })
+
+	if got := limits[len(limits)-1]; got != minDeviceWorkers {
+		t.Errorf("limit %d, want the minimum of %d: %v", got, minDeviceWorkers, limits)
+	}
+	for _, limit := range limits {
+		if limit < minDeviceWorkers {
+			t.Fatalf("limit below the minimum: %v", limits)
+		}
+	}
+}
+
+func TestDeviceLimiterSettles(t *testing.T) {
+	// A spinning disk: every check at once slows all of them down
+	latency := func(running int) time.Duration { return time.Duration(running) * 20 * time.Millisecond }
+
+	for _, start := range []int{2, 64} {
+		d := newDeviceLimiter(start, 64)
+		limits := simulate(d, 100, latency)
+
+		// Past the first adjustments, the limit stays where checks are neither slow nor fast
+		settled := limits[50]
+		for _, limit := range limits[50:] {
+			if limit != settled {
+				t.Fatalf("starting with %d: the limit does not settle: %v", start, limits)
+			}
+		}
+		if got := latency(settled); got < fastCheck || got > slowCheck {
+			t.Errorf("starting with %d: settled on %d workers, checks of %s: %v", start, settled, got, limits)
+		}
+	}
+}
+
+func TestDeviceLimiterAdjustsEverySamples(t *testing.T) {
+	d := newDeviceLimiter(8, 32)
+	for i := 1; i < latencySamples; i++ {
+		d.acquire()
+		if previous, limit, _ := d.release(time.Second); previous != 0 || limit != 8 {
+			t.Fatalf("adjusted after %d samples: %d → %d", i, previous, limit)
+		}
+	}
+	d.acquire()
+	previous, limit, median := d.release(time.Second)
+	if previous != 8 || limit != 4 || median != time.Second {
+		t.Errorf("got %d → %d (median %s), want 8 → 4 (median 1s)", previous, limit, median)
+	}
+
+	// Between fast and slow, the limit is kept
+	for i := 0; i < latencySamples; i++ {
+		d.acquire()
+		previous, limit, _ = d.release(200 * time.Millisecond)
+	}
+	if previous != 0 || limit != 4 {
+		t.Errorf("got %d → %d, want the limit kept", previous, limit)
+	}
+}
+
+func TestDeviceLimiterMedianIgnoresOutliers(t *testing.T) {
+	d := newDeviceLimiter(4, 16)
+	durations := []time.Duration{10 * time.Millisecond, 20 * time.Second, 30 * time.Millisecond, 20 * time.Millisecond, 15 * time.Second}
+	var previous, limit int
+	for _, took := range durations {
+		d.acquire()
+		previous, limit, _ = d.release(took)
+	}
+	if previous != 4 || limit != 5 {
+		t.Errorf("got %d → %d, want 4 → 5: two slow checks out of five are outliers", previous, limit)
+	}
+}
+
+func TestDeviceLimiterBoundsRunningChecks(t *testing.T) {
+	d := newDeviceLimiter(3, 3)
+	var mu sync.Mutex
+	running, peak := 0, 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.acquire()
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			d.release(200 * time.Millisecond)
+		}()
+	}
+	wg.Wait()
+	if peak > 3 {
+		t.Errorf("%d checks at once, want at most 3", peak)
+	}
+}
+
+func TestForEachAdaptive(t *testing.T) {
+	dir := t.TempDir()
+	projects := make([]scanner.Project, 30)
+	for i := range projects {
+		projects[i] = scanner.Project{Name: fmt.Sprintf("p%d", i), Path: dir}
+	}
+
+	var mu sync.Mutex
+	var logs []string
+	seen := make(map[int]int)
+	forEachAdaptive(projects, func(format string, args ...interface{}) {
+		mu.Lock()
+		logs = append(logs, fmt.Sprintf(format, args...))
+		mu.Unlock()
+	}, func(index int, project scanner.Project) {
+		mu.Lock()
+		seen[index]++
+		mu.Unlock()
+	})
+
+	for i := range projects {
+		if seen[i] != 1 {
+			t.Errorf("project %d called %d times", i, seen[i])
+		}
+	}
+	starts := 0
+	for _, line := range logs {
+		if strings.Contains(line, "starting with") {
+			starts++
+		}
+	}
+	if starts != 1 {
+		t.Errorf("%d limiters for projects on one device: %q", starts, logs)
+	}
+}
+
+func TestPaceFits(t *testing.T) {
+	var p pace
+	if !p.fits(context.Background()) {
+		t.Error("no deadline: a check does not fit")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if !p.fits(ctx) {
+		t.Error("before any check: a check does not fit")
+	}
+
+	p.record(10 * time.Second)
+	p.record(30 * time.Second)
+	if !p.fits(ctx) {
+		t.Error("checks of 20s do not fit in an hour")
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShort()
+	if p.fits(short) {
+		t.Error("checks of 20s fit in 5s")
+	}
+}
@@ -0,0 +1,407 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// DefaultConcurrency is the number of repositories checked at once when not configured
+const DefaultConcurrency = 10
+
+// Options controls how projects are checked
+type Options struct {
+	Concurrency  int              // Maximum number of repositories checked at once (0 = adapted to each disk's latency)
+	Timeout      time.Duration    // Per-repository limit for each git operation (0 = none)
+	Fetch        bool             // Fetch each repository before checking it (failed fetches are only reported when refused credentials)
+	FetchRetry   Retry            // Retries of fetches failing for a transient reason
+	FetchOptions git.FetchOptions // What fetches update (prune, remotes)
+	LastCommits  bool             // Also read the date of each repository's last commit
+	DiffStats    bool             // Also count the lines changed in repositories with local changes
+
+	// StaleAfter returns the age of the last commit past which a clean project is
+	// StatusStale (nil or 0 = never)
+	StaleAfter func(project scanner.Project) time.Duration
+
+	// LocalBranches reports whether to look for the project's local branches without
+	// upstream holding unpushed commits (nil = never)
+	LocalBranches func(project scanner.Project) bool
+
+	// MergedBranches reports whether to list the project's local branches merged
+	// into the default branch of its remote (nil = never)
+	MergedBranches func(project scanner.Project) bool
+
+	// OffDefaultBranch reports whether to flag the project when its current branch is
+	// not the default branch of its remote (nil = never)
+	OffDefaultBranch func(project scanner.Project) bool
+
+	// UnpushedTags reports whether to list the project's local tags its remote does
+	// not have, which lists the tags of the remote over the network (nil = never)
+	UnpushedTags func(project scanner.Project) bool
+
+	// ExpectedEmail returns the patterns the user.email of the project must match
+	// (nil or none = not checked)
+	ExpectedEmail func(project scanner.Project) config.Patterns
+
+	// CompareRemotes returns the remotes whose matching branch the project's current
+	// branch is compared with (nil = none)
+	CompareRemotes func(project scanner.Project) []string
+
+	// Logf receives debug messages, such as concurrency changes (nil = discarded)
+	Logf func(format string, args ...interface{})
+}
+
+func (o Options) staleAfter(project scanner.Project) time.Duration {
+	if o.StaleAfter == nil {
+		return 0
+	}
+	return o.StaleAfter(project)
+}
+
+func (o Options) localBranches(project scanner.Project) bool {
+	return o.LocalBranches != nil && o.LocalBranches(project)
+}
+
+func (o Options) mergedBranches(project scanner.Project) bool {
+	return o.MergedBranches != nil && o.MergedBranches(project)
+}
+
+func (o Options) offDefaultBranch(project scanner.Project) bool {
+	return o.OffDefaultBranch != nil && o.OffDefaultBranch(project)
+}
+
+func (o Options) unpushedTags(project scanner.Project) bool {
+	return o.UnpushedTags != nil && o.UnpushedTags(project)
+}
+
+func (o Options) expectedEmail(project scanner.Project) config.Patterns {
+	if o.ExpectedEmail == nil {
+		return nil
+	}
+	return o.ExpectedEmail(project)
+}
+
+func (o Options) compareRemotes(project scanner.Project) []string {
+	if o.CompareRemotes == nil {
+		return nil
+	}
+	return o.CompareRemotes(project)
+}
+
+func (o Options) logf(format string, args ...interface{}) {
+	if o.Logf != nil {
+		o.Logf(format, args...)
+	}
+}
+
+// WithTimeout derives the context for one repository operation (no deadline when timeout is 0)
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Status returns the git status of a single project.
+// It never returns nil: failures are reported as error, timeout, permission or broken symlink statuses.
+func Status(ctx context.Context, project scanner.Project, timeout time.Duration) *git.Status {
+	if project.Unversioned {
+		return git.NewUnversionedStatus()
+	}
+	if project.Repository == nil {
+		return &git.Status{Type: git.StatusBrokenSymlink, Message: "Broken symlink: its target is missing", Symbol: "🔗 ✗"}
+	}
+
+	ctx, cancel := WithTimeout(ctx, timeout)
+	defer cancel()
+
+	status, err := project.Repository.GetStatus(ctx)
+	if err != nil {
+		var timeoutErr *git.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			return git.NewTimeoutStatus(timeoutErr, timeout)
+		}
+		if status := git.PermissionStatus(project.Path, "", err); status != nil {
+			return status
+		}
+
+		// Handle error by marking as error status
+		return &git.Status{
+			Type:    git.StatusError,
+			Message: err.Error(),
+			Symbol:  "❌",
+		}
+	}
+
+	return status
+}
+
+// Recheck returns the status of a project checked once more (e.g. after a fetch),
+// with the local-only branches and staleness of opts. lastCommit is the date
+// of its last commit when known.
+func Recheck(ctx context.Context, project scanner.Project, lastCommit time.Time, opts Options) *git.Status {
+	status := Status(ctx, project, opts.Timeout)
+	addBranches(ctx, project, status, opts)
+	addDiffStat(ctx, project, status, opts)
+	checkIdentity(ctx, project, status, opts)
+	markOffDefault(project, status, opts)
+	return Stale(status, lastCommit, opts.staleAfter(project), time.Now())
+}
+
+// Stale returns the stale status of a clean repository whose last commit is older
+// than threshold, and status itself otherwise: local changes, commits to sync,
+// branches behind their remote and local-only branches all beat staleness
+func Stale(status *git.Status, lastCommit time.Time, threshold time.Duration, now time.Time) *git.Status {
+	if threshold <= 0 || lastCommit.IsZero() || status.Type != git.StatusSync || status.BranchesNeedAttention() {
+		return status
+	}
+	age := now.Sub(lastCommit)
+	if age <= threshold {
+		return status
+	}
+	stale := *status
+	stale.Type = git.StatusStale
+	stale.Message = "No commit for " + git.FormatAge(age)
+	stale.Symbol = "🕓"
+	return &stale
+}
+
+// Result is the status of the project at Index in the slice given to Stream
+type Result struct {
+	Index      int
+	Project    scanner.Project
+	Status     *git.Status
+	LastCommit time.Time // With Options.LastCommits or a StaleAfter threshold (zero without commits)
+}
+
+// ForEach calls fn for every project, running at most concurrency calls at once
+// (DefaultConcurrency when not positive). It returns once every call has returned.
+func ForEach(projects []scanner.Project, concurrency int, fn func(index int, project scanner.Project)) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i, project := range projects {
+		wg.Add(1)
+		go func(idx int, proj scanner.Project) {
+			defer wg.Done()
+			sem <- struct{}{}        // Acquire semaphore
+			defer func() { <-sem }() // Release semaphore
+
+			fn(idx, proj)
+		}(i, project)
+	}
+
+	wg.Wait()
+}
+
+// Stream checks projects concurrently and sends each result as soon as it is ready.
+// Without a fixed Concurrency, the number of checks at once is adapted per storage device.
+// The channel is closed once every project has been checked.
+// Once ctx is cancelled, no new check is started and interrupted checks send no result.
+// Near ctx's deadline, checks that would not complete in time are not started either.
+func Stream(ctx context.Context, projects []scanner.Project, opts Options) <-chan Result {
+	results := make(chan Result, max(opts.Concurrency, 1))
+	var checks pace
+
+	check := func(idx int, proj scanner.Project) {
+		if ctx.Err() != nil || !checks.fits(ctx) {
+			return
+		}
+		began := time.Now()
+		var fetchErr error
+		if opts.Fetch {
+			_, fetchErr = FetchWithRetry(ctx, proj, opts.Timeout, opts.FetchOptions, opts.FetchRetry)
+		}
+		result := Result{Index: idx, Project: proj, Status: Status(ctx, proj, opts.Timeout)}
+		addBranches(ctx, proj, result.Status, opts)
+		addDiffStat(ctx, proj, result.Status, opts)
+		checkIdentity(ctx, proj, result.Status, opts)
+		markOffDefault(proj, result.Status, opts)
+		// Other fetch failures are not reported: the status compares with what was fetched before
+		if authErr := AuthFailure(ctx, proj, opts.Timeout, fetchErr); authErr != nil && result.Status.Type != git.StatusIgnored {
+			result.Status = git.NewAuthRequiredStatus(authErr, result.Status)
+		}
+		threshold := opts.staleAfter(proj)
+		if opts.LastCommits || threshold > 0 {
+			result.LastCommit = lastCommit(ctx, proj, opts.Timeout)
+		}
+		result.Status = Stale(result.Status, result.LastCommit, threshold, time.Now())
+		if ctx.Err() != nil {
+			return
+		}
+		checks.record(time.Since(began))
+		results <- result
+	}
+
+	go func() {
+		if opts.Concurrency > 0 {
+			opts.logf("checking %d repositories at once", opts.Concurrency)
+			ForEach(projects, opts.Concurrency, check)
+		} else {
+			forEachAdaptive(projects, opts.logf, check)
+		}
+		close(results)
+	}()
+
+	return results
+}
+
+// Fetch fetches a project's remote, bounded by timeout
+func Fetch(ctx context.Context, project scanner.Project, timeout time.Duration, opts git.FetchOptions) (git.FetchResult, error) {
+	if project.Repository == nil {
+		return git.FetchResult{}, nil
+	}
+
+	ctx, cancel := WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return project.Repository.Fetch(ctx, opts)
+}
+
+// AuthFailure returns the remote that refused the credentials when err is a fetch
+// failing for that reason, and nil otherwise. The remote is origin (or the first
+// one) for git, the default path for Mercurial.
+func AuthFailure(ctx context.Context, project scanner.Project, timeout time.Duration, err error) *git.RemoteError {
+	var fetchErr *git.FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.Class() != git.RemoteAuth {
+		return nil
+	}
+	authErr := &git.RemoteError{Remote: "default", Class: git.RemoteAuth, Message: fetchErr.Reason()}
+
+	// Both git backends, not Mercurial
+	repo, ok := project.Repository.(interface {
+		Remotes(ctx context.Context) ([]string, error)
+		RemoteURL(ctx context.Context, remote string) (string, error)
+	})
+	if !ok {
+		return authErr
+	}
+	ctx, cancel := WithTimeout(ctx, timeout)
+	defer cancel()
+	names, _ := repo.Remotes(ctx)
+	switch {
+	case slices.Contains(names, "origin"):
+		authErr.Remote = "origin"
+	case len(names) > 0:
+		authErr.Remote = names[0]
+	}
+	authErr.URL, _ = repo.RemoteURL(ctx, authErr.Remote)
+	return authErr
+}
+
+// LastCommits returns the date of the last commit of every project, in the same order.
+// Repositories without commits, broken symlinks and failures are left as the zero time.
+func LastCommits(ctx context.Context, projects []scanner.Project, opts Options) []time.Time {
+	times := make([]time.Time, len(projects))
+	ForEach(projects, opts.Concurrency, func(idx int, proj scanner.Project) {
+		times[idx] = lastCommit(ctx, proj, opts.Timeout)
+	})
+	return times
+}
+
+// lastCommit returns the date of a project's last commit, or the zero time
+func lastCommit(ctx context.Context, project scanner.Project, timeout time.Duration) time.Time {
+	if project.Repository == nil || ctx.Err() != nil {
+		return time.Time{}
+	}
+	ctx, cancel := WithTimeout(ctx, timeout)
+	defer cancel()
+
+	last, err := project.Repository.LastCommitTime(ctx)
+	if err != nil {
+		return time.Time{}
+	}
+	return last
+}
+
+// markOffDefault flags a status whose current branch is not the default branch of
+// its remote, as enabled by opts (never when detached or the default is unknown)
+func markOffDefault(project scanner.Project, status *git.Status, opts Options) {
+	status.OffDefaultBranch = opts.offDefaultBranch(project) && status.DefaultBranch != "" &&
+		status.Branch != "" && status.Branch != "HEAD" && status.Branch != status.DefaultBranch
+}
+
+// addDiffStat adds to the status of a git project with changes to tracked files the
+// lines they insert and delete, with opts.DiffStats (nothing on failure)
+func addDiffStat(ctx context.Context, project scanner.Project, status *git.Status, opts Options) {
+	repo, ok := project.Repository.(*git.Repository)
+	if !opts.DiffStats || !ok || repo.Bare || ctx.Err() != nil || status.Changes == status.UntrackedCount {
+		return
+	}
+	opCtx, cancel := WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	if stat, err := repo.DiffStat(opCtx); err == nil && !stat.Empty() {
+		status.DiffStat = &stat
+	}
+}
+
+// checkIdentity flags the status of a git project whose user.email matches none of
+// the patterns of opts (nothing when it has none, for other VCS and on failure)
+func checkIdentity(ctx context.Context, project scanner.Project, status *git.Status, opts Options) {
+	patterns := opts.expectedEmail(project)
+	repo, ok := project.Repository.(*git.Repository)
+	if len(patterns) == 0 || !ok || ctx.Err() != nil {
+		return
+	}
+	switch status.Type {
+	case git.StatusSync, git.StatusUnsync, git.StatusNoUpstream, git.StatusNoRemote, git.StatusEmpty:
+	default:
+		return
+	}
+
+	opCtx, cancel := WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	email, err := repo.UserEmail(opCtx)
+	if err != nil {
+		return
+	}
+	status.UserEmail = email
+	status.IdentityMismatch = !patterns.Match(email)
+}
+
+// addBranches adds to the status of a git project that could be read its local-only
+// branches, its branches merged into the default one, its unpushed tags and its
+// comparisons with other remotes, as enabled by opts (nothing for other VCS, bare
+// repositories and on failure)
+func addBranches(ctx context.Context, project scanner.Project, status *git.Status, opts Options) {
+	repo, ok := project.Repository.(*git.Repository)
+	if !ok || repo.Bare || ctx.Err() != nil {
+		return
+	}
+	switch status.Type {
+	case git.StatusSync, git.StatusUnsync, git.StatusNoUpstream, git.StatusNoRemote:
+	default:
+		return
+	}
+
+	if opts.localBranches(project) {
+		opCtx, cancel := WithTimeout(ctx, opts.Timeout)
+		status.LocalOnlyBranches, _ = repo.LocalOnlyBranches(opCtx)
+		cancel()
+	}
+	if opts.mergedBranches(project) && status.Origin != nil {
+		opCtx, cancel := WithTimeout(ctx, opts.Timeout)
+		status.MergedInto, status.MergedBranches, _ = repo.MergedBranches(opCtx, status.Origin.Name, status.DefaultBranch)
+		cancel()
+	}
+	if opts.unpushedTags(project) && status.Origin != nil {
+		opCtx, cancel := WithTimeout(ctx, opts.Timeout)
+		status.UnpushedTags, _ = repo.UnpushedTags(opCtx, status.Origin.Name)
+		cancel()
+	}
+	if remotes := opts.compareRemotes(project); len(remotes) > 0 {
+		opCtx, cancel := WithTimeout(ctx, opts.Timeout)
+		status.RemoteComparisons, _ = repo.CompareRemotes(opCtx, remotes)
+		cancel()
+	}
+}
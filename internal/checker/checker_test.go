@@ -0,0 +1,190 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/gittest"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/vcs"
+)
+
+// generateProjects builds n repositories cycling through the usual states: up to
+// date, with local changes, ahead, behind and without upstream
+func generateProjects(tb testing.TB, n int) []scanner.Project {
+	tb.Helper()
+	projects := make([]scanner.Project, n)
+	for i := range projects {
+		r := gittest.NewRepo(tb).Commit("README.md", "main.go").WithBareRemote().PushAll()
+		switch i % 5 {
+		case 1:
+			r.Modify("main.go").Untracked("notes.txt")
+		case 2:
+			r.Ahead(1)
+		case 3:
+			r.Behind(1)
+		case 4:
+			r.Branch("topic")
+		}
+		projects[i] = scanner.Project{
+			Name:       fmt.Sprintf("project-%d", i),
+			Path:       r.Path,
+			Category:   "bench",
+			Repository: r.Repository(),
+			Origin:     scanner.OriginScanned,
+		}
+	}
+	return projects
+}
+
+func TestStream(t *testing.T) {
+	projects := generateProjects(t, 10)
+	want := map[int]git.StatusType{
+		0: git.StatusSync, 1: git.StatusUnsync, 2: git.StatusUnsync, 3: git.StatusUnsync, 4: git.StatusNoUpstream,
+	}
+
+	for _, concurrency := range []int{0, 3} {
+		seen := make(map[int]bool)
+		for result := range Stream(context.Background(), projects, Options{Concurrency: concurrency}) {
+			if seen[result.Index] {
+				t.Errorf("concurrency %d: %s checked twice", concurrency, result.Project.Name)
+			}
+			seen[result.Index] = true
+			if result.Project.Path != projects[result.Index].Path {
+				t.Errorf("concurrency %d: result %d is for %s", concurrency, result.Index, result.Project.Path)
+			}
+			if got := result.Status.Type; got != want[result.Index%5] {
+				t.Errorf("concurrency %d: %s is %s (%s), want %s", concurrency, result.Project.Name, got, result.Status.Message, want[result.Index%5])
+			}
+		}
+		if len(seen) != len(projects) {
+			t.Errorf("concurrency %d: %d results, want %d", concurrency, len(seen), len(projects))
+		}
+	}
+}
+
+func TestStreamCancelled(t *testing.T) {
+	projects := generateProjects(t, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for result := range Stream(ctx, projects, Options{}) {
+		t.Errorf("%s checked after cancellation", result.Project.Name)
+	}
+}
+
+// slowRepo is a repository whose status never comes: GetStatus blocks until its
+// context is done
+type slowRepo struct {
+	vcs.Repository // Only GetStatus is called
+	started        chan<- string
+	returned       *atomic.Int32
+	path           string
+}
+
+func (r *slowRepo) GetStatus(ctx context.Context) (*git.Status, error) {
+	r.started <- r.path
+	<-ctx.Done()
+	r.returned.Add(1)
+	return nil, ctx.Err()
+}
+
+func TestStreamCancelStopsInFlightChecks(t *testing.T) {
+	const concurrency = 2
+	started := make(chan string, 10)
+	var returned atomic.Int32
+	projects := make([]scanner.Project, 6)
+	for i := range projects {
+		path := fmt.Sprintf("/slow/%d", i)
+		projects[i] = scanner.Project{Name: path, Path: path, Repository: &slowRepo{started: started, returned: &returned, path: path}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := Stream(ctx, projects, Options{Concurrency: concurrency})
+	for i := 0; i < concurrency; i++ {
+		<-started
+	}
+	cancel()
+
+	done := make(chan []Result)
+	go func() {
+		var got []Result
+		for result := range results {
+			got = append(got, result)
+		}
+		done <- got
+	}()
+	select {
+	case got := <-done:
+		for _, result := range got {
+			t.Errorf("%s sent a result after cancellation", result.Project.Name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream is still open after cancellation")
+	}
+
+	if n := len(started); n != 0 {
+		t.Errorf("%d checks started after cancellation", n)
+	}
+	if n := returned.Load(); n != concurrency {
+		t.Errorf("%d of the %d checks in flight returned", n, concurrency)
+	}
+}
+
+func BenchmarkStream(b *testing.B) {
+	projects := generateProjects(b, 50)
+	for _, concurrency := range []int{0, 1, DefaultConcurrency} {
+		name := fmt.Sprintf("concurrency=%d", concurrency)
+		if concurrency == 0 {
+			name = "concurrency=adaptive"
+		}
+		b.Run(name, func(b *testing.B) {
+			opts := Options{Concurrency: concurrency, LastCommits: true}
+			for i := 0; i < b.N; i++ {
+				checked := 0
+				for range Stream(context.Background(), projects, opts) {
+					checked++
+				}
+				if checked != len(projects) {
+					b.Fatalf("%d projects checked, want %d", checked, len(projects))
+				}
+			}
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(projects)), "ns/project")
+		})
+	}
+}
+
+func TestStaleKeepsStatusDetails(t *testing.T) {
+	now := time.Now()
+	status := &git.Status{
+		Type:              git.StatusSync,
+		Message:           "Up to date",
+		Symbol:            "✔",
+		Branch:            "main",
+		StashCount:        2,
+		DefaultBranch:     "main",
+		UserEmail:         "me@example.com",
+		RemoteComparisons: []git.RemoteComparison{{Remote: "upstream", Ref: "upstream/main", Behind: 3}},
+	}
+
+	stale := Stale(status, now.Add(-48*time.Hour), 24*time.Hour, now)
+	if stale.Type != git.StatusStale || stale.Symbol != "🕓" {
+		t.Fatalf("got %s %q, want stale", stale.Type, stale.Symbol)
+	}
+	want := *status
+	want.Type, want.Message, want.Symbol = stale.Type, stale.Message, stale.Symbol
+	if !reflect.DeepEqual(*stale, want) {
+		t.Errorf("got %+v, want the details of %+v", *stale, want)
+	}
+	if status.Type != git.StatusSync {
+		t.Error("the checked status was modified")
+	}
+
+	if got := Stale(status, now.Add(-time.Hour), 24*time.Hour, now); got != status {
+		t.Errorf("recent commit: got %+v, want the status itself", got)
+	}
+}
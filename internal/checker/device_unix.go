@@ -0,0 +1,17 @@
+//go:build !windows
+
+package checker
+
+import (
+	"strconv"
+	"syscall"
+)
+
+// deviceOf identifies the storage device holding path (empty when unknown)
+func deviceOf(path string) string {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(st.Dev), 10)
+}
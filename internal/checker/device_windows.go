@@ -0,0 +1,17 @@
+//go:build windows
+
+package checker
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// deviceOf identifies the volume holding path (empty when unknown)
+func deviceOf(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	return strings.ToUpper(filepath.VolumeName(abs))
+}
@@ -0,0 +1,43 @@
+package checker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// FetchProgress is told about each fetch of FetchAll as it completes: how many are
+// done out of total, and the project with its error (nil when it succeeded)
+type FetchProgress func(done, total int, project scanner.Project, err error)
+
+// FetchAll fetches every project, at most concurrency at once (DefaultConcurrency
+// when not positive), with the timeout, retries and fetch options of opts.
+// A failed fetch never stops the others: failures are returned by project index
+// (none when every fetch succeeded). progress, when set, is called once per
+// project, never concurrently.
+func FetchAll(ctx context.Context, projects []scanner.Project, concurrency int, opts Options, progress FetchProgress) map[int]error {
+	var mu sync.Mutex
+	failures := make(map[int]error)
+	done := 0
+
+	ForEach(projects, concurrency, func(idx int, proj scanner.Project) {
+		var err error
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		} else {
+			_, err = FetchWithRetry(ctx, proj, opts.Timeout, opts.FetchOptions, opts.FetchRetry)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failures[idx] = err
+		}
+		done++
+		if progress != nil {
+			progress(done, len(projects), proj, err)
+		}
+	})
+	return failures
+}
@@ -0,0 +1,38 @@
+package checker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pace tracks how long checks take, so that no check is started when ctx's
+// deadline (the run's time budget) would end it before it completes
+type pace struct {
+	mu    sync.Mutex
+	count int
+	total time.Duration
+}
+
+// record adds the duration of a completed check
+func (p *pace) record(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.count++
+	p.total += d
+}
+
+// fits reports whether a check of average duration can complete before ctx's
+// deadline. It always does without a deadline or before any check completed.
+func (p *pace) fits(ctx context.Context) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.count == 0 {
+		return true
+	}
+	return time.Until(deadline) >= p.total/time.Duration(p.count)
+}
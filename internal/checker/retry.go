@@ -0,0 +1,44 @@
+package checker
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// Retry controls how fetches failing for a transient reason (see git.IsTransient) are tried again
+type Retry struct {
+	Retries int           // Attempts after the first one (0 = none)
+	Backoff time.Duration // Delay before the first retry, doubled for each next one
+}
+
+// delay returns the wait before the given retry (0 for the first): the backoff
+// doubled for each previous retry, jittered between half and all of it so that
+// repositories failing together do not retry together
+func (r Retry) delay(retry int) time.Duration {
+	d := r.Backoff << retry
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// FetchWithRetry fetches a project's remote like Fetch, trying again after a
+// transient failure as set by retry. It returns the outcome of the last attempt.
+func FetchWithRetry(ctx context.Context, project scanner.Project, timeout time.Duration, opts git.FetchOptions, retry Retry) (git.FetchResult, error) {
+	result, err := Fetch(ctx, project, timeout, opts)
+	for attempt := 0; attempt < retry.Retries && err != nil && git.IsTransient(err); attempt++ {
+		wait := time.NewTimer(retry.delay(attempt))
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			return result, err
+		case <-wait.C:
+		}
+		result, err = Fetch(ctx, project, timeout, opts)
+	}
+	return result, err
+}
@@ -0,0 +1,103 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/vcs"
+)
+
+// scriptedRepo is a repository whose fetches fail with the scripted errors in
+// order, then succeed
+type scriptedRepo struct {
+	vcs.Repository // Only Fetch is called
+	errs           []error
+	fetches        int
+}
+
+func (r *scriptedRepo) Fetch(ctx context.Context, opts git.FetchOptions) (git.FetchResult, error) {
+	r.fetches++
+	if len(r.errs) == 0 {
+		return git.FetchResult{}, nil
+	}
+	err := r.errs[0]
+	r.errs = r.errs[1:]
+	return git.FetchResult{}, err
+}
+
+func TestFetchWithRetry(t *testing.T) {
+	dns := &git.FetchError{Operation: "fetch", Stderr: "fatal: unable to access 'https://example.com/repo.git/': Could not resolve host: example.com"}
+	timeout := &git.TimeoutError{Operation: "git fetch"}
+	auth := &git.FetchError{Operation: "fetch", Stderr: "remote: Invalid username or password.\nfatal: Authentication failed"}
+	gone := &git.FetchError{Operation: "fetch", Stderr: "ERROR: Repository not found."}
+	unknown := errors.New("index.lock exists")
+
+	tests := []struct {
+		name    string
+		errs    []error
+		retries int
+		fetches int
+		want    error
+	}{
+		{"success", nil, 3, 1, nil},
+		{"dns failure then success", []error{dns}, 3, 2, nil},
+		{"timeouts then success", []error{timeout, timeout}, 3, 3, nil},
+		{"transient failures past the retries", []error{dns, dns, timeout, dns, dns}, 3, 4, dns},
+		{"without retries", []error{dns}, 0, 1, dns},
+		{"refused credentials, not retried", []error{auth}, 3, 1, auth},
+		{"missing repository, not retried", []error{gone}, 3, 1, gone},
+		{"transient then permanent", []error{timeout, auth, dns}, 3, 2, auth},
+		{"unknown error, not retried", []error{unknown}, 3, 1, unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &scriptedRepo{errs: append([]error{}, tt.errs...)}
+			project := scanner.Project{Name: "p", Repository: repo}
+
+			_, err := FetchWithRetry(context.Background(), project, 0, git.FetchOptions{}, Retry{Retries: tt.retries, Backoff: time.Millisecond})
+			if repo.fetches != tt.fetches {
+				t.Errorf("%d fetches, want %d", repo.fetches, tt.fetches)
+			}
+			if err != tt.want {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchWithRetryStopsWithContext(t *testing.T) {
+	timeout := &git.TimeoutError{Operation: "git fetch"}
+	repo := &scriptedRepo{errs: []error{timeout, timeout}}
+	project := scanner.Project{Name: "p", Repository: repo}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := FetchWithRetry(ctx, project, 0, git.FetchOptions{}, Retry{Retries: 3, Backoff: time.Hour}); err != timeout {
+		t.Errorf("got %v, want %v", err, timeout)
+	}
+	if took := time.Since(start); took > 5*time.Second {
+		t.Errorf("gave up after %s", took)
+	}
+	if repo.fetches != 1 {
+		t.Errorf("%d fetches, want 1", repo.fetches)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	r := Retry{Retries: 3, Backoff: 100 * time.Millisecond}
+	for retry, full := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			if d := r.delay(retry); d < full/2 || d > full {
+				t.Errorf("retry %d waits %s, want between %s and %s", retry, d, full/2, full)
+			}
+		}
+	}
+	if d := (Retry{Retries: 3}).delay(2); d != 0 {
+		t.Errorf("waits %s without backoff", d)
+	}
+}
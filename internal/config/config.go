@@ -1,38 +1,170 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
-// Config represents the application configuration
+// Config represents the application configuration.
+// The desc, enum and required tags document the file in its JSON Schema (check-projects schema config).
 type Config struct {
-	Categories       []Category `yaml:"categories"`
-	Display          Display    `yaml:"display"`
-	UseTUIByDefault  bool       `yaml:"use_tui_by_default"`
-	Fetch            bool       `yaml:"fetch"`
-	FetchConcurrency int        `yaml:"fetch_concurrency"`
+	Categories       []Category `yaml:"categories" desc:"Categories of projects, checked in order"`
+	Display          Display    `yaml:"display" desc:"Display options"`
+	UseTUIByDefault  bool       `yaml:"use_tui_by_default" desc:"Same as always passing --tui"`
+	Fetch            bool       `yaml:"fetch" desc:"Same as always passing --fetch"`
+	FetchConcurrency int        `yaml:"fetch_concurrency" desc:"Repositories fetched at once"`
+	FetchRetries     int        `yaml:"fetch_retries" desc:"Retries of fetches failing on DNS, network or timeout errors"`
+	FetchBackoff     Duration   `yaml:"fetch_backoff" desc:"Delay before the first fetch retry, doubled for each next one (jittered)"`
+	FetchPrune       bool       `yaml:"fetch_prune,omitempty" desc:"Fetches remove remote-tracking branches deleted on the remote, like --prune"`
+	FetchAllRemotes  bool       `yaml:"fetch_all_remotes,omitempty" desc:"Fetches update every remote rather than the default one, like --all-remotes"`
+	FetchRemote      string     `yaml:"fetch_remote,omitempty" desc:"Remote fetched rather than the default one (ignored with fetch_all_remotes)"`
+	PullRebase       bool       `yaml:"pull_rebase,omitempty" desc:"Pulling from the TUI rebases local commits onto the upstream rather than refusing to pull diverged branches"`
+	GitTimeout       Duration   `yaml:"git_timeout,omitempty" desc:"Per-repository limit for git operations (0 = none)"`
+	Backend          string     `yaml:"backend,omitempty" desc:"How git repositories are read: with the git binary, or in-process with go-git (default: exec, or gogit when git is not installed)" enum:"exec,gogit"`
+	Webhooks         []Webhook  `yaml:"webhooks,omitempty" desc:"URLs receiving the JSON report after each run"`
+	HistoryKeep      int        `yaml:"history_keep,omitempty" desc:"Snapshots kept by --snapshot (0 = 30)"`
+	CheckRemotes     bool       `yaml:"check_remotes,omitempty" desc:"Flag remotes that cannot be reached (git ls-remote)"`
+	RemoteAllowlist  []string   `yaml:"remote_allowlist,omitempty" desc:"Remote hosts never checked, known to be reachable (patterns such as *.corp.example)"`
+	RemoteCache      Duration   `yaml:"remote_cache,omitempty" desc:"How long reachability results are reused (0 = 24h)"`
+	StaleAfter       Duration   `yaml:"stale_after,omitempty" desc:"Clean repositories without commits for longer are stale (0 = never)"`
+	MaxDepth         int        `yaml:"max_depth,omitempty" desc:"Directory levels below each root the scan descends (0 = unlimited)"`
+	Scan             Scan       `yaml:"scan,omitempty" desc:"Directories left out of the scan of roots"`
+	CompareRemotes   []string   `yaml:"compare_remotes,omitempty" desc:"Remotes whose matching branch the current one is compared with, e.g. origin and upstream for a fork"`
+	PromptMaxAge     Duration   `yaml:"prompt_max_age,omitempty" desc:"--prompt shows ! when the last full run is older (0 = 1h)"`
 
 	// Internal: path where config was loaded from (not serialized)
 	ConfigPath string `yaml:"-"`
 	// Internal: true if config was filtered (don't save to avoid losing data)
 	IsFiltered bool `yaml:"-"`
+	// Internal: ignore patterns applied to every category for this run only (--ignore-pattern)
+	ExtraIgnore []string `yaml:"-"`
+	// Internal: max_depth of every category for this run only (--max-depth, 0 = none)
+	MaxDepthOverride int `yaml:"-"`
 }
 
 // Category represents a project category
 // Either Root (auto-scan) or Projects (explicit list) must be specified
 type Category struct {
-	Name     string   `yaml:"name"`
-	Root     string   `yaml:"root,omitempty"`     // Auto-scan: recursively find all git repos
-	Projects []string `yaml:"projects,omitempty"` // Explicit: list of full paths to repos
-	Ignore   []string `yaml:"ignore,omitempty"`   // Projects to ignore in this category
+	Name     string   `yaml:"name" desc:"Category name, as given to --category" required:"true"`
+	Root     string   `yaml:"root,omitempty" desc:"Auto-scan: recursively find all repositories under this directory"`
+	Projects []string `yaml:"projects,omitempty" desc:"Explicit: list of full paths to repositories, or patterns such as ~/dev/*/backend (** matches any number of directories)"`
+	Ignore   []string `yaml:"ignore,omitempty" desc:"Names or patterns of projects to ignore in this category, or regular expressions prefixed with re: (matched against the path relative to root, then the name alone)"`
+
+	StaleAfter Duration `yaml:"stale_after,omitempty" desc:"Overrides the global stale_after for this category"`
+	MaxDepth   int      `yaml:"max_depth,omitempty" desc:"Overrides the global max_depth for this category"`
+
+	SkipDirs       []string `yaml:"skip_dirs,omitempty" desc:"Added to scan.skip_dirs for this category"`
+	FollowSymlinks bool     `yaml:"follow_symlinks,omitempty" desc:"Scan the directories symlinked under root, guarding against circular links (symlinks to repositories are always listed)"`
+	ScanNested     bool     `yaml:"scan_nested,omitempty" desc:"Scan inside the repositories found under root, listing the repositories nested in them as projects of their own"`
+
+	ReportUntrackedDirs bool `yaml:"report_untracked_dirs,omitempty" desc:"Report the directories right under root holding files but no repository, as not under version control"`
+
+	LocalBranches  *bool    `yaml:"local_branches,omitempty" desc:"Report local branches without upstream holding commits no remote has (default true)"`
+	CompareRemotes []string `yaml:"compare_remotes,omitempty" desc:"Overrides the global compare_remotes for this category"`
+	MergedBranches *bool    `yaml:"merged_branches,omitempty" desc:"List local branches merged into the default branch of the remote, safe to delete (default true)"`
+	DefaultBranch  *bool    `yaml:"default_branch,omitempty" desc:"Report projects whose current branch is not the default branch of their remote (default true)"`
+	UnpushedTags   *bool    `yaml:"unpushed_tags,omitempty" desc:"Report local tags the remote does not have, listing its tags over the network with git ls-remote (default false)"`
+	ExpectedEmail  Patterns `yaml:"expected_email,omitempty" desc:"Patterns the user.email of the repositories must match, e.g. *@company.com (default: not checked)"`
+
+	// Internal: config file the category was loaded from (not serialized)
+	Source string `yaml:"-"`
+}
+
+// Scan represents the directories left out of the scan of roots
+type Scan struct {
+	SkipDirs     []string `yaml:"skip_dirs,omitempty" desc:"Names or patterns of directories never scanned nor listed, e.g. build or *.egg-info (added to the defaults)"`
+	SkipDefaults *bool    `yaml:"skip_defaults,omitempty" desc:"Also skip the default directories: node_modules, vendor, .venv, __pycache__, target and dist (default true)"`
+	SkipHidden   bool     `yaml:"skip_hidden,omitempty" desc:"Skip the directories whose name starts with a dot"`
+	Cache        *bool    `yaml:"cache,omitempty" desc:"Start from the projects found under roots by the previous run, scanning them again while they are checked (default true)"`
+	Sort         string   `yaml:"sort,omitempty" desc:"Order of the projects found under each root, numbers in names compared by value: by name, by path below the root, or as scanned (default name)" enum:"name,path,none"`
+}
+
+// CacheEnabled reports whether the projects found under roots are cached (scan.cache, true unless disabled)
+func (s Scan) CacheEnabled() bool {
+	return s.Cache == nil || *s.Cache
 }
 
+// Orders of the projects found under roots (Scan.Sort)
+const (
+	ScanSortName = "name" // By the name of their directory, then by path (default)
+	ScanSortPath = "path" // By path below the root, keeping the projects of a directory together
+	ScanSortNone = "none" // As scanned
+)
+
+// ValidateScanSort checks a Scan.Sort value ("" is the default, ScanSortName)
+func ValidateScanSort(order string) error {
+	switch order {
+	case "", ScanSortName, ScanSortPath, ScanSortNone:
+		return nil
+	}
+	return fmt.Errorf("invalid value '%s' (valid: %s, %s, %s)", order, ScanSortName, ScanSortPath, ScanSortNone)
+}
+
+// DefaultSkipDirs are the directories skipped by the scan of roots unless
+// scan.skip_defaults is false: dependencies and build outputs, full of files
+// and sometimes of vendored repositories
+var DefaultSkipDirs = []string{"node_modules", ".DS_Store", "vendor", ".venv", "__pycache__", "target", "dist"}
+
 // Display represents display options
 type Display struct {
-	HideClean   bool `yaml:"hide_clean"`
-	HideIgnored bool `yaml:"hide_ignored"`
+	HideClean   bool   `yaml:"hide_clean" desc:"Hide clean projects unless --verbose"`
+	HideIgnored bool   `yaml:"hide_ignored" desc:"Hide ignored projects"`
+	Sort        string `yaml:"sort,omitempty" desc:"Project order within categories" enum:"config,status,name,age"`
+	GroupBy     string `yaml:"group_by,omitempty" desc:"Sections of the text and markdown reports: categories, or hosts of the remotes (default category)" enum:"category,host"`
+	ShowStashes bool   `yaml:"show_stashes" desc:"Show clean projects with stashes even when hide_clean is set"`
+	Bare        string `yaml:"bare,omitempty" desc:"How bare repositories are reported: listed, left out of the scan, or only listed with unpushed branches" enum:"list,hide,unpushed"`
+
+	StaleFetchDays int `yaml:"stale_fetch_days,omitempty" desc:"Mark projects last fetched more than this many days ago with ⏳, even when clean (0 = never)"`
+}
+
+// How bare repositories are reported (Display.Bare)
+const (
+	BareList     = "list"     // Listed as bare, or as unsync with unpushed branches (default)
+	BareHide     = "hide"     // Left out of the scan of roots
+	BareUnpushed = "unpushed" // Only listed with unpushed branches
+)
+
+// ValidateBare checks a Display.Bare value ("" is the default, BareList)
+func ValidateBare(mode string) error {
+	switch mode {
+	case "", BareList, BareHide, BareUnpushed:
+		return nil
+	}
+	return fmt.Errorf("invalid value '%s' (valid: %s, %s, %s)", mode, BareList, BareHide, BareUnpushed)
+}
+
+// How git repositories are read (Config.Backend)
+const (
+	BackendExec  = "exec"  // Running the git binary (default)
+	BackendGoGit = "gogit" // In-process with go-git, without the git binary
+)
+
+// ValidateBackend checks a Config.Backend value ("" is the default, BackendExec
+// or BackendGoGit when git is not installed)
+func ValidateBackend(backend string) error {
+	switch backend {
+	case "", BackendExec, BackendGoGit:
+		return nil
+	}
+	return fmt.Errorf("invalid value '%s' (valid: %s, %s)", backend, BackendExec, BackendGoGit)
+}
+
+// When a webhook is posted to (Webhook.OnlyOn)
+const (
+	WebhookAlways = "always" // After every run (default)
+	WebhookDirty  = "dirty"  // When a project needs attention or errored
+	WebhookChange = "change" // When the report differs from the last one delivered
+)
+
+// Webhook is a URL receiving the --output json document after each run
+type Webhook struct {
+	URL     string            `yaml:"url" desc:"URL the report is posted to" required:"true"`
+	OnlyOn  string            `yaml:"only_on,omitempty" desc:"When to post: after every run, when a project needs attention, or when the report changed" enum:"always,dirty,change"`
+	Headers map[string]string `yaml:"headers,omitempty" desc:"HTTP headers sent with the report, e.g. Authorization"`
 }
 
 // ExpandPath expands ~ to home directory
@@ -47,6 +179,174 @@ func ExpandPath(path string) string {
 	return path
 }
 
+// ContractPath replaces the home directory prefix of an absolute path with ~
+func ContractPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + path[len(home):]
+	}
+	return path
+}
+
+// FindCategory returns the category with the given name, or nil if none
+func (c *Config) FindCategory(name string) *Category {
+	for i := range c.Categories {
+		if c.Categories[i].Name == name {
+			return &c.Categories[i]
+		}
+	}
+	return nil
+}
+
+// SourceOf returns the config file holding a category: the file it was loaded
+// from, or the main config file for categories added since
+func (c *Config) SourceOf(cat *Category) string {
+	if cat.Source != "" {
+		return cat.Source
+	}
+	return c.ConfigPath
+}
+
+// Files returns the config files the configuration was loaded from, in order
+func (c *Config) Files() []string {
+	files := []string{c.ConfigPath}
+	for i := range c.Categories {
+		source := c.SourceOf(&c.Categories[i])
+		if !slices.Contains(files, source) {
+			files = append(files, source)
+		}
+	}
+	return files
+}
+
+// Covers reports whether the category includes path, either under its root
+// or as one of its explicit projects (or patterns). Path must be absolute and clean.
+func (c *Category) Covers(path string) bool {
+	for _, projectPath := range c.Projects {
+		if IsGlob(projectPath) && MatchGlob(projectPath, path) {
+			return true
+		}
+		if filepath.Clean(ExpandPath(projectPath)) == path {
+			return true
+		}
+	}
+
+	if c.Root != "" && len(c.Projects) == 0 {
+		rel, err := filepath.Rel(filepath.Clean(c.GetRootPath()), path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StaleAfterFor returns the stale_after threshold of a category: its own, or the global one
+func (c *Config) StaleAfterFor(category string) time.Duration {
+	if cat := c.FindCategory(category); cat != nil && cat.StaleAfter > 0 {
+		return time.Duration(cat.StaleAfter)
+	}
+	return time.Duration(c.StaleAfter)
+}
+
+// MaxDepthFor returns the directory levels below the root of a category the scan
+// descends: --max-depth, else its own max_depth, else the global one (0 = unlimited)
+func (c *Config) MaxDepthFor(category string) int {
+	if c.MaxDepthOverride > 0 {
+		return c.MaxDepthOverride
+	}
+	if cat := c.FindCategory(category); cat != nil && cat.MaxDepth > 0 {
+		return cat.MaxDepth
+	}
+	return c.MaxDepth
+}
+
+// SkipDirsFor returns the names or patterns of the directories the scan of the root
+// of a category skips: the defaults (unless disabled), scan.skip_dirs and its own
+func (c *Config) SkipDirsFor(category string) []string {
+	var skip []string
+	if c.Scan.SkipDefaults == nil || *c.Scan.SkipDefaults {
+		skip = append(skip, DefaultSkipDirs...)
+	}
+	skip = append(skip, c.Scan.SkipDirs...)
+	if cat := c.FindCategory(category); cat != nil {
+		skip = append(skip, cat.SkipDirs...)
+	}
+	return skip
+}
+
+// LocalBranchesFor reports whether the local-only branches of a category's
+// projects are reported (local_branches, true unless disabled)
+func (c *Config) LocalBranchesFor(category string) bool {
+	if cat := c.FindCategory(category); cat != nil && cat.LocalBranches != nil {
+		return *cat.LocalBranches
+	}
+	return true
+}
+
+// MergedBranchesFor reports whether the local branches of a category's projects
+// merged into their default branch are listed (merged_branches, true unless disabled)
+func (c *Config) MergedBranchesFor(category string) bool {
+	if cat := c.FindCategory(category); cat != nil && cat.MergedBranches != nil {
+		return *cat.MergedBranches
+	}
+	return true
+}
+
+// DefaultBranchFor reports whether the projects of a category are reported when
+// not on the default branch of their remote (default_branch, true unless disabled)
+func (c *Config) DefaultBranchFor(category string) bool {
+	if cat := c.FindCategory(category); cat != nil && cat.DefaultBranch != nil {
+		return *cat.DefaultBranch
+	}
+	return true
+}
+
+// UnpushedTagsFor reports whether the projects of a category are checked for tags
+// their remote does not have (unpushed_tags, false unless enabled: it hits the network)
+func (c *Config) UnpushedTagsFor(category string) bool {
+	if cat := c.FindCategory(category); cat != nil && cat.UnpushedTags != nil {
+		return *cat.UnpushedTags
+	}
+	return false
+}
+
+// ExpectedEmailFor returns the patterns the user.email of the projects of a category
+// must match (expected_email, none when their identity is not checked)
+func (c *Config) ExpectedEmailFor(category string) Patterns {
+	if cat := c.FindCategory(category); cat != nil {
+		return cat.ExpectedEmail
+	}
+	return nil
+}
+
+// CompareRemotesFor returns the remotes compared with in a category: its own, or the global ones
+func (c *Config) CompareRemotesFor(category string) []string {
+	if cat := c.FindCategory(category); cat != nil && cat.CompareRemotes != nil {
+		return cat.CompareRemotes
+	}
+	return c.CompareRemotes
+}
+
+// HasStaleAfter reports whether a stale_after threshold is set globally or for a category
+func (c *Config) HasStaleAfter() bool {
+	if c.StaleAfter > 0 {
+		return true
+	}
+	for _, cat := range c.Categories {
+		if cat.StaleAfter > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // GetRootPath returns the expanded root path
 func (c *Category) GetRootPath() string {
 	return ExpandPath(c.Root)
@@ -59,9 +359,14 @@ func DefaultConfig() *Config {
 		Display: Display{
 			HideClean:   true,
 			HideIgnored: true,
+			Sort:        "config",
+			ShowStashes: true,
+			Bare:        BareList,
 		},
 		UseTUIByDefault:  false,
 		Fetch:            false,
 		FetchConcurrency: 10,
+		FetchRetries:     2,
+		FetchBackoff:     Duration(time.Second),
 	}
 }
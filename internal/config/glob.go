@@ -0,0 +1,97 @@
+package config
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// doubleStar is the path segment matching any number of directories
+const doubleStar = "**"
+
+// IsGlob reports whether an entry of a projects list is a pattern (filepath.Match
+// syntax, plus ** for any number of directories) rather than a path
+func IsGlob(entry string) bool {
+	return strings.ContainsAny(entry, "*?[")
+}
+
+// GlobProjects returns the sorted paths matching a projects entry pattern, after
+// expanding ~. Only directories match ** segments, and .git directories are not
+// descended into.
+func GlobProjects(pattern string) ([]string, error) {
+	pattern = filepath.Clean(ExpandPath(pattern))
+	if !slices.Contains(splitPath(pattern), doubleStar) {
+		return filepath.Glob(pattern)
+	}
+	if _, err := filepath.Match(strings.ReplaceAll(pattern, doubleStar, "*"), ""); err != nil {
+		return nil, err
+	}
+
+	// Walk from the deepest directory free of wildcards
+	base := string(filepath.Separator)
+	if !filepath.IsAbs(pattern) {
+		base = "."
+	}
+	for _, segment := range splitPath(pattern) {
+		if IsGlob(segment) {
+			break
+		}
+		base = filepath.Join(base, segment)
+	}
+
+	var matches []string
+	err := filepath.WalkDir(base, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == base {
+				return nil // A missing base matches nothing
+			}
+			return fs.SkipDir
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if entry.Name() == ".git" {
+			return fs.SkipDir
+		}
+		if MatchGlob(pattern, path) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// MatchGlob reports whether path matches a projects entry pattern, once both are
+// expanded and clean
+func MatchGlob(pattern, path string) bool {
+	return matchSegments(splitPath(filepath.Clean(ExpandPath(pattern))), splitPath(path))
+}
+
+// matchSegments matches path segments one by one, a ** segment matching any
+// number of them
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == doubleStar {
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if matched, err := filepath.Match(pattern[0], path[0]); err != nil || !matched {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// splitPath splits a clean path into its segments
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == filepath.Separator })
+}
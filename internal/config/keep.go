@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeepMarker protects a projects or ignore entry from prune when found in its
+// trailing comment, e.g. `- "*-wip"  # prune:keep`
+const KeepMarker = "prune:keep"
+
+// KeptEntries returns the projects and ignore entries marked with KeepMarker,
+// as a set of entries per category name
+func KeptEntries(cfg *Config) (map[string]map[string]bool, error) {
+	kept := make(map[string]map[string]bool)
+	for _, path := range cfg.Files() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+			continue
+		}
+
+		categories := mappingValue(doc.Content[0], "categories")
+		if categories == nil || categories.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, cat := range categories.Content {
+			name := mappingValue(cat, "name")
+			if name == nil {
+				continue
+			}
+			for _, key := range []string{"projects", "ignore"} {
+				entries := mappingValue(cat, key)
+				if entries == nil || entries.Kind != yaml.SequenceNode {
+					continue
+				}
+				for _, entry := range entries.Content {
+					if !strings.Contains(entry.LineComment, KeepMarker) {
+						continue
+					}
+					if kept[name.Value] == nil {
+						kept[name.Value] = make(map[string]bool)
+					}
+					kept[name.Value][entry.Value] = true
+				}
+			}
+		}
+	}
+	return kept, nil
+}
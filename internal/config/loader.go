@@ -1,6 +1,8 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,15 +10,19 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// LoadConfig loads configuration from file
-// Priority: 1. Provided path, 2. ./check-projects.yml, 3. ~/check-projects.yml
-func LoadConfig(configPath string) (*Config, error) {
-	var paths []string
+// ErrNoConfig is returned by LoadConfig when no configuration file exists
+var ErrNoConfig = errors.New("no configuration file found (searched: ./check-projects.yml, ~/check-projects.yml)")
 
-	if configPath != "" {
-		paths = append(paths, configPath)
+// LoadConfig loads configuration from files.
+// Priority: 1. Provided paths, 2. ./check-projects.yml, 3. ~/check-projects.yml
+// Several provided paths are merged, see mergeConfigs.
+func LoadConfig(configPaths []string) (*Config, error) {
+	if len(configPaths) > 0 {
+		return loadFiles(configPaths)
 	}
 
+	var paths []string
+
 	// Local config
 	if localPath := "check-projects.yml"; fileExists(localPath) {
 		paths = append(paths, localPath)
@@ -32,18 +38,75 @@ func LoadConfig(configPath string) (*Config, error) {
 	}
 
 	if len(paths) == 0 {
-		return nil, fmt.Errorf("no configuration file found (searched: ./check-projects.yml, ~/check-projects.yml)")
+		return nil, ErrNoConfig
 	}
 
 	// Load the first available config
+	return loadFiles(paths[:1])
+}
+
+// loadFiles loads and merges the given config files, in order
+func loadFiles(paths []string) (*Config, error) {
 	cfg, err := loadFromFile(paths[0])
 	if err != nil {
 		return nil, err
 	}
 	cfg.ConfigPath = paths[0]
+	cfg.setSource(paths[0])
+
+	for _, path := range paths[1:] {
+		if err := mergeFile(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.validateIgnore(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
+// mergeFile appends the categories of the config file at path to cfg.
+// Options set in that file override the ones loaded so far, others are kept.
+// A category name defined in two files is an error, as saving could not tell them apart.
+func mergeFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	categories := cfg.Categories
+	cfg.Categories = nil
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		cfg.Categories = categories
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	cfg.setSource(path)
+
+	for _, cat := range cfg.Categories {
+		for _, existing := range categories {
+			if existing.Name == cat.Name {
+				cfg.Categories = categories
+				return fmt.Errorf("category '%s' is defined in both %s and %s", cat.Name, existing.Source, path)
+			}
+		}
+	}
+	cfg.Categories = append(categories, cfg.Categories...)
+
+	if cfg.FetchConcurrency <= 0 {
+		cfg.FetchConcurrency = 10
+	}
+	return nil
+}
+
+// setSource records path as the origin of the categories not having one yet
+func (c *Config) setSource(path string) {
+	for i := range c.Categories {
+		if c.Categories[i].Source == "" {
+			c.Categories[i].Source = path
+		}
+	}
+}
+
 func loadFromFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -63,26 +126,92 @@ func loadFromFile(path string) (*Config, error) {
 	return config, nil
 }
 
-// SaveConfig saves the configuration back to file
+// FileChange is the new content of a config file about to be saved
+type FileChange struct {
+	Path string
+	Old  []byte
+	New  []byte
+}
+
+// SaveConfig saves the configuration back to file, preserving comments.
+// Files whose content would not change are left untouched.
 func SaveConfig(cfg *Config) error {
+	changes, err := PendingChanges(cfg)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if err := os.WriteFile(change.Path, change.New, 0644); err != nil {
+			return fmt.Errorf("failed to write config file %s: %w", change.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// PendingChanges serializes cfg and returns the config files it would change.
+// When merged from several files, each category is written back to the file it
+// came from, and new categories go to the first one.
+func PendingChanges(cfg *Config) ([]FileChange, error) {
 	if cfg.ConfigPath == "" {
-		return fmt.Errorf("config path is not set")
+		return nil, fmt.Errorf("config path is not set")
 	}
 
 	if cfg.IsFiltered {
-		return fmt.Errorf("cannot save filtered config (use without --category to save)")
+		return nil, fmt.Errorf("cannot save filtered config (use without --category to save)")
 	}
 
-	data, err := yaml.Marshal(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	files := cfg.Files()
+	if len(files) == 1 {
+		change, err := fileChange(cfg.ConfigPath, cfg)
+		if err != nil || change == nil {
+			return nil, err
+		}
+		return []FileChange{*change}, nil
 	}
 
-	if err := os.WriteFile(cfg.ConfigPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file %s: %w", cfg.ConfigPath, err)
+	var changes []FileChange
+	for _, path := range files {
+		// Keep the options of each file, only replacing its categories
+		fileCfg, err := loadFromFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fileCfg.Categories = nil
+		for _, cat := range cfg.Categories {
+			if cfg.SourceOf(&cat) == path {
+				fileCfg.Categories = append(fileCfg.Categories, cat)
+			}
+		}
+
+		change, err := fileChange(path, fileCfg)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
 	}
 
-	return nil
+	return changes, nil
+}
+
+// fileChange serializes cfg on top of the current content of path,
+// returning nil when the file would not change
+func fileChange(path string, cfg *Config) (*FileChange, error) {
+	// Keep the user's comments and layout when the file already exists
+	original, _ := os.ReadFile(path)
+
+	data, err := marshalPreservingComments(original, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if bytes.Equal(original, data) {
+		return nil, nil
+	}
+
+	return &FileChange{Path: path, Old: original, New: data}, nil
 }
 
 func fileExists(path string) bool {
@@ -0,0 +1,59 @@
+package config
+
+import "strings"
+
+// CompareNatural orders strings case-insensitively with their runs of digits compared
+// as numbers, so that project2 comes before project10. Ties are broken bytewise so
+// that the order is total.
+func CompareNatural(a, b string) int {
+	x, y := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	for len(x) > 0 && len(y) > 0 {
+		if isDigit(x[0]) && isDigit(y[0]) {
+			var nx, ny []rune
+			nx, x = digits(x)
+			ny, y = digits(y)
+			if c := compareNumbers(nx, ny); c != 0 {
+				return c
+			}
+			continue
+		}
+		if x[0] != y[0] {
+			if x[0] < y[0] {
+				return -1
+			}
+			return 1
+		}
+		x, y = x[1:], y[1:]
+	}
+	if c := len(x) - len(y); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// isDigit reports whether r is an ASCII digit
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// digits splits the leading run of digits of s from the rest
+func digits(s []rune) (number, rest []rune) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// compareNumbers compares runs of digits by value, then by length so that 01 comes after 1
+func compareNumbers(a, b []rune) int {
+	x := strings.TrimLeft(string(a), "0")
+	y := strings.TrimLeft(string(b), "0")
+	if len(x) != len(y) {
+		return len(x) - len(y)
+	}
+	if c := strings.Compare(x, y); c != 0 {
+		return c
+	}
+	return len(a) - len(b)
+}
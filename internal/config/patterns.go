@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Patterns are wildcard patterns (path.Match syntax: *, ? and [...]), read from
+// YAML as a single string or a list of strings
+type Patterns []string
+
+// UnmarshalYAML accepts a single pattern or a list of them
+func (p *Patterns) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*p = Patterns{value.Value}
+		return nil
+	}
+	var patterns []string
+	if err := value.Decode(&patterns); err != nil {
+		return fmt.Errorf("line %d: expected a pattern or a list of patterns", value.Line)
+	}
+	*p = patterns
+	return nil
+}
+
+// MarshalYAML writes a single pattern as a string
+func (p Patterns) MarshalYAML() (interface{}, error) {
+	if len(p) == 1 {
+		return p[0], nil
+	}
+	return []string(p), nil
+}
+
+// Match reports whether s matches one of the patterns, ignoring case
+func (p Patterns) Match(s string) bool {
+	for _, pattern := range p {
+		if matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(s)); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error for the first malformed pattern
+func (p Patterns) Validate() error {
+	for _, pattern := range p {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern '%s'", pattern)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RegexpPrefix marks the ignore entries that are Go regular expressions, such as
+// re:^sandbox-\d+$, rather than names or wildcard patterns
+const RegexpPrefix = "re:"
+
+// regexps caches the compiled expressions of ignore entries, matched against every project
+var regexps sync.Map
+
+// IgnoreRegexp returns the regular expression of an ignore entry, and whether the entry
+// is one (prefixed with re:). A malformed expression is returned as an error.
+func IgnoreRegexp(entry string) (*regexp.Regexp, bool, error) {
+	expr, ok := strings.CutPrefix(entry, RegexpPrefix)
+	if !ok {
+		return nil, false, nil
+	}
+	if re, cached := regexps.Load(expr); cached {
+		return re.(*regexp.Regexp), true, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid ignore pattern '%s': %w", entry, err)
+	}
+	regexps.Store(expr, re)
+	return re, true, nil
+}
+
+// validateIgnore returns an error for the first malformed regular expression of the
+// ignore lists, which would otherwise match no project without a word
+func (c *Config) validateIgnore() error {
+	for _, cat := range c.Categories {
+		for _, entry := range cat.Ignore {
+			if _, _, err := IgnoreRegexp(entry); err != nil {
+				return fmt.Errorf("category '%s' in %s: %w", cat.Name, cat.Source, err)
+			}
+		}
+	}
+	return nil
+}
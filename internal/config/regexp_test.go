@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file in a temporary directory and returns its path
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigRejectsMalformedIgnoreRegexp(t *testing.T) {
+	path := writeConfig(t, "check-projects.yml", `
+categories:
+  - name: clients
+    root: ~/clients
+    ignore:
+      - legacy
+      - 're:('
+`)
+
+	_, err := LoadConfig([]string{path})
+	if err == nil {
+		t.Fatal("a malformed regular expression was accepted")
+	}
+	for _, want := range []string{path, "'clients'", "'re:('"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not name %s", err, want)
+		}
+	}
+}
+
+func TestLoadConfigRejectsMalformedIgnoreRegexpOfMergedFile(t *testing.T) {
+	base := writeConfig(t, "base.yml", `
+categories:
+  - name: work
+    root: ~/work
+    ignore: ['re:^sandbox-\d+$']
+`)
+	extra := writeConfig(t, "extra.yml", `
+categories:
+  - name: games
+    root: ~/games
+    ignore: ['re:[a-']
+`)
+
+	_, err := LoadConfig([]string{base, extra})
+	if err == nil {
+		t.Fatal("a malformed regular expression was accepted")
+	}
+	if !strings.Contains(err.Error(), extra) || !strings.Contains(err.Error(), "'games'") {
+		t.Errorf("error %q does not name %s and its category", err, extra)
+	}
+}
+
+func TestLoadConfigAcceptsIgnoreRegexp(t *testing.T) {
+	path := writeConfig(t, "check-projects.yml", `
+categories:
+  - name: clients
+    root: ~/clients
+    ignore: ['re:^sandbox-\d+$', '*-deprecated']
+`)
+
+	cfg, err := LoadConfig([]string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	re, ok, err := IgnoreRegexp(cfg.Categories[0].Ignore[0])
+	if !ok || err != nil || !re.MatchString("sandbox-42") {
+		t.Fatalf("got %v, %v, %v for %q", re, ok, err, cfg.Categories[0].Ignore[0])
+	}
+}
+
+func TestIgnoreRegexp(t *testing.T) {
+	if _, ok, err := IgnoreRegexp("*-deprecated"); ok || err != nil {
+		t.Errorf("a wildcard pattern was taken for a regular expression (%v)", err)
+	}
+	first, ok, err := IgnoreRegexp(`re:^a\d$`)
+	if !ok || err != nil {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+	if again, _, _ := IgnoreRegexp(`re:^a\d$`); again != first {
+		t.Error("the compiled expression was not cached")
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template returns a new configuration file: the default options and the given
+// categories, under a yaml-language-server hint pointing editors to schemaURL
+func Template(schemaURL string, categories []Category) ([]byte, error) {
+	cfg := DefaultConfig()
+	cfg.Categories = categories
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# yaml-language-server: $schema=%s\n", schemaURL)
+	buf.WriteString("# check-projects configuration, see check-projects --help and check-projects schema config\n\n")
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to serialize config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to serialize config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
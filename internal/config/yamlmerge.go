@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// marshalPreservingComments serializes cfg on top of the original document so
+// that comments, key order and formatting of untouched entries are kept.
+// Keys missing from the original are only added when they differ from defaults.
+func marshalPreservingComments(original []byte, cfg *Config) ([]byte, error) {
+	var updated yaml.Node
+	if err := updated.Encode(cfg); err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(original, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		// Nothing worth preserving
+		return encodeNode(&updated)
+	}
+
+	var defaults yaml.Node
+	if err := defaults.Encode(DefaultConfig()); err != nil {
+		return nil, err
+	}
+
+	mergeNode(doc.Content[0], &updated, &defaults)
+	return encodeNode(&doc)
+}
+
+// encodeNode serializes a node with the 2-space indentation used in config files
+func encodeNode(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeNode updates dst in place so it holds the same data as src.
+// def holds default values for the same position, or nil when unknown.
+func mergeNode(dst, src, def *yaml.Node) {
+	if dst.Kind != src.Kind {
+		*dst = *src
+		return
+	}
+
+	switch dst.Kind {
+	case yaml.MappingNode:
+		mergeMapping(dst, src, def)
+	case yaml.SequenceNode:
+		mergeSequence(dst, src)
+	case yaml.ScalarNode:
+		if dst.Value != src.Value || dst.Tag != src.Tag {
+			dst.Value = src.Value
+			dst.Tag = src.Tag
+			if src.Style != 0 {
+				dst.Style = src.Style
+			}
+		}
+	default:
+		*dst = *src
+	}
+}
+
+func mergeMapping(dst, src, def *yaml.Node) {
+	var content []*yaml.Node
+
+	// Keep existing keys in their original order, dropping those no longer present
+	for i := 0; i+1 < len(dst.Content); i += 2 {
+		key, value := dst.Content[i], dst.Content[i+1]
+		srcValue := mappingValue(src, key.Value)
+		if srcValue == nil {
+			continue
+		}
+		mergeNode(value, srcValue, mappingValue(def, key.Value))
+		content = append(content, key, value)
+	}
+
+	// Append new keys, unless they only restate a default
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		if mappingValue(dst, key.Value) != nil {
+			continue
+		}
+		if defValue := mappingValue(def, key.Value); defValue != nil && sameNode(defValue, value) {
+			continue
+		}
+		content = append(content, key, value)
+	}
+
+	dst.Content = content
+}
+
+func mergeSequence(dst, src *yaml.Node) {
+	used := make([]bool, len(dst.Content))
+	content := make([]*yaml.Node, 0, len(src.Content))
+
+	for i, item := range src.Content {
+		match := -1
+		for j, existing := range dst.Content {
+			if !used[j] && sameIdentity(existing, item) {
+				match = j
+				break
+			}
+		}
+		// Fall back to the item at the same position for mappings without identity
+		if match == -1 && item.Kind == yaml.MappingNode && i < len(dst.Content) && !used[i] &&
+			dst.Content[i].Kind == yaml.MappingNode && mappingValue(dst.Content[i], "name") == nil {
+			match = i
+		}
+
+		if match == -1 {
+			content = append(content, item)
+			continue
+		}
+
+		used[match] = true
+		mergeNode(dst.Content[match], item, nil)
+		content = append(content, dst.Content[match])
+	}
+
+	dst.Content = content
+}
+
+// sameIdentity reports whether two sequence items represent the same entry:
+// equal scalars, or mappings with the same "name"
+func sameIdentity(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case yaml.ScalarNode:
+		return a.Value == b.Value
+	case yaml.MappingNode:
+		nameA, nameB := mappingValue(a, "name"), mappingValue(b, "name")
+		return nameA != nil && nameB != nil && nameA.Value == nameB.Value
+	default:
+		return false
+	}
+}
+
+// sameNode reports whether two nodes hold the same data
+func sameNode(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !sameNode(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsBareRepository checks if a path is a bare git repository (e.g. a foo.git
+// mirror): a git directory without working tree, holding HEAD, objects and refs
+func IsBareRepository(path string) bool {
+	if info, err := os.Stat(filepath.Join(path, "HEAD")); err != nil || info.IsDir() {
+		return false
+	}
+	for _, dir := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(path, dir)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// bareStatus reports a bare repository: unsync when branches are ahead of their
+// upstream (commits that exist nowhere else), bare otherwise
+func (r *Repository) bareStatus(ctx context.Context) (*Status, error) {
+	cmd := r.command(ctx, "for-each-ref", "--format=%(refname:short)%00%(upstream:track,nobracket)", "refs/heads")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git for-each-ref"); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if status := PermissionStatus(r.Path, stderr.String(), err); status != nil {
+			return status, nil
+		}
+		return &Status{
+			Type:    StatusError,
+			Message: fmt.Sprintf("Error: %s", stderr.String()),
+			Symbol:  "❌",
+		}, nil
+	}
+
+	var unpushed []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		branch, track, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		if ahead, _ := parseTrack(track); ahead > 0 {
+			unpushed = append(unpushed, fmt.Sprintf("%s (%d)", branch, ahead))
+		}
+	}
+
+	return newBareStatus(unpushed), nil
+}
+
+// newBareStatus builds the status of a bare repository from its branches ahead of
+// their upstream, described like "main (2)"
+func newBareStatus(unpushed []string) *Status {
+	if len(unpushed) > 0 {
+		return &Status{
+			Type:    StatusUnsync,
+			Message: "Bare repository with unpushed branches: " + strings.Join(unpushed, ", "),
+			Symbol:  "⊙ ⬆",
+			Bare:    true,
+		}
+	}
+	return &Status{
+		Type:    StatusBare,
+		Message: "Bare repository",
+		Symbol:  "⊙",
+		Bare:    true,
+	}
+}
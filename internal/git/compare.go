@@ -0,0 +1,82 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RemoteComparison tells how far the current branch is from the matching branch of a remote
+type RemoteComparison struct {
+	Remote string // e.g. "upstream"
+	Ref    string // Remote-tracking branch compared with, e.g. "upstream/main"
+	Ahead  int    // Commits of HEAD not on Ref
+	Behind int    // Commits of Ref not on HEAD
+}
+
+// CompareRemotes compares HEAD with each of the given remotes: with its branch named
+// like the current one, or else its default branch (<remote>/HEAD), as last fetched.
+// Remotes the repository does not have, or without such a branch, are left out.
+func (r *Repository) CompareRemotes(ctx context.Context, remotes []string) ([]RemoteComparison, error) {
+	configured, err := r.Remotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	branch, err := r.GetCurrentBranch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var comparisons []RemoteComparison
+	for _, remote := range remotes {
+		if !slices.Contains(configured, remote) {
+			continue
+		}
+		ref, ok := r.matchingRef(ctx, remote, branch)
+		if !ok {
+			continue
+		}
+		ahead, behind, err := r.aheadBehind(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		comparisons = append(comparisons, RemoteComparison{Remote: remote, Ref: ref, Ahead: ahead, Behind: behind})
+	}
+	return comparisons, nil
+}
+
+// matchingRef returns the remote-tracking branch of remote to compare branch with
+func (r *Repository) matchingRef(ctx context.Context, remote, branch string) (string, bool) {
+	candidates := []string{remote + "/HEAD"}
+	if branch != "HEAD" {
+		candidates = append([]string{remote + "/" + branch}, candidates...)
+	}
+	for _, ref := range candidates {
+		if err := r.command(ctx, "rev-parse", "--verify", "--quiet", "refs/remotes/"+ref).Run(); err == nil {
+			return ref, true
+		}
+	}
+	return "", false
+}
+
+// aheadBehind counts the commits of HEAD not on ref, and of ref not on HEAD
+func (r *Repository) aheadBehind(ctx context.Context, ref string) (ahead, behind int, err error) {
+	cmd := r.command(ctx, "rev-list", "--left-right", "--count", "HEAD..."+ref)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git rev-list"); ctxErr != nil {
+			return 0, 0, ctxErr
+		}
+		return 0, 0, fmt.Errorf("failed to compare with %s: %s", ref, strings.TrimSpace(stderr.String()))
+	}
+	if _, err := fmt.Sscanf(stdout.String(), "%d %d", &ahead, &behind); err != nil {
+		return 0, 0, fmt.Errorf("unexpected git rev-list output: %q", stdout.String())
+	}
+	return ahead, behind, nil
+}
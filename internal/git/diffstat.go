@@ -0,0 +1,85 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DiffCounts counts the changes of a diff, as git diff --shortstat does
+type DiffCounts struct {
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+// DiffStat counts the changes to tracked files, unstaged and staged
+type DiffStat struct {
+	Unstaged DiffCounts // Worktree compared with the index
+	Staged   DiffCounts // Index compared with HEAD
+}
+
+// Empty reports whether no tracked file changed
+func (d DiffStat) Empty() bool {
+	return d.Unstaged.Files == 0 && d.Staged.Files == 0
+}
+
+// DiffStat returns the lines inserted and deleted in the tracked files of the
+// working copy and of its index. Untracked files are not counted.
+func (r *Repository) DiffStat(ctx context.Context) (DiffStat, error) {
+	var stat DiffStat
+	var err error
+	if stat.Unstaged, err = r.shortStat(ctx); err != nil {
+		return DiffStat{}, err
+	}
+	if stat.Staged, err = r.shortStat(ctx, "--cached"); err != nil {
+		return DiffStat{}, err
+	}
+	return stat, nil
+}
+
+// shortStat runs git diff --shortstat with args
+func (r *Repository) shortStat(ctx context.Context, args ...string) (DiffCounts, error) {
+	cmd := r.command(ctx, append([]string{"diff", "--shortstat"}, args...)...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git diff"); ctxErr != nil {
+			return DiffCounts{}, ctxErr
+		}
+		return DiffCounts{}, fmt.Errorf("failed to diff: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return parseShortStat(stdout.String()), nil
+}
+
+// parseShortStat parses e.g. " 3 files changed, 120 insertions(+), 45 deletions(-)",
+// whose insertions or deletions are left out when there are none (binary files
+// only change files); empty output is an empty diff
+func parseShortStat(output string) DiffCounts {
+	var counts DiffCounts
+	for _, part := range strings.Split(strings.TrimSpace(output), ",") {
+		fields := strings.Fields(part)
+		if len(fields) < 2 {
+			continue
+		}
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(fields[1], "file"):
+			counts.Files = n
+		case strings.HasPrefix(fields[1], "insertion"):
+			counts.Insertions = n
+		case strings.HasPrefix(fields[1], "deletion"):
+			counts.Deletions = n
+		}
+	}
+	return counts
+}
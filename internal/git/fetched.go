@@ -0,0 +1,40 @@
+package git
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lastFetched returns when the repository was last fetched: the modification time
+// of FETCH_HEAD, or else of the newest remote-tracking ref, for repositories only
+// ever cloned or fetched by tools that do not write FETCH_HEAD. It returns the zero
+// time when the repository was never fetched.
+func (r *Repository) lastFetched() time.Time {
+	common := commonDir(r.Path, r.Bare)
+	if common == "" {
+		return time.Time{}
+	}
+	dirs := []string{common}
+	if gitDir := GitDir(r.Path); !r.Bare && gitDir != "" && gitDir != common {
+		dirs = []string{gitDir, common} // FETCH_HEAD of a worktree
+	}
+	for _, dir := range dirs {
+		if info, err := os.Stat(filepath.Join(dir, "FETCH_HEAD")); err == nil {
+			return info.ModTime()
+		}
+	}
+
+	var newest time.Time
+	_ = filepath.WalkDir(filepath.Join(common, "refs", "remotes"), func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+		return nil
+	})
+	return newest
+}
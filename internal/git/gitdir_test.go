@@ -0,0 +1,100 @@
+package git_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/gittest"
+)
+
+// sameDir reports whether a and b are the same directory, /tmp symlinks aside
+func sameDir(t *testing.T, a, b string) bool {
+	t.Helper()
+	if a == "" || b == "" {
+		return a == b
+	}
+	realA, errA := filepath.EvalSymlinks(a)
+	realB, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && realA == realB
+}
+
+func TestDotGitDirectoryAndFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture func(t *testing.T) (r *gittest.Repo, gitDir string)
+		branch  string
+	}{
+		{".git directory", func(t *testing.T) (*gittest.Repo, string) {
+			r := gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll()
+			return r.Modify("README.md"), filepath.Join(r.Path, ".git")
+		}, gittest.DefaultBranch},
+		{".git file of a linked worktree", func(t *testing.T) (*gittest.Repo, string) {
+			main := gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll()
+			wt := main.Worktree("topic")
+			wt.Git("push", "--quiet", "--set-upstream", "origin", "topic")
+			return wt.Modify("README.md"), filepath.Join(main.Path, ".git", "worktrees", "topic")
+		}, "topic"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, want := tt.fixture(t)
+			if !git.IsGitRepository(r.Path) {
+				t.Fatal("not recognized as a git repository")
+			}
+			if got := git.GitDir(r.Path); !sameDir(t, got, want) {
+				t.Errorf("git directory %q, want %q", got, want)
+			}
+
+			for _, backend := range backends {
+				t.Run(backend.name, func(t *testing.T) {
+					status := getStatus(t, backend.open(r))
+					if status.Type != git.StatusUnsync || status.ModifiedCount != 1 || status.Branch != tt.branch {
+						t.Errorf("got %s on %q with %d modified (%s), want %s on %q with 1 modified",
+							status.Type, status.Branch, status.ModifiedCount, status.Message, git.StatusUnsync, tt.branch)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestGitDirFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content func(modules string) string // Of the .git file, given a directory holding a git directory
+		found   bool
+	}{
+		{"absolute gitdir", func(modules string) string { return "gitdir: " + filepath.Join(modules, "sub") + "\n" }, true},
+		{"relative gitdir, as in submodules", func(string) string { return "gitdir: ../modules/sub\n" }, true},
+		{"missing gitdir", func(modules string) string { return "gitdir: " + filepath.Join(modules, "gone") + "\n" }, false},
+		{"not a gitdir file", func(string) string { return "ref: refs/heads/main\n" }, false},
+		{"empty", func(string) string { return "" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			modules, path := filepath.Join(root, "modules"), filepath.Join(root, "sub")
+			for _, dir := range []string{filepath.Join(modules, "sub"), path} {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := os.WriteFile(filepath.Join(path, ".git"), []byte(tt.content(modules)), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			want := ""
+			if tt.found {
+				want = filepath.Join(modules, "sub")
+			}
+			if got := git.GitDir(path); !sameDir(t, got, want) {
+				t.Errorf("git directory %q, want %q", got, want)
+			}
+			if got := git.IsGitRepository(path); got != tt.found {
+				t.Errorf("recognized as a repository: %v, want %v", got, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,688 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// GoGitRepository is a git repository read with go-git, in-process, for systems
+// without the git binary. It reports the same statuses as Repository, with these
+// differences: submodules are not checked, only the .gitignore files of the
+// worktree and .git/info/exclude are honored (not core.excludesFile), staged renames
+// are only detected when the content did not change, and features
+// beyond the vcs.Repository interface (pull, push, upstream setup) need the git binary.
+type GoGitRepository struct {
+	Path string
+	Name string
+	Bare bool // Without working tree: only its branches are checked
+}
+
+// NewGoGitRepository creates a new GoGitRepository instance
+func NewGoGitRepository(path, name string) *GoGitRepository {
+	return &GoGitRepository{
+		Path: path,
+		Name: name,
+		Bare: GitDir(path) == "" && IsBareRepository(path),
+	}
+}
+
+// Kind returns "git", the VCS of the repository
+func (r *GoGitRepository) Kind() string {
+	return "git"
+}
+
+// open opens the repository, linked worktrees included
+func (r *GoGitRepository) open() (*gogit.Repository, error) {
+	repo, err := gogit.PlainOpenWithOptions(r.Path, &gogit.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return repo, nil
+}
+
+// files gives access to the state git keeps in files (shallow clone, operation in
+// progress, changed files), read the same way by both backends
+func (r *GoGitRepository) files() *Repository {
+	return &Repository{Path: r.Path, Name: r.Name, Bare: r.Bare}
+}
+
+// currentBranch returns the branch HEAD points to, born or not, or "HEAD" when detached
+func currentBranch(repo *gogit.Repository) (string, error) {
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if head.Type() == plumbing.SymbolicReference && head.Target().IsBranch() {
+		return head.Target().Short(), nil
+	}
+	return "HEAD", nil
+}
+
+// GetCurrentBranch returns the name of the current branch
+func (r *GoGitRepository) GetCurrentBranch(ctx context.Context) (string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	return currentBranch(repo)
+}
+
+// upstreamRef returns the remote-tracking branch branch is set to track, or ""
+// without upstream. A branch tracking another local one (remote ".") tracks it directly.
+func upstreamRef(cfg *gitconfig.Config, branch string) plumbing.ReferenceName {
+	tracking, ok := cfg.Branches[branch]
+	if !ok || tracking.Remote == "" || tracking.Merge == "" {
+		return ""
+	}
+	if tracking.Remote == "." {
+		return tracking.Merge
+	}
+	return plumbing.NewRemoteReferenceName(tracking.Remote, tracking.Merge.Short())
+}
+
+// GetStatus retrieves the status of the repository like Repository.GetStatus does,
+// classified by the same rules
+func (r *GoGitRepository) GetStatus(ctx context.Context) (*Status, error) {
+	repo, err := r.open()
+	if err != nil {
+		return r.errorStatus(err), nil
+	}
+	if r.Bare {
+		status, err := r.bareStatus(ctx, repo)
+		if status != nil {
+			status.Origin = r.files().origin()
+			status.LastFetched = r.files().lastFetched()
+		}
+		return status, err
+	}
+
+	behindBranches, err := r.branchesTracking(ctx, repo)
+	if err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+	if err != nil {
+		behindBranches = []BranchTracking{}
+	}
+
+	porcelain, err := r.porcelainStatus(ctx, repo)
+	if err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+	if err != nil {
+		status := r.errorStatus(err)
+		status.BehindBranches = behindBranches
+		return status, nil
+	}
+
+	files := r.files()
+	status := porcelain.classify()
+	status.BehindBranches = behindBranches
+	status.OldestChange = files.oldestChange(porcelain.Paths)
+	status.Shallow = files.isShallow()
+	status.Origin = files.origin()
+	status.LastFetched = files.lastFetched()
+	if status.Origin != nil {
+		status.DefaultBranch = remoteHead(repo, status.Origin.Name)
+	}
+	if status.Type == StatusNoUpstream && status.Origin == nil {
+		if remotes, err := repo.Remotes(); err == nil && len(remotes) == 0 {
+			status.withNoRemote()
+		}
+	}
+	if operation, branch := files.operationInProgress(); operation != "" {
+		status.withOperation(operation)
+		if branch != "" {
+			status.Branch = branch // HEAD is detached while rebasing
+			status.DetachedAt = ""
+		}
+	}
+	if lock, lockedAt := files.indexLock(); lock != "" {
+		status.withLock(lock, lockedAt)
+	}
+	status.StashCount = r.stashCount()
+	if porcelain.Commit != "(initial)" {
+		status.LastCommit, _ = lastCommit(repo)
+	}
+	return status, nil
+}
+
+// errorStatus reports a repository go-git could not read
+func (r *GoGitRepository) errorStatus(err error) *Status {
+	if status := PermissionStatus(r.Path, "", err); status != nil {
+		return status
+	}
+	return &Status{
+		Type:    StatusError,
+		Message: fmt.Sprintf("Error: %s", err),
+		Symbol:  "❌",
+	}
+}
+
+// porcelainStatus fills what `git status --porcelain=v2 --branch` would report
+// from the worktree status and the commit graph
+func (r *GoGitRepository) porcelainStatus(ctx context.Context, repo *gogit.Repository) (*porcelainStatus, error) {
+	status := &porcelainStatus{Commit: "(initial)"}
+
+	branch, err := currentBranch(repo)
+	if err != nil {
+		return nil, err
+	}
+	status.Branch = branch
+	status.Detached = branch == "HEAD"
+
+	head, err := repo.Head()
+	switch {
+	case err == nil:
+		status.Commit = head.Hash().String()
+	case !errors.Is(err, plumbing.ErrReferenceNotFound):
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	if !status.Detached {
+		cfg, err := repo.Config()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		if upstream := upstreamRef(cfg, branch); upstream != "" {
+			status.Upstream = upstream.Short()
+			ref, err := repo.Reference(upstream, true)
+			switch {
+			case err == nil && head != nil:
+				status.Ahead, status.Behind, err = aheadBehind(ctx, repo, head.Hash(), ref.Hash())
+				if err != nil {
+					return nil, err
+				}
+			case errors.Is(err, plumbing.ErrReferenceNotFound) && head != nil:
+				status.Gone = true
+			}
+		}
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open worktree: %w", err)
+	}
+	changes, err := worktree.Status()
+	if err != nil {
+		if ctxErr := contextError(ctx, "git status"); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to read worktree status: %w", err)
+	}
+	unmerged, err := unmergedPaths(repo)
+	if err != nil {
+		return nil, err
+	}
+	renamed, err := stagedRenames(repo, head, changes)
+	if err != nil {
+		return nil, err
+	}
+	for path, change := range changes {
+		x, y := change.Staging, change.Worktree
+		if x == gogit.Unmodified && y == gogit.Unmodified {
+			continue
+		}
+		if _, ok := renamed.from[path]; ok {
+			continue // Reported with its new path, as a single change
+		}
+		if _, ok := renamed.to[path]; ok {
+			x = gogit.Renamed
+		}
+		status.Changes++
+		switch {
+		case x == gogit.Untracked:
+			status.Untracked = true
+			status.UntrackedCount++
+		case unmerged[path]:
+			status.Modified = true
+			status.ModifiedCount++
+			status.Conflicts++
+		default:
+			if x != gogit.Unmodified {
+				status.StagedCount++
+			}
+			switch x {
+			case gogit.Unmodified:
+			case gogit.Added:
+				status.StagedAdd = true
+			case gogit.Renamed, gogit.Copied:
+				status.StagedRen = true
+			default:
+				status.Staged = true
+			}
+			switch y {
+			case gogit.Modified:
+				status.Modified = true
+				status.ModifiedCount++
+			case gogit.Deleted:
+				status.Deleted = true
+				status.DeletedCount++
+			}
+		}
+		if x != gogit.Deleted && y != gogit.Deleted {
+			status.Paths = append(status.Paths, path)
+		}
+	}
+	slices.Sort(status.Paths)
+	return status, nil
+}
+
+// renames pairs the old and new paths of renamed files, both ways
+type renames struct {
+	from map[string]string // Old path to new path
+	to   map[string]string // New path to old path
+}
+
+// stagedRenames pairs the files staged as deleted with the files staged as added
+// with the same content, which git reports as renames while go-git does not. Renamed
+// files whose content changed too, matched by git on similarity, stay a deletion and an addition.
+func stagedRenames(repo *gogit.Repository, head *plumbing.Reference, changes gogit.Status) (renames, error) {
+	found := renames{from: map[string]string{}, to: map[string]string{}}
+	var deleted, added []string
+	for path, change := range changes {
+		switch change.Staging {
+		case gogit.Deleted:
+			deleted = append(deleted, path)
+		case gogit.Added:
+			added = append(added, path)
+		}
+	}
+	if head == nil || len(deleted) == 0 || len(added) == 0 {
+		return found, nil
+	}
+	slices.Sort(deleted)
+	slices.Sort(added)
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return found, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return found, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return found, fmt.Errorf("failed to read the index: %w", err)
+	}
+
+	for _, from := range deleted {
+		file, err := tree.File(from)
+		if err != nil {
+			continue
+		}
+		for _, to := range added {
+			if _, taken := found.to[to]; taken {
+				continue
+			}
+			if entry, err := idx.Entry(to); err == nil && entry.Hash == file.Hash {
+				found.from[from] = to
+				found.to[to] = from
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+// unmergedPaths returns the paths with conflicts: go-git reports them as modified,
+// while the index holds their base, ours and theirs versions (stages 1 to 3)
+func unmergedPaths(repo *gogit.Repository) (map[string]bool, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the index: %w", err)
+	}
+	unmerged := map[string]bool{}
+	for _, entry := range idx.Entries {
+		if entry.Stage != 0 { // Merged entries (index.Merged is 1, like index.AncestorMode)
+			unmerged[entry.Name] = true
+		}
+	}
+	return unmerged, nil
+}
+
+// branchesTracking lists the local branches behind their upstream, like readBranchesTracking
+func (r *GoGitRepository) branchesTracking(ctx context.Context, repo *gogit.Repository) ([]BranchTracking, error) {
+	tracks, err := branchTracks(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	var behindBranches []BranchTracking
+	for _, track := range tracks {
+		if track.behind > 0 {
+			behindBranches = append(behindBranches, BranchTracking{
+				Branch:  track.branch,
+				Message: behindMessage(track.ahead, track.behind),
+			})
+		}
+	}
+	return behindBranches, nil
+}
+
+// bareStatus reports a bare repository like Repository.bareStatus
+func (r *GoGitRepository) bareStatus(ctx context.Context, repo *gogit.Repository) (*Status, error) {
+	tracks, err := branchTracks(ctx, repo)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return r.errorStatus(err), nil
+	}
+	var unpushed []string
+	for _, track := range tracks {
+		if track.ahead > 0 {
+			unpushed = append(unpushed, fmt.Sprintf("%s (%d)", track.branch, track.ahead))
+		}
+	}
+	return newBareStatus(unpushed), nil
+}
+
+// branchTrack is how far a local branch is from its upstream
+type branchTrack struct {
+	branch        string
+	ahead, behind int
+}
+
+// branchTracks compares each local branch whose upstream exists with it, by name
+func branchTracks(ctx context.Context, repo *gogit.Repository) ([]branchTrack, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	branches, err := repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+	defer branches.Close()
+
+	var tracks []branchTrack
+	err = branches.ForEach(func(ref *plumbing.Reference) error {
+		branch := ref.Name().Short()
+		upstream := upstreamRef(cfg, branch)
+		if upstream == "" {
+			return nil
+		}
+		upstreamHead, err := repo.Reference(upstream, true)
+		if err != nil {
+			return nil // Gone from the remote
+		}
+		ahead, behind, err := aheadBehind(ctx, repo, ref.Hash(), upstreamHead.Hash())
+		if err != nil {
+			return err
+		}
+		tracks = append(tracks, branchTrack{branch: branch, ahead: ahead, behind: behind})
+		return nil
+	})
+	slices.SortFunc(tracks, func(a, b branchTrack) int { return strings.Compare(a.branch, b.branch) })
+	return tracks, err
+}
+
+// aheadBehind counts the commits of local not reachable from upstream, and of
+// upstream not reachable from local, like git rev-list --left-right --count
+func aheadBehind(ctx context.Context, repo *gogit.Repository, local, upstream plumbing.Hash) (ahead, behind int, err error) {
+	if local == upstream {
+		return 0, 0, nil
+	}
+	fromUpstream, err := ancestors(ctx, repo, upstream, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	fromLocal, err := ancestors(ctx, repo, local, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	onlyLocal, err := ancestors(ctx, repo, local, fromUpstream)
+	if err != nil {
+		return 0, 0, err
+	}
+	onlyUpstream, err := ancestors(ctx, repo, upstream, fromLocal)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(onlyLocal), len(onlyUpstream), nil
+}
+
+// ancestors returns the commits reachable from start (itself included), not
+// walking past those in stop. Parents missing from a shallow clone are left out.
+func ancestors(ctx context.Context, repo *gogit.Repository, start plumbing.Hash, stop map[plumbing.Hash]bool) (map[plumbing.Hash]bool, error) {
+	seen := map[plumbing.Hash]bool{}
+	pending := []plumbing.Hash{start}
+	for len(pending) > 0 {
+		if ctxErr := contextError(ctx, "git rev-list"); ctxErr != nil {
+			return nil, ctxErr
+		}
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if seen[hash] || stop[hash] {
+			continue
+		}
+		commit, err := repo.CommitObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %w", shortCommit(hash.String()), err)
+		}
+		seen[hash] = true
+		pending = append(pending, commit.ParentHashes...)
+	}
+	return seen, nil
+}
+
+// stashCount counts the entries of the stash, one per line of its reflog
+func (r *GoGitRepository) stashCount() int {
+	// Linked worktrees share the stash of the git directory in commondir
+	gitDir := commonDir(r.Path, r.Bare)
+	if gitDir == "" {
+		return 0
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, "logs", "refs", "stash"))
+	if err != nil {
+		return 0
+	}
+	return strings.Count(string(data), "\n")
+}
+
+// lastCommit describes HEAD like Repository.LastCommit
+func lastCommit(repo *gogit.Repository) (*CommitInfo, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last commit: %w", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last commit: %w", err)
+	}
+	return commitInfo(commit), nil
+}
+
+// commitInfo describes a commit, with the first line of its message as subject
+func commitInfo(commit *object.Commit) *CommitInfo {
+	subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	return &CommitInfo{
+		Hash:    shortCommit(commit.Hash.String()),
+		Author:  commit.Author.Name,
+		Date:    commit.Committer.When,
+		Subject: subject,
+	}
+}
+
+// LastCommitTime returns the committer date of HEAD.
+// It returns an error for repositories without commits.
+func (r *GoGitRepository) LastCommitTime(ctx context.Context) (time.Time, error) {
+	repo, err := r.open()
+	if err != nil {
+		return time.Time{}, err
+	}
+	commit, err := lastCommit(repo)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return commit.Date, nil
+}
+
+// IsDirty reports whether the worktree has changes, untracked files included
+func (r *GoGitRepository) IsDirty(ctx context.Context) (bool, error) {
+	repo, err := r.open()
+	if err != nil {
+		return false, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("failed to open worktree: %w", err)
+	}
+	changes, err := worktree.Status()
+	if err != nil {
+		return false, fmt.Errorf("failed to read worktree status: %w", err)
+	}
+	return !changes.IsClean(), nil
+}
+
+// Remotes returns the names of the configured remotes
+func (r *GoGitRepository) Remotes(ctx context.Context) ([]string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	var names []string
+	for name := range cfg.Remotes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// Fetch updates remote-tracking branches like Repository.Fetch, authenticating
+// with the ssh agent or key, or stored https credentials (see fetchAuth)
+func (r *GoGitRepository) Fetch(ctx context.Context, opts FetchOptions) (FetchResult, error) {
+	repo, err := r.open()
+	if err != nil {
+		return FetchResult{}, err
+	}
+
+	remotes := []string{opts.Remote}
+	switch {
+	case opts.AllRemotes:
+		if remotes, err = r.Remotes(ctx); err != nil {
+			return FetchResult{}, err
+		}
+	case opts.Remote == "":
+		remotes = []string{r.defaultRemote(repo)}
+	}
+
+	before, err := fetchedRefs(repo)
+	if err != nil {
+		return FetchResult{}, err
+	}
+	for _, name := range remotes {
+		remote, err := repo.Remote(name)
+		if err != nil {
+			return FetchResult{}, &FetchError{Operation: "fetch", Stderr: fmt.Sprintf("fatal: '%s' does not appear to be a git repository", name)}
+		}
+		fetchOpts := &gogit.FetchOptions{RemoteName: name, Prune: opts.Prune}
+		if urls := remote.Config().URLs; len(urls) > 0 {
+			fetchOpts.Auth = fetchAuth(urls[0])
+		}
+		err = repo.FetchContext(ctx, fetchOpts)
+		if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+			if ctxErr := contextError(ctx, "git fetch"); ctxErr != nil {
+				return FetchResult{}, ctxErr
+			}
+			return FetchResult{}, &FetchError{Operation: "fetch", Stderr: "fatal: " + err.Error()}
+		}
+	}
+	after, err := fetchedRefs(repo)
+	if err != nil {
+		return FetchResult{}, err
+	}
+
+	var result FetchResult
+	for name, hash := range after {
+		if previous, ok := before[name]; !ok || previous != hash {
+			result.Updated++
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			result.Pruned++
+		}
+	}
+	return result, nil
+}
+
+// defaultRemote returns the remote git fetch uses without arguments: the one of
+// the current branch, or origin
+func (r *GoGitRepository) defaultRemote(repo *gogit.Repository) string {
+	branch, err := currentBranch(repo)
+	if err != nil {
+		return "origin"
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return "origin"
+	}
+	if tracking, ok := cfg.Branches[branch]; ok && tracking.Remote != "" && tracking.Remote != "." {
+		return tracking.Remote
+	}
+	return "origin"
+}
+
+// remoteHead returns the default branch of remote, which its <remote>/HEAD points
+// to, or "" when unknown
+func remoteHead(repo *gogit.Repository, remote string) string {
+	ref, err := repo.Storer.Reference(plumbing.NewRemoteHEADReferenceName(remote))
+	if err != nil || ref.Type() != plumbing.SymbolicReference {
+		return ""
+	}
+	branch, ok := strings.CutPrefix(ref.Target().String(), "refs/remotes/"+remote+"/")
+	if !ok {
+		return ""
+	}
+	return branch
+}
+
+// fetchedRefs maps the remote-tracking branches and tags to their commit
+func fetchedRefs(repo *gogit.Repository) (map[plumbing.ReferenceName]plumbing.Hash, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+	defer refs.Close()
+
+	hashes := map[plumbing.ReferenceName]plumbing.Hash{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if (ref.Name().IsRemote() || ref.Name().IsTag()) && ref.Type() == plumbing.HashReference {
+			hashes[ref.Name()] = ref.Hash()
+		}
+		return nil
+	})
+	return hashes, err
+}
+
+// RemoteURL returns the fetch URL of a remote
+func (r *GoGitRepository) RemoteURL(ctx context.Context, remote string) (string, error) {
+	repo, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	found, err := repo.Remote(remote)
+	if err != nil || len(found.Config().URLs) == 0 {
+		return "", fmt.Errorf("failed to read the url of remote %s: no such remote", remote)
+	}
+	return found.Config().URLs[0], nil
+}
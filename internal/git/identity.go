@@ -0,0 +1,36 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// IdentitySymbol flags a repository committing with an unexpected user.email
+const IdentitySymbol = "✉"
+
+// UserEmail returns the user.email commits are made with in the repository, as
+// its local, global and system configs (and their includes) resolve it; "" when unset
+func (r *Repository) UserEmail(ctx context.Context) (string, error) {
+	cmd := r.command(ctx, "config", "--get", "user.email")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git config"); ctxErr != nil {
+			return "", ctxErr
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil // The key is not set
+		}
+		return "", fmt.Errorf("failed to read user.email: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LocalOnlyBranches returns the local branches without upstream holding commits
+// that no remote-tracking branch contains, with how many. Without remote-tracking
+// branches (nothing was ever fetched) there is nothing to compare with: it returns none.
+func (r *Repository) LocalOnlyBranches(ctx context.Context) ([]BranchTracking, error) {
+	cmd := r.command(ctx, "for-each-ref", "--format=%(refname)%00%(upstream)", "refs/heads", "refs/remotes")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git for-each-ref"); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to get branches: %s", stderr.String())
+	}
+
+	var untracked []string
+	hasRemotes := false
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		ref, upstream, _ := strings.Cut(line, "\x00")
+		if strings.HasPrefix(ref, "refs/remotes/") {
+			hasRemotes = true
+		} else if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok && upstream == "" {
+			untracked = append(untracked, branch)
+		}
+	}
+	if !hasRemotes {
+		return nil, nil
+	}
+
+	var localOnly []BranchTracking
+	for _, branch := range untracked {
+		count, err := r.countLocalOnly(ctx, branch)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			localOnly = append(localOnly, BranchTracking{
+				Branch:  branch,
+				Message: fmt.Sprintf("%d local-only commit(s)", count),
+			})
+		}
+	}
+	return localOnly, nil
+}
+
+// countLocalOnly counts the commits of branch that no remote-tracking branch contains
+func (r *Repository) countLocalOnly(ctx context.Context, branch string) (int, error) {
+	cmd := r.command(ctx, "rev-list", "--count", "refs/heads/"+branch, "--not", "--remotes")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git rev-list"); ctxErr != nil {
+			return 0, ctxErr
+		}
+		return 0, fmt.Errorf("failed to count commits of %s: %s", branch, stderr.String())
+	}
+	return strconv.Atoi(strings.TrimSpace(stdout.String()))
+}
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LockSymbol flags a repository blocked by a lock file
+const LockSymbol = "⛔"
+
+// StaleLockAge is the age past which a lock file is deemed left behind by a crashed
+// git process or editor, rather than held by one still running
+const StaleLockAge = 5 * time.Minute
+
+// indexLock returns the index.lock of the repository and when it was created,
+// or "" when git is not locked out of its index
+func (r *Repository) indexLock() (string, time.Time) {
+	gitDir := GitDir(r.Path)
+	if r.Bare || gitDir == "" {
+		return "", time.Time{}
+	}
+	lock := filepath.Join(gitDir, "index.lock")
+	info, err := os.Stat(lock)
+	if err != nil {
+		return "", time.Time{}
+	}
+	return lock, info.ModTime()
+}
+
+// withLock marks a status as locked by lockFile, keeping its branch and changes:
+// git commands writing to the repository fail until the lock is gone
+func (s *Status) withLock(lockFile string, lockedAt time.Time) {
+	s.Type = StatusLocked
+	s.Message = fmt.Sprintf("Locked by %s (another git process, or a stale lock)", lockFile)
+	if !lockedAt.IsZero() {
+		s.Message = fmt.Sprintf("Locked by %s for %s (another git process, or a stale lock)", lockFile, FormatAge(time.Since(lockedAt)))
+	}
+	s.Symbol = LockSymbol
+	s.LockFile = lockFile
+	s.LockedAt = lockedAt
+}
+
+// LockedStatus returns the status of a repository whose git command failed on
+// an existing lock file, read from its stderr, e.g. "fatal: Unable to create
+// '/repo/.git/index.lock': File exists."; nil otherwise
+func LockedStatus(stderr string) *Status {
+	for _, line := range strings.Split(stderr, "\n") {
+		if !strings.Contains(line, ".lock") || !strings.Contains(line, "File exists") {
+			continue
+		}
+		lock := messagePath(line)
+		if lock == "" {
+			continue
+		}
+		status := &Status{}
+		var lockedAt time.Time
+		if info, err := os.Stat(lock); err == nil {
+			lockedAt = info.ModTime()
+		}
+		status.withLock(lock, lockedAt)
+		return status
+	}
+	return nil
+}
+
+// RemoveIndexLock removes the index.lock of the repository once it is older than
+// StaleLockAge: a younger one may still be held by a running git process.
+func (r *Repository) RemoveIndexLock() error {
+	lock, lockedAt := r.indexLock()
+	if lock == "" {
+		return fmt.Errorf("no index.lock in %s", r.Path)
+	}
+	if age := time.Since(lockedAt); age < StaleLockAge {
+		return fmt.Errorf("%s is only %s old: a git process may still be running", lock, age.Round(time.Second))
+	}
+	if err := os.Remove(lock); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", lock, err)
+	}
+	return nil
+}
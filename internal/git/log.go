@@ -0,0 +1,88 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LastCommitTime returns the committer date of HEAD.
+// It returns an error for repositories without commits.
+func (r *Repository) LastCommitTime(ctx context.Context) (time.Time, error) {
+	cmd := r.command(ctx, "log", "-1", "--format=%ct")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git log"); ctxErr != nil {
+			return time.Time{}, ctxErr
+		}
+		return time.Time{}, fmt.Errorf("failed to read last commit: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(stdout.String()), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected git log output: %q", stdout.String())
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// CommitInfo describes a commit
+type CommitInfo struct {
+	Hash    string // Abbreviated
+	Author  string
+	Date    time.Time // Committer date
+	Subject string
+}
+
+// LastCommit describes HEAD with a single git log.
+// It returns an error for repositories without commits.
+func (r *Repository) LastCommit(ctx context.Context) (*CommitInfo, error) {
+	cmd := r.command(ctx, "log", "-1", "--format=%H%x00%an%x00%ct%x00%s")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git log"); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to read last commit: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	fields := strings.SplitN(strings.TrimSuffix(stdout.String(), "\n"), "\x00", 4)
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unexpected git log output: %q", stdout.String())
+	}
+	seconds, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected git log output: %q", stdout.String())
+	}
+	return &CommitInfo{
+		Hash:    shortCommit(fields[0]),
+		Author:  fields[1],
+		Date:    time.Unix(seconds, 0),
+		Subject: fields[3],
+	}, nil
+}
+
+// FormatAge renders an age in its largest whole unit, e.g. "213 days" or "5 hours"
+func FormatAge(age time.Duration) string {
+	unit, n := "minute", int(age.Minutes())
+	switch {
+	case age >= 48*time.Hour:
+		unit, n = "day", int(age.Hours()/24)
+	case age >= 2*time.Hour:
+		unit, n = "hour", int(age.Hours())
+	}
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,86 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MergedBranches returns the local branches whose commits are all on the default
+// branch of remote, which are safe to delete, along with the remote-tracking branch
+// they were compared with (e.g. "origin/main"). The default branch is defaultBranch,
+// as <remote>/HEAD points to; when it is not set, <remote>/main or else
+// <remote>/master. The current branch and the one named like the default branch
+// are left out. Without a default branch there is nothing to compare with: it
+// returns none.
+func (r *Repository) MergedBranches(ctx context.Context, remote, defaultBranch string) (string, []string, error) {
+	base, ok := r.mergeBase(ctx, remote, defaultBranch)
+	if !ok {
+		return "", nil, nil
+	}
+	_, branchName, _ := strings.Cut(base, "/")
+
+	cmd := r.command(ctx, "for-each-ref", "--merged=refs/remotes/"+base, "--format=%(refname)%00%(HEAD)", "refs/heads")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git for-each-ref"); ctxErr != nil {
+			return "", nil, ctxErr
+		}
+		return "", nil, fmt.Errorf("failed to list merged branches: %s", stderr.String())
+	}
+
+	var merged []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		ref, head, _ := strings.Cut(line, "\x00")
+		branch, ok := strings.CutPrefix(ref, "refs/heads/")
+		if !ok || head == "*" || branch == branchName {
+			continue
+		}
+		merged = append(merged, branch)
+	}
+	return base, merged, nil
+}
+
+// mergeBase returns the remote-tracking branch MergedBranches compares with
+func (r *Repository) mergeBase(ctx context.Context, remote, defaultBranch string) (string, bool) {
+	if defaultBranch != "" {
+		return remote + "/" + defaultBranch, true
+	}
+	for _, branch := range []string{"main", "master"} {
+		if err := r.command(ctx, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remote+"/"+branch).Run(); err == nil {
+			return remote + "/" + branch, true
+		}
+	}
+	return "", false
+}
+
+// DeleteMergedBranch deletes a local branch once it made sure that every one of its
+// commits is on base, a remote-tracking branch such as "origin/main"
+func (r *Repository) DeleteMergedBranch(ctx context.Context, branch, base string) error {
+	// git branch -d would only look at the upstream of the branch, or at HEAD
+	err := r.command(ctx, "merge-base", "--is-ancestor", "refs/heads/"+branch, "refs/remotes/"+base).Run()
+	if err != nil {
+		if ctxErr := contextError(ctx, "git merge-base"); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("%s is not merged into %s", branch, base)
+	}
+
+	cmd := r.command(ctx, "branch", "-D", branch)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git branch"); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to delete %s: %s", branch, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Operation is a git operation left in progress in a working copy
+type Operation string
+
+const (
+	OperationMerge      Operation = "merge"
+	OperationRebase     Operation = "rebase"
+	OperationCherryPick Operation = "cherry-pick"
+)
+
+// operationSymbols tells in-progress operations apart in reports
+var operationSymbols = map[Operation]string{
+	OperationMerge:      "⑂",
+	OperationRebase:     "↻",
+	OperationCherryPick: "⇝",
+}
+
+// operationInProgress reads the state files git leaves in the git directory while
+// an operation waits for the user, and returns the operation with, for a rebase,
+// the branch being rebased ("" when unknown)
+func (r *Repository) operationInProgress() (Operation, string) {
+	gitDir := GitDir(r.Path)
+	if gitDir == "" {
+		return "", ""
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(gitDir, name))
+		return err == nil
+	}
+
+	// Interactive and merge-based rebases use rebase-merge, am-based ones rebase-apply
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		if !exists(dir) {
+			continue
+		}
+		if dir == "rebase-apply" && exists(filepath.Join(dir, "applying")) {
+			continue // git am, not a rebase
+		}
+		headName, _ := os.ReadFile(filepath.Join(gitDir, dir, "head-name"))
+		return OperationRebase, strings.TrimPrefix(strings.TrimSpace(string(headName)), "refs/heads/")
+	}
+	switch {
+	case exists("MERGE_HEAD"):
+		return OperationMerge, ""
+	case exists("CHERRY_PICK_HEAD"):
+		return OperationCherryPick, ""
+	}
+	return "", ""
+}
+
+// withOperation turns status into the status of the in-progress operation: it is
+// unsync whatever the working copy holds, since the operation must be finished or
+// aborted. Conflicts left by the operation are flagged with ConflictSymbol.
+func (s *Status) withOperation(operation Operation) {
+	message := map[Operation]string{
+		OperationMerge:      "Merge in progress",
+		OperationRebase:     "Rebase in progress",
+		OperationCherryPick: "Cherry-pick in progress",
+	}[operation]
+	if s.Conflicts > 0 {
+		message += fmt.Sprintf(" (%d conflict(s))", s.Conflicts)
+	}
+
+	s.Type = StatusUnsync
+	s.Message = message
+	s.Symbol = operationSymbols[operation]
+	if s.Conflicts > 0 {
+		s.Symbol = ConflictSymbol + " " + s.Symbol
+	}
+	s.Operation = operation
+}
@@ -0,0 +1,105 @@
+package git
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	formatcfg "github.com/go-git/go-git/v5/plumbing/format/config"
+)
+
+// RemoteInfo tells where a repository pushes to, its URL split into host,
+// owner and repository name when it points to a hosting service
+type RemoteInfo struct {
+	Name  string // Remote name, e.g. "origin"
+	URL   string // Push URL (pushurl, or else url), without its password
+	Host  string // "" for local paths and file:// URLs
+	Owner string // User, organization or group path, e.g. "uralys" or "group/subgroup"
+	Repo  string // Repository name, without .git
+}
+
+// String returns host/owner/repo, or the URL when it does not point to a host
+func (i *RemoteInfo) String() string {
+	if i.Host == "" || i.Repo == "" {
+		return i.URL
+	}
+	if i.Owner == "" {
+		return i.Host + "/" + i.Repo
+	}
+	return i.Host + "/" + i.Owner + "/" + i.Repo
+}
+
+// ParseRemote splits the URL of a remote, scp-like or with a scheme, into its
+// host, owner and repository name. Local paths keep only their URL.
+func ParseRemote(name, remoteURL string) *RemoteInfo {
+	info := &RemoteInfo{Name: name, URL: strings.TrimSpace(remoteURL)}
+
+	var path string
+	if !strings.Contains(info.URL, "://") {
+		var ok bool
+		if info.Host, path, ok = splitScpLike(info.URL); !ok {
+			return info
+		}
+	} else if u, err := url.Parse(info.URL); err == nil && u.Scheme != "file" {
+		info.Host, path = u.Hostname(), u.Path
+		// A token in the URL is not shown
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.User(u.User.Username())
+			info.URL = u.String()
+		}
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if info.Host == "" || path == "" {
+		info.Host = ""
+		return info
+	}
+	if slash := strings.LastIndex(path, "/"); slash != -1 {
+		info.Owner, info.Repo = path[:slash], path[slash+1:]
+	} else {
+		info.Repo = path
+	}
+	return info
+}
+
+// origin returns the remote the repository pushes to, "origin" or else the first
+// one, read from its config file without running git (so insteadOf rewrites are
+// not applied). It returns nil when the repository has no remote.
+func (r *Repository) origin() *RemoteInfo {
+	gitDir := commonDir(r.Path, r.Bare)
+	if gitDir == "" {
+		return nil
+	}
+	file, err := os.Open(filepath.Join(gitDir, "config"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	cfg := formatcfg.New()
+	if err := formatcfg.NewDecoder(file).Decode(cfg); err != nil {
+		return nil
+	}
+
+	var remote *formatcfg.Subsection
+	for _, subsection := range cfg.Section("remote").Subsections {
+		if subsection.Option("url") == "" {
+			continue
+		}
+		if remote == nil || subsection.Name == "origin" {
+			remote = subsection
+		}
+		if subsection.Name == "origin" {
+			break
+		}
+	}
+	if remote == nil {
+		return nil
+	}
+	remoteURL := remote.Option("pushurl")
+	if remoteURL == "" {
+		remoteURL = remote.Option("url")
+	}
+	return ParseRemote(remote.Name, remoteURL)
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// NewPermissionStatus builds the status of a repository that could not be read
+// by the current user, naming the failing path
+func NewPermissionStatus(path string, dubiousOwnership bool) *Status {
+	message := fmt.Sprintf("Permission denied: %s", path)
+	if dubiousOwnership {
+		message = fmt.Sprintf("Owned by another user, refused by git: %s", path)
+	}
+	return &Status{
+		Type:    StatusPermission,
+		Message: message,
+		Symbol:  "🔒",
+		Path:    path,
+	}
+}
+
+// PermissionStatus returns the permission status of the repository at repoPath
+// when its VCS failed because a path could not be read, from the error of the
+// command (when it could not even start in repoPath) or its stderr; nil otherwise
+func PermissionStatus(repoPath, stderr string, err error) *Status {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) && errors.Is(pathErr.Err, fs.ErrPermission) {
+		return NewPermissionStatus(pathErr.Path, false)
+	}
+
+	for _, line := range strings.Split(stderr, "\n") {
+		lower := strings.ToLower(line)
+		dubious := strings.Contains(lower, "dubious ownership")
+		if !dubious && !strings.Contains(lower, "permission denied") {
+			continue
+		}
+		path := messagePath(line)
+		switch {
+		case path == "":
+			path = repoPath
+		case !filepath.IsAbs(path):
+			path = filepath.Join(repoPath, path)
+		}
+		return NewPermissionStatus(path, dubious)
+	}
+	return nil
+}
+
+// messagePath returns the path named by a git or hg message: the first one quoted,
+// e.g. "error: open(\"a.txt\"): Permission denied" or "abort: Permission denied: '.hg/dirstate'",
+// or the one leading it, e.g. "fatal: .git/index: index file open failed: Permission denied"
+func messagePath(line string) string {
+	for _, quote := range []string{"'", `"`} {
+		start := strings.Index(line, quote)
+		if start < 0 {
+			continue
+		}
+		if end := strings.Index(line[start+1:], quote); end > 0 {
+			return line[start+1 : start+1+end]
+		}
+	}
+
+	_, message, found := strings.Cut(line, ": ")
+	if !found {
+		return ""
+	}
+	lead, _, _ := strings.Cut(message, ": ")
+	if strings.ContainsAny(lead, " \t") || !strings.ContainsAny(lead, "/.") {
+		return ""
+	}
+	return lead
+}
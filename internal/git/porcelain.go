@@ -0,0 +1,378 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// porcelainStatus is what `git status --porcelain=v2 --branch` reports
+type porcelainStatus struct {
+	Branch    string // "HEAD" when detached, like rev-parse --abbrev-ref
+	Detached  bool
+	Commit    string // Commit of HEAD, "(initial)" before the first one
+	Upstream  string // Empty without upstream
+	Gone      bool   // The branch of Upstream was deleted on the remote
+	Ahead     int
+	Behind    int
+	StagedAdd bool // Index entries by kind
+	StagedRen bool
+	Staged    bool
+	Modified  bool // Worktree entries by kind (unmerged ones count as modified)
+	Deleted   bool
+	Untracked bool
+	Changes   int      // Changed files, untracked ones included
+	Conflicts int      // Unmerged files
+	Paths     []string // Changed files still in the worktree, relative to its root
+
+	StagedCount    int // Files by kind of change, a file staged then modified counting as both
+	ModifiedCount  int
+	DeletedCount   int
+	UntrackedCount int
+
+	Submodules []string // Submodules with a new commit or changes of their own
+}
+
+// readPorcelainStatus runs a single git status for the branch, upstream and changes
+func (r *Repository) readPorcelainStatus(ctx context.Context) (*porcelainStatus, string, error) {
+	cmd := r.command(ctx, "status", "--porcelain=v2", "--branch")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git status"); ctxErr != nil {
+			return nil, "", ctxErr
+		}
+		return nil, stderr.String(), err
+	}
+
+	status, err := parsePorcelainStatus(stdout.String())
+	return status, "", err
+}
+
+// parsePorcelainStatus parses the output of `git status --porcelain=v2 --branch`
+func parsePorcelainStatus(output string) (*porcelainStatus, error) {
+	status := &porcelainStatus{}
+	hasAheadBehind := false
+
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+
+		if header, ok := strings.CutPrefix(line, "# "); ok {
+			key, value, _ := strings.Cut(header, " ")
+			switch key {
+			case "branch.oid":
+				status.Commit = value
+			case "branch.head":
+				status.Branch = value
+				if value == "(detached)" {
+					status.Branch = "HEAD"
+					status.Detached = true
+				}
+			case "branch.upstream":
+				status.Upstream = value
+			case "branch.ab":
+				// "+<ahead> -<behind>"
+				var ahead, behind int
+				if _, err := fmt.Sscanf(value, "+%d -%d", &ahead, &behind); err != nil {
+					return nil, fmt.Errorf("unexpected git status line: %q", line)
+				}
+				status.Ahead, status.Behind = ahead, behind
+				hasAheadBehind = true
+			}
+			continue
+		}
+
+		switch line[0] {
+		case '1', '2', 'u', '?':
+			status.Changes++
+		}
+
+		switch line[0] {
+		case '1', '2':
+			// "1 XY ..." ordinary change, "2 XY ..." rename or copy
+			if len(line) < 4 {
+				return nil, fmt.Errorf("unexpected git status line: %q", line)
+			}
+			x, y := line[2], line[3]
+			if y != 'D' && x != 'D' {
+				status.Paths = append(status.Paths, porcelainPath(line))
+			}
+			// "S<c><m><u>" for submodules: new commit, tracked and untracked changes
+			if len(line) >= 9 && line[5] == 'S' {
+				if line[6:9] != "..." {
+					status.Submodules = append(status.Submodules, porcelainPath(line))
+				}
+				if y == 'M' {
+					y = '.' // Reported as a dirty submodule rather than a modified file
+				}
+			}
+			if x != '.' {
+				status.StagedCount++
+			}
+			switch x {
+			case '.':
+			case 'A':
+				status.StagedAdd = true
+			case 'R', 'C':
+				status.StagedRen = true
+			default:
+				status.Staged = true
+			}
+			switch y {
+			case 'M', 'T':
+				status.Modified = true
+				status.ModifiedCount++
+			case 'D':
+				status.Deleted = true
+				status.DeletedCount++
+			}
+		case 'u':
+			status.Modified = true
+			status.ModifiedCount++
+			status.Conflicts++
+			status.Paths = append(status.Paths, porcelainPath(line))
+		case '?':
+			status.Untracked = true
+			status.UntrackedCount++
+			status.Paths = append(status.Paths, porcelainPath(line))
+		case '!':
+			// Ignored files are only listed on request
+		default:
+			return nil, fmt.Errorf("unexpected git status line: %q", line)
+		}
+	}
+
+	// git only compares with an upstream that still exists (nor before the first commit)
+	status.Gone = status.Upstream != "" && !hasAheadBehind && status.Commit != "(initial)"
+	return status, nil
+}
+
+// porcelainPath returns the path of a --porcelain=v2 entry line: its last field,
+// after the fixed ones of its kind (paths may contain spaces)
+func porcelainPath(line string) string {
+	fields := map[byte]int{'1': 9, '2': 10, 'u': 11, '?': 2}[line[0]]
+	parts := strings.SplitN(line, " ", fields)
+	path := parts[len(parts)-1]
+	if line[0] == '2' {
+		path, _, _ = strings.Cut(path, "\t") // "<path>\t<original path>"
+	}
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		path = unquoted // Quoted when it contains special characters
+	}
+	return path
+}
+
+// branchRefs is what a single for-each-ref tells about the local branches, the
+// default branch of each remote and the stash
+type branchRefs struct {
+	BehindBranches []BranchTracking     // Local branches behind their upstream
+	HasStash       bool                 // Whether refs/stash exists
+	Tips           map[string]branchTip // Last commit of each local branch, by name
+	RemoteHeads    map[string]string    // Default branch of each remote (<remote>/HEAD), by remote name
+}
+
+// branchTip is the last commit of a branch
+type branchTip struct {
+	Commit string // Full hash, as in git status
+	Info   *CommitInfo
+}
+
+// branchRefsFormat lists, for each ref, what parseBranchRefs reads
+const branchRefsFormat = "--format=%(refname)%00%(symref)%00%(upstream:track,nobracket)%00%(objectname)%00%(authorname)%00%(committerdate:unix)%00%(contents:subject)"
+
+// readBranchRefs lists, in one invocation, the local branches behind their upstream,
+// the last commit of each branch, the default branch of each remote, and whether
+// there are stashes
+func (r *Repository) readBranchRefs(ctx context.Context) (*branchRefs, error) {
+	cmd := r.command(ctx, "for-each-ref", branchRefsFormat, "refs/heads", "refs/remotes/*/HEAD", "refs/stash")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git for-each-ref"); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to get branches: %s", stderr.String())
+	}
+
+	return parseBranchRefs(stdout.String()), nil
+}
+
+// parseBranchRefs reads for-each-ref lines in branchRefsFormat, fields separated by
+// \x00, where track reads like "ahead 1, behind 2" and remote HEADs point to the
+// default branch of their remote (refs/remotes/origin/main)
+func parseBranchRefs(output string) *branchRefs {
+	refs := &branchRefs{Tips: map[string]branchTip{}, RemoteHeads: map[string]string{}}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\x00", 7)
+		if fields[0] == "refs/stash" {
+			refs.HasStash = true
+			continue
+		}
+		if remoteHead, ok := strings.CutPrefix(fields[0], "refs/remotes/"); ok && len(fields) == 7 {
+			remote, _ := strings.CutSuffix(remoteHead, "/HEAD")
+			if branch, ok := strings.CutPrefix(fields[1], "refs/remotes/"+remote+"/"); ok {
+				refs.RemoteHeads[remote] = branch
+			}
+			continue
+		}
+		branch, isBranch := strings.CutPrefix(fields[0], "refs/heads/")
+		if len(fields) != 7 || !isBranch || branch == "" {
+			continue
+		}
+
+		if seconds, err := strconv.ParseInt(fields[5], 10, 64); err == nil {
+			refs.Tips[branch] = branchTip{Commit: fields[3], Info: &CommitInfo{
+				Hash:    shortCommit(fields[3]),
+				Author:  fields[4],
+				Date:    time.Unix(seconds, 0),
+				Subject: fields[6],
+			}}
+		}
+
+		ahead, behind := parseTrack(fields[2])
+		if behind == 0 {
+			continue
+		}
+		refs.BehindBranches = append(refs.BehindBranches, BranchTracking{
+			Branch:  branch,
+			Message: behindMessage(ahead, behind),
+		})
+	}
+
+	return refs
+}
+
+// tip describes the last commit of branch when it is commit, or returns nil
+func (b *branchRefs) tip(branch, commit string) *CommitInfo {
+	if tip, ok := b.Tips[branch]; ok && tip.Commit == commit {
+		return tip.Info
+	}
+	return nil
+}
+
+// behindMessage describes a branch behind its upstream, e.g. "behind by 2, ahead by 1 commit(s)"
+func behindMessage(ahead, behind int) string {
+	if ahead != 0 {
+		return fmt.Sprintf("behind by %d, ahead by %d commit(s)", behind, ahead)
+	}
+	return fmt.Sprintf("behind by %d commit(s)", behind)
+}
+
+// parseTrack reads the commits ahead of and behind the upstream from a
+// %(upstream:track,nobracket) field, e.g. "ahead 1, behind 2"
+func parseTrack(track string) (ahead, behind int) {
+	for _, part := range strings.Split(track, ",") {
+		field, count, _ := strings.Cut(strings.TrimSpace(part), " ")
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			continue // "gone", or no upstream
+		}
+		switch field {
+		case "ahead":
+			ahead = n
+		case "behind":
+			behind = n
+		}
+	}
+	return ahead, behind
+}
+
+// ConflictSymbol flags statuses with unmerged paths
+const ConflictSymbol = "⚡"
+
+// GoneSymbol flags a clean branch whose upstream was deleted on the remote
+const GoneSymbol = "⊗"
+
+// EmptySymbol flags a repository without any commit yet
+const EmptySymbol = "∅ No commits"
+
+// NoRemoteSymbol flags a repository without any remote
+const NoRemoteSymbol = "⌂ No remote"
+
+// withNoRemote marks a status without upstream as having no remote at all: there
+// is nothing for its branch to track, keeping its branch and changes
+func (s *Status) withNoRemote() {
+	s.Type = StatusNoRemote
+	s.Message = "No remote configured"
+	s.Symbol = NoRemoteSymbol
+}
+
+// shortCommitLength is the length of the abbreviated commits shown in reports
+const shortCommitLength = 7
+
+// shortCommit abbreviates a commit as in reports
+func shortCommit(commit string) string {
+	return commit[:min(len(commit), shortCommitLength)]
+}
+
+// classify maps a porcelain status to the Status shown in reports, in order of
+// precedence: conflicts, no commit yet, missing upstream, staged changes, worktree
+// changes, an upstream deleted on the remote, then remote sync
+func (s *porcelainStatus) classify() *Status {
+	var detachedAt string
+	if s.Detached {
+		detachedAt = shortCommit(s.Commit)
+	}
+	status := func(statusType StatusType, message, symbol string) *Status {
+		return &Status{
+			Type: statusType, Message: message, Symbol: symbol, Branch: s.Branch, DetachedAt: detachedAt,
+			Ahead: s.Ahead, Behind: s.Behind, Changes: s.Changes, Conflicts: s.Conflicts,
+			StagedCount: s.StagedCount, ModifiedCount: s.ModifiedCount, DeletedCount: s.DeletedCount, UntrackedCount: s.UntrackedCount,
+			DirtySubmodules: s.Submodules,
+		}
+	}
+	gone := func(status *Status) *Status {
+		if s.Gone {
+			status.GoneUpstream = s.Upstream
+		}
+		return status
+	}
+
+	switch {
+	// Unmerged paths block everything else in the repository
+	case s.Conflicts > 0:
+		return status(StatusUnsync, fmt.Sprintf("Unmerged paths (%d conflict(s))", s.Conflicts), ConflictSymbol)
+	// Before the first commit, the branch cannot track anything: its files are only counted
+	case s.Commit == "(initial)" && !s.Detached:
+		return status(StatusEmpty, "Empty repository (no commits)", EmptySymbol)
+	case !s.Detached && s.Upstream == "":
+		return status(StatusNoUpstream, "No upstream configured", "⚠ No upstream")
+	// An upstream whose branch is gone is still configured: local changes come first
+	case s.StagedRen:
+		return gone(status(StatusUnsync, "Staged renames", "✱ R"))
+	case s.StagedAdd:
+		return gone(status(StatusUnsync, "Staged files", "✱ +"))
+	case s.Staged:
+		return gone(status(StatusUnsync, "Staged changes", "✱"))
+	case s.Modified:
+		return gone(status(StatusUnsync, "Modified files", "* M"))
+	case s.Deleted:
+		return gone(status(StatusUnsync, "Deleted files", "* D"))
+	case len(s.Submodules) > 0:
+		return gone(status(StatusUnsync, "Dirty submodules", "* S"))
+	case s.Untracked:
+		return gone(status(StatusUnsync, "Untracked files", "✱ ✚"))
+	case s.Gone:
+		return gone(status(StatusUnsync, fmt.Sprintf("Upstream %s is gone (deleted on the remote)", s.Upstream), GoneSymbol))
+	case s.Ahead > 0 && s.Behind > 0:
+		return status(StatusUnsync, "Diverged from remote", "⬆⬆")
+	case s.Ahead > 0:
+		return status(StatusUnsync, "Ahead of remote", "⬆")
+	case s.Behind > 0:
+		return status(StatusUnsync, "Behind remote", "↓")
+	default:
+		return status(StatusSync, "Clean", "✔")
+	}
+}
@@ -0,0 +1,300 @@
+package git
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Object names of the fixtures, as git status prints them
+const (
+	oid1 = "3f1c0a4f4b3ad1b1f0a7f6e1e2c3d4b5a6978812"
+	oid2 = "9a8b7c6d5e4f30211223344556677889900aabbc"
+)
+
+// porcelain joins fixture lines into git status output
+func porcelain(lines ...string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestParsePorcelainStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   porcelainStatus
+	}{
+		{
+			name: "clean and up to date",
+			output: porcelain(
+				"# branch.oid "+oid1,
+				"# branch.head main",
+				"# branch.upstream origin/main",
+				"# branch.ab +0 -0",
+			),
+			want: porcelainStatus{Branch: "main", Commit: oid1, Upstream: "origin/main"},
+		},
+		{
+			name: "ahead and behind",
+			output: porcelain(
+				"# branch.oid "+oid1,
+				"# branch.head topic",
+				"# branch.upstream origin/topic",
+				"# branch.ab +2 -3",
+			),
+			want: porcelainStatus{Branch: "topic", Commit: oid1, Upstream: "origin/topic", Ahead: 2, Behind: 3},
+		},
+		{
+			name: "stash header, ignored",
+			output: porcelain(
+				"# branch.oid "+oid1,
+				"# branch.head main",
+				"# branch.upstream origin/main",
+				"# branch.ab +0 -0",
+				"# stash 3",
+			),
+			want: porcelainStatus{Branch: "main", Commit: oid1, Upstream: "origin/main"},
+		},
+		{
+			name: "gone upstream",
+			output: porcelain(
+				"# branch.oid "+oid1,
+				"# branch.head topic",
+				"# branch.upstream origin/topic",
+			),
+			want: porcelainStatus{Branch: "topic", Commit: oid1, Upstream: "origin/topic", Gone: true},
+		},
+		{
+			name: "detached",
+			output: porcelain(
+				"# branch.oid "+oid1,
+				"# branch.head (detached)",
+			),
+			want: porcelainStatus{Branch: "HEAD", Detached: true, Commit: oid1},
+		},
+		{
+			name: "before the first commit",
+			output: porcelain(
+				"# branch.oid (initial)",
+				"# branch.head main",
+				"# branch.upstream origin/main",
+				"? README.md",
+			),
+			want: porcelainStatus{
+				Branch: "main", Commit: "(initial)", Upstream: "origin/main",
+				Untracked: true, Changes: 1, UntrackedCount: 1, Paths: []string{"README.md"},
+			},
+		},
+		{
+			name: "ordinary changes",
+			output: porcelain(
+				"# branch.oid "+oid1,
+				"# branch.head main",
+				"1 .M N... 100644 100644 100644 "+oid1+" "+oid1+" README.md",
+				"1 M. N... 100644 100644 100644 "+oid1+" "+oid2+" src/main.go",
+				"1 MM N... 100644 100644 100644 "+oid1+" "+oid2+" docs/a file.md",
+				"1 A. N... 000000 100644 100644 0000000000000000000000000000000000000000 "+oid2+" new.go",
+				"1 .D N... 100644 100644 000000 "+oid1+" "+oid1+" gone.txt",
+				"1 D. N... 100644 000000 000000 "+oid1+" 0000000000000000000000000000000000000000 removed.txt",
+				"1 .T N... 100644 100644 120000 "+oid1+" "+oid1+" link",
+			),
+			want: porcelainStatus{
+				Branch: "main", Commit: oid1,
+				StagedAdd: true, Staged: true, Modified: true, Deleted: true,
+				Changes:       7,
+				Paths:         []string{"README.md", "src/main.go", "docs/a file.md", "new.go", "link"},
+				StagedCount:   4,
+				ModifiedCount: 3,
+				DeletedCount:  1,
+			},
+		},
+		{
+			name: "rename",
+			output: porcelain(
+				"# branch.oid "+oid1,
+				"# branch.head main",
+				"2 R. N... 100644 100644 100644 "+oid1+" "+oid1+" R100 new name.txt\told name.txt",
+				"2 RM N... 100644 100644 100644 "+oid1+" "+oid1+" R087 lib/b.go\tlib/a.go",
+			),
+			want: porcelainStatus{
+				Branch: "main", Commit: oid1,
+				StagedRen: true, Modified: true,
+				Changes:       2,
+				Paths:         []string{"new name.txt", "lib/b.go"},
+				StagedCount:   2,
+				ModifiedCount: 1,
+			},
+		},
+		{
+			name: "unmerged",
+			output: porcelain(
+				"# branch.oid "+oid1,
+				"# branch.head main",
+				"u UU N... 100644 100644 100644 100644 "+oid1+" "+oid2+" "+oid1+" conflict.txt",
+				"u AA N... 000000 100644 100644 100644 0000000000000000000000000000000000000000 "+oid1+" "+oid2+" both added.txt",
+			),
+			want: porcelainStatus{
+				Branch: "main", Commit: oid1,
+				Modified:      true,
+				Changes:       2,
+				Conflicts:     2,
+				Paths:         []string{"conflict.txt", "both added.txt"},
+				ModifiedCount: 2,
+			},
+		},
+		{
+			name: "untracked and quoted",
+			output: porcelain(
+				"# branch.oid "+oid1,
+				"# branch.head main",
+				"? notes.txt",
+				"? dir/with space.txt",
+				`? "tab\there.txt"`,
+				"! ignored.log",
+			),
+			want: porcelainStatus{
+				Branch: "main", Commit: oid1,
+				Untracked:      true,
+				Changes:        3,
+				Paths:          []string{"notes.txt", "dir/with space.txt", "tab\there.txt"},
+				UntrackedCount: 3,
+			},
+		},
+		{
+			name: "submodules",
+			output: porcelain(
+				"# branch.oid "+oid1,
+				"# branch.head main",
+				"1 .M SC.. 160000 160000 160000 "+oid1+" "+oid1+" vendor/new-commit",
+				"1 .M S.M. 160000 160000 160000 "+oid1+" "+oid1+" vendor/modified",
+				"1 .M S..U 160000 160000 160000 "+oid1+" "+oid1+" vendor/untracked",
+				"1 M. S... 160000 160000 160000 "+oid1+" "+oid2+" vendor/staged",
+			),
+			want: porcelainStatus{
+				Branch: "main", Commit: oid1,
+				Staged:      true,
+				Changes:     4,
+				Paths:       []string{"vendor/new-commit", "vendor/modified", "vendor/untracked", "vendor/staged"},
+				StagedCount: 1,
+				Submodules:  []string{"vendor/new-commit", "vendor/modified", "vendor/untracked"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePorcelainStatus(tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePorcelainStatusErrors(t *testing.T) {
+	for _, output := range []string{
+		porcelain("# branch.ab ahead"),
+		porcelain("1 M"),
+		porcelain("X something new"),
+	} {
+		if _, err := parsePorcelainStatus(output); err == nil {
+			t.Errorf("no error for %q", output)
+		}
+	}
+}
+
+func TestPorcelainPath(t *testing.T) {
+	tests := map[string]string{
+		"1 .M N... 100644 100644 100644 " + oid1 + " " + oid1 + " a b c.txt":             "a b c.txt",
+		"2 R. N... 100644 100644 100644 " + oid1 + " " + oid1 + " R100 to.txt\tfrom.txt": "to.txt",
+		"u UU N... 100644 100644 100644 100644 " + oid1 + " " + oid1 + " " + oid1 + " x": "x",
+		"? \"caf\\303\\251.txt\"": "café.txt",
+	}
+	for line, want := range tests {
+		if got := porcelainPath(line); got != want {
+			t.Errorf("porcelainPath(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+// ref joins the fields of a for-each-ref line in branchRefsFormat
+func ref(fields ...string) string {
+	return strings.Join(fields, "\x00")
+}
+
+func TestParseBranchRefs(t *testing.T) {
+	output := porcelain(
+		ref("refs/heads/main", "", "", oid1, "Ada", "1700000000", "Release 1.0"),
+		ref("refs/heads/behind", "", "behind 2", oid2, "Bob", "1700000100", "Fix: keep spaces, and\x00commas"),
+		ref("refs/heads/diverged", "", "ahead 1, behind 3", oid1, "Ada", "1700000200", "Diverged"),
+		ref("refs/heads/ahead", "", "ahead 4", oid1, "Ada", "1700000300", "Ahead"),
+		ref("refs/heads/gone", "", "gone", oid2, "Bob", "1700000400", "Gone upstream"),
+		ref("refs/heads/feature/deep", "", "behind 1", oid2, "Bob", "1700000500", "Nested"),
+		ref("refs/remotes/origin/HEAD", "refs/remotes/origin/main", "", oid1, "Ada", "1700000000", "Release 1.0"),
+		ref("refs/remotes/up-stream/HEAD", "refs/remotes/up-stream/develop", "", oid2, "Bob", "1700000000", "Develop"),
+		ref("refs/stash", "", "", oid2, "Ada", "1700000600", "WIP on main"),
+	)
+
+	refs := parseBranchRefs(output)
+
+	wantBehind := []BranchTracking{
+		{Branch: "behind", Message: "behind by 2 commit(s)"},
+		{Branch: "diverged", Message: "behind by 3, ahead by 1 commit(s)"},
+		{Branch: "feature/deep", Message: "behind by 1 commit(s)"},
+	}
+	if !reflect.DeepEqual(refs.BehindBranches, wantBehind) {
+		t.Errorf("behind branches %+v, want %+v", refs.BehindBranches, wantBehind)
+	}
+	if !refs.HasStash {
+		t.Error("stash not seen")
+	}
+	wantHeads := map[string]string{"origin": "main", "up-stream": "develop"}
+	if !reflect.DeepEqual(refs.RemoteHeads, wantHeads) {
+		t.Errorf("remote heads %v, want %v", refs.RemoteHeads, wantHeads)
+	}
+	if len(refs.Tips) != 6 {
+		t.Errorf("%d tips, want one per local branch", len(refs.Tips))
+	}
+	tip := refs.tip("behind", oid2)
+	if tip == nil || tip.Hash != oid2[:7] || tip.Author != "Bob" || tip.Date.Unix() != 1700000100 || tip.Subject != "Fix: keep spaces, and\x00commas" {
+		t.Errorf("tip of behind %+v", tip)
+	}
+	// A detached HEAD at a commit other than the tip of the branch
+	if tip := refs.tip("main", oid2); tip != nil {
+		t.Errorf("tip of main at another commit %+v, want nil", tip)
+	}
+}
+
+func TestParseBranchRefsDetachedAndEmpty(t *testing.T) {
+	// No local branch yet, or only a detached HEAD: for-each-ref lists nothing
+	for _, output := range []string{"", "\n"} {
+		refs := parseBranchRefs(output)
+		if len(refs.BehindBranches) != 0 || len(refs.Tips) != 0 || refs.HasStash || len(refs.RemoteHeads) != 0 {
+			t.Errorf("got %+v from %q", refs, output)
+		}
+	}
+	// Lines cut short are skipped
+	refs := parseBranchRefs(porcelain(ref("refs/heads/main", "", "behind 1")))
+	if len(refs.BehindBranches) != 0 || len(refs.Tips) != 0 {
+		t.Errorf("got %+v from a truncated line", refs)
+	}
+}
+
+func TestParseTrack(t *testing.T) {
+	tests := []struct {
+		track         string
+		ahead, behind int
+	}{
+		{"", 0, 0},
+		{"gone", 0, 0},
+		{"ahead 2", 2, 0},
+		{"behind 5", 0, 5},
+		{"ahead 1, behind 12", 1, 12},
+	}
+	for _, tt := range tests {
+		if ahead, behind := parseTrack(tt.track); ahead != tt.ahead || behind != tt.behind {
+			t.Errorf("parseTrack(%q) = %d, %d, want %d, %d", tt.track, ahead, behind, tt.ahead, tt.behind)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AheadBehind returns how many commits the current branch is ahead of and behind its upstream
+func (r *Repository) AheadBehind(ctx context.Context) (ahead, behind int, err error) {
+	cmd := r.command(ctx, "rev-list", "--left-right", "--count", "HEAD...@{u}")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git rev-list"); ctxErr != nil {
+			return 0, 0, ctxErr
+		}
+		return 0, 0, fmt.Errorf("failed to compare with upstream: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	fields := strings.Fields(stdout.String())
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", stdout.String())
+	}
+	if ahead, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", stdout.String())
+	}
+	if behind, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", stdout.String())
+	}
+	return ahead, behind, nil
+}
+
+// IsDirty reports whether the working tree has staged, modified or untracked files
+func (r *Repository) IsDirty(ctx context.Context) (bool, error) {
+	if r.Bare {
+		return false, nil // No working tree to change
+	}
+	cmd := r.command(ctx, "status", "--porcelain")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git status"); ctxErr != nil {
+			return false, ctxErr
+		}
+		return false, fmt.Errorf("failed to get status: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return len(bytes.TrimSpace(stdout.Bytes())) > 0, nil
+}
+
+// FastForward moves the current branch to its upstream, refusing anything but a fast-forward.
+// It does not fetch: remote-tracking refs are used as they are.
+func (r *Repository) FastForward(ctx context.Context) error {
+	cmd := r.command(ctx, "merge", "--ff-only", "@{u}")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git merge"); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("fast-forward failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// PullOptions controls how Pull integrates the upstream's commits
+type PullOptions struct {
+	Rebase bool // Replay local commits onto the upstream rather than refusing to pull when diverged
+}
+
+// PullResult tells what a pull brought in
+type PullResult struct {
+	From         string // Commit of HEAD before the pull (abbreviated)
+	To           string // Commit of HEAD after it, From when already up to date
+	Commits      int    // Upstream commits brought in
+	FilesChanged int
+}
+
+// UpToDate reports whether the pull changed nothing
+func (p PullResult) UpToDate() bool {
+	return p.From == p.To
+}
+
+// String summarizes the result, e.g. "1e7698e..902f3c0: 3 commit(s), 5 file(s) changed"
+func (p PullResult) String() string {
+	if p.UpToDate() {
+		return "already up to date"
+	}
+	return fmt.Sprintf("%s..%s: %d commit(s), %d file(s) changed", p.From, p.To, p.Commits, p.FilesChanged)
+}
+
+// Reasons a pull is refused (PullError.Reason)
+const (
+	PullNoUpstream = "no upstream" // Detached, or the branch tracks nothing
+	PullDirty      = "dirty"       // Local changes, or an operation in progress
+	PullDiverged   = "diverged"    // Local commits the upstream lacks, without Rebase
+	PullConflict   = "conflict"    // The rebase stopped on a conflict, and was aborted
+	PullFailed     = "failed"      // git pull failed for another reason (e.g. the fetch)
+)
+
+// PullError is a pull that was refused or failed, leaving the working copy as it was
+type PullError struct {
+	Reason  string // PullNoUpstream, PullDirty, PullDiverged, PullConflict or PullFailed
+	Message string
+}
+
+func (e *PullError) Error() string {
+	return fmt.Sprintf("pull refused (%s): %s", e.Reason, e.Message)
+}
+
+// Pull runs git pull on the current branch, only as a fast-forward unless opts.Rebase
+// is set: it never creates a merge commit. Dirty working trees are never touched, and
+// a rebase stopping on a conflict is aborted. Refusals are returned as a *PullError.
+func (r *Repository) Pull(ctx context.Context, opts PullOptions) (PullResult, error) {
+	if r.Bare {
+		return PullResult{}, &PullError{Reason: PullFailed, Message: "bare repository, without working tree"}
+	}
+	if operation, _ := r.operationInProgress(); operation != "" {
+		return PullResult{}, &PullError{Reason: PullDirty, Message: fmt.Sprintf("%s in progress", operation)}
+	}
+
+	ahead, behind, err := r.AheadBehind(ctx)
+	if err != nil {
+		var timeoutErr *TimeoutError
+		if errors.As(err, &timeoutErr) || ctx.Err() != nil {
+			return PullResult{}, err
+		}
+		return PullResult{}, &PullError{Reason: PullNoUpstream, Message: "the current branch has no upstream"}
+	}
+	dirty, err := r.IsDirty(ctx)
+	if err != nil {
+		return PullResult{}, err
+	}
+	if dirty {
+		return PullResult{}, &PullError{Reason: PullDirty, Message: "the working tree has local changes"}
+	}
+	// Only ahead is up to date, unless the fetch of the pull brings commits: git refuses then
+	if ahead > 0 && behind > 0 && !opts.Rebase {
+		return PullResult{}, &PullError{
+			Reason:  PullDiverged,
+			Message: fmt.Sprintf("diverged from upstream (%d local, %d upstream commit(s)), not a fast-forward", ahead, behind),
+		}
+	}
+
+	from, err := r.headCommit(ctx)
+	if err != nil {
+		return PullResult{}, err
+	}
+
+	args := []string{"pull", "--no-rebase", "--ff-only"}
+	if opts.Rebase {
+		args = []string{"pull", "--rebase", "--no-autostash"}
+	}
+	cmd := r.command(ctx, args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git pull"); ctxErr != nil {
+			return PullResult{}, ctxErr
+		}
+		message := strings.TrimSpace(stderr.String())
+		if operation, _ := r.operationInProgress(); operation == OperationRebase {
+			_ = r.command(ctx, "rebase", "--abort").Run()
+			if strings.Contains(message, "CONFLICT") || strings.Contains(message, "could not apply") {
+				return PullResult{}, &PullError{Reason: PullConflict, Message: "the rebase stopped on a conflict and was aborted"}
+			}
+		}
+		if strings.Contains(message, "Not possible to fast-forward") {
+			return PullResult{}, &PullError{Reason: PullDiverged, Message: "the upstream has diverged, not a fast-forward"}
+		}
+		return PullResult{}, &PullError{Reason: PullFailed, Message: message}
+	}
+
+	to, err := r.headCommit(ctx)
+	if err != nil {
+		return PullResult{}, err
+	}
+	result := PullResult{From: shortCommit(from), To: shortCommit(to)}
+	if from == to {
+		return result, nil
+	}
+	if result.Commits, err = r.countCommits(ctx, from+"..@{u}"); err != nil {
+		return result, err
+	}
+	if result.FilesChanged, err = r.countChangedFiles(ctx, from, to); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// headCommit returns the full commit of HEAD
+func (r *Repository) headCommit(ctx context.Context) (string, error) {
+	cmd := r.command(ctx, "rev-parse", "HEAD")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git rev-parse"); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", fmt.Errorf("failed to read HEAD: %s", strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// countCommits counts the commits of a revision range, e.g. "a..b"
+func (r *Repository) countCommits(ctx context.Context, revisions string) (int, error) {
+	cmd := r.command(ctx, "rev-list", "--count", revisions)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git rev-list"); ctxErr != nil {
+			return 0, ctxErr
+		}
+		return 0, fmt.Errorf("failed to count commits: %s", strings.TrimSpace(stderr.String()))
+	}
+	return strconv.Atoi(strings.TrimSpace(stdout.String()))
+}
+
+// countChangedFiles counts the files that differ between two commits
+func (r *Repository) countChangedFiles(ctx context.Context, from, to string) (int, error) {
+	cmd := r.command(ctx, "diff", "--name-only", "-z", from, to)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git diff"); ctxErr != nil {
+			return 0, ctxErr
+		}
+		return 0, fmt.Errorf("failed to list changed files: %s", strings.TrimSpace(stderr.String()))
+	}
+	return bytes.Count(stdout.Bytes(), []byte{0}), nil
+}
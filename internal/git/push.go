@@ -0,0 +1,136 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PushTarget is where Push sends the current branch
+type PushTarget struct {
+	Remote      string // e.g. "origin"
+	Branch      string // Local branch pushed
+	RemoteRef   string // Branch it updates on the remote
+	HasUpstream bool   // False when Push must set it up (git push -u <remote> <branch>)
+}
+
+// Reasons a push fails (PushError.Reason)
+const (
+	PushNoUpstream = "no upstream" // Detached, or no upstream and setUpstream unset, or no remote to set it to
+	PushRejected   = "rejected"    // The remote has commits the branch lacks (non-fast-forward)
+	PushAuth       = "auth"        // The host refused the credentials
+	PushFailed     = "failed"      // Any other failure
+)
+
+// PushError is a failed push, with what git printed
+type PushError struct {
+	Reason  string // PushNoUpstream, PushRejected, PushAuth or PushFailed
+	Message string
+	Output  string // git's output, for display
+}
+
+func (e *PushError) Error() string {
+	return fmt.Sprintf("push failed (%s): %s", e.Reason, e.Message)
+}
+
+// PushTarget returns where Push would send the current branch: its upstream,
+// or else the only remote, or origin, under the same name
+func (r *Repository) PushTarget(ctx context.Context) (PushTarget, error) {
+	branch, err := r.GetCurrentBranch(ctx)
+	if err != nil {
+		return PushTarget{}, err
+	}
+	if branch == "HEAD" {
+		return PushTarget{}, &PushError{Reason: PushNoUpstream, Message: "HEAD is detached"}
+	}
+
+	cmd := r.command(ctx, "for-each-ref", "--format=%(upstream:remotename)%00%(upstream:remoteref)", "refs/heads/"+branch)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git for-each-ref"); ctxErr != nil {
+			return PushTarget{}, ctxErr
+		}
+		return PushTarget{}, fmt.Errorf("failed to get the upstream: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	remote, remoteRef, _ := strings.Cut(strings.TrimSpace(stdout.String()), "\x00")
+	if remote != "" && remoteRef != "" {
+		return PushTarget{Remote: remote, Branch: branch, RemoteRef: strings.TrimPrefix(remoteRef, "refs/heads/"), HasUpstream: true}, nil
+	}
+	remote, err = r.upstreamRemote(ctx)
+	if err != nil {
+		return PushTarget{}, err
+	}
+	if remote == "" {
+		remote = "origin"
+	}
+	return PushTarget{Remote: remote, Branch: branch, RemoteRef: branch}, nil
+}
+
+// Push pushes the current branch to its upstream. Without upstream, it pushes the
+// branch to the remote of PushTarget and sets it up as upstream (git push -u
+// <remote> <branch>) when setUpstream is set, and fails otherwise. The output of git is returned for display;
+// failures are returned as a *PushError telling rejected pushes from refused credentials.
+func (r *Repository) Push(ctx context.Context, setUpstream bool) (string, error) {
+	target, err := r.PushTarget(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"push"}
+	if !target.HasUpstream {
+		if !setUpstream {
+			return "", &PushError{Reason: PushNoUpstream, Message: fmt.Sprintf("%s has no upstream", target.Branch)}
+		}
+		remotes, err := r.Remotes(ctx)
+		if err != nil {
+			return "", err
+		}
+		if !slices.Contains(remotes, target.Remote) {
+			return "", &PushError{Reason: PushNoUpstream, Message: fmt.Sprintf("%s has no upstream, and there is no %s remote", target.Branch, target.Remote)}
+		}
+		args = append(args, "-u", target.Remote, target.Branch)
+	}
+	cmd := r.command(ctx, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	output := strings.TrimSpace(stdout.String() + "\n" + stderr.String())
+	if err == nil {
+		return output, nil
+	}
+	if ctxErr := contextError(ctx, "git push"); ctxErr != nil {
+		return output, ctxErr
+	}
+	return output, pushError(stderr.String(), output)
+}
+
+// pushError classifies a failed push from its error output, where refused refs
+// read like " ! [rejected]  main -> main (fetch first)"
+func pushError(stderr, output string) *PushError {
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "! [") {
+			continue
+		}
+		message := "the remote has commits the branch lacks: pull first"
+		if !strings.Contains(line, "(non-fast-forward)") && !strings.Contains(line, "(fetch first)") {
+			message = strings.Join(strings.Fields(strings.TrimPrefix(line, "! ")), " ") // e.g. [remote rejected] main -> main (pre-receive hook declined)
+		}
+		return &PushError{Reason: PushRejected, Message: message, Output: output}
+	}
+	if ClassifyRemoteError(stderr) == RemoteAuth {
+		return &PushError{Reason: PushAuth, Message: "the remote refused the credentials", Output: output}
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(stderr), "\n")
+	return &PushError{Reason: PushFailed, Message: strings.TrimPrefix(line, "fatal: "), Output: output}
+}
@@ -0,0 +1,275 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// RemoteURL returns the fetch URL of a remote
+func (r *Repository) RemoteURL(ctx context.Context, remote string) (string, error) {
+	cmd := r.command(ctx, "remote", "get-url", remote)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git remote get-url"); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", fmt.Errorf("failed to read the url of remote %s: %s", remote, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// WebURL converts a remote URL to the https address of the repository page:
+// scp-like (git@host:owner/repo.git) and ssh:// URLs are mapped to https,
+// credentials, ports and the .git suffix are dropped
+func WebURL(remoteURL string) (string, error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+
+	var host, path string
+	if !strings.Contains(remoteURL, "://") {
+		var ok bool
+		if host, path, ok = splitScpLike(remoteURL); !ok {
+			return "", fmt.Errorf("cannot open %s in a browser", remoteURL)
+		}
+	} else {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid remote url %s: %w", remoteURL, err)
+		}
+		switch u.Scheme {
+		case "ssh", "git", "git+ssh", "http", "https":
+		default:
+			return "", fmt.Errorf("cannot open %s in a browser", remoteURL)
+		}
+		host, path = u.Hostname(), u.Path
+		// Keep the port of web servers, ssh ones differ from the web port
+		if port := u.Port(); port != "" && (u.Scheme == "http" || u.Scheme == "https") {
+			host += ":" + port
+		}
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return "", fmt.Errorf("cannot open %s in a browser", remoteURL)
+	}
+
+	scheme := "https"
+	if strings.HasPrefix(remoteURL, "http://") {
+		scheme = "http"
+	}
+	return scheme + "://" + host + "/" + path, nil
+}
+
+// splitScpLike splits an scp-like remote URL ([user@]host:path) into its host and path.
+// It reports false for local paths.
+func splitScpLike(remoteURL string) (host, path string, ok bool) {
+	hostPart, pathPart, ok := strings.Cut(remoteURL, ":")
+	// (a single letter before the colon is a Windows drive)
+	if !ok || len(hostPart) < 2 || strings.HasPrefix(remoteURL, "/") || strings.Contains(hostPart, "/") {
+		return "", "", false
+	}
+	if _, h, ok := strings.Cut(hostPart, "@"); ok {
+		hostPart = h
+	}
+	return hostPart, pathPart, true
+}
+
+// RemoteHost returns the host name of a remote URL, scp-like or with a scheme,
+// and "" for local paths and file:// URLs
+func RemoteHost(remoteURL string) string {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if !strings.Contains(remoteURL, "://") {
+		host, _, _ := splitScpLike(remoteURL)
+		return host
+	}
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// AuthHint suggests how to provide the credentials of a remote URL: an SSH key
+// for scp-like and ssh:// URLs, a credential helper for http(s) ones
+func AuthHint(remoteURL string) string {
+	if strings.HasPrefix(remoteURL, "http://") || strings.HasPrefix(remoteURL, "https://") {
+		return "are your credentials stored in a credential helper?"
+	}
+	return "is your key loaded (ssh-add -l)?"
+}
+
+// Classes of RemoteError, from the most to the least likely to be permanent
+const (
+	RemoteGone    = "gone"    // The repository does not exist (anymore) on the host
+	RemoteAuth    = "auth"    // The host refused the credentials, or asked for some
+	RemoteDNS     = "dns"     // The host name does not resolve
+	RemoteNetwork = "network" // The host refused the connection or could not be reached
+	RemoteTimeout = "timeout" // The host did not answer in time
+	RemoteUnknown = "unknown"
+)
+
+// RemoteError describes a remote that could not be reached
+type RemoteError struct {
+	Remote  string // Name of the remote, e.g. "origin"
+	URL     string
+	Class   string // RemoteGone, RemoteAuth, RemoteDNS, RemoteNetwork, RemoteTimeout or RemoteUnknown
+	Message string // Line of git's error output telling the class
+}
+
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("remote %s (%s) is unreachable (%s): %s", e.Remote, e.URL, e.Class, e.Message)
+}
+
+// remoteErrorPatterns maps fragments of git, ssh and curl error messages to a class,
+// checked in order (lowercase)
+var remoteErrorPatterns = []struct {
+	fragment string
+	class    string
+}{
+	{"could not resolve host", RemoteDNS},
+	{"could not resolve hostname", RemoteDNS},
+	{"name or service not known", RemoteDNS},
+	{"nodename nor servname provided", RemoteDNS},
+	{"temporary failure in name resolution", RemoteDNS},
+	{"no such host is known", RemoteDNS},
+	{"repository not found", RemoteGone},
+	{"does not appear to be a git repository", RemoteGone},
+	{"does not exist", RemoteGone},
+	{"error: 404", RemoteGone},
+	{"not found", RemoteGone},
+	{"authentication failed", RemoteAuth},
+	{"permission denied (", RemoteAuth}, // ssh: Permission denied (publickey,password), not file permissions
+	{"denied to ", RemoteAuth},          // GitHub: Permission to owner/repo.git denied to user
+	{"invalid username or password", RemoteAuth},
+	{"invalid credentials", RemoteAuth},
+	{"could not read username", RemoteAuth},
+	{"could not read password", RemoteAuth},
+	{"terminal prompts disabled", RemoteAuth},
+	{"authentication required", RemoteAuth}, // go-git
+	{"authorization failed", RemoteAuth},    // go-git
+	{"unable to authenticate", RemoteAuth},  // go-git over ssh
+	{"access denied", RemoteAuth},
+	{"error: 401", RemoteAuth},
+	{"error: 403", RemoteAuth},
+	{"host key verification failed", RemoteAuth},
+	{"connection refused", RemoteNetwork},
+	{"connection timed out", RemoteNetwork},
+	{"network is unreachable", RemoteNetwork},
+	{"no route to host", RemoteNetwork},
+	{"failed to connect", RemoteNetwork},
+	{"could not connect", RemoteNetwork},
+	{"connection reset", RemoteNetwork},
+	{"connection closed", RemoteNetwork},
+}
+
+// ClassifyRemoteError returns the class of a failed git ls-remote from its error output
+func ClassifyRemoteError(stderr string) string {
+	class, _ := classifyRemoteError(stderr)
+	return class
+}
+
+// classifyRemoteError returns the class of a failed git ls-remote along with the
+// line telling it (the first line of stderr when the class is unknown)
+func classifyRemoteError(stderr string) (class, line string) {
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	for _, pattern := range remoteErrorPatterns {
+		for _, line := range lines {
+			if strings.Contains(strings.ToLower(line), pattern.fragment) {
+				return pattern.class, line
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return RemoteUnknown, ""
+	}
+	return RemoteUnknown, lines[0]
+}
+
+// CheckRemote asks remote for its HEAD with git ls-remote, without prompting for
+// credentials. It returns a *RemoteError when the remote cannot be reached, an
+// empty remote without HEAD being reachable.
+func (r *Repository) CheckRemote(ctx context.Context, remote string) error {
+	remoteURL, err := r.RemoteURL(ctx, remote)
+	if err != nil {
+		return err
+	}
+
+	cmd := r.command(ctx, "ls-remote", "--exit-code", remote, "HEAD")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &RemoteError{Remote: remote, URL: remoteURL, Class: RemoteTimeout, Message: "no answer in time"}
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case err == nil:
+		return nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 2:
+		// --exit-code: the remote answered without a HEAD
+		return nil
+	}
+
+	class, message := classifyRemoteError(stderr.String())
+	if message == "" {
+		message = err.Error()
+	}
+	return &RemoteError{Remote: remote, URL: remoteURL, Class: class, Message: message}
+}
+
+// FetchError is a failed fetch, with the error output of the command
+type FetchError struct {
+	Operation string // fetch, or pull for Mercurial
+	Stderr    string
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("%s failed: %s", e.Operation, e.Stderr)
+}
+
+// Class returns the class of the failure, as for a RemoteError
+func (e *FetchError) Class() string {
+	return ClassifyRemoteError(e.Stderr)
+}
+
+// Reason returns the line of the error output telling the class of the failure
+func (e *FetchError) Reason() string {
+	_, line := classifyRemoteError(e.Stderr)
+	return line
+}
+
+// IsTransient reports whether a failed fetch may succeed when tried again: the
+// host did not resolve, could not be reached or did not answer in time. Refused
+// credentials and missing repositories are permanent.
+func IsTransient(err error) bool {
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return true
+	}
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) {
+		return false
+	}
+	switch fetchErr.Class() {
+	case RemoteDNS, RemoteNetwork, RemoteTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package git
+
+import "testing"
+
+func TestClassifyRemoteError(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		class  string
+		line   string
+	}{
+		{
+			name:   "ssh publickey",
+			stderr: "git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.\n\nPlease make sure you have the correct access rights\nand the repository exists.\n",
+			class:  RemoteAuth,
+			line:   "git@github.com: Permission denied (publickey).",
+		},
+		{
+			name:   "https without credentials",
+			stderr: "fatal: could not read Username for 'https://github.com': terminal prompts disabled\n",
+			class:  RemoteAuth,
+			line:   "fatal: could not read Username for 'https://github.com': terminal prompts disabled",
+		},
+		{
+			name:   "http 401",
+			stderr: "fatal: unable to access 'https://git.example.com/team/app.git/': The requested URL returned error: 401\n",
+			class:  RemoteAuth,
+			line:   "fatal: unable to access 'https://git.example.com/team/app.git/': The requested URL returned error: 401",
+		},
+		{
+			name:   "http 403",
+			stderr: "fatal: unable to access 'https://github.com/owner/app.git/': The requested URL returned error: 403\n",
+			class:  RemoteAuth,
+			line:   "fatal: unable to access 'https://github.com/owner/app.git/': The requested URL returned error: 403",
+		},
+		{
+			name:   "refused credentials",
+			stderr: "remote: HTTP Basic: Access denied\nfatal: Authentication failed for 'https://gitlab.com/team/app.git/'\n",
+			class:  RemoteAuth,
+			line:   "fatal: Authentication failed for 'https://gitlab.com/team/app.git/'",
+		},
+		{
+			name:   "github denied to user",
+			stderr: "remote: Permission to owner/app.git denied to someone.\nfatal: unable to access 'https://github.com/owner/app.git/': The requested URL returned error: 403\n",
+			class:  RemoteAuth,
+			line:   "remote: Permission to owner/app.git denied to someone.",
+		},
+		{
+			name:   "unknown host key",
+			stderr: "Host key verification failed.\nfatal: Could not read from remote repository.\n",
+			class:  RemoteAuth,
+			line:   "Host key verification failed.",
+		},
+		{
+			name:   "ssh dns",
+			stderr: "ssh: Could not resolve hostname gitlab.internal: Name or service not known\nfatal: Could not read from remote repository.\n",
+			class:  RemoteDNS,
+			line:   "ssh: Could not resolve hostname gitlab.internal: Name or service not known",
+		},
+		{
+			name:   "https dns",
+			stderr: "fatal: unable to access 'https://gitlab.internal/app.git/': Could not resolve host: gitlab.internal\n",
+			class:  RemoteDNS,
+			line:   "fatal: unable to access 'https://gitlab.internal/app.git/': Could not resolve host: gitlab.internal",
+		},
+		{
+			name:   "ssh timeout",
+			stderr: "ssh: connect to host git.example.com port 22: Connection timed out\nfatal: Could not read from remote repository.\n",
+			class:  RemoteNetwork,
+			line:   "ssh: connect to host git.example.com port 22: Connection timed out",
+		},
+		{
+			name:   "https timeout",
+			stderr: "fatal: unable to access 'https://git.example.com/app.git/': Failed to connect to git.example.com port 443 after 130000 ms: Connection timed out\n",
+			class:  RemoteNetwork,
+			line:   "fatal: unable to access 'https://git.example.com/app.git/': Failed to connect to git.example.com port 443 after 130000 ms: Connection timed out",
+		},
+		{
+			name:   "connection refused",
+			stderr: "ssh: connect to host git.example.com port 22: Connection refused\n",
+			class:  RemoteNetwork,
+			line:   "ssh: connect to host git.example.com port 22: Connection refused",
+		},
+		{
+			name:   "repository not found",
+			stderr: "ERROR: Repository not found.\nfatal: Could not read from remote repository.\n",
+			class:  RemoteGone,
+			line:   "ERROR: Repository not found.",
+		},
+		{
+			name:   "missing local remote",
+			stderr: "fatal: '/srv/git/app.git' does not appear to be a git repository\nfatal: Could not read from remote repository.\n",
+			class:  RemoteGone,
+			line:   "fatal: '/srv/git/app.git' does not appear to be a git repository",
+		},
+		{
+			name:   "unknown",
+			stderr: "\nfatal: the remote end hung up unexpectedly\nfatal: early EOF\n",
+			class:  RemoteUnknown,
+			line:   "fatal: the remote end hung up unexpectedly",
+		},
+		{
+			name:   "empty",
+			stderr: "",
+			class:  RemoteUnknown,
+			line:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			class, line := classifyRemoteError(tt.stderr)
+			if class != tt.class || line != tt.line {
+				t.Errorf("got %s %q, want %s %q", class, line, tt.class, tt.line)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&FetchError{Operation: "fetch", Stderr: "ssh: Could not resolve hostname gitlab.internal: Name or service not known"}, true},
+		{&FetchError{Operation: "fetch", Stderr: "ssh: connect to host git.example.com port 22: Connection timed out"}, true},
+		{&TimeoutError{Operation: "git fetch"}, true},
+		{&FetchError{Operation: "fetch", Stderr: "git@github.com: Permission denied (publickey)."}, false},
+		{&FetchError{Operation: "fetch", Stderr: "ERROR: Repository not found."}, false},
+		{&FetchError{Operation: "fetch", Stderr: "fatal: early EOF"}, false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRemoteHost(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:owner/app.git":           "github.com",
+		"ssh://git@gitlab.internal:2222/app.git": "gitlab.internal",
+		"https://user@git.example.com/app.git":   "git.example.com",
+		"/srv/git/app.git":                       "",
+		"file:///srv/git/app.git":                "",
+		"C:/git/app.git":                         "",
+	}
+	for url, want := range tests {
+		if got := RemoteHost(url); got != want {
+			t.Errorf("RemoteHost(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -2,26 +2,79 @@ package git
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 )
 
 // Repository represents a git repository
 type Repository struct {
 	Path string
 	Name string
+	Bare bool // Without working tree: only its branches are checked
 }
 
-// IsGitRepository checks if a path is a git repository
+// IsGitRepository checks if a path is a git repository with a working tree
 func IsGitRepository(path string) bool {
+	return GitDir(path) != ""
+}
+
+// GitDir returns the git directory of the working copy at path: its .git directory,
+// or the one a .git file points to ("gitdir: <dir>", in linked worktrees and
+// submodules). It returns "" when there is none.
+func GitDir(path string) string {
 	gitPath := filepath.Join(path, ".git")
 	info, err := os.Stat(gitPath)
 	if err != nil {
-		return false
+		return ""
+	}
+	if info.IsDir() {
+		return gitPath
+	}
+
+	data, err := os.ReadFile(gitPath)
+	if err != nil {
+		return ""
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return ""
 	}
-	return info.IsDir()
+	return dir
+}
+
+// commonDir returns the git directory holding what linked worktrees share (config,
+// shallow, stash): the one commondir points to, or the repository's own.
+// path is the git directory itself for a bare repository. It returns "" when there is none.
+func commonDir(path string, bare bool) string {
+	gitDir := path
+	if !bare {
+		gitDir = GitDir(path)
+	}
+	if gitDir == "" {
+		return ""
+	}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		dir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(gitDir, dir)
+		}
+		return dir
+	}
+	return gitDir
 }
 
 // NewRepository creates a new Repository instance
@@ -29,52 +82,207 @@ func NewRepository(path, name string) *Repository {
 	return &Repository{
 		Path: path,
 		Name: name,
+		Bare: GitDir(path) == "" && IsBareRepository(path),
 	}
 }
 
-// GetCurrentBranch returns the name of the current branch
-func (r *Repository) GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = r.Path
+// Kind returns "git", the VCS of the repository
+func (r *Repository) Kind() string {
+	return "git"
+}
+
+// TimeoutError is returned when a git operation exceeds its context deadline
+type TimeoutError struct {
+	Operation string // e.g. "git status"
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out", e.Operation)
+}
+
+// waitDelay bounds how long a cancelled command may keep its output pipes open
+// (e.g. through hooks or helpers spawned by git)
+const waitDelay = 500 * time.Millisecond
+
+// CommandEnv returns the environment of every git command: the inherited one, with
+// untranslated messages (tracking info such as "behind 2" and errors are parsed),
+// no optional locks (checks never make the user's own git commands wait for the
+// index) and no credential prompts (fetches in the background fail instead of
+// waiting for an answer nobody sees)
+func CommandEnv() []string {
+	return append(os.Environ(), "LC_ALL=C", "GIT_OPTIONAL_LOCKS=0", "GIT_TERMINAL_PROMPT=0")
+}
+
+// Command builds a git command running in dir, bound to ctx, with CommandEnv
+func Command(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = CommandEnv()
+	cmd.WaitDelay = waitDelay
+	return cmd
+}
+
+// command builds a git command running in the repository, bound to ctx
+func (r *Repository) command(ctx context.Context, args ...string) *exec.Cmd {
+	return Command(ctx, r.Path, args...)
+}
+
+// contextError converts a context deadline into a TimeoutError for operation.
+// It returns nil when ctx is still alive.
+func contextError(ctx context.Context, operation string) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &TimeoutError{Operation: operation}
+	case ctx.Err() != nil:
+		return ctx.Err()
+	default:
+		return nil
+	}
+}
+
+// ErrNoCommits is returned for operations needing a commit in a repository that
+// has none yet
+var ErrNoCommits = errors.New("no commits yet")
+
+// GetCurrentBranch returns the name of the current branch, even before its first
+// commit, or "HEAD" when detached
+func (r *Repository) GetCurrentBranch(ctx context.Context) (string, error) {
+	cmd := r.command(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
 
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 
 	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git symbolic-ref"); ctxErr != nil {
+			return "", ctxErr
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "HEAD", nil // --quiet: HEAD does not point to a branch
+		}
 		return "", fmt.Errorf("failed to get current branch: %v", err)
 	}
 
 	return string(bytes.TrimSpace(stdout.Bytes())), nil
 }
 
-// SetUpstream configures upstream tracking locally without pushing
-func (r *Repository) SetUpstream() error {
-	// Get current branch name
-	branchCmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	branchCmd.Dir = r.Path
+// hasCommits reports whether HEAD points to a commit (false before the first one)
+func (r *Repository) hasCommits(ctx context.Context) bool {
+	return r.command(ctx, "rev-parse", "--verify", "--quiet", "HEAD").Run() == nil
+}
+
+// BranchNotOnRemoteError is returned by SetUpstream when the remote has no branch
+// to track: it must be pushed first (git push -u)
+type BranchNotOnRemoteError struct {
+	Remote string
+	Branch string
+}
 
-	var branchOut bytes.Buffer
-	branchCmd.Stdout = &branchOut
+func (e *BranchNotOnRemoteError) Error() string {
+	return fmt.Sprintf("%s is not on %s yet", e.Branch, e.Remote)
+}
 
-	if err := branchCmd.Run(); err != nil {
-		return fmt.Errorf("failed to get current branch: %v", err)
+// upstreamRemote returns the remote a branch without upstream tracks: the only
+// remote, or else origin ("" when there is neither)
+func (r *Repository) upstreamRemote(ctx context.Context) (string, error) {
+	remotes, err := r.Remotes(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(remotes) == 1 {
+		return remotes[0], nil
+	}
+	if slices.Contains(remotes, "origin") {
+		return "origin", nil
 	}
+	return "", nil
+}
 
-	branch := bytes.TrimSpace(branchOut.Bytes())
-	branchName := string(branch)
+// SetUpstream configures the current branch to track the branch of the same name
+// on the only remote, or else origin, without pushing. The remote is asked whether
+// it has the branch: when it does not, a *BranchNotOnRemoteError is returned and
+// nothing is configured, since tracking it would report it as gone. It returns
+// ErrNoCommits before the first commit: there is no branch to track anything yet.
+func (r *Repository) SetUpstream(ctx context.Context) error {
+	branchName, err := r.GetCurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	if branchName == "HEAD" {
+		return fmt.Errorf("HEAD is detached")
+	}
+	if !r.hasCommits(ctx) {
+		if ctxErr := contextError(ctx, "git rev-parse"); ctxErr != nil {
+			return ctxErr
+		}
+		return ErrNoCommits
+	}
+
+	remote, err := r.upstreamRemote(ctx)
+	if err != nil {
+		return err
+	}
+	if remote == "" {
+		return fmt.Errorf("no remote to track: add one, or name one origin")
+	}
+	onRemote, err := r.remoteHasBranch(ctx, remote, branchName)
+	if err != nil {
+		return err
+	}
+	if !onRemote {
+		return &BranchNotOnRemoteError{Remote: remote, Branch: branchName}
+	}
 
 	// Set remote tracking locally (without pushing)
-	remoteCmd := exec.Command("git", "config", fmt.Sprintf("branch.%s.remote", branchName), "origin")
-	remoteCmd.Dir = r.Path
+	remoteCmd := r.command(ctx, "config", fmt.Sprintf("branch.%s.remote", branchName), remote)
 	if err := remoteCmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git config"); ctxErr != nil {
+			return ctxErr
+		}
 		return fmt.Errorf("failed to set branch remote: %v", err)
 	}
 
-	mergeCmd := exec.Command("git", "config", fmt.Sprintf("branch.%s.merge", branchName), fmt.Sprintf("refs/heads/%s", branchName))
-	mergeCmd.Dir = r.Path
+	mergeCmd := r.command(ctx, "config", fmt.Sprintf("branch.%s.merge", branchName), fmt.Sprintf("refs/heads/%s", branchName))
 	if err := mergeCmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git config"); ctxErr != nil {
+			return ctxErr
+		}
 		return fmt.Errorf("failed to set branch merge: %v", err)
 	}
 
 	return nil
 }
+
+// remoteHasBranch asks remote over the network whether it has branch
+func (r *Repository) remoteHasBranch(ctx context.Context, remote, branch string) (bool, error) {
+	cmd := r.command(ctx, "ls-remote", "--heads", remote, "refs/heads/"+branch)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git ls-remote"); ctxErr != nil {
+			return false, ctxErr
+		}
+		return false, fmt.Errorf("failed to list the branches of %s: %s", remote, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()) != "", nil
+}
+
+// Remotes returns the names of the configured remotes
+func (r *Repository) Remotes(ctx context.Context) ([]string, error) {
+	cmd := r.command(ctx, "remote")
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git remote"); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to list remotes: %v", err)
+	}
+
+	return strings.Fields(stdout.String()), nil
+}
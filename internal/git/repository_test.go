@@ -0,0 +1,41 @@
+package git
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// forcedEnv is what every git command runs with, whatever the user's environment
+var forcedEnv = []string{"LC_ALL=C", "GIT_OPTIONAL_LOCKS=0", "GIT_TERMINAL_PROMPT=0"}
+
+func TestCommandEnv(t *testing.T) {
+	t.Setenv("CHECK_PROJECTS_INHERITED", "kept")
+	t.Setenv("GIT_SSH_COMMAND", "ssh -o BatchMode=yes")
+
+	env := CommandEnv()
+	for _, want := range append([]string{"CHECK_PROJECTS_INHERITED=kept", "GIT_SSH_COMMAND=ssh -o BatchMode=yes"}, forcedEnv...) {
+		if !slices.Contains(env, want) {
+			t.Errorf("%s missing from %q", want, env)
+		}
+	}
+}
+
+func TestCommandOverridesInheritedEnv(t *testing.T) {
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+	t.Setenv("GIT_OPTIONAL_LOCKS", "1")
+	t.Setenv("GIT_TERMINAL_PROMPT", "1")
+
+	cmd := Command(context.Background(), t.TempDir(), "status")
+	env := cmd.Environ() // As the process gets it: the last of duplicated variables wins
+	for _, want := range forcedEnv {
+		if !slices.Contains(env, want) {
+			t.Errorf("%s missing from %q", want, env)
+		}
+	}
+	for _, overridden := range []string{"LC_ALL=fr_FR.UTF-8", "GIT_OPTIONAL_LOCKS=1", "GIT_TERMINAL_PROMPT=1"} {
+		if slices.Contains(env, overridden) {
+			t.Errorf("%s inherited", overridden)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// isShallow reports whether the repository is a shallow clone (e.g. cloned with
+// --depth 1): git then lists the commits whose parents were cut off in the shallow
+// file of its git directory, shared by linked worktrees
+func (r *Repository) isShallow() bool {
+	gitDir := commonDir(r.Path, r.Bare)
+	if gitDir == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(gitDir, "shallow"))
+	return err == nil
+}
+
+// Unshallow fetches the history a shallow clone lacks, making ahead and behind
+// counts reliable (git fetch --unshallow)
+func (r *Repository) Unshallow(ctx context.Context) error {
+	cmd := r.command(ctx, "fetch", "--unshallow")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git fetch"); ctxErr != nil {
+			return ctxErr
+		}
+		return &FetchError{Operation: "fetch --unshallow", Stderr: stderr.String()}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// StashEntry is an entry of the stash
+type StashEntry struct {
+	Ref     string // e.g. "stash@{0}"
+	Message string // e.g. "WIP on main: 1a2b3c4 Fix typo"
+}
+
+// StashList returns the entries of the stash, most recent first
+func (r *Repository) StashList(ctx context.Context) ([]StashEntry, error) {
+	cmd := r.command(ctx, "stash", "list", "--format=%gd%x00%gs")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git stash list"); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to list stashes: %s", stderr.String())
+	}
+
+	return parseStashList(stdout.String()), nil
+}
+
+// parseStashList parses lines of "<ref>\x00<message>"
+func parseStashList(output string) []StashEntry {
+	var entries []StashEntry
+	for _, line := range strings.Split(output, "\n") {
+		ref, message, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		entries = append(entries, StashEntry{Ref: ref, Message: message})
+	}
+	return entries
+}
@@ -2,9 +2,12 @@ package git
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"os/exec"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
 // StatusType represents the type of git status
@@ -17,8 +20,37 @@ const (
 	StatusIgnored       StatusType = "ignored"
 	StatusNoUpstream    StatusType = "no_upstream"
 	StatusBrokenSymlink StatusType = "broken_symlink"
+	StatusTimeout       StatusType = "timeout"
+	StatusStale         StatusType = "stale" // Clean, but without commits for longer than stale_after
+
+	// StatusRemoteUnreachable is set by the opt-in remote check (check_remotes)
+	StatusRemoteUnreachable StatusType = "remote_unreachable"
+	// StatusAuthRequired is set when the remote refuses the credentials, on fetch or remote check
+	StatusAuthRequired StatusType = "auth_required"
+	// StatusPermission is set when the current user cannot read the repository
+	StatusPermission StatusType = "permission"
+	// StatusUnchecked is set when the run's time budget (--max-duration) ended before the check
+	StatusUnchecked StatusType = "unchecked"
+	// StatusBare is set for bare repositories without unpushed branches
+	StatusBare StatusType = "bare"
+	// StatusEmpty is set for repositories without any commit yet (unborn HEAD)
+	StatusEmpty StatusType = "empty"
+	// StatusLocked is set when a lock file, such as .git/index.lock, blocks git
+	StatusLocked StatusType = "locked"
+	// StatusNoRemote is set for repositories without any remote, whose branch cannot track one
+	StatusNoRemote StatusType = "no_remote"
+	// StatusUnversioned is set for directories under a root holding files but no repository
+	StatusUnversioned StatusType = "unversioned"
 )
 
+// StatusTypes lists every status type
+var StatusTypes = []StatusType{
+	StatusSync, StatusUnsync, StatusError, StatusIgnored, StatusNoUpstream,
+	StatusBrokenSymlink, StatusTimeout, StatusStale, StatusRemoteUnreachable, StatusAuthRequired,
+	StatusPermission, StatusUnchecked, StatusBare, StatusEmpty, StatusLocked, StatusNoRemote,
+	StatusUnversioned,
+}
+
 // BranchTracking represents the tracking status of a branch
 type BranchTracking struct {
 	Branch  string
@@ -27,273 +59,310 @@ type BranchTracking struct {
 
 // Status represents the git status of a repository
 type Status struct {
-	Type            StatusType
-	Message         string
-	Symbol          string
-	Branch          string           // Current branch name
-	BehindBranches  []BranchTracking // Branches that are behind their remote
+	Type              StatusType
+	Message           string
+	Symbol            string
+	Branch            string             // Current branch name ("HEAD" when detached)
+	DetachedAt        string             // Short commit of a detached HEAD ("" on a branch)
+	Ahead             int                // Commits of the current branch not on its upstream
+	Behind            int                // Commits of the upstream not on the current branch
+	BehindBranches    []BranchTracking   // Branches that are behind their remote
+	LocalOnlyBranches []BranchTracking   // Branches without upstream holding commits no remote has (checker.Options.LocalBranches)
+	RemoteComparisons []RemoteComparison // HEAD compared with the branches of other remotes (checker.Options.CompareRemotes)
+	Remote            *RemoteError       // Remote that could not be reached (StatusRemoteUnreachable, StatusAuthRequired)
+	Path              string             // Path that could not be read (StatusPermission)
+	LockFile          string             // Lock file blocking git (StatusLocked)
+	LockedAt          time.Time          // Modification time of LockFile (zero when unknown)
+	Changes           int                // Files with local changes, untracked ones included
+	Conflicts         int                // Unmerged files
+	StagedCount       int                // Files with changes in the index
+	ModifiedCount     int                // Files modified in the worktree, unmerged ones included
+	DeletedCount      int                // Files deleted from the worktree
+	UntrackedCount    int                // Untracked files
+	DiffStat          *DiffStat          // Lines changed in tracked files (checker.Options.DiffStats, nil otherwise)
+	OldestChange      time.Time          // Modification time of the oldest changed file (zero when clean)
+	StashCount        int                // Entries of the stash
+	Operation         Operation          // Merge, rebase or cherry-pick left in progress ("" when none)
+	DirtySubmodules   []string           // Submodules with a new commit or changes of their own
+	Bare              bool               // Bare repository, without working tree
+	LastCommit        *CommitInfo        // Commit of HEAD (nil without commits)
+	Shallow           bool               // Shallow clone: ahead and behind counts are unreliable
+	Origin            *RemoteInfo        // Remote pushed to, "origin" or else the first one (nil without remote)
+	DefaultBranch     string             // Default branch of Origin (its <remote>/HEAD), "" when unknown
+	OffDefaultBranch  bool               // The current branch is not DefaultBranch (checker.Options.OffDefaultBranch)
+	GoneUpstream      string             // Upstream of the current branch deleted on the remote, e.g. "origin/topic" ("" otherwise)
+	MergedBranches    []string           // Local branches merged into MergedInto, safe to delete (checker.Options.MergedBranches)
+	MergedInto        string             // Remote-tracking branch MergedBranches are merged into, e.g. "origin/main"
+	UnpushedTags      []string           // Local tags Origin does not have (checker.Options.UnpushedTags)
+	LastFetched       time.Time          // Last fetch, from FETCH_HEAD or the newest remote-tracking ref (zero when never fetched)
+	UserEmail         string             // user.email commits are made with, when checked (checker.Options.ExpectedEmail)
+	IdentityMismatch  bool               // UserEmail is unset or matches none of the expected patterns
 }
 
-// Fetch runs git fetch to update remote tracking branches
-func (r *Repository) Fetch() error {
-	cmd := exec.Command("git", "fetch")
-	cmd.Dir = r.Path
+// BranchesNeedAttention reports whether branches other than the current one need
+// attention: behind their remote, or local-only. Unpushed tags need it too.
+func (s *Status) BranchesNeedAttention() bool {
+	return len(s.BehindBranches) > 0 || len(s.LocalOnlyBranches) > 0 || len(s.UnpushedTags) > 0
+}
 
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+// NewRemoteUnreachableStatus builds the status of a repository whose remote could not
+// be reached. Everything else of its local status is kept (branch, changes, branches
+// to look after...), as it was checked all the same.
+func NewRemoteUnreachableStatus(err *RemoteError, local *Status) *Status {
+	status := *local
+	status.Type = StatusRemoteUnreachable
+	status.Message = fmt.Sprintf("Remote %s is unreachable (%s): %s", err.Remote, err.Class, err.URL)
+	status.Symbol = "⊘"
+	status.Remote = err
+	return &status
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("fetch failed: %s", stderr.String())
+// NewAuthRequiredStatus builds the status of a repository whose remote refused
+// the credentials, naming the host. Everything else of its local status is kept
+// (branch, changes, branches to look after...), as it was checked all the same.
+func NewAuthRequiredStatus(err *RemoteError, local *Status) *Status {
+	host := RemoteHost(err.URL)
+	if host == "" {
+		host = err.URL
 	}
-
-	return nil
+	status := *local
+	status.Type = StatusAuthRequired
+	status.Message = fmt.Sprintf("Authentication required by %s (remote %s): %s", host, err.Remote, AuthHint(err.URL))
+	status.Symbol = "🔒"
+	status.Remote = err
+	return &status
 }
 
-// GetBranchesTrackingStatus checks all local branches and returns those that are behind their remote
-func (r *Repository) GetBranchesTrackingStatus() ([]BranchTracking, error) {
-	// Get all local branches
-	cmd := exec.Command("git", "branch", "--format=%(refname:short)")
-	cmd.Dir = r.Path
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// NewRemoteStatus builds the status of a repository whose remote could not be
+// reached: auth required when it refused the credentials, unreachable otherwise
+func NewRemoteStatus(err *RemoteError, local *Status) *Status {
+	if err.Class == RemoteAuth {
+		return NewAuthRequiredStatus(err, local)
+	}
+	return NewRemoteUnreachableStatus(err, local)
+}
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to get branches: %s", stderr.String())
+// NewTimeoutStatus builds the status of a repository whose git operation exceeded limit
+func NewTimeoutStatus(err *TimeoutError, limit time.Duration) *Status {
+	return &Status{
+		Type:    StatusTimeout,
+		Message: fmt.Sprintf("%s exceeded the %s timeout", err.Operation, limit),
+		Symbol:  "⌛",
 	}
+}
 
-	branches := strings.Split(strings.TrimSpace(stdout.String()), "\n")
-	var behindBranches []BranchTracking
+// NewUncheckedStatus builds the status of a repository left unchecked when the
+// time budget of the run ran out
+func NewUncheckedStatus() *Status {
+	return &Status{
+		Type:    StatusUnchecked,
+		Message: "Not checked: time budget exceeded",
+		Symbol:  "…",
+	}
+}
 
-	for _, branch := range branches {
-		branch = strings.TrimSpace(branch)
-		if branch == "" {
-			continue
-		}
+// UnversionedSymbol marks the directories not under version control
+const UnversionedSymbol = "▢"
 
-		// Check if this branch has a remote tracking branch
-		trackingCmd := exec.Command("git", "rev-parse", "--abbrev-ref", branch+"@{u}")
-		trackingCmd.Dir = r.Path
+// NewUnversionedStatus builds the status of a directory under a root holding files
+// but no repository (report_untracked_dirs)
+func NewUnversionedStatus() *Status {
+	return &Status{
+		Type:    StatusUnversioned,
+		Message: "Not under version control",
+		Symbol:  UnversionedSymbol,
+	}
+}
 
-		var trackingStderr bytes.Buffer
-		trackingCmd.Stderr = &trackingStderr
+// FetchOptions controls what Fetch updates
+type FetchOptions struct {
+	Prune      bool   // Remove remote-tracking branches deleted on the remote
+	AllRemotes bool   // Fetch every remote rather than the default one
+	Remote     string // Fetch this remote rather than the default one (ignored with AllRemotes)
+}
 
-		if err := trackingCmd.Run(); err != nil {
-			// No upstream for this branch, skip it
-			continue
-		}
+// FetchResult counts the refs a fetch changed
+type FetchResult struct {
+	Updated int // Refs created or moved, tags included
+	Pruned  int // Remote-tracking branches removed by Prune
+}
 
-		// Check if branch is behind its remote
-		statusCmd := exec.Command("git", "status", "-b", "--porcelain")
-		statusCmd.Dir = r.Path
-		statusCmd.Env = append(statusCmd.Env, "GIT_OPTIONAL_LOCKS=0")
+// String summarizes the result, e.g. "3 ref(s) updated, 1 pruned"
+func (f FetchResult) String() string {
+	if f.Updated == 0 && f.Pruned == 0 {
+		return "already up to date"
+	}
+	summary := fmt.Sprintf("%d ref(s) updated", f.Updated)
+	if f.Pruned > 0 {
+		summary += fmt.Sprintf(", %d pruned", f.Pruned)
+	}
+	return summary
+}
 
-		// Temporarily checkout this branch to get its status
-		// Actually, we can use a different approach - check commits behind
-		behindCmd := exec.Command("git", "rev-list", "--count", branch+".."+branch+"@{u}")
-		behindCmd.Dir = r.Path
+// Fetch runs git fetch to update remote tracking branches
+func (r *Repository) Fetch(ctx context.Context, opts FetchOptions) (FetchResult, error) {
+	args := []string{"fetch"}
+	if opts.Prune {
+		args = append(args, "--prune")
+	}
+	switch {
+	case opts.AllRemotes:
+		args = append(args, "--all")
+	case opts.Remote != "":
+		args = append(args, "--", opts.Remote)
+	}
+	cmd := r.command(ctx, args...)
 
-		var behindOut bytes.Buffer
-		behindCmd.Stdout = &behindOut
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 
-		if err := behindCmd.Run(); err != nil {
-			// Error checking behind status, skip
-			continue
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, "git fetch"); ctxErr != nil {
+			return FetchResult{}, ctxErr
 		}
+		return FetchResult{}, &FetchError{Operation: "fetch", Stderr: stderr.String()}
+	}
 
-		behindCount := strings.TrimSpace(behindOut.String())
-		if behindCount != "0" && behindCount != "" {
-			// Get ahead count as well
-			aheadCmd := exec.Command("git", "rev-list", "--count", branch+"@{u}.."+branch)
-			aheadCmd.Dir = r.Path
-
-			var aheadOut bytes.Buffer
-			aheadCmd.Stdout = &aheadOut
-
-			aheadCount := "0"
-			if err := aheadCmd.Run(); err == nil {
-				aheadCount = strings.TrimSpace(aheadOut.String())
-			}
-
-			message := fmt.Sprintf("behind by %s commit(s)", behindCount)
-			if aheadCount != "0" {
-				message = fmt.Sprintf("behind by %s, ahead by %s commit(s)", behindCount, aheadCount)
-			}
+	return parseFetchOutput(stderr.String()), nil
+}
 
-			behindBranches = append(behindBranches, BranchTracking{
-				Branch:  branch,
-				Message: message,
-			})
+// parseFetchOutput counts the ref lines git fetch prints on stderr, such as
+// "   1e7698e..902f3c0  main -> origin/main" or " - [deleted] (none) -> origin/gone".
+// The character after the leading space flags the kind of update.
+func parseFetchOutput(output string) FetchResult {
+	var result FetchResult
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 3 || line[0] != ' ' || line[2] != ' ' || !strings.Contains(line, " -> ") {
+			continue
+		}
+		switch line[1] {
+		case '-':
+			result.Pruned++
+		case ' ', '+', '*', 't':
+			result.Updated++
 		}
+		// '!' rejected, '=' up to date
 	}
-
-	return behindBranches, nil
+	return result
 }
 
-// GetStatus retrieves the git status of a repository
-func (r *Repository) GetStatus() (*Status, error) {
-	// Get current branch name
-	branch, _ := r.GetCurrentBranch()
-
-	// Check all branches for tracking status
-	behindBranches, err := r.GetBranchesTrackingStatus()
+// GetBranchesTrackingStatus checks all local branches and returns those that are behind their remote
+func (r *Repository) GetBranchesTrackingStatus(ctx context.Context) ([]BranchTracking, error) {
+	refs, err := r.readBranchRefs(ctx)
 	if err != nil {
-		// Log error but continue with regular status check
-		behindBranches = []BranchTracking{}
+		return nil, err
 	}
+	return refs.BehindBranches, nil
+}
 
-	// First check if upstream is configured
-	upstreamCmd := exec.Command("git", "rev-list", "@{u}..HEAD", "--count")
-	upstreamCmd.Dir = r.Path
-
-	var upstreamStderr bytes.Buffer
-	upstreamCmd.Stderr = &upstreamStderr
-
-	if err := upstreamCmd.Run(); err != nil {
-		// Check if error is due to missing upstream
-		stderrStr := upstreamStderr.String()
-		if strings.Contains(stderrStr, "no upstream configured") ||
-		   strings.Contains(stderrStr, "upstream branch") ||
-		   strings.Contains(stderrStr, "no such branch") {
-			return &Status{
-				Type:           StatusNoUpstream,
-				Message:        "No upstream configured",
-				Symbol:         "⚠ No upstream",
-				Branch:         branch,
-				BehindBranches: behindBranches,
-			}, nil
+// GetStatus retrieves the git status of a repository with two git invocations:
+// one status for the current branch and working tree, one for-each-ref for the other
+// branches, the last commit and the stash. The stash is only listed when there is
+// one, and the last commit read apart only when HEAD is detached.
+func (r *Repository) GetStatus(ctx context.Context) (*Status, error) {
+	if r.Bare {
+		status, err := r.bareStatus(ctx)
+		if status != nil {
+			status.Origin = r.origin()
+			status.LastFetched = r.lastFetched()
 		}
+		return status, err
 	}
 
-	// Run git status
-	cmd := exec.Command("git", "status")
-	cmd.Dir = r.Path
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return &Status{
-			Type:           StatusError,
-			Message:        fmt.Sprintf("Error: %s", stderr.String()),
-			Symbol:         "❌",
-			Branch:         branch,
-			BehindBranches: behindBranches,
-		}, nil
+	// Check all branches for tracking status
+	refs, err := r.readBranchRefs(ctx)
+	if err != nil && ctx.Err() != nil {
+		return nil, err
 	}
+	if err != nil {
+		// Log error but continue with regular status check
+		refs = &branchRefs{BehindBranches: []BranchTracking{}}
+	}
+	behindBranches := refs.BehindBranches
 
-	output := stdout.String()
-
-	// Check for various git states
-	// Check for staged changes first (Changes to be committed)
-	if strings.Contains(output, "Changes to be committed:") {
-		// Determine the type of staged change
-		if strings.Contains(output, "renamed:") {
-			return &Status{
-				Type:           StatusUnsync,
-				Message:        "Staged renames",
-				Symbol:         "✱ R",
-				Branch:         branch,
-				BehindBranches: behindBranches,
-			}, nil
+	porcelain, stderr, err := r.readPorcelainStatus(ctx)
+	if err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+	if err != nil {
+		if status := PermissionStatus(r.Path, stderr, err); status != nil {
+			status.BehindBranches = behindBranches
+			return status, nil
 		}
-		if strings.Contains(output, "new file:") {
-			return &Status{
-				Type:           StatusUnsync,
-				Message:        "Staged files",
-				Symbol:         "✱ +",
-				Branch:         branch,
-				BehindBranches: behindBranches,
-			}, nil
+		if status := LockedStatus(stderr); status != nil {
+			status.BehindBranches = behindBranches
+			return status, nil
+		}
+		message := stderr
+		if message == "" {
+			message = err.Error()
 		}
-		// Generic staged changes
 		return &Status{
-			Type:           StatusUnsync,
-			Message:        "Staged changes",
-			Symbol:         "✱",
-			Branch:         branch,
+			Type:           StatusError,
+			Message:        fmt.Sprintf("Error: %s", message),
+			Symbol:         "❌",
 			BehindBranches: behindBranches,
 		}, nil
 	}
 
-	if strings.Contains(output, "modified:") {
-		return &Status{
-			Type:           StatusUnsync,
-			Message:        "Modified files",
-			Symbol:         "* M",
-			Branch:         branch,
-			BehindBranches: behindBranches,
-		}, nil
+	status := porcelain.classify()
+	status.BehindBranches = behindBranches
+	status.OldestChange = r.oldestChange(porcelain.Paths)
+	status.Shallow = r.isShallow()
+	status.Origin = r.origin()
+	status.LastFetched = r.lastFetched()
+	if status.Origin != nil {
+		status.DefaultBranch = refs.RemoteHeads[status.Origin.Name]
 	}
-
-	if strings.Contains(output, "deleted:") {
-		return &Status{
-			Type:           StatusUnsync,
-			Message:        "Deleted files",
-			Symbol:         "* D",
-			Branch:         branch,
-			BehindBranches: behindBranches,
-		}, nil
+	if status.Type == StatusNoUpstream && status.Origin == nil {
+		if remotes, err := r.Remotes(ctx); err == nil && len(remotes) == 0 {
+			status.withNoRemote()
+		}
 	}
-
-	if strings.Contains(output, "Untracked files:") {
-		return &Status{
-			Type:           StatusUnsync,
-			Message:        "Untracked files",
-			Symbol:         "✱ ✚",
-			Branch:         branch,
-			BehindBranches: behindBranches,
-		}, nil
+	if operation, branch := r.operationInProgress(); operation != "" {
+		status.withOperation(operation)
+		if branch != "" {
+			status.Branch = branch // HEAD is detached while rebasing
+			status.DetachedAt = ""
+		}
 	}
-
-	if strings.Contains(output, "is ahead of") {
-		return &Status{
-			Type:           StatusUnsync,
-			Message:        "Ahead of remote",
-			Symbol:         "⬆",
-			Branch:         branch,
-			BehindBranches: behindBranches,
-		}, nil
+	// git status does not take the lock, commits and pulls would fail on it
+	if lock, lockedAt := r.indexLock(); lock != "" {
+		status.withLock(lock, lockedAt)
 	}
-
-	if strings.Contains(output, "is behind") {
-		return &Status{
-			Type:           StatusUnsync,
-			Message:        "Behind remote",
-			Symbol:         "↓",
-			Branch:         branch,
-			BehindBranches: behindBranches,
-		}, nil
+	if refs.HasStash {
+		stashes, err := r.StashList(ctx)
+		if err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		status.StashCount = len(stashes)
 	}
-
-	if strings.Contains(output, "diverged") {
-		return &Status{
-			Type:           StatusUnsync,
-			Message:        "Diverged from remote",
-			Symbol:         "⬆⬆",
-			Branch:         branch,
-			BehindBranches: behindBranches,
-		}, nil
+	if porcelain.Commit != "" && porcelain.Commit != "(initial)" {
+		// Listed by for-each-ref, unless HEAD is detached
+		status.LastCommit = refs.tip(porcelain.Branch, porcelain.Commit)
+		if status.LastCommit == nil {
+			lastCommit, err := r.LastCommit(ctx)
+			if err != nil && ctx.Err() != nil {
+				return nil, err
+			}
+			status.LastCommit = lastCommit
+		}
 	}
+	return status, nil
+}
 
-	if strings.Contains(output, "nothing to commit, working tree clean") {
-		return &Status{
-			Type:           StatusSync,
-			Message:        "Clean",
-			Symbol:         "✔",
-			Branch:         branch,
-			BehindBranches: behindBranches,
-		}, nil
+// oldestChange returns the modification time of the oldest of the changed files
+// (paths relative to the worktree root), or zero when none can be read
+func (r *Repository) oldestChange(paths []string) time.Time {
+	var oldest time.Time
+	for _, path := range paths {
+		info, err := os.Lstat(filepath.Join(r.Path, path))
+		if err != nil {
+			continue
+		}
+		if oldest.IsZero() || info.ModTime().Before(oldest) {
+			oldest = info.ModTime()
+		}
 	}
-
-	// Default to unsync if we can't determine
-	return &Status{
-		Type:           StatusUnsync,
-		Message:        "Unknown state",
-		Symbol:         "*",
-		Branch:         branch,
-		BehindBranches: behindBranches,
-	}, nil
+	return oldest
 }
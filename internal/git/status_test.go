@@ -0,0 +1,408 @@
+package git_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/gittest"
+)
+
+// tracked returns a repository whose branch tracks origin, up to date
+func tracked(t *testing.T) *gittest.Repo {
+	t.Helper()
+	return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll()
+}
+
+// statusReader is a repository read by either backend
+type statusReader interface {
+	GetStatus(ctx context.Context) (*git.Status, error)
+}
+
+// backends open a repository with each backend, by name
+var backends = []struct {
+	name string
+	open func(r *gittest.Repo) statusReader
+}{
+	{"exec", func(r *gittest.Repo) statusReader { return git.NewRepository(r.Path, r.Name) }},
+	{"gogit", func(r *gittest.Repo) statusReader { return git.NewGoGitRepository(r.Path, r.Name) }},
+}
+
+// getStatus returns the status of the repository, failing t on error
+func getStatus(t *testing.T, repo statusReader) *git.Status {
+	t.Helper()
+	status, err := repo.GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatus: %v", err)
+	}
+	return status
+}
+
+// remoteStatus checks origin and returns the status it makes of the local one
+func remoteStatus(t *testing.T, r *gittest.Repo) *git.Status {
+	t.Helper()
+	local := getStatus(t, r.Repository())
+	err := r.Repository().CheckRemote(context.Background(), "origin")
+	var remoteErr *git.RemoteError
+	if !errors.As(err, &remoteErr) {
+		t.Fatalf("CheckRemote: got %v, want a RemoteError", err)
+	}
+	return git.NewRemoteStatus(remoteErr, local)
+}
+
+// fakeRemoteHelper puts on PATH a git-remote-fake helper failing with stderr,
+// so that fake:: remotes fail like a host would
+func fakeRemoteHelper(t *testing.T, stderr string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("remote helpers are shell scripts")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho '" + stderr + "' >&2\nexit 128\n"
+	if err := os.WriteFile(filepath.Join(dir, "git-remote-fake"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// statusesNotBuilt are the status types set by the checker or the scanner, rather
+// than read from a repository
+var statusesNotBuilt = map[git.StatusType]string{
+	git.StatusIgnored:       "set by the scanner for ignored projects",
+	git.StatusBrokenSymlink: "set by the checker for symlinks without target",
+	git.StatusTimeout:       "set by the checker past the timeout",
+	git.StatusStale:         "set by the checker past stale_after",
+	git.StatusUnchecked:     "set by the checker for projects left out",
+	git.StatusUnversioned:   "set by the scanner for directories without repository",
+}
+
+func TestStatusTypes(t *testing.T) {
+	tests := map[git.StatusType]func(t *testing.T) *git.Status{
+		git.StatusSync: func(t *testing.T) *git.Status {
+			return getStatus(t, tracked(t).Repository())
+		},
+		git.StatusUnsync: func(t *testing.T) *git.Status {
+			return getStatus(t, tracked(t).Modify("README.md").Repository())
+		},
+		git.StatusNoUpstream: func(t *testing.T) *git.Status {
+			return getStatus(t, tracked(t).Branch("topic").Repository())
+		},
+		git.StatusNoRemote: func(t *testing.T) *git.Status {
+			return getStatus(t, gittest.NewRepo(t).Commit().Repository())
+		},
+		git.StatusEmpty: func(t *testing.T) *git.Status {
+			return getStatus(t, gittest.NewRepo(t).Repository())
+		},
+		git.StatusLocked: func(t *testing.T) *git.Status {
+			r := tracked(t).WriteFile(filepath.Join(".git", "index.lock"), "")
+			return getStatus(t, r.Repository())
+		},
+		git.StatusBare: func(t *testing.T) *git.Status {
+			r := tracked(t)
+			return getStatus(t, git.NewRepository(r.Remote, filepath.Base(r.Remote)))
+		},
+		git.StatusError: func(t *testing.T) *git.Status {
+			r := tracked(t).WriteFile(filepath.Join(".git", "index"), "not an index")
+			return getStatus(t, r.Repository())
+		},
+		git.StatusPermission: func(t *testing.T) *git.Status {
+			if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+				t.Skip("needs file permissions enforced on the current user")
+			}
+			r := tracked(t)
+			if err := os.Chmod(r.Path, 0); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = os.Chmod(r.Path, 0755) })
+			return getStatus(t, r.Repository())
+		},
+		git.StatusRemoteUnreachable: func(t *testing.T) *git.Status {
+			r := tracked(t)
+			r.Git("remote", "set-url", "origin", filepath.Join(t.TempDir(), "missing.git"))
+			return remoteStatus(t, r)
+		},
+		git.StatusAuthRequired: func(t *testing.T) *git.Status {
+			fakeRemoteHelper(t, "fatal: Authentication failed for https://git.example.com/repo.git")
+			r := tracked(t)
+			r.Git("remote", "set-url", "origin", "fake::https://git.example.com/repo.git")
+			return remoteStatus(t, r)
+		},
+	}
+
+	for _, statusType := range git.StatusTypes {
+		build, ok := tests[statusType]
+		if !ok {
+			if _, notBuilt := statusesNotBuilt[statusType]; !notBuilt {
+				t.Errorf("no test builds a repository with status %s", statusType)
+			}
+			continue
+		}
+		t.Run(string(statusType), func(t *testing.T) {
+			status := build(t)
+			if status.Type != statusType {
+				t.Errorf("got %s (%s), want %s", status.Type, status.Message, statusType)
+			}
+		})
+	}
+}
+
+func TestRemoteStatusKeepsLocalStatus(t *testing.T) {
+	fakeRemoteHelper(t, "fatal: could not read Username for 'https://git.example.com': terminal prompts disabled")
+	r := tracked(t).Ahead(1).Modify("README.md")
+	r.Git("stash")
+	r.Modify("README.md")
+	r.Git("remote", "set-url", "origin", "fake::https://git.example.com/repo.git")
+
+	local := getStatus(t, r.Repository())
+	status := remoteStatus(t, r)
+	if status.Type != git.StatusAuthRequired || status.Remote == nil {
+		t.Fatalf("got %s, want %s", status.Type, git.StatusAuthRequired)
+	}
+	if status.Branch != gittest.DefaultBranch || status.Ahead != 1 || status.ModifiedCount != 1 || status.StashCount != 1 {
+		t.Errorf("local details lost: %+v", status)
+	}
+	if local.Type == git.StatusAuthRequired {
+		t.Error("the local status was changed")
+	}
+}
+
+func TestGetStatusInvocations(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(t *testing.T) *gittest.Repo
+		want  int
+	}{
+		{"up to date", tracked, 2},
+		{"changes", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Ahead(1).Stage("README.md").Untracked("notes.txt")
+		}, 2},
+		{"several branches", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Branch("topic").Commit().Branch("other").PushAll().Checkout(gittest.DefaultBranch)
+		}, 2},
+		{"stash", func(t *testing.T) *gittest.Repo {
+			r := tracked(t).Modify("README.md")
+			r.Git("stash")
+			return r
+		}, 3},
+		{"detached", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Detach()
+		}, 3},
+		{"no remote", func(t *testing.T) *gittest.Repo {
+			return gittest.NewRepo(t).Commit()
+		}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.build(t)
+			invocations := gittest.CountInvocations(t)
+			getStatus(t, r.Repository())
+			if got := invocations.Count(); got != tt.want {
+				t.Errorf("%d git processes, want %d: %q", got, tt.want, invocations.Args())
+			}
+		})
+	}
+}
+
+// BenchmarkGetStatus reports the git processes of a status (git/op), whatever the
+// number of branches tracking a remote
+func BenchmarkGetStatus(b *testing.B) {
+	for _, branches := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("branches=%d", branches), func(b *testing.B) {
+			r := gittest.NewRepo(b).Commit("README.md").WithBareRemote()
+			for i := 1; i < branches; i++ {
+				r.Git("branch", fmt.Sprintf("topic-%d", i))
+			}
+			r.PushAll().Behind(1)
+			repo := r.Repository()
+			invocations := gittest.CountInvocations(b)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.GetStatus(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(invocations.Count())/float64(b.N), "git/op")
+		})
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func(t *testing.T) *gittest.Repo
+		symbol string
+		check  func(t *testing.T, status *git.Status)
+	}{
+		{"clean", tracked, "✔", func(t *testing.T, status *git.Status) {
+			if status.Type != git.StatusSync || status.Changes != 0 || status.Branch != gittest.DefaultBranch {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"staged", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Stage("README.md")
+		}, "✱", func(t *testing.T, status *git.Status) {
+			if status.StagedCount != 1 || status.ModifiedCount != 0 || status.Changes != 1 {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"staged new file", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Stage("new.txt")
+		}, "✱ +", func(t *testing.T, status *git.Status) {
+			if status.StagedCount != 1 || status.UntrackedCount != 0 {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"modified", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Modify("README.md")
+		}, "* M", func(t *testing.T, status *git.Status) {
+			if status.ModifiedCount != 1 || status.StagedCount != 0 || status.OldestChange.IsZero() {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"deleted", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Commit("other.txt").PushAll().Delete("other.txt")
+		}, "* D", func(t *testing.T, status *git.Status) {
+			if status.DeletedCount != 1 || status.Changes != 1 {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"renamed", func(t *testing.T) *gittest.Repo {
+			r := tracked(t)
+			r.Git("mv", "README.md", "NEWS.md")
+			return r
+		}, "✱ R", func(t *testing.T, status *git.Status) {
+			if status.StagedCount != 1 || status.Changes != 1 {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"untracked", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Untracked("notes.txt", "todo.txt")
+		}, "✱ ✚", func(t *testing.T, status *git.Status) {
+			if status.UntrackedCount != 2 || status.Changes != 2 {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"ahead", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Ahead(2)
+		}, "⬆", func(t *testing.T, status *git.Status) {
+			if status.Ahead != 2 || status.Behind != 0 {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"behind", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Behind(3)
+		}, "↓", func(t *testing.T, status *git.Status) {
+			if status.Ahead != 0 || status.Behind != 3 {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"diverged", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Diverged()
+		}, "⬆⬆", func(t *testing.T, status *git.Status) {
+			if status.Ahead != 1 || status.Behind != 1 {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"conflicted", func(t *testing.T) *gittest.Repo {
+			return tracked(t).CreateConflict()
+		}, git.ConflictSymbol + " ⑂", func(t *testing.T, status *git.Status) {
+			if status.Conflicts != 1 || status.Operation != git.OperationMerge {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"upstream gone", func(t *testing.T) *gittest.Repo {
+			r := tracked(t).Branch("topic").PushAll()
+			r.Git("push", "--quiet", "origin", "--delete", "topic")
+			return r
+		}, git.GoneSymbol, func(t *testing.T, status *git.Status) {
+			if status.GoneUpstream != "origin/topic" {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"no upstream", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Branch("topic")
+		}, "⚠ No upstream", func(t *testing.T, status *git.Status) {
+			if status.Type != git.StatusNoUpstream || status.Branch != "topic" || status.Origin == nil || status.Origin.Name != "origin" {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"no upstream", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Branch("topic")
+		}, "⚠ No upstream", func(t *testing.T, status *git.Status) {
+			if status.Type != git.StatusNoUpstream || status.Branch != "topic" || status.Origin == nil || status.Origin.Name != "origin" {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"detached", func(t *testing.T) *gittest.Repo {
+			return tracked(t).Detach()
+		}, "✔", func(t *testing.T, status *git.Status) {
+			if status.Branch != "HEAD" || status.DetachedAt == "" || status.LastCommit == nil || status.LastCommit.Hash != status.DetachedAt {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"stash", func(t *testing.T) *gittest.Repo {
+			r := tracked(t).Modify("README.md")
+			r.Git("stash")
+			return r
+		}, "✔", func(t *testing.T, status *git.Status) {
+			if status.StashCount != 1 {
+				t.Errorf("got %+v", status)
+			}
+		}},
+		{"other branch behind", func(t *testing.T) *gittest.Repo {
+			r := tracked(t).Branch("topic").PushAll().Checkout(gittest.DefaultBranch)
+			return r.CommitOnRemoteBranch("topic")
+		}, "✔", func(t *testing.T, status *git.Status) {
+			want := []git.BranchTracking{{Branch: "topic", Message: "behind by 1 commit(s)"}}
+			if len(status.BehindBranches) != 1 || status.BehindBranches[0] != want[0] {
+				t.Errorf("behind branches %+v, want %+v", status.BehindBranches, want)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.build(t)
+			statuses := make([]*git.Status, len(backends))
+			for i, backend := range backends {
+				t.Run(backend.name, func(t *testing.T) {
+					status := getStatus(t, backend.open(r))
+					if status.Symbol != tt.symbol {
+						t.Errorf("symbol %q (%s), want %q", status.Symbol, status.Message, tt.symbol)
+					}
+					tt.check(t, status)
+					statuses[i] = status
+				})
+			}
+			if statuses[0] != nil && statuses[1] != nil {
+				if exec, gogit := comparable(statuses[0]), comparable(statuses[1]); !reflect.DeepEqual(exec, gogit) {
+					t.Errorf("backends disagree:\nexec  %+v\ngogit %+v", exec, gogit)
+				}
+			}
+		})
+	}
+}
+
+// comparable returns what both backends must agree on in status: everything but
+// the times, read from the same files by both anyway
+func comparable(status *git.Status) git.Status {
+	c := *status
+	c.OldestChange = time.Time{}
+	c.LastFetched = time.Time{}
+	c.LastCommit = nil
+	if c.Origin != nil {
+		origin := *c.Origin
+		c.Origin = &origin
+	}
+	if len(c.BehindBranches) == 0 {
+		c.BehindBranches = nil
+	}
+	return c
+}
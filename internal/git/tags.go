@@ -0,0 +1,89 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// UnpushedTags returns the local tags that remote does not have, comparing their
+// names with the tags git ls-remote lists: it reaches the remote over the network.
+func (r *Repository) UnpushedTags(ctx context.Context, remote string) ([]string, error) {
+	local, err := r.refNames(ctx, r.command(ctx, "for-each-ref", "--format=%(refname)", "refs/tags"), "git for-each-ref")
+	if err != nil {
+		return nil, err
+	}
+	if len(local) == 0 {
+		return nil, nil
+	}
+
+	remoteTags, err := r.refNames(ctx, r.command(ctx, "ls-remote", "--tags", "--refs", remote), "git ls-remote")
+	if err != nil {
+		return nil, err
+	}
+	pushed := make(map[string]bool, len(remoteTags))
+	for _, tag := range remoteTags {
+		pushed[tag] = true
+	}
+
+	var unpushed []string
+	for _, tag := range local {
+		if !pushed[tag] {
+			unpushed = append(unpushed, tag)
+		}
+	}
+	return unpushed, nil
+}
+
+// refNames runs a command listing refs, one per line as "<refname>" (for-each-ref)
+// or "<object>\t<refname>" (ls-remote), and returns the names of the tags
+func (r *Repository) refNames(ctx context.Context, cmd *exec.Cmd, name string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := contextError(ctx, name); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to list tags: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	var tags []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		_, ref, found := strings.Cut(line, "\t")
+		if !found {
+			ref = line
+		}
+		if tag, ok := strings.CutPrefix(ref, "refs/tags/"); ok && tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// PushTags pushes the given tags to remote. The output of git is returned for
+// display; failures are returned as a *PushError, as with Push.
+func (r *Repository) PushTags(ctx context.Context, remote string, tags []string) (string, error) {
+	args := []string{"push", remote}
+	for _, tag := range tags {
+		args = append(args, "refs/tags/"+tag)
+	}
+	cmd := r.command(ctx, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	output := strings.TrimSpace(stdout.String() + "\n" + stderr.String())
+	if err == nil {
+		return output, nil
+	}
+	if ctxErr := contextError(ctx, "git push"); ctxErr != nil {
+		return output, ctxErr
+	}
+	return output, pushError(stderr.String(), output)
+}
@@ -0,0 +1,392 @@
+// Package gittest builds git repositories in specific states (ahead, behind,
+// diverged, conflicted, mid-rebase or cherry-pick, without upstream, detached)
+// for tests and benchmarks.
+//
+//	repo := gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll()
+//	repo.CommitOnRemote("CHANGELOG.md") // now behind origin/main
+//	status, err := repo.Repository().GetStatus(ctx)
+//
+// Repositories live in t.TempDir and ignore the user's and system git config.
+// Every failing git command fails the test. CountInvocations counts the git
+// processes started by the code under test.
+package gittest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// DefaultBranch is the branch of new repositories and remotes
+const DefaultBranch = "main"
+
+// Repo is a working copy under construction, with an optional bare remote
+type Repo struct {
+	t      testing.TB
+	Path   string // Working copy
+	Name   string // Base name of Path
+	Remote string // Bare remote registered as origin ("" until WithBareRemote)
+
+	commits int // Numbers the generated file contents, so that every commit changes something
+}
+
+// NewRepo creates an empty repository on DefaultBranch
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "repo")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatalf("gittest: %v", err)
+	}
+	r := &Repo{t: t, Path: path, Name: filepath.Base(path)}
+	r.Git("init", "--quiet")
+	r.Git("symbolic-ref", "HEAD", "refs/heads/"+DefaultBranch)
+	return r
+}
+
+// Repository returns the repository as checked by check-projects
+func (r *Repo) Repository() *git.Repository {
+	return git.NewRepository(r.Path, r.Name)
+}
+
+// Env is the environment of the git commands run by the builder: the user's and
+// system config are ignored, and commits get a fixed identity
+func Env() []string {
+	return append(os.Environ(),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_AUTHOR_NAME=gittest",
+		"GIT_AUTHOR_EMAIL=gittest@example.com",
+		"GIT_COMMITTER_NAME=gittest",
+		"GIT_COMMITTER_EMAIL=gittest@example.com",
+	)
+}
+
+// Git runs git in the working copy and returns its trimmed output
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	return run(r.t, r.Path, args...)
+}
+
+// run runs git in dir, failing t when git fails
+func run(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	out, err := tryRun(dir, args...)
+	if err != nil {
+		t.Fatalf("gittest: %v", err)
+	}
+	return out
+}
+
+// tryRun runs git in dir and returns its trimmed output, or an error with its stderr
+func tryRun(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = Env()
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s in %s: %v: %s", strings.Join(args, " "), dir, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// WriteFile writes content to name in the working copy, creating parent directories
+func (r *Repo) WriteFile(name, content string) *Repo {
+	r.t.Helper()
+	writeFile(r.t, r.Path, name, content)
+	return r
+}
+
+func writeFile(t testing.TB, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("gittest: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("gittest: %v", err)
+	}
+}
+
+// nextContent returns file content that differs from every previous one
+func (r *Repo) nextContent(name string) string {
+	r.commits++
+	return fmt.Sprintf("%s %d\n", name, r.commits)
+}
+
+// Commit writes new content to each file (or to a generated one when none is
+// given) and commits them on the current branch
+func (r *Repo) Commit(files ...string) *Repo {
+	r.t.Helper()
+	if len(files) == 0 {
+		files = []string{fmt.Sprintf("file-%d.txt", r.commits+1)}
+	}
+	for _, file := range files {
+		r.WriteFile(file, r.nextContent(file))
+	}
+	r.Git(append([]string{"add", "--"}, files...)...)
+	r.Git("commit", "--quiet", "-m", "Update "+strings.Join(files, ", "))
+	return r
+}
+
+// Modify changes tracked files without staging them
+func (r *Repo) Modify(files ...string) *Repo {
+	r.t.Helper()
+	for _, file := range files {
+		r.WriteFile(file, r.nextContent(file))
+	}
+	return r
+}
+
+// Stage writes new content to files and stages them without committing
+func (r *Repo) Stage(files ...string) *Repo {
+	r.t.Helper()
+	r.Modify(files...)
+	r.Git(append([]string{"add", "--"}, files...)...)
+	return r
+}
+
+// Untracked creates files that git does not track
+func (r *Repo) Untracked(files ...string) *Repo {
+	r.t.Helper()
+	return r.Modify(files...)
+}
+
+// Delete removes tracked files from the working copy without staging it
+func (r *Repo) Delete(files ...string) *Repo {
+	r.t.Helper()
+	for _, file := range files {
+		if err := os.Remove(filepath.Join(r.Path, file)); err != nil {
+			r.t.Fatalf("gittest: %v", err)
+		}
+	}
+	return r
+}
+
+// Branch creates a branch at HEAD and checks it out
+func (r *Repo) Branch(name string) *Repo {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "-b", name)
+	return r
+}
+
+// Checkout checks out an existing branch or commit
+func (r *Repo) Checkout(ref string) *Repo {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", ref)
+	return r
+}
+
+// Detach detaches HEAD at the current commit
+func (r *Repo) Detach() *Repo {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "--detach")
+	return r
+}
+
+// Worktree adds a linked worktree of the repository on a new branch and returns it.
+// Its .git is a file pointing to the repository's git directory.
+func (r *Repo) Worktree(branch string) *Repo {
+	r.t.Helper()
+	path := filepath.Join(r.t.TempDir(), branch)
+	r.Git("worktree", "add", "--quiet", "-b", branch, path)
+	return &Repo{t: r.t, Path: path, Name: filepath.Base(path), Remote: r.Remote, commits: r.commits}
+}
+
+// WithBareRemote creates an empty bare repository and registers it as origin
+func (r *Repo) WithBareRemote() *Repo {
+	r.t.Helper()
+	remote := filepath.Join(r.t.TempDir(), "remote.git")
+	run(r.t, filepath.Dir(remote), "init", "--quiet", "--bare", remote)
+	run(r.t, remote, "symbolic-ref", "HEAD", "refs/heads/"+DefaultBranch)
+	r.Git("remote", "add", "origin", remote)
+	r.Remote = remote
+	return r
+}
+
+// PushAll pushes every branch to origin and sets them to track their remote branch
+func (r *Repo) PushAll() *Repo {
+	r.t.Helper()
+	r.Git("push", "--quiet", "--set-upstream", "origin", "--all")
+	return r
+}
+
+// Fetch updates the remote-tracking branches from origin
+func (r *Repo) Fetch() *Repo {
+	r.t.Helper()
+	r.Git("fetch", "--quiet", "origin")
+	return r
+}
+
+// CommitOnRemote commits files (or a generated one) on a branch of origin from
+// another clone (the current branch when not given), then fetches it, so that
+// the branch is behind its remote
+func (r *Repo) CommitOnRemote(files ...string) *Repo {
+	r.t.Helper()
+	return r.CommitOnRemoteBranch(r.CurrentBranch(), files...)
+}
+
+// CommitOnRemoteBranch is CommitOnRemote for a given branch of origin
+func (r *Repo) CommitOnRemoteBranch(branch string, files ...string) *Repo {
+	r.t.Helper()
+	if r.Remote == "" {
+		r.t.Fatalf("gittest: CommitOnRemote needs WithBareRemote")
+	}
+
+	clone := filepath.Join(r.t.TempDir(), "clone")
+	run(r.t, filepath.Dir(clone), "clone", "--quiet", "--branch", branch, r.Remote, clone)
+	if len(files) == 0 {
+		files = []string{fmt.Sprintf("remote-%d.txt", r.commits+1)}
+	}
+	for _, file := range files {
+		writeFile(r.t, clone, file, "remote "+r.nextContent(file))
+	}
+	run(r.t, clone, append([]string{"add", "--"}, files...)...)
+	run(r.t, clone, "commit", "--quiet", "-m", "Remote update of "+strings.Join(files, ", "))
+	run(r.t, clone, "push", "--quiet", "origin", branch)
+
+	return r.Fetch()
+}
+
+// CreateConflict commits the same file locally and on origin with different
+// contents, then merges the remote branch, leaving the working copy conflicted
+func (r *Repo) CreateConflict() *Repo {
+	r.t.Helper()
+	const file = "conflict.txt"
+	r.CommitOnRemote(file)
+	r.Commit(file)
+
+	upstream := "origin/" + r.CurrentBranch()
+	if _, err := tryRun(r.Path, "merge", "--no-edit", upstream); err == nil {
+		r.t.Fatalf("gittest: merging %s did not conflict", upstream)
+	}
+	return r
+}
+
+// CreateRebaseConflict is CreateConflict with a rebase onto the remote branch,
+// leaving the rebase in progress
+func (r *Repo) CreateRebaseConflict() *Repo {
+	r.t.Helper()
+	const file = "conflict.txt"
+	r.CommitOnRemote(file)
+	r.Commit(file)
+
+	upstream := "origin/" + r.CurrentBranch()
+	if _, err := tryRun(r.Path, "rebase", upstream); err == nil {
+		r.t.Fatalf("gittest: rebasing onto %s did not conflict", upstream)
+	}
+	return r
+}
+
+// CreateCherryPickConflict is CreateConflict with a cherry-pick of the remote
+// commit, leaving the cherry-pick in progress
+func (r *Repo) CreateCherryPickConflict() *Repo {
+	r.t.Helper()
+	const file = "conflict.txt"
+	r.CommitOnRemote(file)
+	r.Commit(file)
+
+	upstream := "origin/" + r.CurrentBranch()
+	if _, err := tryRun(r.Path, "cherry-pick", upstream); err == nil {
+		r.t.Fatalf("gittest: cherry-picking %s did not conflict", upstream)
+	}
+	return r
+}
+
+// CurrentBranch returns the checked out branch ("HEAD" when detached)
+func (r *Repo) CurrentBranch() string {
+	r.t.Helper()
+	return r.Git("rev-parse", "--abbrev-ref", "HEAD")
+}
+
+// Ahead makes the current branch n commits ahead of its upstream
+func (r *Repo) Ahead(n int) *Repo {
+	r.t.Helper()
+	for i := 0; i < n; i++ {
+		r.Commit()
+	}
+	return r
+}
+
+// Behind makes the current branch n commits behind its upstream
+func (r *Repo) Behind(n int) *Repo {
+	r.t.Helper()
+	for i := 0; i < n; i++ {
+		r.CommitOnRemote()
+	}
+	return r
+}
+
+// Diverged makes the current branch both ahead of and behind its upstream
+func (r *Repo) Diverged() *Repo {
+	r.t.Helper()
+	return r.Behind(1).Ahead(1)
+}
+
+// Invocations counts the git processes started, through a git wrapper put first
+// on PATH for the rest of the test. Commands of the builder are counted as well:
+// build the repository before, or call Reset.
+type Invocations struct {
+	t   testing.TB
+	log string
+}
+
+// CountInvocations starts counting the git processes of the test. It skips the
+// test on Windows, where the wrapper would need to be an executable.
+func CountInvocations(t testing.TB) *Invocations {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("gittest: counting git processes needs a shell")
+	}
+	real, err := exec.LookPath("git")
+	if err != nil {
+		t.Fatalf("gittest: %v", err)
+	}
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "invocations.log")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$*\" >> '%s'\nexec '%s' \"$@\"\n", log, real)
+	if err := os.WriteFile(filepath.Join(dir, "git"), []byte(script), 0755); err != nil {
+		t.Fatalf("gittest: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return &Invocations{t: t, log: log}
+}
+
+// Args returns the arguments of each git process started since the last Reset
+func (i *Invocations) Args() []string {
+	i.t.Helper()
+	data, err := os.ReadFile(i.log)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		i.t.Fatalf("gittest: %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// Count returns how many git processes were started since the last Reset
+func (i *Invocations) Count() int {
+	i.t.Helper()
+	return len(i.Args())
+}
+
+// Reset starts counting from zero again
+func (i *Invocations) Reset() {
+	i.t.Helper()
+	if err := os.Remove(i.log); err != nil && !errors.Is(err, os.ErrNotExist) {
+		i.t.Fatalf("gittest: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package gittest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tracked returns a cloned repository whose branch tracks origin
+func tracked(t *testing.T) *Repo {
+	t.Helper()
+	return NewRepo(t).Commit("README.md").WithBareRemote().PushAll()
+}
+
+// aheadBehind returns the commits of HEAD not on its upstream, and the reverse
+func aheadBehind(r *Repo) string {
+	r.t.Helper()
+	return r.Git("rev-list", "--left-right", "--count", "HEAD...@{upstream}")
+}
+
+func TestNewRepo(t *testing.T) {
+	r := NewRepo(t)
+	if got := r.Git("symbolic-ref", "HEAD"); got != "refs/heads/"+DefaultBranch {
+		t.Errorf("HEAD is %s, want %s", got, DefaultBranch)
+	}
+	if _, err := tryRun(r.Path, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		t.Error("a new repository has a commit")
+	}
+}
+
+func TestAhead(t *testing.T) {
+	r := tracked(t).Ahead(2)
+	if got := aheadBehind(r); got != "2\t0" {
+		t.Errorf("ahead/behind %q, want 2 ahead", got)
+	}
+}
+
+func TestBehind(t *testing.T) {
+	r := tracked(t).Behind(3)
+	if got := aheadBehind(r); got != "0\t3" {
+		t.Errorf("ahead/behind %q, want 3 behind", got)
+	}
+}
+
+func TestDiverged(t *testing.T) {
+	r := tracked(t).Diverged()
+	if got := aheadBehind(r); got != "1\t1" {
+		t.Errorf("ahead/behind %q, want 1 ahead and 1 behind", got)
+	}
+}
+
+func TestCreateConflict(t *testing.T) {
+	r := tracked(t).CreateConflict()
+	if got := r.Git("diff", "--name-only", "--diff-filter=U"); got != "conflict.txt" {
+		t.Errorf("unmerged paths %q, want conflict.txt", got)
+	}
+	if _, err := tryRun(r.Path, "rev-parse", "--verify", "--quiet", "MERGE_HEAD"); err != nil {
+		t.Error("no merge in progress")
+	}
+}
+
+func TestCreateRebaseConflict(t *testing.T) {
+	r := tracked(t).CreateRebaseConflict()
+	if got := r.Git("diff", "--name-only", "--diff-filter=U"); got != "conflict.txt" {
+		t.Errorf("unmerged paths %q, want conflict.txt", got)
+	}
+	gitDir := r.Git("rev-parse", "--absolute-git-dir")
+	if _, err := os.Stat(filepath.Join(gitDir, "rebase-merge")); err != nil {
+		t.Errorf("no rebase in progress: %v", err)
+	}
+}
+
+func TestCreateCherryPickConflict(t *testing.T) {
+	r := tracked(t).CreateCherryPickConflict()
+	if got := r.Git("diff", "--name-only", "--diff-filter=U"); got != "conflict.txt" {
+		t.Errorf("unmerged paths %q, want conflict.txt", got)
+	}
+	if _, err := tryRun(r.Path, "rev-parse", "--verify", "--quiet", "CHERRY_PICK_HEAD"); err != nil {
+		t.Error("no cherry-pick in progress")
+	}
+}
+
+func TestNoUpstream(t *testing.T) {
+	r := tracked(t).Branch("topic").Commit()
+	if _, err := tryRun(r.Path, "rev-parse", "--abbrev-ref", "@{upstream}"); err == nil {
+		t.Error("a new branch has an upstream")
+	}
+	if got := r.CurrentBranch(); got != "topic" {
+		t.Errorf("current branch %s, want topic", got)
+	}
+}
+
+func TestDetach(t *testing.T) {
+	r := NewRepo(t).Commit().Detach()
+	if _, err := tryRun(r.Path, "symbolic-ref", "--quiet", "HEAD"); err == nil {
+		t.Error("HEAD is still on a branch")
+	}
+	if got := r.CurrentBranch(); got != "HEAD" {
+		t.Errorf("current branch %s, want HEAD", got)
+	}
+}
+
+func TestWorktree(t *testing.T) {
+	r := NewRepo(t).Commit()
+	wt := r.Worktree("topic")
+	info, err := os.Stat(filepath.Join(wt.Path, ".git"))
+	if err != nil || info.IsDir() {
+		t.Fatalf("the .git of a linked worktree is not a file (%v)", err)
+	}
+	if got := wt.CurrentBranch(); got != "topic" {
+		t.Errorf("current branch %s, want topic", got)
+	}
+}
+
+func TestWorkingCopyChanges(t *testing.T) {
+	r := NewRepo(t).Commit("a.txt", "b.txt", "c.txt")
+	r.Stage("a.txt").Modify("b.txt").Delete("c.txt").Untracked("d.txt")
+
+	want := []string{"M  a.txt", " M b.txt", " D c.txt", "?? d.txt"}
+	if got := strings.Split(r.Git("status", "--porcelain"), "\n"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("status %q, want %q", got, want)
+	}
+}
+
+func TestCommitOnRemoteBranch(t *testing.T) {
+	r := tracked(t).Branch("topic").PushAll().Checkout(DefaultBranch)
+	r.CommitOnRemoteBranch("topic", "remote.txt")
+
+	if got := r.Git("rev-list", "--count", "topic..origin/topic"); got != "1" {
+		t.Errorf("topic is %s commits behind origin/topic, want 1", got)
+	}
+	if got := aheadBehind(r); got != "0\t0" {
+		t.Errorf("ahead/behind of %s %q, want up to date", DefaultBranch, got)
+	}
+}
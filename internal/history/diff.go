@@ -0,0 +1,101 @@
+package history
+
+import (
+	"slices"
+	"sort"
+)
+
+// Kinds of Change between two snapshots
+const (
+	ChangeDirty       = "became_dirty"
+	ChangeClean       = "became_clean"
+	ChangeStatus      = "status" // Another status type, while staying dirty (or clean)
+	ChangeAheadBehind = "ahead_behind"
+	ChangeBranches    = "behind_branches" // Other branches behind their remote changed
+	ChangeAppeared    = "appeared"
+	ChangeDisappeared = "disappeared"
+)
+
+// Change is how one project differs between two snapshots.
+// Before is nil for appeared projects, After for disappeared ones.
+type Change struct {
+	Kind   string           `json:"kind"`
+	Path   string           `json:"path"`
+	Before *SnapshotProject `json:"before,omitempty"`
+	After  *SnapshotProject `json:"after,omitempty"`
+}
+
+// Project returns the latest known state of the changed project
+func (c Change) Project() SnapshotProject {
+	if c.After != nil {
+		return *c.After
+	}
+	return *c.Before
+}
+
+// Diff compares two snapshots, matching projects by path. A project that stays
+// dirty (or clean) is only reported when its status type, its ahead/behind counts
+// or its other branches behind their remote changed.
+// Changes are ordered by category then name, as of their latest state.
+func Diff(older, newer Snapshot) []Change {
+	before := make(map[string]*SnapshotProject, len(older.Projects))
+	for i := range older.Projects {
+		before[older.Projects[i].Path] = &older.Projects[i]
+	}
+
+	var changes []Change
+	seen := make(map[string]bool, len(newer.Projects))
+	for i := range newer.Projects {
+		after := &newer.Projects[i]
+		seen[after.Path] = true
+
+		prev, ok := before[after.Path]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: ChangeAppeared, Path: after.Path, After: after})
+		case prev.Clean && !after.Clean:
+			changes = append(changes, Change{Kind: ChangeDirty, Path: after.Path, Before: prev, After: after})
+		case !prev.Clean && after.Clean:
+			changes = append(changes, Change{Kind: ChangeClean, Path: after.Path, Before: prev, After: after})
+		case prev.Status != after.Status:
+			changes = append(changes, Change{Kind: ChangeStatus, Path: after.Path, Before: prev, After: after})
+		case prev.Ahead != after.Ahead || prev.Behind != after.Behind:
+			changes = append(changes, Change{Kind: ChangeAheadBehind, Path: after.Path, Before: prev, After: after})
+		case behindBranchesChanged(prev, after):
+			changes = append(changes, Change{Kind: ChangeBranches, Path: after.Path, Before: prev, After: after})
+		}
+	}
+	for i := range older.Projects {
+		if prev := &older.Projects[i]; !seen[prev.Path] {
+			changes = append(changes, Change{Kind: ChangeDisappeared, Path: prev.Path, Before: prev})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i].Project(), changes[j].Project()
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Path < b.Path
+	})
+	return changes
+}
+
+// behindBranchesChanged reports whether other branches fell behind their remote or
+// caught up. Names are only compared when both snapshots recorded them.
+func behindBranchesChanged(before, after *SnapshotProject) bool {
+	if before.BehindBranches != after.BehindBranches {
+		return true
+	}
+	if before.BehindBranchNames == nil || after.BehindBranchNames == nil {
+		return false
+	}
+	beforeNames := slices.Clone(before.BehindBranchNames)
+	afterNames := slices.Clone(after.BehindBranchNames)
+	slices.Sort(beforeNames)
+	slices.Sort(afterNames)
+	return !slices.Equal(beforeNames, afterNames)
+}
@@ -0,0 +1,208 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+// SchemaVersion is the version of the snapshot documents written by Save.
+// Load rejects documents of a newer version.
+const SchemaVersion = 1
+
+// DefaultKeep is the number of snapshots kept when not configured
+const DefaultKeep = 30
+
+// timestampLayout names snapshot files, sorting them by date
+const timestampLayout = "20060102T150405.000Z"
+
+// Snapshot is the state of every project at the end of a run
+type Snapshot struct {
+	Version   int               `json:"version"`
+	CreatedAt time.Time         `json:"created_at"`
+	Projects  []SnapshotProject `json:"projects"`
+}
+
+// SnapshotProject is the state of one project in a Snapshot
+type SnapshotProject struct {
+	Name           string         `json:"name"`
+	Category       string         `json:"category"`
+	Path           string         `json:"path"`
+	Status         git.StatusType `json:"status"`
+	Message        string         `json:"message"`
+	Symbol         string         `json:"symbol"`
+	Branch         string         `json:"branch,omitempty"`
+	Ahead          int            `json:"ahead"`
+	Behind         int            `json:"behind"`
+	BehindBranches int            `json:"behind_branches,omitempty"` // Other branches behind their upstream
+	Clean          bool           `json:"clean"`                     // Needs no attention
+
+	// Names of the branches counted in BehindBranches (missing from older snapshots)
+	BehindBranchNames []string `json:"behind_branch_names,omitempty"`
+}
+
+// NewSnapshot records the state of results at createdAt
+func NewSnapshot(results []reporter.ProjectResult, createdAt time.Time) Snapshot {
+	snapshot := Snapshot{
+		Version:   SchemaVersion,
+		CreatedAt: createdAt.UTC(),
+		Projects:  make([]SnapshotProject, 0, len(results)),
+	}
+	for _, result := range results {
+		var behindBranchNames []string
+		for _, branch := range result.Status.BehindBranches {
+			behindBranchNames = append(behindBranchNames, branch.Branch)
+		}
+		snapshot.Projects = append(snapshot.Projects, SnapshotProject{
+			Name:           result.Name,
+			Category:       result.Category,
+			Path:           result.Path,
+			Status:         result.Status.Type,
+			Message:        result.Status.Message,
+			Symbol:         result.Status.Symbol,
+			Branch:         result.Status.Branch,
+			Ahead:          result.Status.Ahead,
+			Behind:         result.Status.Behind,
+			BehindBranches: len(result.Status.BehindBranches),
+			Clean:          reporter.IsClean(result),
+
+			BehindBranchNames: behindBranchNames,
+		})
+	}
+	return snapshot
+}
+
+// Dir returns where snapshots are stored: $XDG_DATA_HOME/check-projects/history,
+// or ~/.local/share/check-projects/history
+func Dir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "check-projects", "history"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "check-projects", "history"), nil
+}
+
+// Save writes snapshot to dir as <timestamp>.json, then removes the oldest
+// snapshots beyond the keep most recent ones. It returns the written path.
+func Save(dir string, snapshot Snapshot, keep int) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create history directory: %w", err)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, snapshot.CreatedAt.UTC().Format(timestampLayout)+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	paths, err := List(dir)
+	if err != nil {
+		return path, err
+	}
+	for _, old := range paths[:max(len(paths)-keep, 0)] {
+		if err := os.Remove(old); err != nil {
+			return path, fmt.Errorf("failed to remove old snapshot: %w", err)
+		}
+	}
+	return path, nil
+}
+
+// StateDir returns where the state kept between runs is stored:
+// $XDG_STATE_HOME/check-projects, or ~/.local/state/check-projects
+func StateDir() (string, error) {
+	if stateHome := os.Getenv("XDG_STATE_HOME"); stateHome != "" {
+		return filepath.Join(stateHome, "check-projects"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "check-projects"), nil
+}
+
+// Write writes snapshot to path, replacing the previous one, for Load to read it back
+func Write(path string, snapshot Snapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// List returns the snapshot files of dir, oldest first
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		if _, err := time.Parse(timestampLayout, strings.TrimSuffix(name, ".json")); err != nil {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Load reads a snapshot written by Save
+func Load(path string) (Snapshot, error) {
+	var snapshot Snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	switch {
+	case snapshot.Version == 0:
+		return snapshot, fmt.Errorf("%s is not a check-projects snapshot", path)
+	case snapshot.Version > SchemaVersion:
+		return snapshot, fmt.Errorf("snapshot %s has version %d, this check-projects only reads up to %d: upgrade it", path, snapshot.Version, SchemaVersion)
+	}
+	return snapshot, nil
+}
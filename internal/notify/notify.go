@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Notifier sends desktop notifications
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// executor finds and runs the platform helper commands
+type executor interface {
+	LookPath(file string) (string, error)
+	Run(name string, args ...string) error
+}
+
+type osExecutor struct{}
+
+func (osExecutor) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+func (osExecutor) Run(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %s", name, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// New returns the notifier of the running platform: osascript on macOS, a PowerShell
+// toast on Windows, notify-send elsewhere. It does nothing when the helper is missing.
+func New() Notifier {
+	return newNotifier(runtime.GOOS, osExecutor{})
+}
+
+func newNotifier(goos string, exec executor) Notifier {
+	name, _ := command(goos, "", "")
+	if _, err := exec.LookPath(name); err != nil {
+		return noop{}
+	}
+	return &commandNotifier{goos: goos, exec: exec}
+}
+
+// commandNotifier notifies through the platform helper command
+type commandNotifier struct {
+	goos string
+	exec executor
+}
+
+func (n *commandNotifier) Notify(title, message string) error {
+	name, args := command(n.goos, title, message)
+	return n.exec.Run(name, args...)
+}
+
+// noop is used when no notification helper is available
+type noop struct{}
+
+func (noop) Notify(title, message string) error {
+	return nil
+}
+
+// command builds the helper command showing a notification on goos
+func command(goos, title, message string) (string, []string) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}
+	case "windows":
+		// Best effort: toasts need an app id, PowerShell's own is used
+		script := fmt.Sprintf(`[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$texts = $template.GetElementsByTagName('text')
+$texts.Item(0).AppendChild($template.CreateTextNode(%s)) > $null
+$texts.Item(1).AppendChild($template.CreateTextNode(%s)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe').Show($toast)`,
+			powerShellString(title), powerShellString(message))
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+	default:
+		return "notify-send", []string{"--app-name=check-projects", title, message}
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// powerShellString quotes s as a verbatim PowerShell string literal
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
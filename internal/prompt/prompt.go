@@ -0,0 +1,103 @@
+// Package prompt records the outcome of the last full run, so that a shell
+// prompt can show it without scanning anything or running git.
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+// DefaultMaxAge is how long a recorded run is trusted when prompt_max_age is not configured
+const DefaultMaxAge = time.Hour
+
+// Tokens printed by --prompt besides the count of projects needing attention
+const (
+	TokenClean   = "✔"
+	TokenUnknown = "!" // No run recorded, or recorded too long ago
+)
+
+// State is the outcome of the last full run
+type State struct {
+	CreatedAt     time.Time `json:"created_at"`
+	Total         int       `json:"total"`
+	NeedAttention int       `json:"need_attention"` // Errors included
+}
+
+// NewState records the outcome of results at createdAt
+func NewState(results []reporter.ProjectResult, createdAt time.Time) State {
+	summary := reporter.Summarize(results)
+	return State{
+		CreatedAt:     createdAt.UTC(),
+		Total:         summary.Total,
+		NeedAttention: summary.NeedAttention() + summary.Errors,
+	}
+}
+
+// DefaultPath returns where the state is stored
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "check-projects", "prompt.json"), nil
+}
+
+// Save writes state to path, through a temporary file renamed over it so that a
+// prompt reading it at the same time never sees a partial file
+func Save(path string, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".prompt-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load reads the state at path. ok is false when none was recorded or it cannot be read.
+func Load(path string) (state State, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return State{}, false
+	}
+	if err := json.Unmarshal(data, &state); err != nil || state.CreatedAt.IsZero() {
+		return State{}, false
+	}
+	return state, true
+}
+
+// Stale reports whether state was recorded more than maxAge before now
+func (s State) Stale(maxAge time.Duration, now time.Time) bool {
+	return now.Sub(s.CreatedAt) > maxAge
+}
+
+// Token renders state for a prompt: ✔ when every project is clean, ✱N when N
+// projects need attention, ! when no state was recorded or it is older than maxAge
+func Token(state State, ok bool, maxAge time.Duration, now time.Time) string {
+	switch {
+	case !ok || state.Stale(maxAge, now):
+		return TokenUnknown
+	case state.NeedAttention == 0:
+		return TokenClean
+	default:
+		return fmt.Sprintf("✱%d", state.NeedAttention)
+	}
+}
@@ -0,0 +1,109 @@
+package remotes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// DefaultCachePath returns where reachability results are cached
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "check-projects", "remotes.json"), nil
+}
+
+// cacheEntry is the result of checking a remote URL
+type cacheEntry struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Class     string    `json:"class,omitempty"` // Empty when the remote was reachable
+	Message   string    `json:"message,omitempty"`
+}
+
+func newCacheEntry(err *git.RemoteError, now time.Time) cacheEntry {
+	if err == nil {
+		return cacheEntry{CheckedAt: now}
+	}
+	return cacheEntry{CheckedAt: now, Class: err.Class, Message: err.Message}
+}
+
+// remoteError returns the cached failure for remote, or nil when it was reachable
+func (e cacheEntry) remoteError(remote, remoteURL string) *git.RemoteError {
+	if e.Class == "" {
+		return nil
+	}
+	return &git.RemoteError{Remote: remote, URL: remoteURL, Class: e.Class, Message: e.Message}
+}
+
+// cache holds the latest result of each remote URL, keyed by a digest so that
+// credentials embedded in URLs are not stored
+type cache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// loadCache reads the cache at path. A missing or unreadable cache is empty.
+func loadCache(path string) *cache {
+	c := &cache{path: path, entries: make(map[string]cacheEntry)}
+	if path == "" {
+		return c
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &c.entries)
+	}
+	return c
+}
+
+func cacheKey(remoteURL string) string {
+	sum := sha256.Sum256([]byte(remoteURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// lookup returns the entry of remoteURL when it was checked less than maxAge before now
+func (c *cache) lookup(remoteURL string, now time.Time, maxAge time.Duration) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[cacheKey(remoteURL)]
+	if !ok || now.Sub(entry.CheckedAt) >= maxAge || entry.CheckedAt.After(now) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *cache) store(remoteURL string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[cacheKey(remoteURL)] = entry
+}
+
+// save writes the cache, dropping the entries older than maxAge
+func (c *cache) save(maxAge time.Duration) error {
+	if c.path == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if time.Since(entry.CheckedAt) >= maxAge {
+			delete(c.entries, key)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
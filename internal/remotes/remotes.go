@@ -0,0 +1,131 @@
+// Package remotes checks that the remote of each repository can still be reached,
+// to flag remotes that were deleted or moved.
+package remotes
+
+import (
+	"context"
+	"errors"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/uralys/check-projects/internal/checker"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// Defaults of Options
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultCacheFor    = 24 * time.Hour
+	DefaultConcurrency = 10
+)
+
+// Options controls how remotes are checked
+type Options struct {
+	Concurrency int           // Remotes checked at once (0 = DefaultConcurrency)
+	Timeout     time.Duration // Limit for each git ls-remote (0 = DefaultTimeout)
+	CacheFor    time.Duration // How long a result is reused (0 = DefaultCacheFor, negative = never)
+	Allowlist   []string      // Hosts known to be reachable, never checked (path.Match patterns)
+	CachePath   string        // Cache file ("" = DefaultCachePath)
+}
+
+// Allowed reports whether the host of remoteURL matches a pattern of allowlist,
+// such as "github.com" or "*.corp.example"
+func Allowed(remoteURL string, allowlist []string) bool {
+	host := git.RemoteHost(remoteURL)
+	if host == "" {
+		return false
+	}
+	for _, pattern := range allowlist {
+		if matched, err := path.Match(pattern, host); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheable reports whether a result is worth reusing: reachable remotes, and
+// remotes that are gone or refuse the credentials. DNS, network and timeout
+// failures may only mean that the machine is offline.
+func cacheable(err *git.RemoteError) bool {
+	return err == nil || err.Class == git.RemoteGone || err.Class == git.RemoteAuth
+}
+
+// Check checks the remote of every git project ("origin", or the first one) and
+// returns the unreachable ones by project index. Projects without remote, with an
+// allowlisted host or of another VCS are skipped, as are those failing to list
+// their remotes.
+func Check(ctx context.Context, projects []scanner.Project, opts Options) map[int]*git.RemoteError {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.CacheFor == 0 {
+		opts.CacheFor = DefaultCacheFor
+	}
+	if opts.CachePath == "" {
+		opts.CachePath, _ = DefaultCachePath()
+	}
+	cache := loadCache(opts.CachePath)
+
+	var mu sync.Mutex
+	unreachable := make(map[int]*git.RemoteError)
+	checker.ForEach(projects, opts.Concurrency, func(idx int, proj scanner.Project) {
+		repo, ok := proj.Repository.(*git.Repository)
+		if !ok || ctx.Err() != nil {
+			return
+		}
+		remoteErr, ok := checkRepository(ctx, repo, cache, opts)
+		if ok && remoteErr != nil {
+			mu.Lock()
+			unreachable[idx] = remoteErr
+			mu.Unlock()
+		}
+	})
+
+	if opts.CacheFor > 0 {
+		_ = cache.save(opts.CacheFor)
+	}
+	return unreachable
+}
+
+// checkRepository checks the remote of repo, through the cache.
+// It reports false when the remote was not checked.
+func checkRepository(ctx context.Context, repo *git.Repository, cache *cache, opts Options) (*git.RemoteError, bool) {
+	remoteCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	names, err := repo.Remotes(remoteCtx)
+	if err != nil || len(names) == 0 {
+		return nil, false
+	}
+	remote := names[0]
+	if slices.Contains(names, "origin") {
+		remote = "origin"
+	}
+	remoteURL, err := repo.RemoteURL(remoteCtx, remote)
+	if err != nil || Allowed(remoteURL, opts.Allowlist) {
+		return nil, false
+	}
+
+	if opts.CacheFor > 0 {
+		if entry, ok := cache.lookup(remoteURL, time.Now(), opts.CacheFor); ok {
+			return entry.remoteError(remote, remoteURL), true
+		}
+	}
+
+	err = repo.CheckRemote(remoteCtx, remote)
+	var remoteErr *git.RemoteError
+	if err != nil && !errors.As(err, &remoteErr) {
+		// Interrupted, or the remote vanished from the config meanwhile
+		return nil, false
+	}
+	if cacheable(remoteErr) {
+		cache.store(remoteURL, newCacheEntry(remoteErr, time.Now()))
+	}
+	return remoteErr, true
+}
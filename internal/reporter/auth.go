@@ -0,0 +1,66 @@
+package reporter
+
+import (
+	"fmt"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// AuthGroup is the projects whose credentials were refused by the same host
+type AuthGroup struct {
+	Host     string   // "" when unknown (Mercurial)
+	URL      string   // Remote URL of the first project, telling the kind of credentials
+	Projects []string // category/name
+}
+
+// GroupAuthFailures groups the remotes refusing credentials by host, in order of
+// first appearance. projects[i] names the project of remotes[i].
+func GroupAuthFailures(projects []string, remotes []*git.RemoteError) []AuthGroup {
+	var groups []AuthGroup
+	index := make(map[string]int)
+	for i, remote := range remotes {
+		host := git.RemoteHost(remote.URL)
+		at, ok := index[host]
+		if !ok {
+			at = len(groups)
+			index[host] = at
+			groups = append(groups, AuthGroup{Host: host, URL: remote.URL})
+		}
+		groups[at].Projects = append(groups[at].Projects, projects[i])
+	}
+	return groups
+}
+
+// Line summarizes the group, e.g. "3 repos failed auth against gitlab.internal — is your key loaded (ssh-add -l)?"
+func (g AuthGroup) Line() string {
+	host := g.Host
+	if host == "" {
+		host = "their remote"
+	}
+	repos := "repos"
+	if len(g.Projects) == 1 {
+		repos = "repo"
+	}
+	return fmt.Sprintf("%d %s failed auth against %s — %s", len(g.Projects), repos, host, git.AuthHint(g.URL))
+}
+
+// displayAuthRequired lists the projects whose remote refused the credentials, by host
+func (r *Reporter) displayAuthRequired(results []ProjectResult) {
+	if len(results) == 0 {
+		return
+	}
+	names := make([]string, len(results))
+	remotes := make([]*git.RemoteError, len(results))
+	for i, result := range results {
+		names[i] = result.Category + "/" + result.Name
+		remotes[i] = result.Status.Remote
+	}
+
+	fmt.Fprintf(r.out, "🔒 %s\n", underline("Authentication required"))
+	for _, group := range GroupAuthFailures(names, remotes) {
+		fmt.Fprintf(r.out, "  %s\n", yellow(group.Line()))
+		for _, name := range group.Projects {
+			fmt.Fprintf(r.out, "    %s %s\n", red("🔒"), name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package reporter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+func TestGroupAuthFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		projects []string
+		urls     []string
+		want     []AuthGroup
+	}{
+		{
+			name:     "none",
+			projects: nil,
+			urls:     nil,
+			want:     nil,
+		},
+		{
+			name:     "several hosts, in order of first appearance",
+			projects: []string{"work/api", "oss/lib", "work/web"},
+			urls:     []string{"git@gitlab.internal:team/api.git", "https://github.com/me/lib.git", "git@gitlab.internal:team/web.git"},
+			want: []AuthGroup{
+				{Host: "gitlab.internal", URL: "git@gitlab.internal:team/api.git", Projects: []string{"work/api", "work/web"}},
+				{Host: "github.com", URL: "https://github.com/me/lib.git", Projects: []string{"oss/lib"}},
+			},
+		},
+		{
+			name:     "same host over ssh and https",
+			projects: []string{"oss/lib", "oss/app", "oss/cli"},
+			urls:     []string{"https://github.com/me/lib.git", "git@github.com:me/app.git", "ssh://git@github.com/me/cli.git"},
+			want: []AuthGroup{
+				{Host: "github.com", URL: "https://github.com/me/lib.git", Projects: []string{"oss/lib", "oss/app", "oss/cli"}},
+			},
+		},
+		{
+			name:     "unparseable and local urls",
+			projects: []string{"work/broken", "work/api", "work/local"},
+			urls:     []string{"https://[::1", "git@gitlab.internal:team/api.git", "/srv/git/local.git"},
+			want: []AuthGroup{
+				{Host: "", URL: "https://[::1", Projects: []string{"work/broken", "work/local"}},
+				{Host: "gitlab.internal", URL: "git@gitlab.internal:team/api.git", Projects: []string{"work/api"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remotes := make([]*git.RemoteError, len(tt.urls))
+			for i, url := range tt.urls {
+				remotes[i] = &git.RemoteError{Remote: "origin", URL: url, Class: git.RemoteAuth}
+			}
+			if got := GroupAuthFailures(tt.projects, remotes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthGroupLine(t *testing.T) {
+	tests := []struct {
+		group AuthGroup
+		want  string
+	}{
+		{
+			AuthGroup{Host: "gitlab.internal", URL: "git@gitlab.internal:team/api.git", Projects: []string{"work/api", "work/web", "work/cli"}},
+			"3 repos failed auth against gitlab.internal — is your key loaded (ssh-add -l)?",
+		},
+		{
+			AuthGroup{Host: "github.com", URL: "https://github.com/me/lib.git", Projects: []string{"oss/lib"}},
+			"1 repo failed auth against github.com — are your credentials stored in a credential helper?",
+		},
+		{
+			AuthGroup{URL: "/srv/git/local.git", Projects: []string{"work/local"}},
+			"1 repo failed auth against their remote — is your key loaded (ssh-add -l)?",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.group.Line(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -2,10 +2,16 @@ package reporter
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/uralys/check-projects/internal/config"
 	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/sizes"
 )
 
 var (
@@ -15,12 +21,14 @@ var (
 	greenBold = color.New(color.FgGreen, color.Bold).SprintFunc()
 	redBold   = color.New(color.FgRed, color.Bold).SprintFunc()
 	underline = color.New(color.Bold, color.Underline).SprintFunc()
+	yellow    = color.New(color.FgYellow, color.Bold).SprintFunc()
 )
 
 // Reporter handles output formatting
 type Reporter struct {
 	config  *config.Config
 	verbose bool
+	out     io.Writer
 }
 
 // NewReporter creates a new Reporter
@@ -28,61 +36,171 @@ func NewReporter(cfg *config.Config, verbose bool) *Reporter {
 	return &Reporter{
 		config:  cfg,
 		verbose: verbose,
+		out:     os.Stdout,
 	}
 }
 
 // ProjectResult represents the result of checking a project
 type ProjectResult struct {
 	Name          string
+	Path          string
 	Status        *git.Status
 	Category      string
 	IsSymlink     bool
 	SymlinkTarget string
+	VCS           string    // Version control system ("git", "hg"), empty for a broken symlink
+	Changed       bool      // Status differs from the previous check (watch mode)
+	Active        bool      // HEAD has commits since the --since cutoff
+	LastCommit    time.Time // Date of the last commit, only loaded for --since and --sort age
+	SizeBytes     int64     // Disk usage, .git included (--sizes)
+	GitSizeBytes  int64     // Disk usage of the .git directory (--sizes)
 }
 
 // Report generates and displays the final report
 func (r *Reporter) Report(results []ProjectResult) {
-	// Group results by category
-	categoryResults := make(map[string][]ProjectResult)
+	sections := splitSections(results)
+
+	// Group results by category, in config order so that reports are stable (or by host)
+	categories, categoryResults := groupResults(sections.others, r.config.Display.GroupBy)
+
+	if r.allClean(sections) {
+		fmt.Fprintln(r.out, greenBold("✔ All projects are clean!"))
+	} else {
+		// Display results by category
+		for _, category := range categories {
+			r.displayCategory(category, categoryResults[category])
+		}
+	}
+	r.displaySections(sections, results)
+}
+
+// sections are the results of a report, split between the categories (others) and
+// the sections listed after them
+type sections struct {
+	unreachable, authRequired, denied, stale, unversioned, others []ProjectResult
+}
+
+// splitSections puts unreachable remotes, refused credentials, unreadable repositories,
+// stale projects and directories not under version control in their own sections,
+// listed after the categories
+func splitSections(results []ProjectResult) sections {
+	var s sections
 	for _, result := range results {
-		categoryResults[result.Category] = append(categoryResults[result.Category], result)
+		switch result.Status.Type {
+		case git.StatusRemoteUnreachable:
+			s.unreachable = append(s.unreachable, result)
+		case git.StatusAuthRequired:
+			s.authRequired = append(s.authRequired, result)
+		case git.StatusPermission:
+			s.denied = append(s.denied, result)
+		case git.StatusStale:
+			s.stale = append(s.stale, result)
+		case git.StatusUnversioned:
+			s.unversioned = append(s.unversioned, result)
+		default:
+			s.others = append(s.others, result)
+		}
 	}
+	return s
+}
 
-	// Check if all projects are clean
-	allClean := true
+// allClean reports whether the report comes down to "All projects are clean!": no
+// project needs attention (behind branches included) nor is highlighted, unless verbose
+func (r *Reporter) allClean(s sections) bool {
+	if r.verbose || len(s.unreachable) > 0 || len(s.authRequired) > 0 || len(s.denied) > 0 || len(s.unversioned) > 0 {
+		return false
+	}
+	for _, result := range s.others {
+		if !IsClean(result) {
+			return false
+		}
+	}
+	return !r.anyHighlighted(s.others)
+}
+
+// displaySections lists the sections following the categories
+func (r *Reporter) displaySections(s sections, results []ProjectResult) {
+	r.displayUnreachable(s.unreachable)
+	r.displayAuthRequired(s.authRequired)
+	r.displayPermissionDenied(s.denied)
+	r.displayStale(s.stale)
+	r.displayUnversioned(s.unversioned)
+	r.displayLargest(results)
+}
+
+// largestCount is the number of projects listed by displayLargest, unless verbose
+const largestCount = 10
+
+// displayLargest lists the projects by decreasing disk usage, when sizes were measured
+func (r *Reporter) displayLargest(results []ProjectResult) {
+	var measured []ProjectResult
 	for _, result := range results {
-		if result.Status.Type != git.StatusSync && result.Status.Type != git.StatusIgnored {
-			allClean = false
-			break
+		if result.SizeBytes > 0 {
+			measured = append(measured, result)
 		}
-		// Also check if there are behind branches
-		if len(result.Status.BehindBranches) > 0 {
-			allClean = false
-			break
+	}
+	if len(measured) == 0 {
+		return
+	}
+	sort.SliceStable(measured, func(i, j int) bool {
+		return measured[i].SizeBytes > measured[j].SizeBytes
+	})
+	if !r.verbose && len(measured) > largestCount {
+		measured = measured[:largestCount]
+	}
+
+	fmt.Fprintf(r.out, "%s\n", underline("Largest projects"))
+	for _, result := range measured {
+		vcs := result.VCS
+		if vcs == "" {
+			vcs = "git"
 		}
+		fmt.Fprintf(r.out, "  %9s  %s/%s (.%s %s)\n",
+			sizes.Format(result.SizeBytes), result.Category, result.Name, vcs, sizes.Format(result.GitSizeBytes))
+	}
+}
+
+// displayStale lists the clean projects without recent commits, with their age
+func (r *Reporter) displayStale(results []ProjectResult) {
+	if len(results) == 0 {
+		return
+	}
+	fmt.Fprintf(r.out, "🕓 %s\n", underline("Stale projects"))
+	for _, result := range results {
+		fmt.Fprintf(r.out, "  %s/%s - %s\n", result.Category, result.Name, result.Status.Message)
 	}
+}
 
-	if allClean && !r.verbose {
-		fmt.Println(greenBold("✔ All projects are clean!"))
+// displayUnversioned lists the directories under roots holding files but no repository
+func (r *Reporter) displayUnversioned(results []ProjectResult) {
+	if len(results) == 0 {
 		return
 	}
+	fmt.Fprintf(r.out, "%s %s\n", yellow(git.UnversionedSymbol), underline("Not under version control"))
+	for _, result := range results {
+		fmt.Fprintf(r.out, "  %s/%s - %s\n", result.Category, result.Name, config.ContractPath(result.Path))
+	}
+}
 
-	// Display results by category
-	for category, categoryProjects := range categoryResults {
-		r.displayCategory(category, categoryProjects)
+// displayUnreachable lists the projects whose remote cannot be reached, with the
+// remote URL and the class of the error
+func (r *Reporter) displayUnreachable(results []ProjectResult) {
+	if len(results) == 0 {
+		return
+	}
+	fmt.Fprintf(r.out, "%s %s\n", redBold("⊘"), underline("Unreachable remotes"))
+	for _, result := range results {
+		remote := result.Status.Remote
+		fmt.Fprintf(r.out, "  %s %s/%s - %s %s\n", red("⊘"), result.Category, result.Name, remote.Remote, remote.URL)
+		fmt.Fprintf(r.out, "    %s: %s\n", yellow(remote.Class), remote.Message)
 	}
 }
 
 func (r *Reporter) displayCategory(category string, results []ProjectResult) {
-	// Check if all projects in this category are clean
+	// Check if all projects in this category are clean (including behind branches)
 	allClean := true
 	for _, result := range results {
-		if result.Status.Type != git.StatusSync && result.Status.Type != git.StatusIgnored {
-			allClean = false
-			break
-		}
-		// Also check if there are behind branches
-		if len(result.Status.BehindBranches) > 0 {
+		if !IsClean(result) {
 			allClean = false
 			break
 		}
@@ -90,9 +208,9 @@ func (r *Reporter) displayCategory(category string, results []ProjectResult) {
 
 	// Display category header
 	if allClean {
-		fmt.Printf("%s %s\n", greenBold("✔"), greenBold(category))
+		fmt.Fprintf(r.out, "%s %s\n", greenBold("✔"), greenBold(category))
 	} else {
-		fmt.Printf("%s %s\n", redBold("x"), underline(category))
+		fmt.Fprintf(r.out, "%s %s\n", redBold("x"), underline(category))
 	}
 
 	// Display projects
@@ -103,22 +221,47 @@ func (r *Reporter) displayCategory(category string, results []ProjectResult) {
 				continue
 			}
 
-			// Skip clean projects unless verbose mode or they have behind branches
-			if r.config.Display.HideClean && !r.verbose && result.Status.Type == git.StatusSync && len(result.Status.BehindBranches) == 0 {
+			// Skip clean projects (bare ones included) unless verbose mode, they have behind branches or are highlighted
+			clean := (result.Status.Type == git.StatusSync && !result.Status.BranchesNeedAttention()) || result.Status.Type == git.StatusBare
+			if r.config.Display.HideClean && !r.verbose && clean && !r.isHighlighted(result) {
 				continue
 			}
 
 			r.displayProject(result)
 		}
-	} else if r.verbose {
-		// In verbose mode, show all projects even if category is clean
+	} else {
+		// In verbose mode, show all projects even if category is clean,
+		// otherwise only the highlighted ones
 		for _, result := range results {
 			if r.config.Display.HideIgnored && result.Status.Type == git.StatusIgnored {
 				continue
 			}
-			r.displayProject(result)
+			if r.verbose || r.isHighlighted(result) {
+				r.displayProject(result)
+			}
+		}
+	}
+}
+
+// isHighlighted reports whether a project is shown even when clean (changed in watch
+// mode, active, with stashes unless show_stashes is off, last fetched too long ago,
+// or bare unless display.bare only lists those with unpushed branches)
+func (r *Reporter) isHighlighted(result ProjectResult) bool {
+	listsBare := result.Status.Type == git.StatusBare && r.config.Display.Bare != config.BareUnpushed
+	return result.Changed || result.Active || r.showsStashes(result) || r.fetchIsStale(result) || result.Status.IdentityMismatch || listsBare
+}
+
+func (r *Reporter) fetchIsStale(result ProjectResult) bool {
+	return FetchIsStale(result.Status, r.config.Display.StaleFetchDays, time.Now())
+}
+
+func (r *Reporter) anyHighlighted(results []ProjectResult) bool {
+	for _, result := range results {
+		if r.isHighlighted(result) {
+			return true
 		}
 	}
+	return false
 }
 
 func (r *Reporter) displayProject(result ProjectResult) {
@@ -126,51 +269,386 @@ func (r *Reporter) displayProject(result ProjectResult) {
 	if result.IsSymlink && result.SymlinkTarget != "" {
 		displayName = fmt.Sprintf("%s -> %s", result.Name, result.SymlinkTarget)
 	}
+	if result.VCS != "" && result.VCS != "git" {
+		displayName += fmt.Sprintf(" (%s)", result.VCS)
+	}
+	if result.Status.Shallow {
+		displayName += " (shallow)" // Ahead and behind counts are unreliable
+	}
+	if result.Active {
+		displayName += " " + yellow("★")
+	}
+
+	// Projects whose status just changed are marked in the margin
+	lead := "  "
+	if result.Changed {
+		lead = yellow("Δ") + " "
+	}
 
 	switch result.Status.Type {
 	case git.StatusSync:
-		fmt.Printf("  %s %s\n", green(result.Status.Symbol), displayName)
+		fmt.Fprintf(r.out, "%s%s %s\n", lead, green(result.Status.Symbol), displayName)
 		r.displayBehindBranches(result)
 	case git.StatusUnsync:
-		if len(result.Status.Symbol) >= 3 && result.Status.Symbol[0:3] == "✱ " {
-			letter := result.Status.Symbol[len("✱ "):]
+		if symbol := ChangedSymbol(result.Status); strings.HasPrefix(symbol, "✱ ") {
+			letter := symbol[len("✱ "):]
 			if result.Status.Branch != "" {
-				fmt.Printf("  %s %s %s - %s\n", red("✱"), green(letter), displayName, blue(result.Status.Branch))
+				fmt.Fprintf(r.out, "%s%s %s %s - %s\n", lead, red("✱"), green(letter), displayName, coloredBranch(result.Status))
 			} else {
-				fmt.Printf("  %s %s %s\n", red("✱"), green(letter), displayName)
+				fmt.Fprintf(r.out, "%s%s %s %s\n", lead, red("✱"), green(letter), displayName)
 			}
 		} else if result.Status.Symbol == "⬆" && result.Status.Branch != "" {
-			fmt.Printf("  %s %s - %s\n", green(result.Status.Symbol), displayName, blue(result.Status.Branch))
+			fmt.Fprintf(r.out, "%s%s %s - %s\n", lead, green(CountedSymbol(result.Status)), displayName, coloredBranch(result.Status))
 		} else if result.Status.Branch != "" {
-			message := fmt.Sprintf("%s %s", result.Status.Symbol, displayName)
-			fmt.Printf("  %s - %s\n", red(message), blue(result.Status.Branch))
+			message := fmt.Sprintf("%s %s", ChangedSymbol(result.Status), displayName)
+			fmt.Fprintf(r.out, "%s%s - %s\n", lead, red(message), coloredBranch(result.Status))
 		} else {
-			message := fmt.Sprintf("%s %s", result.Status.Symbol, displayName)
-			fmt.Printf("  %s\n", red(message))
+			message := fmt.Sprintf("%s %s", ChangedSymbol(result.Status), displayName)
+			fmt.Fprintf(r.out, "%s%s\n", lead, red(message))
 		}
 		r.displayBehindBranches(result)
-	case git.StatusError:
+	case git.StatusError, git.StatusTimeout:
 		message := fmt.Sprintf("%s %s", result.Status.Symbol, displayName)
-		fmt.Printf("  %s\n", red(message))
+		fmt.Fprintf(r.out, "%s%s\n", lead, red(message))
+		r.displayBehindBranches(result)
+	case git.StatusLocked:
+		message := fmt.Sprintf("%s %s", result.Status.Symbol, displayName)
+		fmt.Fprintf(r.out, "%s%s - %s\n", lead, red(message), coloredBranch(result.Status))
+		fmt.Fprintf(r.out, "    %s\n", LockHint(result.Status, time.Now()))
 		r.displayBehindBranches(result)
 	case git.StatusBrokenSymlink:
 		message := fmt.Sprintf("🔗 ✗ %s (broken symlink)", displayName)
-		fmt.Printf("  %s\n", red(message))
-	case git.StatusNoUpstream:
+		fmt.Fprintf(r.out, "%s%s\n", lead, red(message))
+	case git.StatusNoUpstream, git.StatusNoRemote:
 		message := fmt.Sprintf("%s %s", result.Status.Symbol, displayName)
-		fmt.Printf("  %s\n", message)
+		fmt.Fprintf(r.out, "%s%s\n", lead, message)
 		r.displayBehindBranches(result)
+	case git.StatusEmpty:
+		message := fmt.Sprintf("%s %s", yellow(result.Status.Symbol), displayName)
+		if counts := ChangeCounts(result.Status); counts != "" {
+			message += " (" + counts + ")"
+		}
+		fmt.Fprintf(r.out, "%s%s - %s\n", lead, message, coloredBranch(result.Status))
+		if result.Status.IdentityMismatch {
+			fmt.Fprintf(r.out, "    %s %s\n", yellow(git.IdentitySymbol), IdentityLabel(result.Status))
+		}
 	default:
 		message := fmt.Sprintf("%s %s", result.Status.Symbol, displayName)
-		fmt.Printf("  %s\n", message)
+		fmt.Fprintf(r.out, "%s%s\n", lead, message)
 		r.displayBehindBranches(result)
 	}
 }
 
 func (r *Reporter) displayBehindBranches(result ProjectResult) {
+	if r.verbose && result.Status.DiffStat != nil {
+		fmt.Fprintf(r.out, "    %s\n", DiffStatLabel(*result.Status.DiffStat))
+	}
+	// A deleted upstream already suggests the default branch
+	if result.Status.GoneUpstream != "" {
+		fmt.Fprintf(r.out, "    %s %s\n", red(git.GoneSymbol), GoneLabel(result.Status))
+	} else if result.Status.OffDefaultBranch {
+		fmt.Fprintf(r.out, "    %s %s\n", yellow("⎇"), OffDefaultLabel(result.Status))
+	}
 	if len(result.Status.BehindBranches) > 0 {
 		for _, branch := range result.Status.BehindBranches {
-			fmt.Printf("    %s %s: %s\n", red("↓"), branch.Branch, branch.Message)
+			fmt.Fprintf(r.out, "    %s %s: %s\n", red("↓"), branch.Branch, branch.Message)
+		}
+	}
+	for _, branch := range result.Status.LocalOnlyBranches {
+		fmt.Fprintf(r.out, "    %s %s: %s\n", yellow("⬆"), branch.Branch, branch.Message)
+	}
+	for _, comparison := range result.Status.RemoteComparisons {
+		if comparison.Ahead > 0 || comparison.Behind > 0 {
+			fmt.Fprintf(r.out, "    %s %s\n", yellow("⇅"), ComparisonLabel(comparison))
 		}
 	}
+	for _, submodule := range result.Status.DirtySubmodules {
+		fmt.Fprintf(r.out, "    %s submodule %s\n", red("*"), submodule)
+	}
+	if len(result.Status.UnpushedTags) > 0 {
+		fmt.Fprintf(r.out, "    %s %s\n", yellow("🏷"), TagsLabel(len(result.Status.UnpushedTags)))
+	}
+	if result.Status.StashCount > 0 {
+		fmt.Fprintf(r.out, "    %s %s\n", yellow("⚑"), StashLabel(result.Status.StashCount))
+	}
+	if result.Status.IdentityMismatch {
+		fmt.Fprintf(r.out, "    %s %s\n", yellow(git.IdentitySymbol), IdentityLabel(result.Status))
+	}
+	if r.verbose && len(result.Status.MergedBranches) > 0 {
+		fmt.Fprintf(r.out, "    %s %s (%s)\n", green("✂"), MergedLabel(len(result.Status.MergedBranches)), strings.Join(result.Status.MergedBranches, ", "))
+	}
+	if r.verbose && result.Status.LastCommit != nil {
+		fmt.Fprintf(r.out, "    last commit %s\n", CommitLabel(result.Status.LastCommit, time.Now()))
+	}
+	if r.fetchIsStale(result) {
+		fmt.Fprintf(r.out, "    %s %s\n", yellow("⏳"), FetchedLabel(result.Status, time.Now()))
+	} else if r.verbose && !result.Status.LastFetched.IsZero() {
+		fmt.Fprintf(r.out, "    %s\n", FetchedLabel(result.Status, time.Now()))
+	}
+}
+
+// LockHint tells what blocks a locked repository and how to unblock it, e.g.
+// "/repo/.git/index.lock left 3 hours ago: rm it if no git process is running"
+func LockHint(status *git.Status, now time.Time) string {
+	if status.LockedAt.IsZero() {
+		return status.LockFile + " blocks git: rm it if no git process is running"
+	}
+	return fmt.Sprintf("%s left %s: rm it if no git process is running", status.LockFile, Ago(status.LockedAt, now))
+}
+
+// CountedSymbol is the symbol of a status, with the commit counts when it is ahead
+// of, behind or diverged from its upstream, e.g. "⬆ 3", "↓ 7" or "⬆ 3 ↓ 7"
+func CountedSymbol(status *git.Status) string {
+	switch status.Symbol {
+	case "⬆", "↓", "⬆⬆":
+	default:
+		return status.Symbol
+	}
+	var counts []string
+	if status.Ahead > 0 {
+		counts = append(counts, fmt.Sprintf("⬆ %d", status.Ahead))
+	}
+	if status.Behind > 0 {
+		counts = append(counts, fmt.Sprintf("↓ %d", status.Behind))
+	}
+	if len(counts) == 0 {
+		return status.Symbol // Counts unknown (e.g. Mercurial)
+	}
+	return strings.Join(counts, " ")
+}
+
+// ChangeCounts renders the changed files of a status by kind, e.g. "2+ 4M 1D 3?"
+// for staged, modified, deleted and untracked files ("" when none were counted)
+func ChangeCounts(status *git.Status) string {
+	var counts []string
+	for _, count := range []struct {
+		n    int
+		kind string
+	}{
+		{status.StagedCount, "+"},
+		{status.ModifiedCount, "M"},
+		{status.DeletedCount, "D"},
+		{status.UntrackedCount, "?"},
+	} {
+		if count.n > 0 {
+			counts = append(counts, fmt.Sprintf("%d%s", count.n, count.kind))
+		}
+	}
+	return strings.Join(counts, " ")
+}
+
+// ChangedSymbol is the symbol of a status with local changes followed by their
+// counts, e.g. "* 4M 2?" or "✱ 2+ 1?", or else CountedSymbol
+func ChangedSymbol(status *git.Status) string {
+	switch status.Symbol {
+	case "* M", "* D", "✱ ✚", "✱ +", "✱ R", "✱":
+		if counts := ChangeCounts(status); counts != "" {
+			head, _, _ := strings.Cut(status.Symbol, " ")
+			return head + " " + counts
+		}
+	}
+	return CountedSymbol(status)
+}
+
+// ChangeSummary spells out the changed files of a status by kind, e.g.
+// "2 staged, 4 modified, 1 deleted, 3 untracked" ("" when none were counted)
+func ChangeSummary(status *git.Status) string {
+	var counts []string
+	for _, count := range []struct {
+		n    int
+		kind string
+	}{
+		{status.StagedCount, "staged"},
+		{status.ModifiedCount, "modified"},
+		{status.DeletedCount, "deleted"},
+		{status.UntrackedCount, "untracked"},
+	} {
+		if count.n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", count.n, count.kind))
+		}
+	}
+	return strings.Join(counts, ", ")
+}
+
+// CompactChangeCounts renders the changed files of a status in short, tracked ones
+// then untracked ones, e.g. "4±, 2?" ("" when none were counted)
+func CompactChangeCounts(status *git.Status) string {
+	var counts []string
+	if tracked := status.Changes - status.UntrackedCount; tracked > 0 && ChangeCounts(status) != "" {
+		counts = append(counts, fmt.Sprintf("%d±", tracked))
+	}
+	if status.UntrackedCount > 0 {
+		counts = append(counts, fmt.Sprintf("%d?", status.UntrackedCount))
+	}
+	return strings.Join(counts, ", ")
+}
+
+// DiffStatLabel summarizes the lines changed in tracked files, e.g. "3 files changed,
+// +120 −45 (staged: 1 file, +10)"; binary files change files without lines
+// ("" when nothing tracked changed)
+func DiffStatLabel(stat git.DiffStat) string {
+	label := diffCountsLabel(stat.Unstaged, " changed")
+	if staged := diffCountsLabel(stat.Staged, ""); staged != "" {
+		if label == "" {
+			return "staged: " + staged
+		}
+		label += " (staged: " + staged + ")"
+	}
+	return label
+}
+
+// diffCountsLabel renders counts as e.g. "3 files<suffix>, +120 −45" ("" without files)
+func diffCountsLabel(counts git.DiffCounts, suffix string) string {
+	if counts.Files == 0 {
+		return ""
+	}
+	label := "1 file" + suffix
+	if counts.Files > 1 {
+		label = fmt.Sprintf("%d files%s", counts.Files, suffix)
+	}
+	var lines []string
+	if counts.Insertions > 0 {
+		lines = append(lines, fmt.Sprintf("+%d", counts.Insertions))
+	}
+	if counts.Deletions > 0 {
+		lines = append(lines, fmt.Sprintf("−%d", counts.Deletions))
+	}
+	if len(lines) > 0 {
+		label += ", " + strings.Join(lines, " ")
+	}
+	return label
+}
+
+// BranchLabel names the branch of a status, or the commit of a detached HEAD
+// (e.g. "detached at a1b2c3d")
+func BranchLabel(status *git.Status) string {
+	if status.DetachedAt != "" {
+		return "detached at " + status.DetachedAt
+	}
+	return status.Branch
+}
+
+// coloredBranch is BranchLabel in blue, or in yellow when detached
+func coloredBranch(status *git.Status) string {
+	if status.DetachedAt != "" {
+		return yellow(BranchLabel(status))
+	}
+	return blue(status.Branch)
+}
+
+// ComparisonLabel tells how far HEAD is from a remote, e.g.
+// "upstream/main: behind by 40 commit(s)" or "origin/main: in sync"
+func ComparisonLabel(comparison git.RemoteComparison) string {
+	switch {
+	case comparison.Ahead > 0 && comparison.Behind > 0:
+		return fmt.Sprintf("%s: behind by %d, ahead by %d commit(s)", comparison.Ref, comparison.Behind, comparison.Ahead)
+	case comparison.Behind > 0:
+		return fmt.Sprintf("%s: behind by %d commit(s)", comparison.Ref, comparison.Behind)
+	case comparison.Ahead > 0:
+		return fmt.Sprintf("%s: ahead by %d commit(s)", comparison.Ref, comparison.Ahead)
+	default:
+		return comparison.Ref + ": in sync"
+	}
+}
+
+// OriginLabel tells where a git repository pushes to, e.g. "github.com/uralys/check-projects",
+// naming the remote unless it is origin, or "no remote". It returns "" when the status
+// was not read (errors, timeouts), leaving the remote unknown.
+func OriginLabel(status *git.Status) string {
+	if status.Branch == "" && !status.Bare {
+		return ""
+	}
+	if status.Origin == nil {
+		return "no remote"
+	}
+	if status.Origin.Name != "origin" {
+		return fmt.Sprintf("%s (%s)", status.Origin, status.Origin.Name)
+	}
+	return status.Origin.String()
+}
+
+// OffDefaultLabel tells that a project is not on the default branch of its remote,
+// e.g. "on feature/foo, default is main"
+func OffDefaultLabel(status *git.Status) string {
+	return fmt.Sprintf("on %s, default is %s", status.Branch, status.DefaultBranch)
+}
+
+// GoneLabel tells that the upstream of the current branch was deleted on the remote,
+// suggesting the default branch when known, e.g.
+// "remote branch origin/topic deleted, consider switching to main"
+func GoneLabel(status *git.Status) string {
+	label := "remote branch " + status.GoneUpstream + " deleted"
+	if status.DefaultBranch != "" && status.DefaultBranch != status.Branch {
+		label += ", consider switching to " + status.DefaultBranch
+	}
+	return label
+}
+
+// CommitLabel tells how old a commit is and what it is, e.g.
+// "3 days ago: Fix the parser (Ada, a1b2c3d)"
+func CommitLabel(commit *git.CommitInfo, now time.Time) string {
+	return fmt.Sprintf("%s: %s (%s, %s)", Ago(commit.Date, now), commit.Subject, commit.Author, commit.Hash)
+}
+
+// FetchedLabel tells when a project was last fetched, e.g. "fetched 12 days ago"
+func FetchedLabel(status *git.Status, now time.Time) string {
+	return "fetched " + Ago(status.LastFetched, now)
+}
+
+// FetchIsStale reports whether a project was last fetched more than days ago
+// (display.stale_fetch_days). It never is when days is 0, or when the project
+// has no remote or was never fetched.
+func FetchIsStale(status *git.Status, days int, now time.Time) bool {
+	if days <= 0 || status == nil || status.Origin == nil || status.LastFetched.IsZero() {
+		return false
+	}
+	return now.Sub(status.LastFetched) > time.Duration(days)*24*time.Hour
+}
+
+// Ago renders the time elapsed since t, e.g. "3 days ago" or "just now"
+func Ago(t, now time.Time) string {
+	age := now.Sub(t)
+	if age < time.Minute {
+		return "just now"
+	}
+	return git.FormatAge(age) + " ago"
+}
+
+// showsStashes reports whether a clean project is shown for its stashes
+func (r *Reporter) showsStashes(result ProjectResult) bool {
+	return r.config.Display.ShowStashes && result.Status.StashCount > 0
+}
+
+// IdentityLabel tells which user.email commits are made with when it is not the
+// expected one, e.g. "identity mismatch: personal@gmail.com"
+func IdentityLabel(status *git.Status) string {
+	if status.UserEmail == "" {
+		return "identity mismatch: user.email is not set"
+	}
+	return "identity mismatch: " + status.UserEmail
+}
+
+// StashLabel counts stashes, e.g. "2 stashes"
+func StashLabel(count int) string {
+	if count == 1 {
+		return "1 stash"
+	}
+	return fmt.Sprintf("%d stashes", count)
+}
+
+// MergedLabel tells how many local branches are merged into the default branch,
+// e.g. "3 merged branches can be deleted"
+func MergedLabel(count int) string {
+	if count == 1 {
+		return "1 merged branch can be deleted"
+	}
+	return fmt.Sprintf("%d merged branches can be deleted", count)
+}
+
+// TagsLabel tells how many local tags the remote does not have, e.g. "2 unpushed tags"
+func TagsLabel(count int) string {
+	if count == 1 {
+		return "1 unpushed tag"
+	}
+	return fmt.Sprintf("%d unpushed tags", count)
 }
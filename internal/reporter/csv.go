@@ -0,0 +1,31 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// CSVReporter writes results as CSV with a header row
+type CSVReporter struct {
+	out io.Writer
+}
+
+// Report writes the results as CSV
+func (r *CSVReporter) Report(results []ProjectResult) {
+	w := csv.NewWriter(r.out)
+	_ = w.Write([]string{"category", "name", "path", "status", "symbol", "branch", "message"})
+
+	for _, result := range results {
+		_ = w.Write([]string{
+			result.Category,
+			result.Name,
+			result.Path,
+			string(result.Status.Type),
+			result.Status.Symbol,
+			result.Status.Branch,
+			result.Status.Message,
+		})
+	}
+
+	w.Flush()
+}
@@ -0,0 +1,132 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// DetailReporter prints the full detail of each project, without category grouping
+type DetailReporter struct {
+	out io.Writer
+}
+
+// NewDetailReporter creates a DetailReporter writing to w
+func NewDetailReporter(w io.Writer) *DetailReporter {
+	return &DetailReporter{out: w}
+}
+
+// Report prints one detail block per project
+func (r *DetailReporter) Report(results []ProjectResult) {
+	for i, result := range results {
+		if i > 0 {
+			fmt.Fprintln(r.out)
+		}
+		r.displayDetail(result)
+	}
+}
+
+// ShallowNote warns that the counts of a shallow clone are unreliable
+const ShallowNote = "shallow clone, ahead and behind counts are unreliable"
+
+func (r *DetailReporter) displayDetail(result ProjectResult) {
+	fmt.Fprintf(r.out, "%s %s\n", underline(result.Name), fmt.Sprintf("(%s)", result.Category))
+	fmt.Fprintf(r.out, "  Path:     %s\n", result.Path)
+	if result.IsSymlink && result.SymlinkTarget != "" {
+		fmt.Fprintf(r.out, "  Target:   %s\n", result.SymlinkTarget)
+	}
+	if result.VCS != "" && result.VCS != "git" {
+		fmt.Fprintf(r.out, "  VCS:      %s\n", result.VCS)
+	}
+	if origin := OriginLabel(result.Status); result.VCS == "git" && origin != "" {
+		fmt.Fprintf(r.out, "  Origin:   %s\n", origin)
+	}
+
+	status := fmt.Sprintf("%s %s", result.Status.Symbol, result.Status.Message)
+	switch result.Status.Type {
+	case git.StatusSync:
+		status = green(status)
+	case git.StatusUnsync, git.StatusError, git.StatusBrokenSymlink, git.StatusTimeout, git.StatusRemoteUnreachable, git.StatusAuthRequired, git.StatusPermission, git.StatusLocked:
+		status = red(status)
+	case git.StatusStale:
+		status = yellow(status)
+	}
+	fmt.Fprintf(r.out, "  Status:   %s\n", status)
+	if changes := ChangeSummary(result.Status); changes != "" {
+		fmt.Fprintf(r.out, "  Changes:  %s\n", changes)
+	}
+	if remote := result.Status.Remote; remote != nil {
+		fmt.Fprintf(r.out, "  Remote:   %s %s: %s\n", remote.Remote, remote.URL, remote.Message)
+	}
+	if result.Status.Type == git.StatusPermission {
+		fmt.Fprintf(r.out, "  Fix:      %s\n", PermissionHint(result.Status.Path))
+	}
+	if result.Status.Type == git.StatusLocked {
+		fmt.Fprintf(r.out, "  Fix:      %s\n", LockHint(result.Status, time.Now()))
+	}
+
+	if result.Status.Branch != "" {
+		fmt.Fprintf(r.out, "  Branch:   %s\n", coloredBranch(result.Status))
+	}
+	if result.Status.GoneUpstream != "" {
+		fmt.Fprintf(r.out, "  Upstream: %s\n", red(GoneLabel(result.Status)))
+	}
+	if result.Status.OffDefaultBranch && result.Status.GoneUpstream == "" {
+		fmt.Fprintf(r.out, "  Default:  %s\n", yellow(result.Status.DefaultBranch+", not checked out"))
+	}
+	if result.Status.IdentityMismatch {
+		identity := "user.email is not set"
+		if result.Status.UserEmail != "" {
+			identity = result.Status.UserEmail + ", not an expected_email"
+		}
+		fmt.Fprintf(r.out, "  Identity: %s\n", yellow(identity))
+	}
+	if result.Status.Shallow {
+		fmt.Fprintf(r.out, "  History:  %s\n", yellow(ShallowNote))
+	}
+	if !result.Status.LastFetched.IsZero() {
+		fmt.Fprintf(r.out, "  Fetched:  %s\n", Ago(result.Status.LastFetched, time.Now()))
+	}
+
+	if len(result.Status.BehindBranches) > 0 {
+		fmt.Fprintln(r.out, "  Branches behind remote:")
+		for _, branch := range result.Status.BehindBranches {
+			fmt.Fprintf(r.out, "    %s %s: %s\n", red("↓"), branch.Branch, branch.Message)
+		}
+	}
+	if len(result.Status.LocalOnlyBranches) > 0 {
+		fmt.Fprintln(r.out, "  Local-only branches:")
+		for _, branch := range result.Status.LocalOnlyBranches {
+			fmt.Fprintf(r.out, "    %s %s: %s\n", yellow("⬆"), branch.Branch, branch.Message)
+		}
+	}
+	if len(result.Status.MergedBranches) > 0 {
+		fmt.Fprintf(r.out, "  Merged into %s, safe to delete:\n", result.Status.MergedInto)
+		for _, branch := range result.Status.MergedBranches {
+			fmt.Fprintf(r.out, "    %s %s\n", green("✂"), branch)
+		}
+	}
+	if len(result.Status.UnpushedTags) > 0 {
+		fmt.Fprintln(r.out, "  Unpushed tags:")
+		for _, tag := range result.Status.UnpushedTags {
+			fmt.Fprintf(r.out, "    %s %s\n", yellow("🏷"), tag)
+		}
+	}
+	if len(result.Status.RemoteComparisons) > 0 {
+		fmt.Fprintln(r.out, "  Remotes:")
+		for _, comparison := range result.Status.RemoteComparisons {
+			fmt.Fprintf(r.out, "    %s %s\n", yellow("⇅"), ComparisonLabel(comparison))
+		}
+	}
+	if len(result.Status.DirtySubmodules) > 0 {
+		fmt.Fprintln(r.out, "  Dirty submodules:")
+		for _, submodule := range result.Status.DirtySubmodules {
+			fmt.Fprintf(r.out, "    %s %s\n", red("*"), submodule)
+		}
+	}
+	if result.Status.StashCount > 0 {
+		fmt.Fprintf(r.out, "  Stashes:  %d\n", result.Status.StashCount)
+	}
+}
@@ -0,0 +1,50 @@
+package reporter
+
+import "github.com/uralys/check-projects/internal/git"
+
+// Exit codes returned with --exit-code, the highest applicable one wins
+const (
+	ExitClean   = 0 // every project is clean or ignored
+	ExitChanges = 1 // at least one project has changes, is ahead/behind, lacks an upstream or a remote, has an unreachable remote or refused credentials, or is not under version control
+	ExitErrors  = 2 // at least one project could not be checked
+)
+
+// IsClean reports whether a result needs no attention, including its other branches
+// (errored results are not clean, stale ones and empty repositories without files are)
+func IsClean(result ProjectResult) bool {
+	switch result.Status.Type {
+	case git.StatusSync, git.StatusIgnored, git.StatusStale, git.StatusBare:
+	case git.StatusEmpty:
+		return result.Status.Changes == 0 // Nothing to commit yet
+	default:
+		return false
+	}
+	return !result.Status.BranchesNeedAttention()
+}
+
+// isErrored reports whether a result could not be checked
+func isErrored(result ProjectResult) bool {
+	return IsErrorType(result.Status.Type)
+}
+
+// IsErrorType reports whether statusType means the project could not be checked
+func IsErrorType(statusType git.StatusType) bool {
+	switch statusType {
+	case git.StatusError, git.StatusBrokenSymlink, git.StatusTimeout, git.StatusPermission, git.StatusLocked:
+		return true
+	}
+	return false
+}
+
+// ExitCode maps results to the --exit-code value
+func ExitCode(results []ProjectResult) int {
+	summary := Summarize(results)
+	switch {
+	case summary.Errors > 0:
+		return ExitErrors
+	case summary.NeedAttention() > 0:
+		return ExitChanges
+	default:
+		return ExitClean
+	}
+}
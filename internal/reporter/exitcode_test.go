@@ -0,0 +1,136 @@
+package reporter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/gittest"
+)
+
+// fixtures build repositories in the states exit codes tell apart
+var fixtures = map[string]func(t *testing.T) *gittest.Repo{
+	"clean": func(t *testing.T) *gittest.Repo {
+		return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll()
+	},
+	"dirty": func(t *testing.T) *gittest.Repo {
+		return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Modify("README.md")
+	},
+	"ahead": func(t *testing.T) *gittest.Repo {
+		return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Ahead(1)
+	},
+	"behind": func(t *testing.T) *gittest.Repo {
+		return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Behind(1)
+	},
+	"other branch behind": func(t *testing.T) *gittest.Repo {
+		r := gittest.NewRepo(t).Commit("README.md").WithBareRemote().Branch("topic").PushAll().Checkout(gittest.DefaultBranch)
+		return r.CommitOnRemoteBranch("topic")
+	},
+	"error": func(t *testing.T) *gittest.Repo {
+		return gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().WriteFile(filepath.Join(".git", "index"), "not an index")
+	},
+}
+
+// fixtureResults checks a repository built by each of the named fixtures
+func fixtureResults(t *testing.T, names ...string) []ProjectResult {
+	t.Helper()
+	results := make([]ProjectResult, len(names))
+	for i, name := range names {
+		r := fixtures[name](t)
+		status, err := r.Repository().GetStatus(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		results[i] = ProjectResult{Name: name, Path: r.Path, Category: "fixtures", Status: status}
+	}
+	return results
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixtures []string
+		want     int
+	}{
+		{"none", nil, ExitClean},
+		{"clean", []string{"clean", "clean"}, ExitClean},
+		{"dirty", []string{"clean", "dirty"}, ExitChanges},
+		{"ahead", []string{"ahead", "clean"}, ExitChanges},
+		{"behind", []string{"behind"}, ExitChanges},
+		{"other branch behind", []string{"other branch behind"}, ExitChanges},
+		{"error", []string{"clean", "error"}, ExitErrors},
+		{"mixed: errors win over changes", []string{"dirty", "error", "behind", "clean"}, ExitErrors},
+		{"mixed: changes win over clean", []string{"clean", "behind", "clean"}, ExitChanges},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := fixtureResults(t, tt.fixtures...)
+			if got := ExitCode(results); got != tt.want {
+				t.Errorf("exit code %d, want %d (%+v)", got, tt.want, Summarize(results))
+			}
+		})
+	}
+}
+
+func TestSummarizeFixtures(t *testing.T) {
+	results := fixtureResults(t, "clean", "dirty", "ahead", "behind", "other branch behind", "error")
+	want := Summary{Total: 6, Clean: 1, Changes: 2, Behind: 2, Errors: 1}
+	if got := Summarize(results); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	behindBranches := []git.BranchTracking{{Branch: "topic", Message: "behind by 1 commit(s)"}}
+	localOnly := []git.BranchTracking{{Branch: "spike", Message: "1 local-only commit(s)"}}
+	tests := []struct {
+		name   string
+		status git.Status
+		want   Summary
+		code   int
+	}{
+		{"sync", git.Status{Type: git.StatusSync, Symbol: "✔"}, Summary{Clean: 1}, ExitClean},
+		{"ignored", git.Status{Type: git.StatusIgnored}, Summary{Clean: 1}, ExitClean},
+		{"bare", git.Status{Type: git.StatusBare}, Summary{Clean: 1}, ExitClean},
+		{"empty", git.Status{Type: git.StatusEmpty}, Summary{Clean: 1}, ExitClean},
+		{"empty with files", git.Status{Type: git.StatusEmpty, Changes: 1}, Summary{Changes: 1}, ExitChanges},
+		{"stale", git.Status{Type: git.StatusStale}, Summary{Stale: 1}, ExitClean},
+		{"changes", git.Status{Type: git.StatusUnsync, Symbol: "* M"}, Summary{Changes: 1}, ExitChanges},
+		{"behind", git.Status{Type: git.StatusUnsync, Symbol: "↓"}, Summary{Behind: 1}, ExitChanges},
+		{"other branch behind", git.Status{Type: git.StatusSync, Symbol: "✔", BehindBranches: behindBranches}, Summary{Behind: 1}, ExitChanges},
+		{"local-only branch", git.Status{Type: git.StatusSync, Symbol: "✔", LocalOnlyBranches: localOnly}, Summary{Changes: 1}, ExitChanges},
+		{"no upstream", git.Status{Type: git.StatusNoUpstream}, Summary{NoUpstream: 1}, ExitChanges},
+		{"no remote", git.Status{Type: git.StatusNoRemote}, Summary{NoRemote: 1}, ExitChanges},
+		{"unversioned", git.Status{Type: git.StatusUnversioned}, Summary{Unversioned: 1}, ExitChanges},
+		{"remote unreachable", git.Status{Type: git.StatusRemoteUnreachable}, Summary{Unreachable: 1}, ExitChanges},
+		{"auth required", git.Status{Type: git.StatusAuthRequired}, Summary{AuthRequired: 1}, ExitChanges},
+		{"unchecked", git.Status{Type: git.StatusUnchecked}, Summary{Unchecked: 1}, ExitClean},
+		{"error", git.Status{Type: git.StatusError}, Summary{Errors: 1}, ExitErrors},
+		{"timeout", git.Status{Type: git.StatusTimeout}, Summary{Errors: 1}, ExitErrors},
+		{"broken symlink", git.Status{Type: git.StatusBrokenSymlink}, Summary{Errors: 1}, ExitErrors},
+		{"permission", git.Status{Type: git.StatusPermission}, Summary{Errors: 1}, ExitErrors},
+		{"locked", git.Status{Type: git.StatusLocked}, Summary{Errors: 1}, ExitErrors},
+	}
+	covered := make(map[git.StatusType]bool)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			results := []ProjectResult{{Name: tt.name, Status: &status}}
+			want := tt.want
+			want.Total = 1
+			if got := Summarize(results); got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+			if got := ExitCode(results); got != tt.code {
+				t.Errorf("exit code %d, want %d", got, tt.code)
+			}
+		})
+		covered[tt.status.Type] = true
+	}
+	for _, statusType := range git.StatusTypes {
+		if !covered[statusType] {
+			t.Errorf("no test summarizes a %s status", statusType)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package reporter
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata with the current output")
+
+// golden compares got with the content of testdata/name, or rewrites it with -update
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run go test -update if the change is intended):\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// goldenResults is a fixed set of results covering the usual statuses, in two categories
+func goldenResults() []ProjectResult {
+	return []ProjectResult{
+		{Name: "api", Category: "work", Path: "/home/me/work/api", VCS: "git", Status: &git.Status{
+			Type: git.StatusSync, Message: "Up to date", Symbol: "✔", Branch: "main",
+		}},
+		{Name: "web", Category: "work", Path: "/home/me/work/web", VCS: "git", Status: &git.Status{
+			Type: git.StatusUnsync, Message: "Modified files", Symbol: "* M", Branch: "feature/login",
+			Changes: 2, ModifiedCount: 2, StashCount: 1,
+		}},
+		{Name: "infra", Category: "work", Path: "/home/me/work/infra", VCS: "git", Status: &git.Status{
+			Type: git.StatusUnsync, Message: "Ahead 2, behind 1", Symbol: "⬆⬆", Branch: "main", Ahead: 2, Behind: 1,
+			BehindBranches: []git.BranchTracking{{Branch: "release", Message: "behind by 3 commit(s)"}},
+		}},
+		{Name: "blog", Category: "personal", Path: "/home/me/personal/blog", VCS: "git", Status: &git.Status{
+			Type: git.StatusNoUpstream, Message: "No upstream branch", Symbol: "⚠ No upstream", Branch: "drafts",
+		}},
+		{Name: "notes", Category: "personal", Path: "/home/me/personal/notes", VCS: "hg", Status: &git.Status{
+			Type: git.StatusUnsync, Message: "Untracked files", Symbol: "✱ ✚", Branch: "default", Changes: 1, UntrackedCount: 1,
+		}},
+		{Name: "broken", Category: "personal", Path: "/home/me/personal/broken", VCS: "git", Status: &git.Status{
+			Type: git.StatusError, Message: "Error: not a git repository", Symbol: "❌",
+		}},
+	}
+}
+
+func TestReportGolden(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var out bytes.Buffer
+			rep, err := New(format, config.DefaultConfig(), true, &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prometheus, ok := rep.(*PrometheusReporter); ok {
+				prometheus.now = func() time.Time { return time.Unix(1700000000, 0) }
+			}
+			rep.Report(goldenResults())
+			golden(t, "report."+format+".golden", out.Bytes())
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// Sections of the text and markdown reports, as accepted by --group-by and display.group_by
+const (
+	GroupByCategory = "category" // One section per category (default)
+	GroupByHost     = "host"     // One section per host of the remote pushed to
+)
+
+// Groupings lists every supported grouping
+var Groupings = []string{GroupByCategory, GroupByHost}
+
+// Sections of GroupByHost for projects not pushing to a host
+const (
+	HostLocal    = "local"     // The remote is a local path or a file:// URL
+	HostNoRemote = "no remote" // No remote, or unknown (e.g. unreadable repositories)
+)
+
+// ValidateGroupBy returns an error listing the valid groupings when by is unknown
+// ("" is the default, GroupByCategory)
+func ValidateGroupBy(by string) error {
+	if by == "" {
+		return nil
+	}
+	for _, g := range Groupings {
+		if g == by {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid grouping '%s' (valid: %s)", by, strings.Join(Groupings, ", "))
+}
+
+// HostLabel names the host a project pushes to, e.g. "github.com", or else
+// HostLocal or HostNoRemote
+func HostLabel(status *git.Status) string {
+	switch {
+	case status.Origin == nil:
+		return HostNoRemote
+	case status.Origin.Host == "":
+		return HostLocal
+	}
+	return status.Origin.Host
+}
+
+// groupResults groups results into sections: by category in the order they first
+// appear, or with GroupByHost by host in alphabetical order, local and no remote last.
+// Results keep their order within each section.
+func groupResults(results []ProjectResult, by string) ([]string, map[string][]ProjectResult) {
+	if by != GroupByHost {
+		return groupByCategory(results)
+	}
+
+	var hosts []string
+	groups := make(map[string][]ProjectResult)
+	for _, result := range results {
+		host := HostLabel(result.Status)
+		if _, ok := groups[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		groups[host] = append(groups[host], result)
+	}
+	last := map[string]int{HostLocal: 1, HostNoRemote: 2}
+	sort.SliceStable(hosts, func(i, j int) bool {
+		if last[hosts[i]] != last[hosts[j]] {
+			return last[hosts[i]] < last[hosts[j]]
+		}
+		return hosts[i] < hosts[j]
+	})
+	return hosts, groups
+}
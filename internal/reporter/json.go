@@ -0,0 +1,165 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// JSONReporter writes results as a single JSON document
+type JSONReporter struct {
+	out io.Writer
+}
+
+// JSONReport is the document written by --output json.
+// The desc and enum tags document it in its JSON Schema (check-projects schema report).
+type JSONReport struct {
+	Projects []JSONProject `json:"projects" desc:"Checked projects, in report order"`
+}
+
+// JSONProject is a single project entry in a JSONReport
+type JSONProject struct {
+	Name              string           `json:"name" desc:"Project name"`
+	Category          string           `json:"category" desc:"Category the project belongs to"`
+	Path              string           `json:"path" desc:"Absolute path of the working copy"`
+	Status            git.StatusType   `json:"status" desc:"Status type"`
+	Message           string           `json:"message" desc:"Human-readable status"`
+	Symbol            string           `json:"symbol" desc:"Symbol of the status in the text report"`
+	Branch            string           `json:"branch,omitempty" desc:"Current branch, HEAD when detached"`
+	DetachedAt        string           `json:"detached_at,omitempty" desc:"Short commit of a detached HEAD"`
+	BehindBranches    []JSONBranch     `json:"behind_branches,omitempty" desc:"Other branches behind their remote"`
+	LocalOnlyBranches []JSONBranch     `json:"local_only_branches,omitempty" desc:"Branches without upstream holding commits no remote has"`
+	RemoteComparisons []JSONComparison `json:"remote_comparisons,omitempty" desc:"Current branch compared with the branches of other remotes (compare_remotes)"`
+	SymlinkTarget     string           `json:"symlink_target,omitempty" desc:"Target of the project when it is a symlink"`
+	Remote            *JSONRemote      `json:"remote,omitempty" desc:"Unreachable remote (remote_unreachable), or refusing the credentials (auth_required)"`
+	Origin            *JSONOrigin      `json:"origin,omitempty" desc:"Remote the repository pushes to, origin or else the first one (absent without remote)"`
+	GoneUpstream      string           `json:"gone_upstream,omitempty" desc:"Upstream of the current branch deleted on the remote, e.g. origin/topic"`
+	DefaultBranch     string           `json:"default_branch,omitempty" desc:"Default branch of the origin remote, absent when unknown"`
+	OffDefaultBranch  bool             `json:"off_default_branch,omitempty" desc:"The current branch is not the default branch (default_branch)"`
+	MergedBranches    []string         `json:"merged_branches,omitempty" desc:"Local branches merged into the default branch, safe to delete (merged_branches)"`
+	MergedInto        string           `json:"merged_into,omitempty" desc:"Remote-tracking branch merged_branches are merged into, e.g. origin/main"`
+	UnpushedTags      []string         `json:"unpushed_tags,omitempty" desc:"Local tags the remote does not have (unpushed_tags)"`
+	LastFetched       string           `json:"last_fetched,omitempty" desc:"When the repository was last fetched (RFC 3339), absent when never fetched"`
+	LockFile          string           `json:"lock_file,omitempty" desc:"Lock file blocking git (locked)"`
+	UserEmail         string           `json:"user_email,omitempty" desc:"user.email commits are made with, when checked (expected_email)"`
+	IdentityMismatch  bool             `json:"identity_mismatch,omitempty" desc:"user.email is unset or matches none of the expected_email patterns"`
+	DeniedPath        string           `json:"denied_path,omitempty" desc:"Path the current user cannot read (permission)"`
+	StashCount        int              `json:"stash_count,omitempty" desc:"Entries of the stash"`
+	Operation         git.Operation    `json:"operation,omitempty" desc:"Operation left in progress" enum:"merge,rebase,cherry-pick"`
+	DirtySubmodules   []string         `json:"dirty_submodules,omitempty" desc:"Submodules with a new commit or changes of their own"`
+	Bare              bool             `json:"bare,omitempty" desc:"Bare repository, without working tree"`
+	Shallow           bool             `json:"shallow,omitempty" desc:"Shallow clone: ahead and behind counts are unreliable"`
+	VCS               string           `json:"vcs,omitempty" desc:"Version control system of the working copy" enum:"git,hg"`
+	SizeBytes         int64            `json:"size_bytes,omitempty" desc:"Disk usage, .git included (--sizes)"`
+	GitSizeBytes      int64            `json:"git_size_bytes,omitempty" desc:"Disk usage of the .git (or .hg) directory (--sizes)"`
+	Changed           bool             `json:"changed,omitempty" desc:"Status differs from the previous check (--watch)"`
+	Active            bool             `json:"active,omitempty" desc:"HEAD has commits since the --since cutoff"`
+}
+
+// JSONBranch is a branch tracking entry in a JSONProject
+type JSONBranch struct {
+	Branch  string `json:"branch" desc:"Branch name"`
+	Message string `json:"message" desc:"How far it is behind its remote, or its local-only commits"`
+}
+
+// JSONComparison compares the current branch of a JSONProject with a remote
+type JSONComparison struct {
+	Remote string `json:"remote" desc:"Remote name, e.g. upstream"`
+	Ref    string `json:"ref" desc:"Remote-tracking branch compared with, e.g. upstream/main"`
+	Ahead  int    `json:"ahead" desc:"Commits of the current branch not on ref"`
+	Behind int    `json:"behind" desc:"Commits of ref not on the current branch"`
+}
+
+// JSONRemote is the unreachable remote of a JSONProject
+type JSONRemote struct {
+	Name       string `json:"name" desc:"Remote name, e.g. origin"`
+	URL        string `json:"url" desc:"Remote URL"`
+	ErrorClass string `json:"error_class" desc:"Why it cannot be reached" enum:"gone,auth,dns,network,timeout,unknown"`
+	Error      string `json:"error" desc:"Error reported by git"`
+}
+
+// JSONOrigin is the remote a JSONProject pushes to
+type JSONOrigin struct {
+	Name  string `json:"name" desc:"Remote name, e.g. origin"`
+	URL   string `json:"url" desc:"Push URL"`
+	Host  string `json:"host,omitempty" desc:"Host of the URL, absent for local paths"`
+	Owner string `json:"owner,omitempty" desc:"User, organization or group path owning the repository on the host"`
+	Repo  string `json:"repo,omitempty" desc:"Repository name on the host, without .git"`
+}
+
+// NewJSONReport builds the JSON document for the given results
+func NewJSONReport(results []ProjectResult) JSONReport {
+	report := JSONReport{Projects: make([]JSONProject, 0, len(results))}
+	for _, result := range results {
+		project := JSONProject{
+			Name:             result.Name,
+			Category:         result.Category,
+			Path:             result.Path,
+			Status:           result.Status.Type,
+			Message:          result.Status.Message,
+			Symbol:           result.Status.Symbol,
+			Branch:           result.Status.Branch,
+			DetachedAt:       result.Status.DetachedAt,
+			GoneUpstream:     result.Status.GoneUpstream,
+			DefaultBranch:    result.Status.DefaultBranch,
+			OffDefaultBranch: result.Status.OffDefaultBranch,
+			SymlinkTarget:    result.SymlinkTarget,
+			LockFile:         result.Status.LockFile,
+			UserEmail:        result.Status.UserEmail,
+			IdentityMismatch: result.Status.IdentityMismatch,
+			DeniedPath:       result.Status.Path,
+			StashCount:       result.Status.StashCount,
+			Operation:        result.Status.Operation,
+			DirtySubmodules:  result.Status.DirtySubmodules,
+			MergedBranches:   result.Status.MergedBranches,
+			MergedInto:       result.Status.MergedInto,
+			UnpushedTags:     result.Status.UnpushedTags,
+			Bare:             result.Status.Bare,
+			Shallow:          result.Status.Shallow,
+			VCS:              result.VCS,
+			SizeBytes:        result.SizeBytes,
+			GitSizeBytes:     result.GitSizeBytes,
+			Changed:          result.Changed,
+			Active:           result.Active,
+		}
+		if remote := result.Status.Remote; remote != nil {
+			project.Remote = &JSONRemote{Name: remote.Remote, URL: remote.URL, ErrorClass: remote.Class, Error: remote.Message}
+		}
+		if origin := result.Status.Origin; origin != nil {
+			project.Origin = &JSONOrigin{Name: origin.Name, URL: origin.URL, Host: origin.Host, Owner: origin.Owner, Repo: origin.Repo}
+		}
+		if !result.Status.LastFetched.IsZero() {
+			project.LastFetched = result.Status.LastFetched.Format(time.RFC3339)
+		}
+		for _, branch := range result.Status.BehindBranches {
+			project.BehindBranches = append(project.BehindBranches, JSONBranch{
+				Branch:  branch.Branch,
+				Message: branch.Message,
+			})
+		}
+		for _, comparison := range result.Status.RemoteComparisons {
+			project.RemoteComparisons = append(project.RemoteComparisons, JSONComparison(comparison))
+		}
+		for _, branch := range result.Status.LocalOnlyBranches {
+			project.LocalOnlyBranches = append(project.LocalOnlyBranches, JSONBranch{
+				Branch:  branch.Branch,
+				Message: branch.Message,
+			})
+		}
+		report.Projects = append(report.Projects, project)
+	}
+	return report
+}
+
+// Report writes the results as indented JSON
+func (r *JSONReporter) Report(results []ProjectResult) {
+	data, err := json.MarshalIndent(NewJSONReport(results), "", "  ")
+	if err != nil {
+		fmt.Fprintf(r.out, "{\"error\": %q}\n", err.Error())
+		return
+	}
+	fmt.Fprintln(r.out, string(data))
+}
@@ -0,0 +1,84 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// MarkdownReporter writes results as one markdown table per category
+type MarkdownReporter struct {
+	config  *config.Config
+	verbose bool
+	out     io.Writer
+}
+
+// Report writes the results as markdown
+func (r *MarkdownReporter) Report(results []ProjectResult) {
+	categories, groups := groupResults(results, r.config.Display.GroupBy)
+
+	for i, category := range categories {
+		if i > 0 {
+			fmt.Fprintln(r.out)
+		}
+		fmt.Fprintf(r.out, "## %s\n\n", escapeMarkdown(category))
+		fmt.Fprintln(r.out, "| Project | Status | Branch | Details |")
+		fmt.Fprintln(r.out, "| --- | --- | --- | --- |")
+
+		for _, result := range groups[category] {
+			if r.config.Display.HideIgnored && result.Status.Type == git.StatusIgnored {
+				continue
+			}
+			if r.config.Display.Bare == config.BareUnpushed && result.Status.Type == git.StatusBare {
+				continue
+			}
+			fetchIsStale := FetchIsStale(result.Status, r.config.Display.StaleFetchDays, time.Now())
+			if r.config.Display.HideClean && !r.verbose && result.Status.Type == git.StatusSync && !result.Status.BranchesNeedAttention() &&
+				!(r.config.Display.ShowStashes && result.Status.StashCount > 0) && !fetchIsStale && !result.Status.IdentityMismatch {
+				continue
+			}
+
+			var details []string
+			if result.Status.Message != "" {
+				details = append(details, result.Status.Message)
+			}
+			for _, branch := range result.Status.BehindBranches {
+				details = append(details, fmt.Sprintf("%s: %s", branch.Branch, branch.Message))
+			}
+			for _, branch := range result.Status.LocalOnlyBranches {
+				details = append(details, fmt.Sprintf("%s: %s", branch.Branch, branch.Message))
+			}
+			if len(result.Status.UnpushedTags) > 0 {
+				details = append(details, fmt.Sprintf("%s: %s", TagsLabel(len(result.Status.UnpushedTags)), strings.Join(result.Status.UnpushedTags, ", ")))
+			}
+			for _, submodule := range result.Status.DirtySubmodules {
+				details = append(details, "submodule "+submodule)
+			}
+			if result.Status.StashCount > 0 {
+				details = append(details, StashLabel(result.Status.StashCount))
+			}
+			if fetchIsStale {
+				details = append(details, "⏳ "+FetchedLabel(result.Status, time.Now()))
+			}
+			if result.Status.IdentityMismatch {
+				details = append(details, git.IdentitySymbol+" "+IdentityLabel(result.Status))
+			}
+
+			fmt.Fprintf(r.out, "| %s | %s | %s | %s |\n",
+				escapeMarkdown(result.Name),
+				escapeMarkdown(result.Status.Symbol),
+				escapeMarkdown(BranchLabel(result.Status)),
+				escapeMarkdown(strings.Join(details, "; ")))
+		}
+	}
+}
+
+// escapeMarkdown escapes characters that would break a markdown table cell
+func escapeMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
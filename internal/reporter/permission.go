@@ -0,0 +1,47 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// currentUser names the user running the check, for the chown hints
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "$USER"
+}
+
+// PermissionHint tells how to make path readable by the current user again,
+// e.g. "sudo chown -R bob /srv/api (or chmod -R u+rwX /srv/api)"
+func PermissionHint(path string) string {
+	return strings.ReplaceAll(permissionHint(), "PATH", path)
+}
+
+func permissionHint() string {
+	return fmt.Sprintf("sudo chown -R %s PATH (or chmod -R u+rwX PATH)", currentUser())
+}
+
+// displayPermissionDenied lists the repositories the current user cannot read,
+// with the failing path of each and how to fix their owner or modes
+func (r *Reporter) displayPermissionDenied(results []ProjectResult) {
+	if len(results) == 0 {
+		return
+	}
+	repos := "repos"
+	if len(results) == 1 {
+		repos = "repo"
+	}
+
+	fmt.Fprintf(r.out, "🔒 %s\n", underline("Permission denied"))
+	fmt.Fprintf(r.out, "  %s\n", yellow(fmt.Sprintf("%d %s cannot be read by %s — fix with %s", len(results), repos, currentUser(), permissionHint())))
+	for _, result := range results {
+		fmt.Fprintf(r.out, "    %s %s/%s — %s\n", red("🔒"), result.Category, result.Name, result.Status.Path)
+	}
+}
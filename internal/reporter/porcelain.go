@@ -0,0 +1,24 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+)
+
+// PorcelainReporter writes one stable, tab-separated line per project:
+// <status> <category> <name> <branch> <path>
+type PorcelainReporter struct {
+	out io.Writer
+}
+
+// Report writes the results in porcelain format
+func (r *PorcelainReporter) Report(results []ProjectResult) {
+	for _, result := range results {
+		fmt.Fprintf(r.out, "%s\t%s\t%s\t%s\t%s\n",
+			result.Status.Type,
+			result.Category,
+			result.Name,
+			result.Status.Branch,
+			result.Path)
+	}
+}
@@ -0,0 +1,86 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// PrometheusReporter writes gauges in the Prometheus text format, for node_exporter's
+// textfile collector: HELP and TYPE lines, and no timestamp on samples
+type PrometheusReporter struct {
+	out io.Writer
+	now func() time.Time
+}
+
+// prometheusRepoMetric is a per-repository gauge
+type prometheusRepoMetric struct {
+	name  string
+	help  string
+	value func(ProjectResult) int
+}
+
+var prometheusRepoMetrics = []prometheusRepoMetric{
+	{"check_projects_repo_dirty", "Whether the repository needs attention: changes, commits to sync or no upstream (1) or not (0).", func(r ProjectResult) int {
+		return boolGauge(!IsClean(r) && !isErrored(r))
+	}},
+	{"check_projects_repo_ahead", "Commits of the current branch not pushed to its upstream.", func(r ProjectResult) int {
+		return r.Status.Ahead
+	}},
+	{"check_projects_repo_behind", "Commits of the upstream not pulled into the current branch.", func(r ProjectResult) int {
+		return r.Status.Behind
+	}},
+	{"check_projects_repo_error", "Whether the repository could not be checked (1) or not (0).", func(r ProjectResult) int {
+		return boolGauge(isErrored(r))
+	}},
+}
+
+// Report writes one sample per repository for each metric, then the totals
+func (r *PrometheusReporter) Report(results []ProjectResult) {
+	for _, metric := range prometheusRepoMetrics {
+		fmt.Fprintf(r.out, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name)
+		for _, result := range results {
+			fmt.Fprintf(r.out, "%s{category=\"%s\",name=\"%s\"} %d\n",
+				metric.name, escapeLabelValue(result.Category), escapeLabelValue(result.Name), metric.value(result))
+		}
+	}
+
+	summary := Summarize(results)
+	fmt.Fprintf(r.out, "# HELP check_projects_repos Number of repositories by state.\n# TYPE check_projects_repos gauge\n")
+	for _, state := range []struct {
+		label string
+		count int
+	}{
+		{"clean", summary.Clean},
+		{"stale", summary.Stale},
+		{"changes", summary.Changes},
+		{"behind", summary.Behind},
+		{"no_upstream", summary.NoUpstream},
+		{"no_remote", summary.NoRemote},
+		{"unversioned", summary.Unversioned},
+		{"remote_unreachable", summary.Unreachable},
+		{"auth_required", summary.AuthRequired},
+		{"error", summary.Errors},
+		{"unchecked", summary.Unchecked},
+	} {
+		fmt.Fprintf(r.out, "check_projects_repos{state=\"%s\"} %d\n", state.label, state.count)
+	}
+	fmt.Fprintf(r.out, "# HELP check_projects_repos_total Number of checked repositories.\n# TYPE check_projects_repos_total gauge\n")
+	fmt.Fprintf(r.out, "check_projects_repos_total %d\n", summary.Total)
+
+	fmt.Fprintf(r.out, "# HELP check_projects_last_run_timestamp_seconds Unix time of the end of the last check.\n# TYPE check_projects_last_run_timestamp_seconds gauge\n")
+	fmt.Fprintf(r.out, "check_projects_last_run_timestamp_seconds %d\n", r.now().Unix())
+}
+
+func boolGauge(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// escapeLabelValue escapes backslashes, double quotes and newlines in a label value
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,99 @@
+package reporter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+func TestPrometheusReport(t *testing.T) {
+	results := []ProjectResult{
+		{Category: "work", Name: "api", Status: &git.Status{Type: git.StatusSync, Symbol: "✔"}},
+		{Category: `cli"ents`, Name: `back\slash`, Status: &git.Status{Type: git.StatusUnsync, Symbol: "⬆⬆", Ahead: 2, Behind: 1}},
+		{Category: "work", Name: "multi\nline", Status: &git.Status{Type: git.StatusNoUpstream, Symbol: "⚠ No upstream"}},
+		{Category: "work", Name: "broken", Status: &git.Status{Type: git.StatusError, Symbol: "❌"}},
+	}
+
+	var out bytes.Buffer
+	now := time.Unix(1700000000, 0)
+	reporter := &PrometheusReporter{out: &out, now: func() time.Time { return now }}
+	reporter.Report(results)
+
+	want := `# HELP check_projects_repo_dirty Whether the repository needs attention: changes, commits to sync or no upstream (1) or not (0).
+# TYPE check_projects_repo_dirty gauge
+check_projects_repo_dirty{category="work",name="api"} 0
+check_projects_repo_dirty{category="cli\"ents",name="back\\slash"} 1
+check_projects_repo_dirty{category="work",name="multi\nline"} 1
+check_projects_repo_dirty{category="work",name="broken"} 0
+# HELP check_projects_repo_ahead Commits of the current branch not pushed to its upstream.
+# TYPE check_projects_repo_ahead gauge
+check_projects_repo_ahead{category="work",name="api"} 0
+check_projects_repo_ahead{category="cli\"ents",name="back\\slash"} 2
+check_projects_repo_ahead{category="work",name="multi\nline"} 0
+check_projects_repo_ahead{category="work",name="broken"} 0
+# HELP check_projects_repo_behind Commits of the upstream not pulled into the current branch.
+# TYPE check_projects_repo_behind gauge
+check_projects_repo_behind{category="work",name="api"} 0
+check_projects_repo_behind{category="cli\"ents",name="back\\slash"} 1
+check_projects_repo_behind{category="work",name="multi\nline"} 0
+check_projects_repo_behind{category="work",name="broken"} 0
+# HELP check_projects_repo_error Whether the repository could not be checked (1) or not (0).
+# TYPE check_projects_repo_error gauge
+check_projects_repo_error{category="work",name="api"} 0
+check_projects_repo_error{category="cli\"ents",name="back\\slash"} 0
+check_projects_repo_error{category="work",name="multi\nline"} 0
+check_projects_repo_error{category="work",name="broken"} 1
+# HELP check_projects_repos Number of repositories by state.
+# TYPE check_projects_repos gauge
+check_projects_repos{state="clean"} 1
+check_projects_repos{state="stale"} 0
+check_projects_repos{state="changes"} 1
+check_projects_repos{state="behind"} 0
+check_projects_repos{state="no_upstream"} 1
+check_projects_repos{state="no_remote"} 0
+check_projects_repos{state="unversioned"} 0
+check_projects_repos{state="remote_unreachable"} 0
+check_projects_repos{state="auth_required"} 0
+check_projects_repos{state="error"} 1
+check_projects_repos{state="unchecked"} 0
+# HELP check_projects_repos_total Number of checked repositories.
+# TYPE check_projects_repos_total gauge
+check_projects_repos_total 4
+# HELP check_projects_last_run_timestamp_seconds Unix time of the end of the last check.
+# TYPE check_projects_last_run_timestamp_seconds gauge
+check_projects_last_run_timestamp_seconds 1700000000
+`
+	if got := out.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrometheusReportEmpty(t *testing.T) {
+	var out bytes.Buffer
+	reporter := &PrometheusReporter{out: &out, now: func() time.Time { return time.Unix(0, 0) }}
+	reporter.Report(nil)
+
+	if !bytes.Contains(out.Bytes(), []byte("# TYPE check_projects_repo_dirty gauge\n# HELP check_projects_repo_ahead")) {
+		t.Errorf("metrics without samples lost their HELP and TYPE lines:\n%s", out.String())
+	}
+	if !bytes.Contains(out.Bytes(), []byte("check_projects_repos_total 0\n")) {
+		t.Errorf("no total:\n%s", out.String())
+	}
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	tests := map[string]string{
+		"plain":           "plain",
+		`say "hi"`:        `say \"hi\"`,
+		`C:\dev\app`:      `C:\\dev\\app`,
+		"two\nlines":      `two\nlines`,
+		`\"` + "\n" + `"`: `\\\"\n\"`,
+	}
+	for value, want := range tests {
+		if got := escapeLabelValue(value); got != want {
+			t.Errorf("escapeLabelValue(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
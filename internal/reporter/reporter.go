@@ -0,0 +1,80 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+)
+
+// Output formats accepted by --output
+const (
+	FormatText       = "text"
+	FormatJSON       = "json"
+	FormatMarkdown   = "markdown"
+	FormatCSV        = "csv"
+	FormatPorcelain  = "porcelain"
+	FormatPrometheus = "prometheus"
+)
+
+// Formats lists every supported output format, in the order shown to users
+var Formats = []string{FormatText, FormatJSON, FormatMarkdown, FormatCSV, FormatPorcelain, FormatPrometheus}
+
+// ResultReporter renders project results in a given output format
+type ResultReporter interface {
+	Report(results []ProjectResult)
+}
+
+// ValidateFormat returns an error listing the valid formats when format is unknown
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format '%s' (valid: %s)", format, strings.Join(Formats, ", "))
+}
+
+// IsMachineFormat reports whether format is meant to be parsed by other tools
+func IsMachineFormat(format string) bool {
+	return format != FormatText
+}
+
+// New creates the reporter for the given output format, writing to w
+func New(format string, cfg *config.Config, verbose bool, w io.Writer) (ResultReporter, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatJSON:
+		return &JSONReporter{out: w}, nil
+	case FormatMarkdown:
+		return &MarkdownReporter{config: cfg, verbose: verbose, out: w}, nil
+	case FormatCSV:
+		return &CSVReporter{out: w}, nil
+	case FormatPorcelain:
+		return &PorcelainReporter{out: w}, nil
+	case FormatPrometheus:
+		return &PrometheusReporter{out: w, now: time.Now}, nil
+	default:
+		rep := NewReporter(cfg, verbose)
+		rep.out = w
+		return rep, nil
+	}
+}
+
+// groupByCategory groups results by category, keeping categories in the order they first appear
+func groupByCategory(results []ProjectResult) ([]string, map[string][]ProjectResult) {
+	var order []string
+	groups := make(map[string][]ProjectResult)
+	for _, result := range results {
+		if _, ok := groups[result.Category]; !ok {
+			order = append(order, result.Category)
+		}
+		groups[result.Category] = append(groups[result.Category], result)
+	}
+	return order, groups
+}
@@ -0,0 +1,136 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// Project orderings accepted by --sort and display.sort
+const (
+	SortConfig = "config" // Order in which projects are listed or found
+	SortStatus = "status" // Worst status first
+	SortName   = "name"   // Alphabetical, numbers compared by value
+	SortAge    = "age"    // Oldest last commit first
+)
+
+// SortOrders lists every supported ordering, in the order the TUI cycles through them
+var SortOrders = []string{SortConfig, SortStatus, SortName, SortAge}
+
+// ValidateSort returns an error listing the valid orderings when order is unknown
+func ValidateSort(order string) error {
+	for _, o := range SortOrders {
+		if o == order {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid sort order '%s' (valid: %s)", order, strings.Join(SortOrders, ", "))
+}
+
+// SortItem holds what orderings compare, so that the report and the TUI sort alike
+type SortItem struct {
+	Name       string
+	Status     *git.Status
+	LastCommit time.Time // Zero when unknown
+}
+
+// Compare returns a negative number when a comes before b in order, a positive one
+// when it comes after, and 0 when the order keeps them as they are.
+// Projects with conflicts come first whatever the order, since they block
+// everything else in their repository. Ties are broken by name, except for the
+// config order.
+func Compare(order string, a, b SortItem) int {
+	if conflicted(a.Status) != conflicted(b.Status) {
+		if conflicted(a.Status) {
+			return -1
+		}
+		return 1
+	}
+	switch order {
+	case SortStatus:
+		if diff := StatusRank(a.Status) - StatusRank(b.Status); diff != 0 {
+			return diff
+		}
+	case SortAge:
+		// Unknown dates go last
+		switch {
+		case a.LastCommit.IsZero() != b.LastCommit.IsZero():
+			if a.LastCommit.IsZero() {
+				return 1
+			}
+			return -1
+		case a.LastCommit.Before(b.LastCommit):
+			return -1
+		case b.LastCommit.Before(a.LastCommit):
+			return 1
+		}
+	case SortName:
+	default:
+		return 0
+	}
+	return compareNames(a.Name, b.Name)
+}
+
+// conflicted reports whether a status has unmerged paths
+func conflicted(status *git.Status) bool {
+	return status != nil && status.Conflicts > 0
+}
+
+// compareNames orders names as the scan of roots does (see config.CompareNatural)
+func compareNames(a, b string) int {
+	return config.CompareNatural(a, b)
+}
+
+// StatusRank orders statuses from the most to the least in need of attention
+func StatusRank(status *git.Status) int {
+	if status == nil {
+		return 7
+	}
+	switch status.Type {
+	case git.StatusError, git.StatusTimeout, git.StatusBrokenSymlink, git.StatusRemoteUnreachable, git.StatusAuthRequired, git.StatusPermission, git.StatusLocked:
+		return 0
+	case git.StatusUnsync:
+		return 1
+	case git.StatusNoUpstream, git.StatusNoRemote, git.StatusEmpty, git.StatusUnversioned:
+		return 2
+	case git.StatusSync:
+		if status.BranchesNeedAttention() {
+			return 3
+		}
+		return 5
+	case git.StatusStale:
+		return 4
+	default:
+		return 6
+	}
+}
+
+// SortResults returns a copy of results sorted by order within each category,
+// keeping categories in the order they first appear
+func SortResults(results []ProjectResult, order string) []ProjectResult {
+	categoryIndex := make(map[string]int)
+	for _, result := range results {
+		if _, ok := categoryIndex[result.Category]; !ok {
+			categoryIndex[result.Category] = len(categoryIndex)
+		}
+	}
+
+	sorted := make([]ProjectResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Category != b.Category {
+			return categoryIndex[a.Category] < categoryIndex[b.Category]
+		}
+		return Compare(order, a.sortItem(), b.sortItem()) < 0
+	})
+	return sorted
+}
+
+func (r ProjectResult) sortItem() SortItem {
+	return SortItem{Name: r.Name, Status: r.Status, LastCommit: r.LastCommit}
+}
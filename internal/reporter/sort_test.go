@@ -0,0 +1,109 @@
+package reporter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+func TestSortResults(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2024, 5, n, 12, 0, 0, 0, time.UTC) }
+	sync := &git.Status{Type: git.StatusSync}
+	behindBranch := &git.Status{Type: git.StatusSync, BehindBranches: []git.BranchTracking{{Branch: "topic"}}}
+	dirty := &git.Status{Type: git.StatusUnsync}
+	conflicts := &git.Status{Type: git.StatusUnsync, Conflicts: 1}
+	noUpstream := &git.Status{Type: git.StatusNoUpstream}
+	stale := &git.Status{Type: git.StatusStale}
+	failed := &git.Status{Type: git.StatusError}
+	ignored := &git.Status{Type: git.StatusIgnored}
+
+	tests := []struct {
+		name    string
+		order   string
+		results []ProjectResult
+		want    []string
+	}{
+		{"config keeps the order", SortConfig, []ProjectResult{
+			{Name: "b", Status: sync}, {Name: "a", Status: failed}, {Name: "c", Status: dirty},
+		}, []string{"b", "a", "c"}},
+		{"config puts conflicts first", SortConfig, []ProjectResult{
+			{Name: "b", Status: sync}, {Name: "a", Status: dirty}, {Name: "c", Status: conflicts},
+		}, []string{"c", "b", "a"}},
+		{"name", SortName, []ProjectResult{
+			{Name: "b", Status: sync}, {Name: "C", Status: sync}, {Name: "a", Status: sync},
+		}, []string{"a", "b", "C"}},
+		{"name compares numbers by value", SortName, []ProjectResult{
+			{Name: "app10", Status: sync}, {Name: "app2", Status: sync}, {Name: "app1", Status: sync},
+		}, []string{"app1", "app2", "app10"}},
+		{"name puts conflicts first", SortName, []ProjectResult{
+			{Name: "a", Status: sync}, {Name: "z", Status: conflicts},
+		}, []string{"z", "a"}},
+		{"status, worst first", SortStatus, []ProjectResult{
+			{Name: "ignored", Status: ignored}, {Name: "sync", Status: sync}, {Name: "stale", Status: stale},
+			{Name: "branch", Status: behindBranch}, {Name: "upstream", Status: noUpstream},
+			{Name: "dirty", Status: dirty}, {Name: "failed", Status: failed}, {Name: "unchecked"},
+		}, []string{"failed", "dirty", "upstream", "branch", "stale", "sync", "ignored", "unchecked"}},
+		{"status ties broken by name", SortStatus, []ProjectResult{
+			{Name: "b", Status: dirty}, {Name: "c", Status: sync}, {Name: "a", Status: dirty}, {Name: "a2", Status: sync},
+		}, []string{"a", "b", "a2", "c"}},
+		{"status puts conflicts before errors", SortStatus, []ProjectResult{
+			{Name: "failed", Status: failed}, {Name: "conflicts", Status: conflicts},
+		}, []string{"conflicts", "failed"}},
+		{"age, oldest first", SortAge, []ProjectResult{
+			{Name: "new", Status: sync, LastCommit: day(3)}, {Name: "old", Status: sync, LastCommit: day(1)}, {Name: "mid", Status: sync, LastCommit: day(2)},
+		}, []string{"old", "mid", "new"}},
+		{"age puts unknown dates last", SortAge, []ProjectResult{
+			{Name: "unknown", Status: failed}, {Name: "new", Status: sync, LastCommit: day(3)}, {Name: "old", Status: sync, LastCommit: day(1)},
+		}, []string{"old", "new", "unknown"}},
+		{"age ties broken by name", SortAge, []ProjectResult{
+			{Name: "b", Status: sync, LastCommit: day(1)}, {Name: "y"}, {Name: "a", Status: sync, LastCommit: day(1)}, {Name: "x"},
+		}, []string{"a", "b", "x", "y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := append([]ProjectResult{}, tt.results...)
+			var got []string
+			for _, result := range SortResults(tt.results, tt.order) {
+				got = append(got, result.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.results, input) {
+				t.Error("the results given were reordered")
+			}
+		})
+	}
+}
+
+func TestSortResultsKeepsCategories(t *testing.T) {
+	results := []ProjectResult{
+		{Category: "work", Name: "b"}, {Category: "home", Name: "z"}, {Category: "work", Name: "a"}, {Category: "home", Name: "y"},
+	}
+	for _, order := range SortOrders {
+		var got []string
+		for _, result := range SortResults(results, order) {
+			got = append(got, result.Category+"/"+result.Name)
+		}
+		want := []string{"work/a", "work/b", "home/y", "home/z"}
+		if order == SortConfig {
+			want = []string{"work/b", "work/a", "home/z", "home/y"}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", order, got, want)
+		}
+	}
+}
+
+func TestValidateSort(t *testing.T) {
+	for _, order := range SortOrders {
+		if err := ValidateSort(order); err != nil {
+			t.Errorf("%s: %v", order, err)
+		}
+	}
+	if err := ValidateSort("size"); err == nil {
+		t.Error("size accepted")
+	}
+}
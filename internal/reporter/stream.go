@@ -0,0 +1,139 @@
+package reporter
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Streamer prints the console report as results come in: each category as soon as
+// all its projects are checked, in config order, then the sections following the
+// categories with Finish. Categories without any project needing attention are held
+// back until one shows that the report is not "All projects are clean!".
+type Streamer struct {
+	r     *Reporter
+	order string // Project order within categories, see SortOrders
+
+	// Before is called before anything is printed, e.g. to clear a progress line
+	Before func()
+
+	final      bool                // The projects expected are known for sure
+	categories []string            // In config order
+	expected   map[string][]string // Paths of the projects of each category, in config order
+	results    map[string]ProjectResult
+	printed    int  // Categories printed, the first ones
+	attention  bool // A project printed needs attention: no category is held back
+}
+
+// NewStreamer returns a Streamer printing the report of r, with projects in order
+// within their category (display.sort)
+func (r *Reporter) NewStreamer(order string) *Streamer {
+	return &Streamer{r: r, order: order, attention: r.verbose, results: make(map[string]ProjectResult)}
+}
+
+// Expect sets the projects of the report (only their category and path matter), in
+// config order. Nothing is printed until they are final: scanning the roots again
+// may still add or remove projects.
+func (s *Streamer) Expect(projects []ProjectResult, final bool) {
+	s.final = final
+	s.categories = nil
+	s.expected = make(map[string][]string)
+	for _, project := range projects {
+		if _, ok := s.expected[project.Category]; !ok {
+			s.categories = append(s.categories, project.Category)
+		}
+		s.expected[project.Category] = append(s.expected[project.Category], project.Path)
+	}
+	s.flush()
+}
+
+// Add records the result of a project, printing the categories it completes
+func (s *Streamer) Add(result ProjectResult) {
+	s.results[resultKey(result.Category, result.Path)] = result
+	s.flush()
+}
+
+// Finish prints the categories not printed yet and the sections following them,
+// from every result of the report (the projects left unchecked included)
+func (s *Streamer) Finish(results []ProjectResult) {
+	sections := splitSections(results)
+	categories, categoryResults := groupResults(sections.others, GroupByCategory)
+
+	if s.printed == 0 && s.r.allClean(sections) {
+		s.print(func() { fmt.Fprintln(s.r.out, greenBold("✔ All projects are clean!")) })
+	} else {
+		done := s.categories[:s.printed]
+		for _, category := range categories {
+			if !slices.Contains(done, category) {
+				s.print(func() { s.r.displayCategory(category, categoryResults[category]) })
+			}
+		}
+	}
+	s.print(func() { s.r.displaySections(sections, results) })
+}
+
+// flush prints the categories whose projects are all checked, in order, unless they
+// are held back
+func (s *Streamer) flush() {
+	if !s.final {
+		return
+	}
+
+	// Categories complete after those printed
+	ready := s.printed
+	for ready < len(s.categories) && s.complete(s.categories[ready]) {
+		if !s.attention && s.needsAttention(s.categories[ready]) {
+			s.attention = true
+		}
+		ready++
+	}
+	if !s.attention {
+		return
+	}
+
+	for ; s.printed < ready; s.printed++ {
+		category := s.categories[s.printed]
+		others := splitSections(s.categoryResults(category)).others
+		if len(others) > 0 {
+			s.print(func() { s.r.displayCategory(category, SortResults(others, s.order)) })
+		}
+	}
+}
+
+// complete reports whether every project of category was checked
+func (s *Streamer) complete(category string) bool {
+	for _, path := range s.expected[category] {
+		if _, ok := s.results[resultKey(category, path)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// needsAttention reports whether a project of category keeps the report from being
+// "All projects are clean!"
+func (s *Streamer) needsAttention(category string) bool {
+	sections := splitSections(s.categoryResults(category))
+	return !s.r.allClean(sections)
+}
+
+// categoryResults returns the results of the projects of category, in config order
+func (s *Streamer) categoryResults(category string) []ProjectResult {
+	results := make([]ProjectResult, 0, len(s.expected[category]))
+	for _, path := range s.expected[category] {
+		results = append(results, s.results[resultKey(category, path)])
+	}
+	return results
+}
+
+// print calls Before, then display
+func (s *Streamer) print(display func()) {
+	if s.Before != nil {
+		s.Before()
+	}
+	display()
+}
+
+// resultKey identifies the result of a project within the report
+func resultKey(category, path string) string {
+	return category + "\x00" + path
+}
@@ -0,0 +1,116 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// Summary counts results by class, for --summary and the exit code
+type Summary struct {
+	Total        int `json:"total"`
+	Clean        int `json:"clean"`   // Clean, ignored included
+	Stale        int `json:"stale"`   // Clean, but without commits for longer than stale_after
+	Changes      int `json:"changes"` // Local changes or commits to push
+	Behind       int `json:"behind"`  // Behind the remote, or with other branches behind theirs
+	NoUpstream   int `json:"no_upstream"`
+	NoRemote     int `json:"no_remote"`     // Without any remote
+	Unreachable  int `json:"unreachable"`   // Remote cannot be reached (--check-remotes)
+	AuthRequired int `json:"auth_required"` // Remote refused the credentials (--fetch, --check-remotes)
+	Errors       int `json:"errors"`        // Errors, timeouts, broken symlinks, unreadable and locked repositories
+	Unchecked    int `json:"unchecked"`     // Left unchecked when the time budget ran out (--max-duration)
+	Unversioned  int `json:"unversioned"`   // Directories holding files but no repository (report_untracked_dirs)
+}
+
+// Summarize classifies every result into exactly one class of a Summary
+func Summarize(results []ProjectResult) Summary {
+	s := Summary{Total: len(results)}
+	for _, result := range results {
+		switch {
+		case isErrored(result):
+			s.Errors++
+		case result.Status.Type == git.StatusUnchecked:
+			s.Unchecked++
+		case result.Status.Type == git.StatusStale:
+			s.Stale++
+		case IsClean(result):
+			s.Clean++
+		case result.Status.Type == git.StatusNoUpstream:
+			s.NoUpstream++
+		case result.Status.Type == git.StatusNoRemote:
+			s.NoRemote++
+		case result.Status.Type == git.StatusUnversioned:
+			s.Unversioned++
+		case result.Status.Type == git.StatusRemoteUnreachable:
+			s.Unreachable++
+		case result.Status.Type == git.StatusAuthRequired:
+			s.AuthRequired++
+		case result.Status.Type == git.StatusSync && len(result.Status.BehindBranches) == 0:
+			s.Changes++ // Local-only commits to push
+		case result.Status.Symbol == "↓" || result.Status.Type == git.StatusSync:
+			s.Behind++
+		default:
+			s.Changes++
+		}
+	}
+	return s
+}
+
+// NeedAttention returns the number of projects that are neither clean (stale included) nor errored
+func (s Summary) NeedAttention() int {
+	return s.Changes + s.Behind + s.NoUpstream + s.NoRemote + s.Unreachable + s.AuthRequired + s.Unversioned
+}
+
+// Line renders the summary on one line, e.g. "repos: 212 ✔203 ✱6 ↓2 ❌1".
+// Empty classes other than clean are left out.
+func (s Summary) Line() string {
+	parts := []string{fmt.Sprintf("repos: %d", s.Total), green(fmt.Sprintf("✔%d", s.Clean))}
+	if s.Stale > 0 {
+		parts = append(parts, fmt.Sprintf("🕓%d", s.Stale))
+	}
+	if s.Changes > 0 {
+		parts = append(parts, red(fmt.Sprintf("✱%d", s.Changes)))
+	}
+	if s.Behind > 0 {
+		parts = append(parts, red(fmt.Sprintf("↓%d", s.Behind)))
+	}
+	if s.NoUpstream > 0 {
+		parts = append(parts, yellow(fmt.Sprintf("⚠%d", s.NoUpstream)))
+	}
+	if s.NoRemote > 0 {
+		parts = append(parts, yellow(fmt.Sprintf("⌂%d", s.NoRemote)))
+	}
+	if s.Unversioned > 0 {
+		parts = append(parts, yellow(fmt.Sprintf("%s%d", git.UnversionedSymbol, s.Unversioned)))
+	}
+	if s.Unreachable > 0 {
+		parts = append(parts, yellow(fmt.Sprintf("⊘%d", s.Unreachable)))
+	}
+	if s.AuthRequired > 0 {
+		parts = append(parts, yellow(fmt.Sprintf("🔒%d", s.AuthRequired)))
+	}
+	if s.Errors > 0 {
+		parts = append(parts, redBold(fmt.Sprintf("❌%d", s.Errors)))
+	}
+	if s.Unchecked > 0 {
+		parts = append(parts, fmt.Sprintf("…%d", s.Unchecked))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseSummaryFormat parses a --summary-format template, executed with a Summary
+// (e.g. "{{.Clean}}/{{.Total}} clean")
+func ParseSummaryFormat(format string) (*template.Template, error) {
+	tmpl, err := template.New("summary").Parse(format)
+	if err == nil {
+		// Catch unknown fields before checking anything
+		err = tmpl.Execute(io.Discard, Summary{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid --summary-format: %w", err)
+	}
+	return tmpl, nil
+}
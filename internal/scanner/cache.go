@@ -0,0 +1,193 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/vcs"
+)
+
+// DefaultCachePath returns where the projects found under roots are cached
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "check-projects", "scan.json"), nil
+}
+
+// NewCachedScanner creates a Scanner reading roots from the cache at DefaultCachePath,
+// unless disabled (scan.cache: false). With rescan, it scans them all again instead,
+// refreshing the cache.
+func NewCachedScanner(cfg *config.Config, rescan bool) *Scanner {
+	s := NewScanner(cfg)
+	if cfg.Scan.CacheEnabled() {
+		path, _ := DefaultCachePath()
+		s.Cache = LoadCache(path)
+		s.Rescan = rescan
+	}
+	return s
+}
+
+// cachedProject is a project found under a root, Repository left out
+type cachedProject struct {
+	Name          string `json:"name"`
+	Path          string `json:"path"`
+	Kind          string `json:"kind,omitempty"` // VCS of the repository, "" for a broken symlink or an unversioned directory
+	IsSymlink     bool   `json:"symlink,omitempty"`
+	SymlinkTarget string `json:"symlink_target,omitempty"`
+	IgnoredBy     string `json:"ignored_by,omitempty"`
+	Unversioned   bool   `json:"unversioned,omitempty"`
+}
+
+// cachedRoot is the scan of the root of a category
+type cachedRoot struct {
+	Key       string           `json:"key"` // See rootKey
+	ScannedAt time.Time        `json:"scanned_at"`
+	Dirs      map[string]int64 `json:"dirs"` // Modification times of the directories walked, in nanoseconds
+	Projects  []cachedProject  `json:"projects"`
+	Warnings  []Warning        `json:"warnings,omitempty"`
+}
+
+// Cache keeps the projects found under the root of each category between runs,
+// along with the modification times of the directories walked to find them: a
+// repository appearing or disappearing changes the time of its parent directory.
+type Cache struct {
+	path    string
+	mu      sync.Mutex
+	roots   map[string]cachedRoot // By category
+	changed bool
+}
+
+// LoadCache reads the cache at path ("" for a cache that is never saved).
+// A missing or unreadable cache is empty.
+func LoadCache(path string) *Cache {
+	c := &Cache{path: path, roots: make(map[string]cachedRoot)}
+	if path == "" {
+		return c
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &c.roots)
+	}
+	return c
+}
+
+// rootKey identifies the settings the scan of the root of a category depends on,
+// so that changing them in the config scans it again
+func (s *Scanner) rootKey(category config.Category) string {
+	data, _ := json.Marshal(struct {
+		Category       config.Category
+		Scan           config.Scan
+		MaxDepth       int
+		Bare           string
+		IncludeIgnored bool
+		Unversioned    bool
+	}{category, s.config.Scan, s.config.MaxDepthFor(category.Name), s.config.Display.Bare, s.IncludeIgnored, s.Unversioned})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// lookup returns the cached scan of the root of a category, unless its settings changed
+func (c *Cache) lookup(category, key string) (cachedRoot, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	root, ok := c.roots[category]
+	if !ok || root.Key != key {
+		return cachedRoot{}, false
+	}
+	return root, true
+}
+
+// store records the scan of the root of a category
+func (c *Cache) store(category string, root cachedRoot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.roots[category] = root
+	c.changed = true
+}
+
+// Save writes the cache when a root was scanned
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.path == "" || !c.changed {
+		return nil
+	}
+
+	data, err := json.Marshal(c.roots)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// newCachedRoot records the scan of a root: the directories walked and the projects
+// found, with the warnings it raised
+func newCachedRoot(key string, walk *rootWalk, warnings []Warning) cachedRoot {
+	root := cachedRoot{
+		Key:       key,
+		ScannedAt: time.Now(),
+		Dirs:      walk.dirs,
+		Projects:  make([]cachedProject, len(walk.projects)),
+		Warnings:  warnings,
+	}
+	for i, project := range walk.projects {
+		root.Projects[i] = cachedProject{
+			Name:          project.Name,
+			Path:          project.Path,
+			Kind:          project.VCS(),
+			IsSymlink:     project.IsSymlink,
+			SymlinkTarget: project.SymlinkTarget,
+			IgnoredBy:     project.IgnoredBy,
+			Unversioned:   project.Unversioned,
+		}
+	}
+	return root
+}
+
+// unchanged reports whether scanning the root again would find the same projects:
+// none of the directories walked changed and every repository is still there
+func (r cachedRoot) unchanged() bool {
+	for dir, modTime := range r.Dirs {
+		info, err := os.Stat(dir)
+		if err != nil || info.ModTime().UnixNano() != modTime {
+			return false
+		}
+	}
+	for _, project := range r.Projects {
+		if project.Kind != "" && vcs.Detect(project.Path) != project.Kind {
+			return false
+		}
+	}
+	return true
+}
+
+// projects opens the cached projects of a category with backend
+func (r cachedRoot) projects(category, backend string) []Project {
+	projects := make([]Project, len(r.Projects))
+	for i, cached := range r.Projects {
+		projects[i] = Project{
+			Name:          cached.Name,
+			Path:          cached.Path,
+			Category:      category,
+			IsSymlink:     cached.IsSymlink,
+			SymlinkTarget: cached.SymlinkTarget,
+			Origin:        OriginScanned,
+			IgnoredBy:     cached.IgnoredBy,
+			Unversioned:   cached.Unversioned,
+		}
+		if cached.Kind != "" {
+			projects[i].Repository = vcs.Open(cached.Kind, cached.Path, cached.Name, backend)
+		}
+	}
+	return projects
+}
@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/uralys/check-projects/internal/config"
+)
+
+// IgnoreMarker is the file ignoring, during the scan of roots, the directory holding
+// it and everything under it. When it lists patterns (one per line, # for comments),
+// only the projects under that directory matching them are ignored, the patterns
+// being relative to the directory as those of a category are to its root.
+const IgnoreMarker = ".checkprojects-ignore"
+
+// dirIgnore is the ignore marker of a directory
+type dirIgnore struct {
+	dir      string
+	patterns []string // None: the whole directory is ignored
+}
+
+// readIgnoreMarker returns the ignore marker of dir, when it has one
+func readIgnoreMarker(dir string) (dirIgnore, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, IgnoreMarker))
+	if err != nil {
+		return dirIgnore{}, false
+	}
+
+	marker := dirIgnore{dir: dir}
+	lines := bufio.NewScanner(bytes.NewReader(data))
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			marker.patterns = append(marker.patterns, line)
+		}
+	}
+	return marker, true
+}
+
+// ignoresAll reports whether the marker ignores its whole directory
+func (d dirIgnore) ignoresAll() bool {
+	return len(d.patterns) == 0
+}
+
+// match returns what ignores the project at path under the directory of the
+// marker, as shown by "ignored by": the marker itself, or its pattern matching
+func (d dirIgnore) match(path string) (string, bool) {
+	marker := config.ContractPath(filepath.Join(d.dir, IgnoreMarker))
+	if d.ignoresAll() {
+		return marker, true
+	}
+	rel, err := filepath.Rel(d.dir, path)
+	if err != nil {
+		return "", false
+	}
+	if pattern, ok := matchPatterns(rel, d.patterns); ok {
+		return fmt.Sprintf("%s in %s", pattern, marker), true
+	}
+	return "", false
+}
+
+// markersOf returns the markers applying to dir and under it: those applying to its
+// parent, then its own. excluded reports whether its own ignores it whole.
+func markersOf(parent []dirIgnore, dir string) (markers []dirIgnore, excluded bool) {
+	marker, ok := readIgnoreMarker(dir)
+	if !ok {
+		return parent, false
+	}
+	return append(parent[:len(parent):len(parent)], marker), marker.ignoresAll()
+}
+
+// matchMarkers returns what ignores the project at path among the markers applying to it
+func matchMarkers(markers []dirIgnore, path string) (string, bool) {
+	for _, marker := range markers {
+		if ignoredBy, ok := marker.match(path); ok {
+			return ignoredBy, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,112 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/uralys/check-projects/internal/config"
+)
+
+// maxSuggestions limits the "did you mean" list
+const maxSuggestions = 5
+
+// ResolveError is returned when a project name matches no project or several projects
+type ResolveError struct {
+	Name       string
+	Ambiguous  bool
+	Candidates []Project // Matching projects when ambiguous, suggestions otherwise
+}
+
+func (e *ResolveError) Error() string {
+	var b strings.Builder
+	if e.Ambiguous {
+		fmt.Fprintf(&b, "project '%s' is ambiguous, use --category to pick one of:", e.Name)
+	} else {
+		fmt.Fprintf(&b, "project '%s' not found", e.Name)
+		if len(e.Candidates) > 0 {
+			b.WriteString(", did you mean:")
+		}
+	}
+	for _, candidate := range e.Candidates {
+		fmt.Fprintf(&b, "\n  %s/%s", candidate.Category, candidate.Name)
+	}
+	return b.String()
+}
+
+// ResolveProject finds the single project matching name.
+// Name may be the project name, its last path element, or its path.
+// Exact name matches win over base name matches.
+func ResolveProject(projects []Project, name string) (Project, error) {
+	expanded := filepath.Clean(config.ExpandPath(name))
+
+	var exact, byBase []Project
+	for _, project := range projects {
+		switch {
+		case project.Name == name || project.Path == expanded:
+			exact = append(exact, project)
+		case filepath.Base(project.Name) == name:
+			byBase = append(byBase, project)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = byBase
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return Project{}, &ResolveError{Name: name, Candidates: suggestProjects(projects, name)}
+	default:
+		return Project{}, &ResolveError{Name: name, Ambiguous: true, Candidates: matches}
+	}
+}
+
+// suggestProjects returns projects whose name is close to name
+func suggestProjects(projects []Project, name string) []Project {
+	query := strings.ToLower(name)
+	threshold := len(query)/3 + 1
+
+	var suggestions []Project
+	for _, project := range projects {
+		candidate := strings.ToLower(project.Name)
+		base := strings.ToLower(filepath.Base(project.Name))
+
+		if strings.Contains(candidate, query) ||
+			strings.Contains(query, base) ||
+			levenshtein(base, query) <= threshold {
+			suggestions = append(suggestions, project)
+			if len(suggestions) == maxSuggestions {
+				break
+			}
+		}
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
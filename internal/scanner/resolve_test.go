@@ -0,0 +1,145 @@
+package scanner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// resolvable lists projects of several categories, two of them named api
+var resolvable = []Project{
+	{Name: "check-projects", Path: "/home/me/dev/check-projects", Category: "dev"},
+	{Name: "clients/acme/api", Path: "/home/me/clients/acme/api", Category: "clients"},
+	{Name: "clients/globex/api", Path: "/home/me/clients/globex/api", Category: "clients"},
+	{Name: "api", Path: "/home/me/dev/api", Category: "dev"},
+	{Name: "web", Path: "/home/me/dev/web", Category: "dev"},
+	{Name: "web", Path: "/home/me/games/web", Category: "games"},
+	{Name: "clients/acme/website", Path: "/home/me/clients/acme/website", Category: "clients"},
+}
+
+func TestResolveProject(t *testing.T) {
+	tests := []struct {
+		name string
+		want string // Path of the project found
+	}{
+		{"check-projects", "/home/me/dev/check-projects"},
+		{"clients/acme/api", "/home/me/clients/acme/api"},
+		{"website", "/home/me/clients/acme/website"},                   // Base name
+		{"api", "/home/me/dev/api"},                                    // Exact name wins over base names
+		{"/home/me/games/web", "/home/me/games/web"},                   // Path
+		{"/home/me/games/../games/web/", "/home/me/games/web"},         // Path, cleaned
+		{"/home/me/clients/globex/api", "/home/me/clients/globex/api"}, // Path of an ambiguous base name
+	}
+	for _, tt := range tests {
+		project, err := ResolveProject(resolvable, tt.name)
+		if err != nil {
+			t.Errorf("ResolveProject(%q): %v", tt.name, err)
+			continue
+		}
+		if project.Path != tt.want {
+			t.Errorf("ResolveProject(%q) = %s, want %s", tt.name, project.Path, tt.want)
+		}
+	}
+}
+
+func TestResolveProjectHomePath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	projects := []Project{{Name: "app", Path: filepath.Join(home, "dev", "app"), Category: "dev"}}
+	project, err := ResolveProject(projects, "~/dev/app")
+	if err != nil || project.Name != "app" {
+		t.Errorf("got %+v, %v", project, err)
+	}
+}
+
+func TestResolveProjectAmbiguous(t *testing.T) {
+	_, err := ResolveProject(resolvable, "web")
+
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) || !resolveErr.Ambiguous {
+		t.Fatalf("got %v, want an ambiguous name", err)
+	}
+	if len(resolveErr.Candidates) != 2 {
+		t.Errorf("candidates %+v, want both web projects", resolveErr.Candidates)
+	}
+	want := "project 'web' is ambiguous, use --category to pick one of:\n  dev/web\n  games/web"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err, want)
+	}
+}
+
+func TestResolveProjectAmbiguousBaseName(t *testing.T) {
+	projects := []Project{resolvable[1], resolvable[2]}
+	_, err := ResolveProject(projects, "api")
+
+	var resolveErr *ResolveError
+	if !errors.As(err, &resolveErr) || !resolveErr.Ambiguous || len(resolveErr.Candidates) != 2 {
+		t.Fatalf("got %v, want both api projects", err)
+	}
+}
+
+func TestResolveProjectNotFound(t *testing.T) {
+	tests := []struct {
+		name        string
+		suggestions []string // category/name
+	}{
+		{"chek-projects", []string{"dev/check-projects"}}, // Typo
+		{"acme", []string{"clients/clients/acme/api", "clients/clients/acme/website"}},
+		{"webs", []string{"dev/web", "games/web", "clients/clients/acme/website"}}, // In the order of the projects
+		{"zzzzzzzzzz", nil},
+	}
+	for _, tt := range tests {
+		_, err := ResolveProject(resolvable, tt.name)
+
+		var resolveErr *ResolveError
+		if !errors.As(err, &resolveErr) || resolveErr.Ambiguous {
+			t.Errorf("ResolveProject(%q): got %v, want not found", tt.name, err)
+			continue
+		}
+		var got []string
+		for _, candidate := range resolveErr.Candidates {
+			got = append(got, candidate.Category+"/"+candidate.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.suggestions, ",") {
+			t.Errorf("ResolveProject(%q) suggested %q, want %q", tt.name, got, tt.suggestions)
+		}
+		if tt.suggestions == nil && err.Error() != "project '"+tt.name+"' not found" {
+			t.Errorf("got %q", err)
+		}
+		if tt.suggestions != nil && !strings.Contains(err.Error(), "did you mean:\n  "+tt.suggestions[0]) {
+			t.Errorf("got %q", err)
+		}
+	}
+}
+
+func TestSuggestProjectsIsBounded(t *testing.T) {
+	var projects []Project
+	for i := 0; i < 2*maxSuggestions; i++ {
+		projects = append(projects, Project{Name: "app", Category: "dev"})
+	}
+	if got := suggestProjects(projects, "ap"); len(got) != maxSuggestions {
+		t.Errorf("%d suggestions, want %d", len(got), maxSuggestions)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"api", "", 3},
+		{"api", "api", 0},
+		{"kitten", "sitting", 3},
+		{"café", "cafe", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,12 +1,23 @@
 package scanner
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/uralys/check-projects/internal/config"
-	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/vcs"
+)
+
+// Origin values describing how a project was discovered
+const (
+	OriginExplicit = "explicit" // Listed in a category's projects
+	OriginScanned  = "scanned"  // Found while scanning a category's root
 )
 
 // Project represents a discovered project
@@ -14,14 +25,111 @@ type Project struct {
 	Name          string
 	Path          string
 	Category      string
-	Repository    *git.Repository
+	Repository    vcs.Repository // nil for a broken symlink or an unversioned directory
 	IsSymlink     bool
 	SymlinkTarget string
+	Origin        string
+	IgnoredBy     string // Ignore pattern matching this project (only set with IncludeIgnored)
+	Unversioned   bool   // A directory holding files but no repository (report_untracked_dirs)
+}
+
+// VCS returns the version control system of the project, or "" for a broken symlink
+func (p Project) VCS() string {
+	if p.Repository == nil {
+		return ""
+	}
+	return p.Repository.Kind()
 }
 
 // Scanner scans for projects based on configuration
 type Scanner struct {
 	config *config.Config
+
+	// IncludeIgnored keeps projects matching an ignore pattern, with IgnoredBy set
+	IncludeIgnored bool
+	// Unversioned lists the directories of report_untracked_dirs categories holding
+	// no repository as projects, with Unversioned set
+	Unversioned bool
+
+	// Cache, when set, keeps the projects found under roots between runs: ScanAll
+	// returns those of the previous scan, to be checked with Revalidate
+	Cache *Cache
+	// Rescan scans every root rather than reading it from the Cache, refreshing it
+	Rescan bool
+	// FromCache reports whether the last ScanAll read roots from the Cache
+	FromCache bool
+	// validate only reads roots from the Cache when nothing changed under them
+	validate bool
+
+	// SkippedByExtra counts the projects left out by the config's ExtraIgnore
+	// patterns during the last ScanAll
+	SkippedByExtra int
+
+	// Warnings lists what the last ScanAll could not discover
+	Warnings []Warning
+}
+
+// Warning is a configured project or directory left out of a scan
+type Warning struct {
+	Category string // Empty for warnings about the whole scan
+	Path     string
+	Message  string
+	Denied   bool // The current user is not allowed to read Path
+	Dead     bool // Path is an entry of a projects list that lists no repository
+}
+
+func (w Warning) String() string {
+	switch {
+	case w.Category == "":
+		return w.Message
+	case w.Path == "":
+		return fmt.Sprintf("category '%s': %s", w.Category, w.Message)
+	default:
+		return fmt.Sprintf("category '%s': %s: %s", w.Category, w.Path, w.Message)
+	}
+}
+
+func (s *Scanner) warn(category, path, message string) {
+	s.Warnings = append(s.Warnings, Warning{Category: category, Path: path, Message: message})
+}
+
+// warnDead records an entry of the projects of a category that lists no repository,
+// such as a project moved since it was configured
+func (s *Scanner) warnDead(category, entry, message string) {
+	s.Warnings = append(s.Warnings, Warning{Category: category, Path: entry, Message: message, Dead: true})
+}
+
+// warnRead records why path could not be read, flagging permission errors
+func (s *Scanner) warnRead(category, path string, err error) {
+	s.Warnings = append(s.Warnings, Warning{
+		Category: category,
+		Path:     path,
+		Message:  readError(err),
+		Denied:   errors.Is(err, fs.ErrPermission),
+	})
+}
+
+// readError describes why a path could not be read, without repeating the path
+func readError(err error) string {
+	if errors.Is(err, fs.ErrNotExist) {
+		return "missing"
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// ValidatePattern returns an error when pattern is not a valid ignore pattern
+func ValidatePattern(pattern string) error {
+	if _, ok, err := config.IgnoreRegexp(pattern); ok {
+		return err
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid ignore pattern '%s': %w", pattern, err)
+	}
+	return nil
 }
 
 // NewScanner creates a new Scanner
@@ -29,37 +137,101 @@ func NewScanner(cfg *config.Config) *Scanner {
 	return &Scanner{config: cfg}
 }
 
-// ScanAll scans all categories and returns discovered projects
-func (s *Scanner) ScanAll() ([]Project, error) {
+// ScanAll scans all categories and returns discovered projects.
+// It stops early and returns ctx's error when ctx is cancelled.
+func (s *Scanner) ScanAll(ctx context.Context) ([]Project, error) {
+	if err := config.ValidateBackend(s.config.Backend); err != nil {
+		return nil, fmt.Errorf("backend in config: %w", err)
+	}
+
 	var projects []Project
+	s.SkippedByExtra = 0
+	s.Warnings = nil
+	s.FromCache = false
 
 	for _, category := range s.config.Categories {
-		categoryProjects, err := s.scanCategory(category)
+		if err := ctx.Err(); err != nil {
+			return projects, err
+		}
+		categoryProjects, err := s.scanCategory(ctx, category)
 		if err != nil {
 			// Log error but continue with other categories
 			continue
 		}
+		if len(category.Projects) == 0 {
+			sortProjects(categoryProjects, s.config.Scan.Sort)
+		}
 		projects = append(projects, categoryProjects...)
 	}
 
-	return projects, nil
+	if s.SkippedByExtra > 0 {
+		s.warn("", "", fmt.Sprintf("%d project(s) skipped by the extra ignore patterns (--ignore-pattern)", s.SkippedByExtra))
+	}
+	if s.Cache != nil {
+		_ = s.Cache.Save() // A cache that cannot be written only slows the next run down
+	}
+	return projects, ctx.Err()
+}
+
+// Revalidate scans again the roots the last ScanAll read from the Cache when one of
+// the directories walked to find their projects changed, or one of their repositories
+// is gone. It returns every project and the warnings, as ScanAll without cache would.
+func (s *Scanner) Revalidate(ctx context.Context) ([]Project, []Warning, error) {
+	fresh := &Scanner{config: s.config, IncludeIgnored: s.IncludeIgnored, Unversioned: s.Unversioned, Cache: s.Cache, validate: true}
+	projects, err := fresh.ScanAll(ctx)
+	return projects, fresh.Warnings, err
 }
 
-func (s *Scanner) scanCategory(category config.Category) ([]Project, error) {
+func (s *Scanner) scanCategory(ctx context.Context, category config.Category) ([]Project, error) {
 	var projects []Project
 
 	// Mode 1: Explicit projects list (full paths)
 	if len(category.Projects) > 0 {
+		seen := make(map[string]bool)
 		for _, projectPath := range category.Projects {
+			if config.IsGlob(projectPath) {
+				projects = append(projects, s.scanPattern(category, projectPath, seen)...)
+				continue
+			}
 			expandedPath := config.ExpandPath(projectPath)
-			if !git.IsGitRepository(expandedPath) {
+			if seen[filepath.Clean(expandedPath)] {
+				continue
+			}
+			seen[filepath.Clean(expandedPath)] = true
+			if target, ok := brokenSymlink(expandedPath); ok {
+				projectName := filepath.Base(expandedPath)
+				if pattern, ignored := s.matchIgnore(projectName, category.Ignore); !ignored || s.IncludeIgnored {
+					projects = append(projects, Project{
+						Name:          projectName,
+						Path:          expandedPath,
+						Category:      category.Name,
+						IsSymlink:     true,
+						SymlinkTarget: target,
+						Origin:        OriginExplicit,
+						IgnoredBy:     pattern,
+					})
+				}
+				continue
+			}
+			kind := vcs.Detect(expandedPath)
+			if kind == "" {
+				if _, err := os.Stat(expandedPath); errors.Is(err, fs.ErrNotExist) {
+					s.warnDead(category.Name, projectPath, readError(err))
+				} else if err != nil {
+					s.warnRead(category.Name, projectPath, err)
+				} else if _, err := os.ReadDir(expandedPath); errors.Is(err, fs.ErrPermission) {
+					s.warnRead(category.Name, projectPath, err)
+				} else {
+					s.warnDead(category.Name, projectPath, "not a repository")
+				}
 				continue
 			}
 			// Extract project name from path
 			projectName := filepath.Base(expandedPath)
 
 			// Check if ignored in this category
-			if s.isIgnored(projectName, category.Ignore) {
+			pattern, ignored := s.matchIgnore(projectName, category.Ignore)
+			if ignored && !s.IncludeIgnored {
 				continue
 			}
 
@@ -67,32 +239,281 @@ func (s *Scanner) scanCategory(category config.Category) ([]Project, error) {
 				Name:       projectName,
 				Path:       expandedPath,
 				Category:   category.Name,
-				Repository: git.NewRepository(expandedPath, projectName),
+				Repository: vcs.Open(kind, expandedPath, projectName, s.config.Backend),
+				Origin:     OriginExplicit,
+				IgnoredBy:  pattern,
 			})
 		}
 		return projects, nil
 	}
 
-	// Mode 2: Auto-scan root directory recursively
+	// Mode 2: Auto-scan root directory recursively, or read the previous scan from the cache
 	if category.Root != "" {
-		rootPath := config.ExpandPath(category.Root)
-		projects = s.scanRecursive(rootPath, category.Name, category.Ignore)
-		return projects, nil
+		// The projects skipped by --ignore-pattern are counted while scanning
+		if s.Cache == nil || len(s.config.ExtraIgnore) > 0 {
+			return s.scanRoot(ctx, category, false).projects, nil
+		}
+
+		key := s.rootKey(category)
+		if cached, ok := s.Cache.lookup(category.Name, key); ok && !s.Rescan && (!s.validate || cached.unchanged()) {
+			s.FromCache = s.FromCache || !s.validate
+			s.Warnings = append(s.Warnings, cached.Warnings...)
+			return cached.projects(category.Name, s.config.Backend), nil
+		}
+		first := len(s.Warnings)
+		walk := s.scanRoot(ctx, category, true)
+		if ctx.Err() == nil {
+			s.Cache.store(category.Name, newCachedRoot(key, walk, slices.Clone(s.Warnings[first:])))
+		}
+		return walk.projects, nil
 	}
 
 	return projects, nil
 }
 
-// scanRecursive recursively scans a directory for git repositories
-func (s *Scanner) scanRecursive(rootPath, categoryName string, ignored []string) []Project {
+// brokenSymlink returns the target of path when path is a symlink whose target is missing
+func brokenSymlink(path string) (string, bool) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		return "", false
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", false
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return target, true
+}
+
+// scanPattern lists the repositories matching a pattern of the projects of a
+// category, leaving out the paths already seen and the matches that are not
+// repositories. A pattern matching no repository is a warning, to catch typos.
+func (s *Scanner) scanPattern(category config.Category, pattern string, seen map[string]bool) []Project {
+	matches, err := config.GlobProjects(pattern)
+	if err != nil {
+		s.warn(category.Name, pattern, "invalid pattern")
+		return nil
+	}
+
 	var projects []Project
-	s.scanRecursiveHelper(rootPath, rootPath, categoryName, ignored, &projects)
+	found := false
+	for _, path := range matches {
+		kind := vcs.Detect(path)
+		if kind == "" {
+			continue
+		}
+		found = true
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		projectName := filepath.Base(path)
+		ignoredBy, ignored := s.matchIgnore(projectName, category.Ignore)
+		if ignored && !s.IncludeIgnored {
+			continue
+		}
+		projects = append(projects, Project{
+			Name:       projectName,
+			Path:       path,
+			Category:   category.Name,
+			Repository: vcs.Open(kind, path, projectName, s.config.Backend),
+			Origin:     OriginExplicit,
+			IgnoredBy:  ignoredBy,
+		})
+	}
+	if !found {
+		s.warnDead(category.Name, pattern, "matches no repository")
+	}
 	return projects
 }
 
-func (s *Scanner) scanRecursiveHelper(basePath, currentPath, categoryName string, ignored []string, projects *[]Project) {
+// rootWalk is the state of the scan of a category root
+type rootWalk struct {
+	basePath     string
+	categoryName string
+	ignored      []string
+	maxDepth     int              // Levels below basePath scanned (0 = unlimited)
+	skipDirs     []string         // Names or patterns of the directories skipped
+	skipHidden   bool             // Skip the directories whose name starts with a dot
+	follow       bool             // Recurse into symlinked directories (follow_symlinks)
+	nested       bool             // Recurse into repositories (scan_nested)
+	dirs         map[string]int64 // Modification times of the directories walked, when recorded for the Cache
+	visited      map[string]bool  // Real paths of the directories scanned, when following symlinks
+	projects     []Project
+}
+
+// skips reports whether the directory name is left out of the scan (scan.skip_dirs)
+func (w *rootWalk) skips(name string) bool {
+	if w.skipHidden && strings.HasPrefix(name, ".") {
+		return true
+	}
+	for _, pattern := range w.skipDirs {
+		if matched, err := filepath.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// record keeps the modification time of the directory at path for the Cache, when
+// recorded; one that cannot be read gets -1, so that it is always scanned again
+func (w *rootWalk) record(path string) {
+	if w.dirs == nil {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		w.dirs[path] = -1
+		return
+	}
+	w.dirs[path] = info.ModTime().UnixNano()
+}
+
+// enter reports whether the directory at path is to be scanned: always, unless
+// symlinks are followed and its real path was already scanned (a circular link,
+// or two links to the same directory)
+func (w *rootWalk) enter(path string) bool {
+	if !w.follow {
+		return true
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	if w.visited[realPath] {
+		return false
+	}
+	w.visited[realPath] = true
+	return true
+}
+
+// scanRoot recursively scans the root of a category for git repositories, at most
+// max_depth levels below it (0 = unlimited): with 2, root/a/b is still a project.
+// Symlinks to repositories and broken ones are listed, and symlinked directories only
+// scanned with follow_symlinks. Repositories are only scanned inside with scan_nested.
+// With recordDirs, the walk records the directories read.
+func (s *Scanner) scanRoot(ctx context.Context, category config.Category, recordDirs bool) *rootWalk {
+	rootPath := config.ExpandPath(category.Root)
+	walk := &rootWalk{
+		basePath:     rootPath,
+		categoryName: category.Name,
+		ignored:      category.Ignore,
+		maxDepth:     s.config.MaxDepthFor(category.Name),
+		skipDirs:     s.config.SkipDirsFor(category.Name),
+		skipHidden:   s.config.Scan.SkipHidden,
+		follow:       category.FollowSymlinks,
+		nested:       category.ScanNested,
+		visited:      make(map[string]bool),
+	}
+	if recordDirs {
+		walk.dirs = make(map[string]int64)
+		walk.record(rootPath)
+	}
+	markers, excluded := markersOf(nil, rootPath)
+	if excluded && !s.IncludeIgnored {
+		return walk
+	}
+	if walk.enter(rootPath) {
+		s.scanRecursiveHelper(ctx, walk, rootPath, 1, markers)
+	}
+	if s.Unversioned && category.ReportUntrackedDirs && ctx.Err() == nil {
+		s.listUnversioned(walk, markers)
+	}
+	return walk
+}
+
+// listUnversioned adds to the projects of walk the directories right under its root
+// holding files but no repository (report_untracked_dirs), unless skipped or ignored
+func (s *Scanner) listUnversioned(walk *rootWalk, markers []dirIgnore) {
+	entries, err := os.ReadDir(walk.basePath)
+	if err != nil {
+		return // Already warned about
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(walk.basePath, name)
+		if !entry.IsDir() || walk.skips(name) || vcs.Detect(path) != "" || walk.holdsProject(path) {
+			continue
+		}
+		markers, excluded := markersOf(markers, path)
+		if excluded && !s.IncludeIgnored || !walk.holdsFiles(path) {
+			continue
+		}
+		if pattern, isIgnored := s.matchScanIgnore(name, path, walk.ignored, markers); !isIgnored || s.IncludeIgnored {
+			walk.projects = append(walk.projects, Project{
+				Name:        name,
+				Path:        path,
+				Category:    walk.categoryName,
+				Origin:      OriginScanned,
+				IgnoredBy:   pattern,
+				Unversioned: true,
+			})
+		}
+	}
+}
+
+// holdsProject reports whether a project was found under dir
+func (w *rootWalk) holdsProject(dir string) bool {
+	for _, project := range w.projects {
+		if strings.HasPrefix(project.Path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// holdsFiles reports whether dir holds a file, at any depth, leaving out hidden files
+// (such as .DS_Store) and skipped directories
+func (w *rootWalk) holdsFiles(dir string) bool {
+	found := false
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return nil
+		case entry.IsDir():
+			if path != dir && w.skips(entry.Name()) {
+				return fs.SkipDir
+			}
+		case entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), "."):
+			found = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// scanRecursiveHelper scans the entries of currentPath, which are depth levels below the
+// root, under the ignore markers of currentPath and of the directories above it
+func (s *Scanner) scanRecursiveHelper(ctx context.Context, walk *rootWalk, currentPath string, depth int, markers []dirIgnore) {
+	if ctx.Err() != nil {
+		return
+	}
+	basePath, categoryName, ignored, projects := walk.basePath, walk.categoryName, walk.ignored, &walk.projects
+
+	walk.record(currentPath)
 	entries, err := os.ReadDir(currentPath)
 	if err != nil {
+		if walk.dirs != nil {
+			walk.dirs[currentPath] = -1 // Scanned again until readable
+		}
+		if currentPath == basePath && errors.Is(err, fs.ErrPermission) {
+			// The whole category is lost: say so rather than naming a directory
+			s.Warnings = append(s.Warnings, Warning{
+				Category: categoryName,
+				Path:     config.ContractPath(currentPath),
+				Message:  "root is not readable by the current user (permission denied)",
+				Denied:   true,
+			})
+			return
+		}
+		s.warnRead(categoryName, config.ContractPath(currentPath), err)
 		return
 	}
 
@@ -115,24 +536,33 @@ func (s *Scanner) scanRecursiveHelper(basePath, currentPath, categoryName string
 			symlinkTarget = target
 
 			// Skip ignored before any expensive I/O on the target
-			if s.shouldIgnore(name) {
+			if walk.skips(name) {
+				continue
+			}
+			markers, excluded := markersOf(markers, fullPath)
+			if excluded && !s.IncludeIgnored {
 				continue
 			}
 
-			// Try git repo check first (single stat on target/.git)
-			if git.IsGitRepository(fullPath) {
+			// Try repository check first (single stat on target/.git)
+			if kind := vcs.Detect(fullPath); kind != "" {
+				if s.skipsBare(fullPath) {
+					continue
+				}
 				relPath, relErr := filepath.Rel(basePath, fullPath)
 				if relErr != nil {
 					relPath = name
 				}
-				if !s.isIgnored(relPath, ignored) {
+				if pattern, isIgnored := s.matchScanIgnore(relPath, fullPath, ignored, markers); !isIgnored || s.IncludeIgnored {
 					*projects = append(*projects, Project{
 						Name:          relPath,
 						Path:          fullPath,
 						Category:      categoryName,
-						Repository:    git.NewRepository(fullPath, relPath),
+						Repository:    vcs.Open(kind, fullPath, relPath, s.config.Backend),
 						IsSymlink:     true,
 						SymlinkTarget: symlinkTarget,
+						Origin:        OriginScanned,
+						IgnoredBy:     pattern,
 					})
 				}
 				continue
@@ -146,84 +576,170 @@ func (s *Scanner) scanRecursiveHelper(basePath, currentPath, categoryName string
 				if relErr != nil {
 					relPath = name
 				}
-				if !s.isIgnored(relPath, ignored) {
+				if pattern, isIgnored := s.matchScanIgnore(relPath, fullPath, ignored, markers); !isIgnored || s.IncludeIgnored {
 					*projects = append(*projects, Project{
 						Name:          relPath,
 						Path:          fullPath,
 						Category:      categoryName,
 						IsSymlink:     true,
 						SymlinkTarget: symlinkTarget,
+						Origin:        OriginScanned,
+						IgnoredBy:     pattern,
 					})
 				}
 				continue
 			}
 
-			if !info.IsDir() {
+			if !info.IsDir() || !walk.follow || depth == walk.maxDepth || !walk.enter(fullPath) {
 				continue
 			}
 
-			// Symlink to a non-git directory: recurse
-			s.scanRecursiveHelper(basePath, fullPath, categoryName, ignored, projects)
+			// Symlink to a non-git directory: recurse (follow_symlinks)
+			s.scanRecursiveHelper(ctx, walk, fullPath, depth+1, markers)
 			continue
 		} else if !isDir {
 			continue
 		}
 
-		// Skip ignored directories (hardcoded)
-		if s.shouldIgnore(name) {
+		// Skip the directories of scan.skip_dirs, and those holding an ignore marker.
+		// Inside repositories (scan_nested), their metadata and submodules are skipped too.
+		if walk.skips(name) || walk.nested && (slices.Contains(metadataDirs, name) || isSubmodule(fullPath)) {
+			continue
+		}
+		markers, excluded := markersOf(markers, fullPath)
+		if excluded && !s.IncludeIgnored {
 			continue
 		}
 
-		// If this directory is a git repo, check if it should be added
-		if git.IsGitRepository(fullPath) {
+		// If this directory is a repository, check if it should be added
+		if kind := vcs.Detect(fullPath); kind != "" {
+			if s.skipsBare(fullPath) {
+				continue
+			}
 			relPath, err := filepath.Rel(basePath, fullPath)
 			if err != nil {
 				relPath = name
 			}
 
-			if !s.isIgnored(relPath, ignored) {
+			if pattern, isIgnored := s.matchScanIgnore(relPath, fullPath, ignored, markers); !isIgnored || s.IncludeIgnored {
 				*projects = append(*projects, Project{
 					Name:       relPath,
 					Path:       fullPath,
 					Category:   categoryName,
-					Repository: git.NewRepository(fullPath, relPath),
+					Repository: vcs.Open(kind, fullPath, relPath, s.config.Backend),
+					Origin:     OriginScanned,
+					IgnoredBy:  pattern,
 				})
 			}
 
-			continue
+			if !walk.nested {
+				continue
+			}
 		}
 
-		// Recurse into subdirectories
-		s.scanRecursiveHelper(basePath, fullPath, categoryName, ignored, projects)
+		// Recurse into subdirectories, down to the maximum depth
+		if depth != walk.maxDepth && walk.enter(fullPath) {
+			s.scanRecursiveHelper(ctx, walk, fullPath, depth+1, markers)
+		}
 	}
 }
 
-// shouldIgnore checks if a directory name should be ignored
-// These are common patterns that should always be skipped during scanning
-func (s *Scanner) shouldIgnore(name string) bool {
-	// Common directories that should never be scanned for git repos
-	commonIgnored := []string{"node_modules", ".DS_Store"}
-	for _, ignored := range commonIgnored {
-		if name == ignored {
-			return true
+// metadataDirs are the directories of repositories holding their metadata
+var metadataDirs = []string{".git", ".hg"}
+
+// isSubmodule reports whether path is a git submodule checkout, whose .git file points
+// into the modules of its parent repository
+func isSubmodule(path string) bool {
+	data, err := os.ReadFile(filepath.Join(path, ".git"))
+	return err == nil && strings.HasPrefix(string(data), "gitdir:") && strings.Contains(filepath.ToSlash(string(data)), "/modules/")
+}
+
+// sortProjects orders the projects found under a root as set by scan.sort, so that
+// the same tree always gives the same order
+func sortProjects(projects []Project, order string) {
+	switch order {
+	case config.ScanSortNone:
+	case config.ScanSortPath:
+		slices.SortStableFunc(projects, func(a, b Project) int {
+			return comparePaths(a.Name, b.Name)
+		})
+	default:
+		slices.SortStableFunc(projects, func(a, b Project) int {
+			if c := config.CompareNatural(filepath.Base(a.Name), filepath.Base(b.Name)); c != 0 {
+				return c
+			}
+			return comparePaths(a.Name, b.Name)
+		})
+	}
+}
+
+// comparePaths orders paths segment by segment, so that a directory and what it holds
+// stay together (a/b, a/c, a-b rather than a/b, a-b, a/c)
+func comparePaths(a, b string) int {
+	x, y := strings.Split(a, string(filepath.Separator)), strings.Split(b, string(filepath.Separator))
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if c := config.CompareNatural(x[i], y[i]); c != 0 {
+			return c
 		}
 	}
-	return false
+	return len(x) - len(y)
+}
+
+// skipsBare reports whether path is a bare repository left out of the scan (display.bare: hide)
+func (s *Scanner) skipsBare(path string) bool {
+	return s.config.Display.Bare == config.BareHide && vcs.IsBare(path)
+}
+
+// matchScanIgnore returns what ignores a project found at path under a root, relPath
+// below it: one of the ignore markers above it, or a pattern (see matchIgnore)
+func (s *Scanner) matchScanIgnore(relPath, path string, ignored []string, markers []dirIgnore) (string, bool) {
+	if ignoredBy, ok := matchMarkers(markers, path); ok {
+		return ignoredBy, true
+	}
+	return s.matchIgnore(relPath, ignored)
+}
+
+// matchIgnore returns the first pattern matching a project path, among the
+// category's ignore list then the config's ExtraIgnore patterns
+func (s *Scanner) matchIgnore(projectPath string, ignored []string) (string, bool) {
+	if pattern, ok := matchPatterns(projectPath, ignored); ok {
+		return pattern, true
+	}
+	pattern, ok := matchPatterns(projectPath, s.config.ExtraIgnore)
+	if ok && !s.IncludeIgnored {
+		s.SkippedByExtra++
+	}
+	return pattern, ok
 }
 
-// isIgnored checks if a project path matches any ignored pattern from config
-func (s *Scanner) isIgnored(projectPath string, ignored []string) bool {
-	for _, pattern := range ignored {
+// MatchesPattern reports whether an ignore pattern matches a project path
+// (relative to its category root, or its name for explicit projects)
+func MatchesPattern(projectPath, pattern string) bool {
+	_, ok := matchPatterns(projectPath, []string{pattern})
+	return ok
+}
+
+// matchPatterns returns the first of patterns matching a project path
+func matchPatterns(projectPath string, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		// Regular expression (e.g., "re:^sandbox-\d+$"), tried on the basename too like wildcards
+		if re, ok, err := config.IgnoreRegexp(pattern); ok {
+			if err == nil && (re.MatchString(projectPath) || re.MatchString(filepath.Base(projectPath))) {
+				return pattern, true
+			}
+			continue
+		}
+
 		// Exact match
 		if projectPath == pattern || filepath.Base(projectPath) == pattern {
-			return true
+			return pattern, true
 		}
 
 		// Prefix match with wildcard (e.g., "_archives/*" matches "_archives/anything")
 		if strings.HasSuffix(pattern, "/*") {
 			prefix := strings.TrimSuffix(pattern, "/*")
 			if strings.HasPrefix(projectPath, prefix+"/") || projectPath == prefix {
-				return true
+				return pattern, true
 			}
 		}
 
@@ -231,14 +747,14 @@ func (s *Scanner) isIgnored(projectPath string, ignored []string) bool {
 		if strings.Contains(pattern, "*") {
 			matched, err := filepath.Match(pattern, projectPath)
 			if err == nil && matched {
-				return true
+				return pattern, true
 			}
 			// Also try matching against basename
 			matched, err = filepath.Match(pattern, filepath.Base(projectPath))
 			if err == nil && matched {
-				return true
+				return pattern, true
 			}
 		}
 	}
-	return false
+	return "", false
 }
@@ -0,0 +1,71 @@
+package scanner
+
+import "testing"
+
+func TestMatchesPattern(t *testing.T) {
+	tests := []struct {
+		path    string
+		pattern string
+		want    bool
+	}{
+		// Exact names, on the whole path or the basename
+		{"legacy", "legacy", true},
+		{"clients/acme/legacy", "legacy", true},
+		{"clients/acme/legacy", "clients/acme/legacy", true},
+		{"clients/acme/legacy-app", "legacy", false},
+		{"clients/acme", "legacy", false},
+
+		// Wildcards, on the whole path or the basename
+		{"foo-deprecated", "*-deprecated", true},
+		{"clients/acme/foo-deprecated", "*-deprecated", true},
+		{"clients/acme/api", "clients/*/api", true},
+		{"clients/acme/deep/api", "clients/*/api", false},
+		{"clients/acme/foo-deprecated-2", "*-deprecated", false},
+
+		// Prefixes
+		{"_archives", "_archives/*", true},
+		{"_archives/old", "_archives/*", true},
+		{"_archives/2019/clients/old", "_archives/*", true},
+		{"clients/_archives/old", "_archives/*", false},
+		{"_archives-2019/old", "_archives/*", false},
+
+		// Regular expressions, on the whole path or the basename
+		{"sandbox-7", `re:^sandbox-\d+$`, true},
+		{"clients/acme/sandbox-42", `re:^sandbox-\d+$`, true},
+		{"clients/acme/sandbox-x", `re:^sandbox-\d+$`, false},
+		{"clients/acme/api", `re:^clients/`, true},
+		{"internal/clients/api", `re:^clients/`, false},
+		{"clients/acme/deep/api", `re:^clients/[^/]+/deep/`, true},
+		{"clients/acme/api", `re:(api|web)$`, true},
+		{"clients/acme/api-tools", `re:(api|web)$`, false},
+
+		// Malformed regular expressions match nothing
+		{"(", "re:(", false},
+	}
+	for _, tt := range tests {
+		if got := MatchesPattern(tt.path, tt.pattern); got != tt.want {
+			t.Errorf("MatchesPattern(%q, %q) = %v, want %v", tt.path, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestMatchPatternsReturnsFirstMatch(t *testing.T) {
+	patterns := []string{"re:(", "other", `re:-\d+$`, "*-1"}
+	pattern, ok := matchPatterns("clients/sandbox-1", patterns)
+	if !ok || pattern != `re:-\d+$` {
+		t.Fatalf("got %q, %v, want the regular expression", pattern, ok)
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	for _, pattern := range []string{"legacy", "*-deprecated", "_archives/*", `re:^sandbox-\d+$`} {
+		if err := ValidatePattern(pattern); err != nil {
+			t.Errorf("ValidatePattern(%q) = %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"re:(", "re:[a-"} {
+		if err := ValidatePattern(pattern); err == nil {
+			t.Errorf("ValidatePattern(%q) accepted a malformed expression", pattern)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Package schema generates the JSON Schemas of the configuration file and of the
+// --output json report from their Go structs, so that both stay in sync.
+//
+// Field names come from the yaml (config) or json (report) tags, descriptions from
+// desc tags and allowed values from enum tags (comma-separated). Config fields are
+// required when tagged required:"true", report fields unless omitted when empty.
+package schema
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+// Version is bumped when a document changes in a way its previous schema rejects
+const Version = 1
+
+// BaseURL is where the schemas of this Version are published (the schemas directory of the repository)
+const BaseURL = "https://raw.githubusercontent.com/uralys/check-projects/main/schemas/v1/"
+
+// Names of the documents described by a schema
+const (
+	NameConfig = "config"
+	NameReport = "report"
+)
+
+// Names lists every schema, as accepted by Get
+var Names = []string{NameConfig, NameReport}
+
+// Schema is a JSON Schema document or subschema
+type Schema map[string]interface{}
+
+var (
+	durationType   = reflect.TypeOf(config.Duration(0))
+	patternsType   = reflect.TypeOf(config.Patterns(nil))
+	statusTypeType = reflect.TypeOf(git.StatusType(""))
+)
+
+// Get returns the schema with the given name, ok is false for unknown names
+func Get(name string) (schema Schema, ok bool) {
+	switch name {
+	case NameConfig:
+		return Config(), true
+	case NameReport:
+		return Report(), true
+	}
+	return nil, false
+}
+
+// Config returns the schema of the configuration file
+func Config() Schema {
+	g := generator{tag: "yaml", strict: true}
+	return document(NameConfig, "check-projects configuration", g.schemaOf(reflect.TypeOf(config.Config{})))
+}
+
+// Report returns the schema of the document written by --output json
+func Report() Schema {
+	g := generator{tag: "json"}
+	return document(NameReport, "check-projects JSON report", g.schemaOf(reflect.TypeOf(reporter.JSONReport{})))
+}
+
+// URL returns where the schema with the given name is published
+func URL(name string) string {
+	return BaseURL + name + ".json"
+}
+
+func document(name, title string, root Schema) Schema {
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["$id"] = URL(name)
+	root["title"] = title
+	return root
+}
+
+// generator turns Go types into schemas
+type generator struct {
+	tag    string // Struct tag naming the fields: yaml or json
+	strict bool   // Reject unknown properties, and only require fields tagged required:"true"
+}
+
+func (g generator) schemaOf(t reflect.Type) Schema {
+	switch t {
+	case durationType:
+		return Schema{
+			"type":    "string",
+			"pattern": `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h|d|w))+)$`,
+		}
+	case patternsType:
+		return Schema{"anyOf": []Schema{
+			{"type": "string"},
+			{"type": "array", "items": Schema{"type": "string"}},
+		}}
+	case statusTypeType:
+		values := make([]string, len(git.StatusTypes))
+		for i, status := range git.StatusTypes {
+			values[i] = string(status)
+		}
+		return Schema{"type": "string", "enum": values}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaOf(t.Elem())
+	case reflect.Struct:
+		return g.structSchema(t)
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	}
+	return Schema{}
+}
+
+func (g generator) structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get(g.tag), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		property := g.schemaOf(field.Type)
+		if desc := field.Tag.Get("desc"); desc != "" {
+			property["description"] = desc
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			property["enum"] = strings.Split(enum, ",")
+		}
+		properties[name] = property
+
+		omitEmpty := strings.Contains(","+options+",", ",omitempty,")
+		if field.Tag.Get("required") == "true" || (!g.strict && !omitEmpty) {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	if g.strict {
+		schema["additionalProperties"] = false
+	}
+	return schema
+}
@@ -0,0 +1,178 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+	"gopkg.in/yaml.v3"
+)
+
+// encode serializes s as check-projects schema does
+func encode(t *testing.T, s Schema) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// compile returns the validator of the schema document data
+func compile(t *testing.T, name string, data []byte) *jsonschema.Schema {
+	t.Helper()
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(URL(name), bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	validator, err := compiler.Compile(URL(name))
+	if err != nil {
+		t.Fatalf("schema %s does not compile: %v", name, err)
+	}
+	return validator
+}
+
+// validators returns the validators of the published schema with the given name and
+// of the one generated from the code, which must not differ
+func validators(t *testing.T, name string) []*jsonschema.Schema {
+	t.Helper()
+	published, err := os.ReadFile(filepath.Join("..", "..", "schemas", "v1", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := Get(name)
+	generated := encode(t, s)
+	if !bytes.Equal(published, generated) {
+		t.Errorf("schemas/v1/%s.json is out of date, run make schemas", name)
+	}
+	return []*jsonschema.Schema{compile(t, name, published), compile(t, name, generated)}
+}
+
+// fromYAML decodes a YAML document into the values a JSON document would give
+func fromYAML(t *testing.T, data []byte) interface{} {
+	t.Helper()
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return fromJSON(t, doc)
+}
+
+// fromJSON round-trips v through JSON, as a consumer of the document reads it
+func fromJSON(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestExampleConfigMatchesSchema(t *testing.T) {
+	example, err := os.ReadFile(filepath.Join("..", "..", "check-projects.example.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template, err := config.Template(URL(NameConfig), []config.Category{{Name: "dev", Root: "~/dev"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, validator := range validators(t, NameConfig) {
+		if err := validator.Validate(fromYAML(t, example)); err != nil {
+			t.Errorf("check-projects.example.yml: %v", err)
+		}
+		if err := validator.Validate(fromYAML(t, template)); err != nil {
+			t.Errorf("init template: %v", err)
+		}
+	}
+}
+
+func TestConfigSchemaRejectsInvalidConfigs(t *testing.T) {
+	tests := []string{
+		"categories:\n  - root: ~/dev\n",                   // No name
+		"categories: []\nfetch_backoff: soon\n",            // Not a duration
+		"categories: []\nbackend: svn\n",                   // Not in the enum
+		"categories: []\nunknown_option: true\n",           // Unknown property
+		"categories: []\nwebhooks:\n  - only_on: always\n", // No URL
+	}
+	validator := compile(t, NameConfig, encode(t, Config()))
+	for _, document := range tests {
+		if err := validator.Validate(fromYAML(t, []byte(document))); err == nil {
+			t.Errorf("accepted %q", document)
+		}
+	}
+}
+
+func TestTemplateStartsWithSchemaHint(t *testing.T) {
+	template, err := config.Template(URL(NameConfig), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# yaml-language-server: $schema=" + URL(NameConfig) + "\n"; !strings.HasPrefix(string(template), want) {
+		t.Errorf("template starts with %q, want %q", strings.SplitN(string(template), "\n", 2)[0], want)
+	}
+}
+
+func TestExampleReportMatchesSchema(t *testing.T) {
+	results := []reporter.ProjectResult{
+		{Name: "api", Category: "work", Path: "/home/me/work/api", VCS: "git", Status: &git.Status{
+			Type: git.StatusSync, Message: "Up to date", Symbol: "✔", Branch: "main",
+			Origin:      &git.RemoteInfo{Name: "origin", URL: "git@github.com:acme/api.git", Host: "github.com", Owner: "acme", Repo: "api"},
+			LastFetched: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+			RemoteComparisons: []git.RemoteComparison{
+				{Remote: "upstream", Ref: "upstream/main", Ahead: 1, Behind: 2},
+			},
+		}},
+		{Name: "web", Category: "work", Path: "/home/me/work/web", VCS: "git", Status: &git.Status{
+			Type: git.StatusUnsync, Message: "2 modified", Symbol: "M", Branch: "topic",
+			BehindBranches: []git.BranchTracking{{Branch: "main", Message: "behind by 3 commit(s)"}},
+			Operation:      git.OperationRebase, StashCount: 1,
+		}},
+		{Name: "lib", Category: "oss", Path: "/home/me/oss/lib", Status: &git.Status{
+			Type: git.StatusRemoteUnreachable, Message: "Remote origin is unreachable", Symbol: "⊘",
+			Remote: &git.RemoteError{Remote: "origin", URL: "https://example.com/lib.git", Class: git.RemoteDNS, Message: "Could not resolve host"},
+		}},
+		{Name: "broken", Category: "oss", Path: "/home/me/oss/broken", Status: &git.Status{Type: git.StatusError, Message: "not a git repository", Symbol: "❌"}},
+	}
+
+	var out bytes.Buffer
+	rep, err := reporter.New(reporter.FormatJSON, config.DefaultConfig(), false, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.Report(results)
+
+	var doc interface{}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	for _, validator := range validators(t, NameReport) {
+		if err := validator.Validate(doc); err != nil {
+			t.Errorf("report: %v\n%s", err, out.String())
+		}
+	}
+
+	// Without omitempty fields
+	empty := fromJSON(t, reporter.NewJSONReport(nil))
+	for _, validator := range validators(t, NameReport) {
+		if err := validator.Validate(empty); err != nil {
+			t.Errorf("empty report: %v", err)
+		}
+	}
+}
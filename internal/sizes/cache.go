@@ -0,0 +1,114 @@
+package sizes
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxCacheAge bounds how long a cached size is trusted, since changes deep in the
+// tree do not show in the modification times the cache is keyed on
+const MaxCacheAge = 7 * 24 * time.Hour
+
+// DefaultCachePath returns where measured sizes are cached
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "check-projects", "sizes.json"), nil
+}
+
+// cacheEntry is the size of a project measured at a given state
+type cacheEntry struct {
+	Stamp      string    `json:"stamp"` // See stamp
+	Bytes      int64     `json:"bytes"`
+	GitBytes   int64     `json:"git_bytes"`
+	MeasuredAt time.Time `json:"measured_at"`
+}
+
+// Cache reuses the sizes of projects that did not change since they were measured,
+// keyed by path and modification times
+type Cache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	changed bool
+}
+
+// LoadCache reads the cache at path ("" for a cache that is never saved).
+// A missing or unreadable cache is empty.
+func LoadCache(path string) *Cache {
+	c := &Cache{path: path, entries: make(map[string]cacheEntry)}
+	if path == "" {
+		return c
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &c.entries)
+	}
+	return c
+}
+
+// stamp lists the modification times of the project directory and of the VCS files
+// that change with commits, checkouts and (through the index) edits
+func stamp(path string) string {
+	var times []string
+	for _, name := range []string{"", ".git", filepath.Join(".git", "index"), filepath.Join(".git", "HEAD"), ".hg", filepath.Join(".hg", "dirstate")} {
+		if info, err := os.Stat(filepath.Join(path, name)); err == nil {
+			times = append(times, strconv.FormatInt(info.ModTime().UnixNano(), 10))
+		} else {
+			times = append(times, "-")
+		}
+	}
+	return strings.Join(times, ",")
+}
+
+// Measure returns the size of the project at path, from the cache when it did not
+// change since it was measured
+func (c *Cache) Measure(ctx context.Context, path string) (Size, error) {
+	current := stamp(path)
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && entry.Stamp == current && time.Since(entry.MeasuredAt) < MaxCacheAge {
+		return Size{Bytes: entry.Bytes, GitBytes: entry.GitBytes}, nil
+	}
+
+	size, err := Measure(ctx, path)
+	if err != nil {
+		return size, err
+	}
+	c.mu.Lock()
+	c.entries[path] = cacheEntry{Stamp: current, Bytes: size.Bytes, GitBytes: size.GitBytes, MeasuredAt: time.Now()}
+	c.changed = true
+	c.mu.Unlock()
+	return size, nil
+}
+
+// Save writes the cache when sizes were measured, dropping expired entries
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.path == "" || !c.changed {
+		return nil
+	}
+
+	for path, entry := range c.entries {
+		if time.Since(entry.MeasuredAt) >= MaxCacheAge {
+			delete(c.entries, path)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
@@ -0,0 +1,93 @@
+// Package sizes measures the disk usage of projects, separating their .git
+// directory from the working tree.
+package sizes
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Size is the disk usage of a project
+type Size struct {
+	Bytes    int64 // Whole project, .git included
+	GitBytes int64 // .git directory only (.hg for Mercurial)
+}
+
+// Measure sums the apparent size of the files under path (following path itself when
+// it is a symlink). Symlinks inside are not followed, and nested repositories are left
+// out since they are projects of their own. Unreadable entries are skipped.
+func Measure(ctx context.Context, path string) (Size, error) {
+	root, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return Size{}, err
+	}
+	gitDir := filepath.Join(root, ".git")
+	if _, err := os.Lstat(gitDir); err != nil {
+		gitDir = filepath.Join(root, ".hg")
+	}
+
+	var size Size
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil // Unreadable entry: counted as empty
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if d.IsDir() {
+			if p != root && p != gitDir && isRepository(p) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size.Bytes += info.Size()
+		if isUnder(p, gitDir) {
+			size.GitBytes += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// isRepository reports whether dir holds a working copy (.git directory or file, or .hg)
+func isRepository(dir string) bool {
+	for _, name := range []string{".git", ".hg"} {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func isUnder(path, dir string) bool {
+	return len(path) > len(dir) && path[:len(dir)] == dir && path[len(dir)] == filepath.Separator
+}
+
+// Format renders a size in binary units with one decimal, e.g. "1.4 GiB" or "512 B"
+func Format(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value, exp := float64(bytes)/unit, 0
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTP"[exp])
+}
@@ -0,0 +1,168 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+var title = color.New(color.Bold, color.Underline).SprintFunc()
+
+// column is a status class of the per-category table
+type column struct {
+	header   string
+	count    func(reporter.Summary) int
+	optional bool // Only shown when a category has such projects
+}
+
+var columns = []column{
+	{"TOTAL", func(s reporter.Summary) int { return s.Total }, false},
+	{"CLEAN", func(s reporter.Summary) int { return s.Clean }, false},
+	{"STALE", func(s reporter.Summary) int { return s.Stale }, true},
+	{"CHANGES", func(s reporter.Summary) int { return s.Changes }, false},
+	{"BEHIND", func(s reporter.Summary) int { return s.Behind }, false},
+	{"NO UPSTREAM", func(s reporter.Summary) int { return s.NoUpstream }, true},
+	{"NO REMOTE", func(s reporter.Summary) int { return s.NoRemote }, true},
+	{"UNREACHABLE", func(s reporter.Summary) int { return s.Unreachable }, true},
+	{"AUTH", func(s reporter.Summary) int { return s.AuthRequired }, true},
+	{"ERRORS", func(s reporter.Summary) int { return s.Errors }, false},
+	{"UNCHECKED", func(s reporter.Summary) int { return s.Unchecked }, true},
+}
+
+// Render writes the report as text tables
+func Render(w io.Writer, report Report) {
+	renderCategories(w, report.Categories)
+	renderDirtiest(w, report.Dirtiest)
+	renderOldestChange(w, report.OldestChange, report.GeneratedAt)
+	renderHistory(w, report)
+}
+
+func renderCategories(w io.Writer, categories []CategoryCounts) {
+	var all reporter.Summary
+	for _, category := range categories {
+		all = add(all, category.Summary)
+	}
+	var shown []column
+	for _, col := range columns {
+		if !col.optional || col.count(all) > 0 {
+			shown = append(shown, col)
+		}
+	}
+
+	fmt.Fprintln(w, title("Projects by category"))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := []string{"CATEGORY"}
+	for _, col := range shown {
+		headers = append(headers, col.header)
+	}
+	fmt.Fprintf(tw, "  %s\n", strings.Join(headers, "\t"))
+	row := func(name string, summary reporter.Summary) {
+		cells := []string{name}
+		for _, col := range shown {
+			cells = append(cells, fmt.Sprint(col.count(summary)))
+		}
+		fmt.Fprintf(tw, "  %s\n", strings.Join(cells, "\t"))
+	}
+	for _, category := range categories {
+		row(category.Category, category.Summary)
+	}
+	if len(categories) > 1 {
+		row("all", all)
+	}
+	tw.Flush()
+}
+
+// add sums two summaries, class by class
+func add(a, b reporter.Summary) reporter.Summary {
+	return reporter.Summary{
+		Total:        a.Total + b.Total,
+		Clean:        a.Clean + b.Clean,
+		Stale:        a.Stale + b.Stale,
+		Changes:      a.Changes + b.Changes,
+		Behind:       a.Behind + b.Behind,
+		NoUpstream:   a.NoUpstream + b.NoUpstream,
+		NoRemote:     a.NoRemote + b.NoRemote,
+		Unreachable:  a.Unreachable + b.Unreachable,
+		AuthRequired: a.AuthRequired + b.AuthRequired,
+		Errors:       a.Errors + b.Errors,
+		Unchecked:    a.Unchecked + b.Unchecked,
+	}
+}
+
+func renderDirtiest(w io.Writer, dirtiest []Dirty) {
+	fmt.Fprintf(w, "\n%s\n", title("Dirtiest repositories"))
+	if len(dirtiest) == 0 {
+		fmt.Fprintln(w, "  None: every repository is clean")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, dirty := range dirtiest {
+		fmt.Fprintf(tw, "  %2d.\t%s/%s\t%d\t%s\n", i+1, dirty.Category, dirty.Name, dirty.Score, dirty.breakdown())
+	}
+	tw.Flush()
+}
+
+// breakdown tells what the score is made of, e.g. "2 conflicts, 5 changed files"
+func (d Dirty) breakdown() string {
+	var parts []string
+	count := func(n int, one, many string) {
+		switch {
+		case n == 1:
+			parts = append(parts, "1 "+one)
+		case n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", n, many))
+		}
+	}
+	count(d.Conflicts, "conflict", "conflicts")
+	count(d.Changes, "changed file", "changed files")
+	count(d.Behind, "commit behind", "commits behind")
+	count(d.StaleDays, "day without commits", "days without commits")
+	return strings.Join(parts, ", ")
+}
+
+func renderOldestChange(w io.Writer, change *Change, now time.Time) {
+	if change == nil {
+		return
+	}
+	days := int(now.Sub(change.ChangedAt).Hours() / 24)
+	fmt.Fprintf(w, "\n%s\n", title("Oldest uncommitted change"))
+	fmt.Fprintf(w, "  %s/%s: %d day(s) old (%s)\n", change.Category, change.Name, days, change.ChangedAt.Local().Format("2006-01-02"))
+}
+
+// renderHistory lists the totals of the previous snapshots, then the run's with
+// arrows telling how they moved since the latest snapshot
+func renderHistory(w io.Writer, report Report) {
+	previous, ok := report.Previous()
+	if !ok {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", title("Over time"))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  DATE\tTOTAL\tCLEAN\tATTENTION\tERRORS")
+	for _, totals := range report.History {
+		fmt.Fprintf(tw, "  %s\t%d\t%d\t%d\t%d\n", totals.At.Local().Format("2006-01-02 15:04"),
+			totals.Total, totals.Clean, totals.Attention, totals.Errors)
+	}
+	current := report.Totals
+	fmt.Fprintf(tw, "  now\t%s\t%s\t%s\t%s\n",
+		trend(current.Total, previous.Total), trend(current.Clean, previous.Clean),
+		trend(current.Attention, previous.Attention), trend(current.Errors, previous.Errors))
+	tw.Flush()
+}
+
+// trend renders a count with how it moved since before, e.g. "203 ↑3"
+func trend(now, before int) string {
+	switch {
+	case now > before:
+		return fmt.Sprintf("%d ↑%d", now, now-before)
+	case now < before:
+		return fmt.Sprintf("%d ↓%d", now, before-now)
+	default:
+		return fmt.Sprintf("%d =", now)
+	}
+}
@@ -0,0 +1,197 @@
+// Package stats aggregates check results into an overview of repository hygiene:
+// counts by status per category, the dirtiest repositories, the oldest uncommitted
+// change, and totals over time from the history snapshots.
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/history"
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+// TopCount is the number of repositories listed in Report.Dirtiest
+const TopCount = 10
+
+// HistoryCount is the number of previous snapshots listed in Report.History
+const HistoryCount = 5
+
+// Weights of the dirtiness score: conflicts weigh more than changed files, which
+// weigh more than commits behind, which weigh more than the age of stale projects
+const (
+	ConflictWeight = 100 // Per unmerged file
+	ChangeWeight   = 10  // Per changed file
+	BehindWeight   = 2   // Per commit behind the upstream
+	StaleWeight    = 1   // Per 30 days without commits, for stale projects
+)
+
+// Report is the overview printed by the stats command
+type Report struct {
+	GeneratedAt  time.Time        `json:"generated_at"`
+	Totals       Totals           `json:"totals"`
+	Categories   []CategoryCounts `json:"categories"`
+	Dirtiest     []Dirty          `json:"dirtiest"`
+	OldestChange *Change          `json:"oldest_change,omitempty"`
+	History      []Totals         `json:"history"` // Previous snapshots, oldest first
+}
+
+// Totals counts the projects of a run
+type Totals struct {
+	At        time.Time `json:"at"`
+	Total     int       `json:"total"`
+	Clean     int       `json:"clean"`     // Needing no attention, stale included
+	Attention int       `json:"attention"` // Changes, behind, no upstream, remote issues
+	Errors    int       `json:"errors"`    // Could not be checked
+}
+
+// CategoryCounts counts the projects of a category by status class
+type CategoryCounts struct {
+	Category string `json:"category"`
+	reporter.Summary
+}
+
+// Dirty is a repository of the Dirtiest ranking, with what its score is made of
+type Dirty struct {
+	Category  string `json:"category"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Score     int    `json:"score"`
+	Conflicts int    `json:"conflicts"`
+	Changes   int    `json:"changes"`
+	Behind    int    `json:"behind"`
+	StaleDays int    `json:"stale_days"`
+}
+
+// Change is the oldest uncommitted change among the repositories
+type Change struct {
+	Category  string    `json:"category"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	ChangedAt time.Time `json:"changed_at"` // Modification time of the changed file
+}
+
+// Score weighs how much a repository needs cleaning up (0 when it does not)
+func Score(status *git.Status, lastCommit, now time.Time) int {
+	return status.Conflicts*ConflictWeight + status.Changes*ChangeWeight + status.Behind*BehindWeight +
+		staleDays(status, lastCommit, now)/30*StaleWeight
+}
+
+// staleDays is the number of days since the last commit of a stale project
+func staleDays(status *git.Status, lastCommit, now time.Time) int {
+	if status.Type != git.StatusStale || lastCommit.IsZero() {
+		return 0
+	}
+	return int(now.Sub(lastCommit).Hours() / 24)
+}
+
+// Build aggregates results, with the totals of snapshots (oldest first) as history
+func Build(results []reporter.ProjectResult, snapshots []history.Snapshot, now time.Time) Report {
+	report := Report{
+		GeneratedAt: now,
+		Totals:      resultTotals(results, now),
+		Categories:  countByCategory(results),
+		Dirtiest:    dirtiest(results, now),
+		History:     []Totals{},
+	}
+
+	for _, result := range results {
+		changedAt := result.Status.OldestChange
+		if changedAt.IsZero() {
+			continue
+		}
+		if report.OldestChange == nil || changedAt.Before(report.OldestChange.ChangedAt) {
+			report.OldestChange = &Change{Category: result.Category, Name: result.Name, Path: result.Path, ChangedAt: changedAt}
+		}
+	}
+
+	for _, snapshot := range snapshots[max(len(snapshots)-HistoryCount, 0):] {
+		report.History = append(report.History, snapshotTotals(snapshot))
+	}
+	return report
+}
+
+// Previous returns the totals of the latest snapshot, to compare the run with
+func (r Report) Previous() (Totals, bool) {
+	if len(r.History) == 0 {
+		return Totals{}, false
+	}
+	return r.History[len(r.History)-1], true
+}
+
+func resultTotals(results []reporter.ProjectResult, at time.Time) Totals {
+	summary := reporter.Summarize(results)
+	return Totals{
+		At:        at,
+		Total:     summary.Total,
+		Clean:     summary.Clean + summary.Stale,
+		Attention: summary.NeedAttention(),
+		Errors:    summary.Errors,
+	}
+}
+
+func snapshotTotals(snapshot history.Snapshot) Totals {
+	totals := Totals{At: snapshot.CreatedAt, Total: len(snapshot.Projects)}
+	for _, project := range snapshot.Projects {
+		switch {
+		case reporter.IsErrorType(project.Status):
+			totals.Errors++
+		case project.Clean:
+			totals.Clean++
+		case project.Status != git.StatusUnchecked:
+			totals.Attention++
+		}
+	}
+	return totals
+}
+
+// countByCategory summarizes each category, in order of first appearance
+func countByCategory(results []reporter.ProjectResult) []CategoryCounts {
+	var categories []string
+	byCategory := make(map[string][]reporter.ProjectResult)
+	for _, result := range results {
+		if _, ok := byCategory[result.Category]; !ok {
+			categories = append(categories, result.Category)
+		}
+		byCategory[result.Category] = append(byCategory[result.Category], result)
+	}
+
+	counts := make([]CategoryCounts, 0, len(categories))
+	for _, category := range categories {
+		counts = append(counts, CategoryCounts{Category: category, Summary: reporter.Summarize(byCategory[category])})
+	}
+	return counts
+}
+
+// dirtiest ranks the repositories with a positive score, highest first, keeping TopCount
+func dirtiest(results []reporter.ProjectResult, now time.Time) []Dirty {
+	ranked := []Dirty{}
+	for _, result := range results {
+		status := result.Status
+		score := Score(status, result.LastCommit, now)
+		if score == 0 {
+			continue
+		}
+		ranked = append(ranked, Dirty{
+			Category:  result.Category,
+			Name:      result.Name,
+			Path:      result.Path,
+			Score:     score,
+			Conflicts: status.Conflicts,
+			Changes:   status.Changes,
+			Behind:    status.Behind,
+			StaleDays: staleDays(status, result.LastCommit, now),
+		})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if ranked[i].Category != ranked[j].Category {
+			return ranked[i].Category < ranked[j].Category
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+	return ranked[:min(len(ranked), TopCount)]
+}
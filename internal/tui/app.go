@@ -1,18 +1,44 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"sync"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/uralys/check-projects/internal/checker"
 	"github.com/uralys/check-projects/internal/config"
 	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
 	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/vcs"
+	"github.com/uralys/check-projects/internal/watcher"
+	"github.com/uralys/check-projects/pkg/checkprojects"
 )
 
-// Run starts the TUI application
-func Run(cfg *config.Config, version string) error {
-	m := NewModel(cfg, version)
+// Run starts the TUI application.
+// Git commands still running when the TUI exits, or when ctx is cancelled, are stopped.
+// With rescan, the first scan does not start from the projects found by the previous run.
+// With watch, projects are checked again as they change on disk, and all of them every
+// watchInterval when set.
+func Run(ctx context.Context, cfg *config.Config, version string, opts checkprojects.Options, rescan bool, watch bool, watchInterval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m := NewModel(ctx, cfg, version, opts)
+	m.rescan = rescan
+	if watch {
+		w, err := watcher.New(watcher.DefaultDebounce)
+		if err != nil {
+			return fmt.Errorf("failed to watch the projects: %w", err)
+		}
+		defer w.Close()
+		m.watcher = w
+		m.changes = w.Changes(ctx)
+		m.watchInterval = watchInterval
+	}
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
@@ -24,98 +50,484 @@ func Run(cfg *config.Config, version string) error {
 
 // Init initializes the model and starts the initial scan
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.spinner.Tick,
-		scanProjectsCmd(m.config),
-	)
+		scanProjectsCmd(m.ctx, m.config, m.checkOptions, m.sortOrder == reporter.SortAge, m.rescan),
+	}
+	if m.watcher != nil {
+		cmds = append(cmds, waitForChange(m.changes))
+	}
+	if m.watchInterval > 0 {
+		cmds = append(cmds, watchTickCmd(m.watchInterval))
+	}
+	return tea.Batch(cmds...)
 }
 
-// scanProjectsCmd scans all projects and returns their status
-func scanProjectsCmd(cfg *config.Config) tea.Cmd {
+// scanProjectsCmd scans all projects, then checks them: their status streams in (see
+// waitForStatus) along with their last commit date when withLastCommits is set. Roots are read from the scan cache unless
+// rescan is set, and then scanned again in the background (see revalidateCmd).
+func scanProjectsCmd(ctx context.Context, cfg *config.Config, opts checkprojects.Options, withLastCommits, rescan bool) tea.Cmd {
 	return func() tea.Msg {
-		// Scan for projects
-		s := scanner.NewScanner(cfg)
-		projects, err := s.ScanAll()
+		// Discover projects
+		s := scanner.NewCachedScanner(cfg, rescan)
+		s.Unversioned = true
+		projects, err := s.ScanAll(ctx)
 		if err != nil {
 			return scanCompleteMsg{err: err}
 		}
+		dead := deadWarnings(s.Warnings)
 
-		// Check git status for each project concurrently
+		// Check git status for each project concurrently, listing them meanwhile
+		opts.LastCommits = withLastCommits
 		results := make([]ProjectWithStatus, len(projects))
-		var wg sync.WaitGroup
-		sem := make(chan struct{}, 10) // Limit concurrency to 10
-
 		for i, project := range projects {
-			wg.Add(1)
-			go func(idx int, proj scanner.Project) {
-				defer wg.Done()
-				sem <- struct{}{}        // Acquire semaphore
-				defer func() { <-sem }() // Release semaphore
+			results[i] = ProjectWithStatus{Project: project}
+		}
 
-				if proj.Repository == nil {
-					results[idx] = ProjectWithStatus{
-						Project: proj,
-						Status:  &git.Status{Type: git.StatusBrokenSymlink, Symbol: "🔗 ✗"},
-					}
-					return
-				}
+		msg := scanCompleteMsg{
+			projects:    results,
+			statuses:    checkprojects.Stream(ctx, projects, opts),
+			lastCommits: withLastCommits,
+			dead:        dead,
+			err:         nil,
+		}
+		if s.FromCache {
+			msg.revalidate = s
+		}
+		return msg
+	}
+}
+
+// waitForStatus reads the next result of the checks started by a scan
+func waitForStatus(statuses <-chan checkprojects.Result) tea.Cmd {
+	return func() tea.Msg {
+		result, ok := <-statuses
+		if !ok {
+			return checksCompleteMsg{statuses: statuses}
+		}
+		return statusMsg{result: result, statuses: statuses}
+	}
+}
+
+// waitForChange reads the next changes seen on disk by the watcher
+func waitForChange(changes <-chan watcher.Change) tea.Cmd {
+	return func() tea.Msg {
+		change, ok := <-changes
+		if !ok {
+			return nil // The TUI exits
+		}
+		return changeMsg{change: change}
+	}
+}
+
+// watchTickCmd waits for the next --watch interval
+func watchTickCmd(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return watchTickMsg{}
+	})
+}
+
+// watchProjectsCmd has the watcher follow the scanned projects
+func watchProjectsCmd(w *watcher.Watcher, cfg *config.Config, projects []ProjectWithStatus) tea.Cmd {
+	scanned := make([]scanner.Project, len(projects))
+	for i, p := range projects {
+		scanned[i] = p.Project
+	}
+
+	return func() tea.Msg {
+		err := w.Set(cfg, scanned)
+		return watchedMsg{paths: w.Paths(), err: err}
+	}
+}
+
+// recheckCmd checks a project again once it changed on disk. Nothing is fetched, as
+// fetching writes to the repository and would be seen as another change.
+func recheckCmd(ctx context.Context, projectWithStatus ProjectWithStatus, projectIndex int, opts checkprojects.Options) tea.Cmd {
+	return func() tea.Msg {
+		status := checker.Recheck(ctx, projectWithStatus.Project, projectWithStatus.LastCommit, opts)
+		return recheckedMsg{projectIndex: projectIndex, path: projectWithStatus.Project.Path, status: status}
+	}
+}
+
+// revalidateCmd scans the roots read from the scan cache again, and checks the
+// projects that appeared since the previous run (those not in known)
+func revalidateCmd(ctx context.Context, s *scanner.Scanner, known []ProjectWithStatus, opts checkprojects.Options, withLastCommits bool) tea.Cmd {
+	checked := make(map[projectKey]bool, len(known))
+	for _, p := range known {
+		checked[keyOf(p.Project)] = true
+	}
+
+	return func() tea.Msg {
+		projects, warnings, err := s.Revalidate(ctx)
+		if err != nil {
+			return rescanCompleteMsg{err: err}
+		}
 
-				status, err := proj.Repository.GetStatus()
-				if err != nil {
-					// Handle error by marking as error status
-					status = &git.Status{
-						Type:    git.StatusError,
-						Message: err.Error(),
-						Symbol:  "❌",
-					}
-				}
+		var appeared []scanner.Project
+		for _, project := range projects {
+			if !checked[keyOf(project)] {
+				appeared = append(appeared, project)
+			}
+		}
+		opts.LastCommits = withLastCommits
+		results, err := checkprojects.Check(ctx, appeared, opts)
+		if err != nil {
+			return rescanCompleteMsg{err: err}
+		}
+		msg := rescanCompleteMsg{
+			projects: projects,
+			appeared: make(map[projectKey]ProjectWithStatus, len(results)),
+			dead:     deadWarnings(warnings),
+		}
+		for _, result := range results {
+			msg.appeared[keyOf(result.Project)] = ProjectWithStatus{
+				Project:    result.Project,
+				Status:     result.Status,
+				LastCommit: result.LastCommit,
+			}
+		}
+		return msg
+	}
+}
 
-				results[idx] = ProjectWithStatus{
-					Project: proj,
-					Status:  status,
-				}
-			}(i, project)
+// deadWarnings keeps the warnings about entries of projects lists listing no repository
+func deadWarnings(warnings []scanner.Warning) []scanner.Warning {
+	var dead []scanner.Warning
+	for _, warning := range warnings {
+		if warning.Dead {
+			dead = append(dead, warning)
 		}
+	}
+	return dead
+}
 
-		wg.Wait()
+// loadLastCommitsCmd loads the last commit date of the scanned projects
+func loadLastCommitsCmd(ctx context.Context, projects []ProjectWithStatus, opts checkprojects.Options) tea.Cmd {
+	scanned := make([]scanner.Project, len(projects))
+	for i, p := range projects {
+		scanned[i] = p.Project
+	}
 
-		return scanCompleteMsg{
-			projects: results,
-			err:      nil,
+	return func() tea.Msg {
+		lastCommits := make(map[string]time.Time, len(scanned))
+		for i, last := range checkprojects.LastCommits(ctx, scanned, opts) {
+			lastCommits[scanned[i].Path] = last
 		}
+		return lastCommitsMsg{lastCommits: lastCommits}
 	}
 }
 
 // fetchProjectCmd fetches a single project and refreshes its status
-func fetchProjectCmd(projectWithStatus *ProjectWithStatus, projectIndex int) tea.Cmd {
+func fetchProjectCmd(ctx context.Context, projectWithStatus *ProjectWithStatus, projectIndex int, opts checkprojects.Options) tea.Cmd {
 	return func() tea.Msg {
 		if projectWithStatus.Project.Repository == nil {
-			return fetchCompleteMsg{projectIndex: projectIndex, err: nil}
+			return fetchCompleteMsg{projectIndex: projectIndex, summary: "Nothing to fetch"}
 		}
 
 		// Fetch from remote
-		if err := projectWithStatus.Project.Repository.Fetch(); err != nil {
+		result, err := checker.FetchWithRetry(ctx, projectWithStatus.Project, opts.Timeout, opts.FetchOptions, opts.FetchRetry)
+		if err != nil {
+			auth := checker.AuthFailure(ctx, projectWithStatus.Project, opts.Timeout, err)
+			if auth != nil && projectWithStatus.Status != nil {
+				projectWithStatus.Status = git.NewAuthRequiredStatus(auth, projectWithStatus.Status)
+			}
 			return fetchCompleteMsg{
 				projectIndex: projectIndex,
 				err:          err,
+				auth:         auth,
 			}
 		}
 
-		// Get updated status after fetch
-		status, err := projectWithStatus.Project.Repository.GetStatus()
+		// Get updated status after fetch (stale once more when it was, clean and without new commits)
+		projectWithStatus.Status = checker.Recheck(ctx, projectWithStatus.Project, projectWithStatus.LastCommit, opts)
+
+		// Only git reports the refs it changed
+		summary := "Fetched"
+		if projectWithStatus.Project.VCS() == vcs.KindGit {
+			summary += ": " + result.String()
+		}
+		return fetchCompleteMsg{
+			projectIndex: projectIndex,
+			summary:      summary,
+		}
+	}
+}
+
+// unshallowProjectCmd fetches the full history of a shallow clone and refreshes its status
+func unshallowProjectCmd(ctx context.Context, projectWithStatus *ProjectWithStatus, projectIndex int, opts checkprojects.Options) tea.Cmd {
+	return func() tea.Msg {
+		repo, ok := projectWithStatus.Project.Repository.(*git.Repository)
+		if !ok {
+			return fetchCompleteMsg{projectIndex: projectIndex, summary: "Nothing to fetch"}
+		}
+
+		fetchCtx, cancel := checker.WithTimeout(ctx, opts.Timeout)
+		err := repo.Unshallow(fetchCtx)
+		cancel()
 		if err != nil {
-			return fetchCompleteMsg{
-				projectIndex: projectIndex,
+			return fetchCompleteMsg{projectIndex: projectIndex, err: err}
+		}
+
+		projectWithStatus.Status = checker.Recheck(ctx, projectWithStatus.Project, projectWithStatus.LastCommit, opts)
+		return fetchCompleteMsg{projectIndex: projectIndex, summary: "Fetched the full history"}
+	}
+}
+
+// fetchAllCmd fetches the given projects of m.projects concurrently, sending a
+// fetchAllProgressMsg as each fetch completes
+func fetchAllCmd(ctx context.Context, projects []ProjectWithStatus, indexes []int, concurrency int, opts checkprojects.Options) tea.Cmd {
+	toFetch := make([]scanner.Project, len(indexes))
+	for i, idx := range indexes {
+		toFetch[i] = projects[idx].Project
+	}
+
+	// Buffered for every project, so that a progress is never waited for
+	progress := make(chan fetchAllProgressMsg, len(toFetch))
+	go func() {
+		defer close(progress)
+		byPath := make(map[string]int, len(indexes))
+		for _, idx := range indexes {
+			byPath[projects[idx].Project.Path] = idx
+		}
+		checker.FetchAll(ctx, toFetch, concurrency, opts, func(done, total int, project scanner.Project, err error) {
+			progress <- fetchAllProgressMsg{
+				projectIndex: byPath[project.Path],
+				path:         project.Path,
+				name:         project.Name,
+				done:         done,
+				total:        total,
 				err:          err,
+				progress:     progress,
 			}
+		})
+	}()
+	return waitForFetchAll(progress)
+}
+
+// waitForFetchAll reads the next progress of fetch all
+func waitForFetchAll(progress <-chan fetchAllProgressMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-progress
+		if !ok {
+			return fetchAllDoneMsg{}
 		}
+		return msg
+	}
+}
 
-		// Update the status in the project
-		projectWithStatus.Status = status
+// refreshFetchedCmd refreshes the status of a project fetched by fetch all:
+// rechecked when the fetch succeeded, marked when the remote refused the credentials
+func refreshFetchedCmd(ctx context.Context, projectWithStatus ProjectWithStatus, msg fetchAllProgressMsg, opts checkprojects.Options) tea.Cmd {
+	return func() tea.Msg {
+		status := projectWithStatus.Status
+		if msg.err == nil {
+			status = checker.Recheck(ctx, projectWithStatus.Project, projectWithStatus.LastCommit, opts)
+		} else if auth := checker.AuthFailure(ctx, projectWithStatus.Project, opts.Timeout, msg.err); auth != nil && status != nil {
+			status = git.NewAuthRequiredStatus(auth, status)
+		}
+		return fetchAllStatusMsg{projectIndex: msg.projectIndex, path: msg.path, status: status}
+	}
+}
 
-		return fetchCompleteMsg{
-			projectIndex: projectIndex,
-			err:          nil,
+// fetchAllSummary tells how fetch all went, naming the projects that failed
+func fetchAllSummary(state *fetchAllState) string {
+	if len(state.failed) == 0 {
+		return fmt.Sprintf("Fetched %d project(s)", state.total)
+	}
+	return fmt.Sprintf("Fetched %d/%d project(s), failed: %s", state.total-len(state.failed), state.total, strings.Join(state.failed, ", "))
+}
+
+// pullProjectCmd pulls a single project and refreshes its status
+func pullProjectCmd(ctx context.Context, projectWithStatus *ProjectWithStatus, projectIndex int, pullOpts git.PullOptions, opts checkprojects.Options) tea.Cmd {
+	return func() tea.Msg {
+		repo, ok := projectWithStatus.Project.Repository.(*git.Repository)
+		if !ok {
+			return pullCompleteMsg{projectIndex: projectIndex, summary: "Pull is only supported for git repositories", failed: true}
 		}
+
+		pullCtx, cancel := checker.WithTimeout(ctx, opts.Timeout)
+		result, err := repo.Pull(pullCtx, pullOpts)
+		cancel()
+		if err != nil {
+			return pullCompleteMsg{projectIndex: projectIndex, summary: pullFailure(err), failed: true}
+		}
+
+		// New commits make the status and the last commit date outdated
+		if !result.UpToDate() {
+			if !projectWithStatus.LastCommit.IsZero() {
+				projectWithStatus.LastCommit = checker.LastCommits(ctx, []scanner.Project{projectWithStatus.Project}, opts)[0]
+			}
+			projectWithStatus.Status = checker.Recheck(ctx, projectWithStatus.Project, projectWithStatus.LastCommit, opts)
+		}
+
+		return pullCompleteMsg{projectIndex: projectIndex, summary: "Pulled " + result.String()}
+	}
+}
+
+// deleteBranchCmd deletes a local branch merged into base and refreshes the status
+func deleteBranchCmd(ctx context.Context, projectWithStatus *ProjectWithStatus, projectIndex int, branch, base string, opts checkprojects.Options) tea.Cmd {
+	return func() tea.Msg {
+		repo, ok := projectWithStatus.Project.Repository.(*git.Repository)
+		if !ok {
+			return deleteBranchCompleteMsg{projectIndex: projectIndex, summary: "Deleting branches is only supported for git repositories", failed: true}
+		}
+
+		deleteCtx, cancel := checker.WithTimeout(ctx, opts.Timeout)
+		err := repo.DeleteMergedBranch(deleteCtx, branch, base)
+		cancel()
+		if err != nil {
+			return deleteBranchCompleteMsg{projectIndex: projectIndex, summary: "Delete failed: " + err.Error(), failed: true}
+		}
+
+		projectWithStatus.Status = checker.Recheck(ctx, projectWithStatus.Project, projectWithStatus.LastCommit, opts)
+		return deleteBranchCompleteMsg{projectIndex: projectIndex, summary: fmt.Sprintf("Deleted %s (merged into %s)", branch, base)}
+	}
+}
+
+// removeLockCmd removes the index.lock left behind in a project and refreshes its status
+func removeLockCmd(ctx context.Context, projectWithStatus *ProjectWithStatus, projectIndex int, opts checkprojects.Options) tea.Cmd {
+	return func() tea.Msg {
+		repo, ok := projectWithStatus.Project.Repository.(*git.Repository)
+		if !ok {
+			return lockRemovedMsg{projectIndex: projectIndex, summary: "Removing locks is only supported for git repositories", failed: true}
+		}
+		lockFile := projectWithStatus.Status.LockFile
+		if err := repo.RemoveIndexLock(); err != nil {
+			return lockRemovedMsg{projectIndex: projectIndex, summary: "Lock kept: " + err.Error(), failed: true}
+		}
+
+		projectWithStatus.Status = checker.Recheck(ctx, projectWithStatus.Project, projectWithStatus.LastCommit, opts)
+		return lockRemovedMsg{projectIndex: projectIndex, summary: "Removed " + lockFile}
+	}
+}
+
+// lockPrompt asks to confirm the removal of a lock file
+func lockPrompt(confirm *lockConfirm) string {
+	return fmt.Sprintf("Remove %s, left %s? Make sure no git process is running (y/n)", confirm.lockFile, reporter.Ago(confirm.lockedAt, time.Now()))
+}
+
+// deletePrompt asks to confirm the deletion of the selected merged branch
+func deletePrompt(confirm *deleteConfirm) string {
+	prompt := fmt.Sprintf("Delete %s, merged into %s? (y/n", confirm.branches[confirm.selected], confirm.base)
+	if len(confirm.branches) > 1 {
+		prompt += fmt.Sprintf(", tab: next of %d", len(confirm.branches))
+	}
+	return prompt + ")"
+}
+
+// pullFailure describes a refused or failed pull on a single line
+func pullFailure(err error) string {
+	var pullErr *git.PullError
+	if errors.As(err, &pullErr) {
+		line, _, _ := strings.Cut(pullErr.Message, "\n")
+		return fmt.Sprintf("Pull refused (%s): %s", pullErr.Reason, line)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(err.Error()), "\n")
+	return "Pull failed: " + line
+}
+
+// pushTarget returns where pushing the project would send its current branch,
+// bounded like the details panel since it runs before the confirmation prompt
+func pushTarget(ctx context.Context, project scanner.Project, opts checkprojects.Options) (git.PushTarget, error) {
+	repo, ok := project.Repository.(*git.Repository)
+	if !ok {
+		return git.PushTarget{}, errors.New("push is only supported for git repositories")
+	}
+	ctx, cancel := checker.WithTimeout(ctx, detailTimeout(opts.Timeout))
+	defer cancel()
+	return repo.PushTarget(ctx)
+}
+
+// pushTargetCmd resolves where a push of the project's current branch would go,
+// out of Update since it runs git
+func pushTargetCmd(ctx context.Context, project scanner.Project, projectIndex int, opts checkprojects.Options) tea.Cmd {
+	return func() tea.Msg {
+		target, err := pushTarget(ctx, project, opts)
+		return pushTargetMsg{projectIndex: projectIndex, path: project.Path, target: target, err: err}
+	}
+}
+
+// pushPrompt asks to confirm a push, naming the branch and where it goes
+func pushPrompt(target git.PushTarget) string {
+	if !target.HasUpstream {
+		return fmt.Sprintf("Push %s to %s, setting it as upstream? (y/n)", target.Branch, target.Remote)
+	}
+	return fmt.Sprintf("Push %s to %s/%s? (y/n)", target.Branch, target.Remote, target.RemoteRef)
+}
+
+// pushProjectCmd pushes the current branch of a single project, setting up its
+// upstream when missing, and refreshes its status
+func pushProjectCmd(ctx context.Context, projectWithStatus *ProjectWithStatus, projectIndex int, target git.PushTarget, opts checkprojects.Options) tea.Cmd {
+	return func() tea.Msg {
+		repo, ok := projectWithStatus.Project.Repository.(*git.Repository)
+		if !ok {
+			return pushCompleteMsg{projectIndex: projectIndex, summary: "Push is only supported for git repositories", failed: true}
+		}
+
+		pushCtx, cancel := checker.WithTimeout(ctx, opts.Timeout)
+		output, err := repo.Push(pushCtx, true)
+		cancel()
+
+		// Even a failed push may have set up the upstream
+		projectWithStatus.Status = checker.Recheck(ctx, projectWithStatus.Project, projectWithStatus.LastCommit, opts)
+
+		if err != nil {
+			return pushCompleteMsg{projectIndex: projectIndex, summary: pushFailure(err), output: output, failed: true}
+		}
+		return pushCompleteMsg{projectIndex: projectIndex, summary: fmt.Sprintf("Pushed %s to %s/%s", target.Branch, target.Remote, target.RemoteRef), output: output}
+	}
+}
+
+// tagsPrompt asks to confirm the push of unpushed tags
+func tagsPrompt(confirm *tagsConfirm) string {
+	return fmt.Sprintf("Push %s (%s) to %s? (y/n)", reporter.TagsLabel(len(confirm.tags)), strings.Join(confirm.tags, ", "), confirm.remote)
+}
+
+// pushTagsCmd pushes tags of a single project to remote and refreshes its status
+func pushTagsCmd(ctx context.Context, projectWithStatus *ProjectWithStatus, projectIndex int, remote string, tags []string, opts checkprojects.Options) tea.Cmd {
+	return func() tea.Msg {
+		repo, ok := projectWithStatus.Project.Repository.(*git.Repository)
+		if !ok {
+			return pushCompleteMsg{projectIndex: projectIndex, summary: "Push is only supported for git repositories", failed: true}
+		}
+
+		pushCtx, cancel := checker.WithTimeout(ctx, opts.Timeout)
+		output, err := repo.PushTags(pushCtx, remote, tags)
+		cancel()
+
+		projectWithStatus.Status = checker.Recheck(ctx, projectWithStatus.Project, projectWithStatus.LastCommit, opts)
+
+		if err != nil {
+			return pushCompleteMsg{projectIndex: projectIndex, summary: pushFailure(err), output: output, failed: true}
+		}
+		return pushCompleteMsg{projectIndex: projectIndex, summary: fmt.Sprintf("Pushed %s to %s", strings.Join(tags, ", "), remote), output: output}
+	}
+}
+
+// pushFailure describes a failed push on a single line
+func pushFailure(err error) string {
+	var pushErr *git.PushError
+	if errors.As(err, &pushErr) {
+		return fmt.Sprintf("Push failed (%s): %s", pushErr.Reason, pushErr.Message)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(err.Error()), "\n")
+	return "Push failed: " + line
+}
+
+// fetchFailure describes a failed fetch on a single line, naming the host when
+// it refused the credentials
+func fetchFailure(msg fetchCompleteMsg) string {
+	if msg.auth != nil {
+		host := git.RemoteHost(msg.auth.URL)
+		if host == "" {
+			host = "the remote"
+		}
+		return fmt.Sprintf("Fetch failed: authentication required by %s — %s", host, git.AuthHint(msg.auth.URL))
+	}
+	var fetchErr *git.FetchError
+	if errors.As(msg.err, &fetchErr) && fetchErr.Reason() != "" {
+		return "Fetch failed: " + fetchErr.Reason()
 	}
+	line, _, _ := strings.Cut(strings.TrimSpace(msg.err.Error()), "\n")
+	return "Fetch failed: " + line
 }
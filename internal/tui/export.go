@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fatih/color"
+	"github.com/uralys/check-projects/internal/reporter"
+)
+
+// toastDuration is how long a toast stays in the footer
+const toastDuration = 4 * time.Second
+
+// exportDoneMsg is sent once the view was exported to a file or the clipboard
+type exportDoneMsg struct {
+	target string // File path, or "clipboard"
+	err    error
+}
+
+// clearToastMsg hides the toast with the given id, unless a newer one replaced it
+type clearToastMsg struct {
+	id int
+}
+
+// showToast displays message in the footer for toastDuration
+func (m *Model) showToast(message string, isError bool) tea.Cmd {
+	m.toastID++
+	m.toast = message
+	m.toastIsError = isError
+	id := m.toastID
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return clearToastMsg{id: id}
+	})
+}
+
+// exportText renders every project with its status (the console report, clean
+// projects included), then the detail of the selected project, without colors
+func (m Model) exportText(now time.Time) string {
+	results := make([]reporter.ProjectResult, 0, len(m.projects))
+	for _, p := range m.projects {
+		if p.Status == nil {
+			continue
+		}
+		results = append(results, reporter.ProjectResult{
+			Name:          p.Project.Name,
+			Path:          p.Project.Path,
+			Status:        p.Status,
+			Category:      p.Project.Category,
+			IsSymlink:     p.Project.IsSymlink,
+			SymlinkTarget: p.Project.SymlinkTarget,
+			VCS:           p.Project.VCS(),
+			LastCommit:    p.LastCommit,
+		})
+	}
+
+	// The reporters color their output for the terminal the TUI runs in
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "check-projects %s — %s\n\n", m.version, now.Format("2006-01-02 15:04"))
+
+	rep, _ := reporter.New(reporter.FormatText, m.config, true, &b)
+	rep.Report(reporter.SortResults(results, m.sortOrder))
+
+	if filtered := m.getFilteredProjects(); m.selectedProject < len(filtered) {
+		selected := filtered[m.selectedProject]
+		for _, result := range results {
+			if result.Path == selected.Project.Path {
+				fmt.Fprintln(&b, "\nSelected project:")
+				reporter.NewDetailReporter(&b).Report([]reporter.ProjectResult{result})
+				break
+			}
+		}
+	}
+	return b.String()
+}
+
+// exportFileCmd writes text to a timestamped file in the current directory
+func exportFileCmd(text string, now time.Time) tea.Cmd {
+	return func() tea.Msg {
+		// Exports made the same second get a suffix rather than overwriting each other
+		base := "check-projects-" + now.Format("20060102-150405")
+		path := base + ".txt"
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		for n := 2; errors.Is(err, fs.ErrExist) && n < 100; n++ {
+			path = fmt.Sprintf("%s-%d.txt", base, n)
+			f, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		}
+		if err != nil {
+			return exportDoneMsg{target: path, err: err}
+		}
+		if _, err := f.WriteString(text); err != nil {
+			f.Close()
+			return exportDoneMsg{target: path, err: err}
+		}
+		return exportDoneMsg{target: path, err: f.Close()}
+	}
+}
+
+// exportClipboardCmd copies text to the system clipboard
+func exportClipboardCmd(text string) tea.Cmd {
+	return func() tea.Msg {
+		return exportDoneMsg{target: "clipboard", err: copyToClipboard(text)}
+	}
+}
+
+// copyToClipboard pipes text to the clipboard command of the platform
+func copyToClipboard(text string) error {
+	name, args, err := clipboardCommand(runtime.GOOS, os.Getenv("WAYLAND_DISPLAY") != "", exec.LookPath)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %v %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// clipboardCommand returns the command copying its stdin to the clipboard:
+// pbcopy on macOS, clip on Windows, wl-copy, xclip or xsel elsewhere
+func clipboardCommand(goos string, wayland bool, lookPath func(string) (string, error)) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		return "pbcopy", nil, nil
+	case "windows":
+		return "clip", nil, nil
+	}
+
+	candidates := [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	if wayland {
+		candidates = append([][]string{{"wl-copy"}}, candidates...)
+	}
+	for _, candidate := range candidates {
+		if _, err := lookPath(candidate[0]); err == nil {
+			return candidate[0], candidate[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("no clipboard command found (install wl-copy, xclip or xsel)")
+}
@@ -1,29 +1,191 @@
 package tui
 
 import (
+	"time"
+
 	"github.com/uralys/check-projects/internal/git"
 	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/watcher"
+	"github.com/uralys/check-projects/pkg/checkprojects"
 )
 
 // ProjectWithStatus represents a project with its Git status
 type ProjectWithStatus struct {
-	Project scanner.Project
-	Status  *git.Status
+	Project      scanner.Project
+	Status       *git.Status
+	LastCommit   time.Time // Only loaded when sorting by age
+	Action       string    // Outcome of the last pull or push from the TUI, shown in the details panel
+	ActionFailed bool
+	ActionOutput string // What git printed, for a push
 }
 
-// scanCompleteMsg is sent when the initial scan is complete
+// scanCompleteMsg is sent when the initial scan is complete, before the projects are
+// checked: their statuses then stream in (see statusMsg)
 type scanCompleteMsg struct {
-	projects []ProjectWithStatus
+	projects    []ProjectWithStatus
+	statuses    <-chan checkprojects.Result // Where the results of the checks are read from
+	lastCommits bool                        // Last commit dates are loaded with the statuses
+	dead        []scanner.Warning           // Entries of projects lists that listed no repository
+	revalidate  *scanner.Scanner            // Read roots from the scan cache, to scan again
+	err         error
+}
+
+// projectKey identifies a project across scans
+type projectKey struct {
+	category, path string
+}
+
+func keyOf(project scanner.Project) projectKey {
+	return projectKey{project.Category, project.Path}
+}
+
+// rescanCompleteMsg is sent once the roots read from the scan cache were scanned again
+type rescanCompleteMsg struct {
+	projects []scanner.Project                // Every project, in order
+	appeared map[projectKey]ProjectWithStatus // Projects not known before, checked
+	dead     []scanner.Warning
 	err      error
 }
 
+// statusMsg carries the result of checking a project after a scan
+type statusMsg struct {
+	result   checkprojects.Result
+	statuses <-chan checkprojects.Result // Where the next result is read from
+}
+
+// checksCompleteMsg is sent once every project of a scan is checked
+type checksCompleteMsg struct {
+	statuses <-chan checkprojects.Result
+}
+
+// lastCommitsMsg is sent when the last commit dates are loaded, keyed by project path
+type lastCommitsMsg struct {
+	lastCommits map[string]time.Time
+}
+
 // fetchCompleteMsg is sent when a fetch operation is complete
 type fetchCompleteMsg struct {
 	projectIndex int
+	summary      string // What the fetch changed, when it succeeded
+	err          error
+	auth         *git.RemoteError // Remote that refused the credentials, when that is why it failed
+}
+
+// pullCompleteMsg is sent when a pull operation is complete
+type pullCompleteMsg struct {
+	projectIndex int
+	summary      string // What the pull brought in, or why it was refused
+	failed       bool
+}
+
+// pushCompleteMsg is sent when a push operation is complete
+type pushCompleteMsg struct {
+	projectIndex int
+	summary      string // Where the branch was pushed, or why it failed
+	output       string // What git printed
+	failed       bool
+}
+
+// pushTargetMsg is sent once the branch and remote a push would go to are
+// resolved, to ask for the confirmation
+type pushTargetMsg struct {
+	projectIndex int
+	path         string // Of the project, to tell whether a refresh moved it meanwhile
+	target       git.PushTarget
 	err          error
 }
 
+// pushConfirm is a push waiting for the user's confirmation
+type pushConfirm struct {
+	projectIndex int
+	target       git.PushTarget
+}
+
+// tagsConfirm is a push of unpushed tags waiting for the user's confirmation
+type tagsConfirm struct {
+	projectIndex int
+	remote       string
+	tags         []string
+}
+
+// lockConfirm is the removal of a lock file waiting for the user's confirmation
+type lockConfirm struct {
+	projectIndex int
+	lockFile     string
+	lockedAt     time.Time
+}
+
+// deleteConfirm is the deletion of a merged branch waiting for the user's
+// confirmation, tab moving to the next of the project's merged branches
+type deleteConfirm struct {
+	projectIndex int
+	branches     []string
+	selected     int
+	base         string // Remote-tracking branch they are merged into
+}
+
+// deleteBranchCompleteMsg is sent when deleting a merged branch is complete
+type deleteBranchCompleteMsg struct {
+	projectIndex int
+	summary      string // Which branch was deleted, or why it was not
+	failed       bool
+}
+
+// lockRemovedMsg is sent when removing a lock file is complete
+type lockRemovedMsg struct {
+	projectIndex int
+	summary      string // Which lock was removed, or why it was not
+	failed       bool
+}
+
 // fetchingMsg is sent when a fetch operation starts
 type fetchingMsg struct {
 	projectIndex int
 }
+
+// fetchAllProgressMsg is sent as each fetch started with F completes
+type fetchAllProgressMsg struct {
+	projectIndex int
+	path         string // Path of the project, to skip it once a refresh reordered them
+	name         string
+	done, total  int
+	err          error
+	progress     <-chan fetchAllProgressMsg // Where the next progress is read from
+}
+
+// fetchAllDoneMsg is sent once every fetch started with F has completed
+type fetchAllDoneMsg struct{}
+
+// fetchAllStatusMsg carries the status of a project refreshed after fetch all
+type fetchAllStatusMsg struct {
+	projectIndex int
+	path         string
+	status       *git.Status
+}
+
+// changeMsg carries changes seen on disk by the watcher (--watch)
+type changeMsg struct {
+	change watcher.Change
+}
+
+// watchTickMsg is sent every --watch interval, to check every project again
+type watchTickMsg struct{}
+
+// watchedMsg is sent once the watches follow the projects of a scan
+type watchedMsg struct {
+	paths int
+	err   error // Paths that could not be watched
+}
+
+// recheckedMsg carries the status of a project checked again after it changed on disk
+type recheckedMsg struct {
+	projectIndex int
+	path         string
+	status       *git.Status
+}
+
+// fetchAllState is the progress of fetch all, shown in the footer
+type fetchAllState struct {
+	done, total int
+	failed      []string // Names of the projects whose fetch failed
+}
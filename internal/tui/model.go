@@ -1,25 +1,59 @@
 package tui
 
 import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/uralys/check-projects/internal/config"
 	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/watcher"
+	"github.com/uralys/check-projects/pkg/checkprojects"
 )
 
 // Model represents the application state for the TUI
 type Model struct {
 	// Configuration
-	config *config.Config
+	config       *config.Config
+	checkOptions checkprojects.Options
+	ctx          context.Context // Cancelled when the TUI exits
 
 	// Projects and results
 	projects []ProjectWithStatus
+	statuses <-chan checkprojects.Result // Results of the checks of the latest scan, streaming in (nil once all arrived)
 
 	// UI state
 	loading         bool
 	hideClean       bool
+	staleOnly       bool   // Only list stale projects
+	sortOrder       string // Project order, see reporter.SortOrders
+	lastCommitsSet  bool   // Last commit dates are loaded (needed to sort by age)
 	errorMsg        string
-	fetchingProject int // Index of project being fetched (-1 means none)
+	deadEntries     []scanner.Warning // Config entries listing no repository, noticed above the footer
+	rescan          bool              // The first scan does not read roots from the scan cache (--rescan)
+	toast           string            // Transient message in the footer, e.g. where the view was exported
+	toastIsError    bool
+	toastID         int            // Identifies the latest toast, so that older timers do not clear it
+	fetchingProject int            // Index of project being fetched (-1 means none)
+	pullingProject  int            // Index of project being pulled (-1 means none)
+	pushingProject  int            // Index of project being pushed (-1 means none)
+	resolvingPush   int            // Index of project whose push target is being resolved (-1 means none)
+	confirmPush     *pushConfirm   // Push waiting for y/n, shown in the footer
+	fetchAll        *fetchAllState // Progress of fetching every listed project (nil means none)
+	confirmDelete   *deleteConfirm // Deletion of a merged branch waiting for y/n, shown in the footer
+	confirmTags     *tagsConfirm   // Push of unpushed tags waiting for y/n, shown in the footer
+	confirmLock     *lockConfirm   // Removal of a lock file waiting for y/n, shown in the footer
+
+	// Watch mode (--watch)
+	watcher       *watcher.Watcher      // Follows the projects on disk (nil without --watch)
+	changes       <-chan watcher.Change // Changes seen by the watcher
+	watchInterval time.Duration         // Every project is checked again that often (0 = only as they change)
+	watchedPaths  int                   // Shown in the footer
 
 	// Selection
 	selectedCategory int
@@ -43,7 +77,7 @@ type Model struct {
 }
 
 // NewModel creates a new TUI model
-func NewModel(cfg *config.Config, version string) Model {
+func NewModel(ctx context.Context, cfg *config.Config, version string, opts checkprojects.Options) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 
@@ -55,17 +89,60 @@ func NewModel(cfg *config.Config, version string) Model {
 
 	return Model{
 		config:           cfg,
+		checkOptions:     opts,
+		ctx:              ctx,
 		loading:          true,
 		hideClean:        true, // Hide clean projects by default in TUI
+		sortOrder:        cfg.Display.Sort,
 		spinner:          s,
 		categories:       categories,
 		selectedCategory: 0,
 		selectedProject:  0,
 		version:          version,
 		fetchingProject:  -1, // No project being fetched initially
+		pullingProject:   -1,
+		pushingProject:   -1,
+		resolvingPush:    -1,
 	}
 }
 
+// fetchIsStale reports whether a project was last fetched longer ago than
+// display.stale_fetch_days
+func (m Model) fetchIsStale(status *git.Status) bool {
+	return reporter.FetchIsStale(status, m.config.Display.StaleFetchDays, time.Now())
+}
+
+// selectedProjectIndex returns the index in m.projects of the selected project (-1 when none)
+func (m Model) selectedProjectIndex() int {
+	filtered := m.getFilteredProjects()
+	if m.selectedProject >= len(filtered) {
+		return -1
+	}
+	selected := filtered[m.selectedProject]
+	for i, p := range m.projects {
+		if p.Project.Path == selected.Project.Path {
+			return i
+		}
+	}
+	return -1
+}
+
+// fetchableIndexes returns the indexes in m.projects of the listed projects that
+// have a repository to fetch
+func (m Model) fetchableIndexes() []int {
+	listed := make(map[string]bool)
+	for _, p := range m.getFilteredProjects() {
+		listed[p.Project.Path] = true
+	}
+	var indexes []int
+	for i, p := range m.projects {
+		if listed[p.Project.Path] && p.Project.Repository != nil {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
 // getFilteredProjects returns projects filtered by current settings
 func (m Model) getFilteredProjects() []ProjectWithStatus {
 	if len(m.projects) == 0 {
@@ -84,28 +161,56 @@ func (m Model) getFilteredProjects() []ProjectWithStatus {
 			continue
 		}
 
+		// Filter by status bucket
+		if m.staleOnly && (p.Status == nil || p.Status.Type != git.StatusStale) {
+			continue
+		}
+
 		// Filter by clean status - skip if clean AND no behind branches
-		if m.hideClean && p.Status != nil && p.Status.Type == git.StatusSync && len(p.Status.BehindBranches) == 0 {
+		if m.hideClean && p.Status != nil && p.Status.Type == git.StatusSync && !p.Status.BranchesNeedAttention() && !m.fetchIsStale(p.Status) && !p.Status.IdentityMismatch {
 			continue
 		}
 
 		filtered = append(filtered, p)
 	}
 
+	// Same ordering as the report
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return reporter.Compare(m.sortOrder, filtered[i].sortItem(), filtered[j].sortItem()) < 0
+	})
+
 	return filtered
 }
 
+// nextSortOrder returns the ordering following the current one
+func (m Model) nextSortOrder() string {
+	for i, order := range reporter.SortOrders {
+		if order == m.sortOrder {
+			return reporter.SortOrders[(i+1)%len(reporter.SortOrders)]
+		}
+	}
+	return reporter.SortOrders[0]
+}
+
+func (p ProjectWithStatus) sortItem() reporter.SortItem {
+	return reporter.SortItem{Name: p.Project.Name, Status: p.Status, LastCommit: p.LastCommit}
+}
+
 // categoryHasChanges checks if a category has any projects with changes or behind branches
 func (m Model) categoryHasChanges(categoryName string) bool {
 	for _, p := range m.projects {
 		if p.Project.Category == categoryName {
+			// Not known to be clean yet
+			if p.Status == nil && m.statuses != nil {
+				return true
+			}
 			if p.Status != nil {
 				// Check if status is not clean
 				if p.Status.Type != git.StatusSync {
 					return true
 				}
-				// Check if there are branches behind remote
-				if len(p.Status.BehindBranches) > 0 {
+				// Check if there are branches behind remote or local-only
+				if p.Status.BranchesNeedAttention() {
 					return true
 				}
 			}
@@ -130,19 +235,118 @@ func (m Model) getVisibleCategories() []string {
 	return visible
 }
 
+// hasStale reports whether a project is stale
+func (m Model) hasStale() bool {
+	for _, p := range m.projects {
+		if p.Status != nil && p.Status.Type == git.StatusStale {
+			return true
+		}
+	}
+	return false
+}
+
 // hasAnyChanges checks if there are any projects with changes or behind branches across all categories
 func (m Model) hasAnyChanges() bool {
 	for _, p := range m.projects {
+		// Not known to be clean yet
+		if p.Status == nil && m.statuses != nil {
+			return true
+		}
 		if p.Status != nil {
 			// Check if status is not clean
 			if p.Status.Type != git.StatusSync {
 				return true
 			}
-			// Check if there are branches behind remote
-			if len(p.Status.BehindBranches) > 0 {
+			// Check if there are branches behind remote or local-only
+			if p.Status.BranchesNeedAttention() {
 				return true
 			}
 		}
 	}
 	return false
 }
+
+// setStatus records the result of checking a project, unless it left the list. The
+// selected project stays selected as the list is filtered and sorted again.
+func (m *Model) setStatus(result checkprojects.Result) {
+	selected := m.selectedProjectIndex()
+	defer m.reselect(selected)
+
+	i := result.Index
+	if i >= len(m.projects) || keyOf(m.projects[i].Project) != keyOf(result.Project) {
+		// Projects moved as the roots were scanned again
+		i = slices.IndexFunc(m.projects, func(p ProjectWithStatus) bool {
+			return keyOf(p.Project) == keyOf(result.Project)
+		})
+		if i == -1 {
+			return
+		}
+	}
+	m.projects[i].Status = result.Status
+	if !result.LastCommit.IsZero() {
+		m.projects[i].LastCommit = result.LastCommit
+	}
+}
+
+// reselect selects the project at index i of m.projects when listed, or keeps the
+// selection within the list
+func (m *Model) reselect(i int) {
+	filtered := m.getFilteredProjects()
+	if i != -1 {
+		if j := slices.IndexFunc(filtered, func(p ProjectWithStatus) bool {
+			return p.Project.Path == m.projects[i].Project.Path
+		}); j != -1 {
+			m.selectedProject = j
+			return
+		}
+	}
+	if m.selectedProject >= len(filtered) {
+		m.selectedProject = max(len(filtered)-1, 0)
+	}
+}
+
+// showChangedCategory selects the first category with changes when the selected one
+// has none and clean projects are hidden
+func (m *Model) showChangedCategory() {
+	if !m.hideClean || m.selectedCategory >= len(m.categories) || m.categoryHasChanges(m.categories[m.selectedCategory]) {
+		return
+	}
+	visibleCategories := m.getVisibleCategories()
+	if len(visibleCategories) == 0 {
+		return
+	}
+	// Find first visible category in full list
+	if i := slices.Index(m.categories, visibleCategories[0]); i != -1 {
+		m.selectedCategory = i
+		m.selectedProject = 0
+	}
+}
+
+// mergeRescan brings the projects in line with those found by scanning the roots read
+// from the scan cache again: projects gone leave the list and those that appeared join
+// it, with their status. Nothing moves when the same projects were found.
+func (m *Model) mergeRescan(msg rescanCompleteMsg) {
+	m.deadEntries = msg.dead
+
+	known := make(map[projectKey]ProjectWithStatus, len(m.projects))
+	for _, p := range m.projects {
+		known[keyOf(p.Project)] = p
+	}
+	if len(msg.appeared) == 0 && len(msg.projects) == len(m.projects) {
+		return
+	}
+
+	projects := make([]ProjectWithStatus, 0, len(msg.projects))
+	for _, project := range msg.projects {
+		if p, ok := known[keyOf(project)]; ok {
+			projects = append(projects, p)
+		} else if p, ok := msg.appeared[keyOf(project)]; ok {
+			projects = append(projects, p)
+		}
+	}
+	m.projects = projects
+
+	if filtered := m.getFilteredProjects(); m.selectedProject >= len(filtered) {
+		m.selectedProject = max(len(filtered)-1, 0)
+	}
+}
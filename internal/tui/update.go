@@ -2,9 +2,13 @@ package tui
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
 )
 
 // Update handles incoming messages and updates the model
@@ -20,6 +24,54 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.Height = msg.Height - 6 // Reserve space for header and footer
 
 	case tea.KeyMsg:
+		// A push waiting for confirmation takes the key: y pushes, anything else cancels
+		if m.confirmPush != nil && msg.String() != "ctrl+c" {
+			confirm := m.confirmPush
+			m.confirmPush = nil
+			if msg.String() == "y" {
+				m.pushingProject = confirm.projectIndex
+				return m, pushProjectCmd(m.ctx, &m.projects[confirm.projectIndex], confirm.projectIndex, confirm.target, m.checkOptions)
+			}
+			return m, m.showToast("Push cancelled", false)
+		}
+
+		// So does a push of unpushed tags
+		if m.confirmTags != nil && msg.String() != "ctrl+c" {
+			confirm := m.confirmTags
+			m.confirmTags = nil
+			if msg.String() == "y" {
+				m.pushingProject = confirm.projectIndex
+				return m, pushTagsCmd(m.ctx, &m.projects[confirm.projectIndex], confirm.projectIndex, confirm.remote, confirm.tags, m.checkOptions)
+			}
+			return m, m.showToast("Push cancelled", false)
+		}
+
+		// So does the removal of a lock file
+		if m.confirmLock != nil && msg.String() != "ctrl+c" {
+			confirm := m.confirmLock
+			m.confirmLock = nil
+			if msg.String() == "y" {
+				return m, removeLockCmd(m.ctx, &m.projects[confirm.projectIndex], confirm.projectIndex, m.checkOptions)
+			}
+			return m, m.showToast("Lock kept", false)
+		}
+
+		// So does the deletion of a merged branch, tab picking the next one
+		if m.confirmDelete != nil && msg.String() != "ctrl+c" {
+			confirm := m.confirmDelete
+			switch msg.String() {
+			case "y":
+				m.confirmDelete = nil
+				branch := confirm.branches[confirm.selected]
+				return m, deleteBranchCmd(m.ctx, &m.projects[confirm.projectIndex], confirm.projectIndex, branch, confirm.base, m.checkOptions)
+			case "tab":
+				confirm.selected = (confirm.selected + 1) % len(confirm.branches)
+				return m, nil
+			}
+			m.confirmDelete = nil
+			return m, m.showToast("Deletion cancelled", false)
+		}
+
 		// Global keys
 		switch msg.String() {
 		case "ctrl+c", "q", "esc":
@@ -28,25 +80,83 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "r":
 			// Refresh
 			m.loading = true
-			return m, scanProjectsCmd(m.config)
+			return m, tea.Batch(m.spinner.Tick, scanProjectsCmd(m.ctx, m.config, m.checkOptions, m.sortOrder == reporter.SortAge, true))
+
+		case "s":
+			// Cycle through project orders, loading last commit dates to sort by age
+			m.sortOrder = m.nextSortOrder()
+			m.selectedProject = 0
+			m.detailsScroll = 0
+			if m.sortOrder == reporter.SortAge && !m.lastCommitsSet && !m.loading {
+				return m, loadLastCommitsCmd(m.ctx, m.projects, m.checkOptions)
+			}
 
 		case "f":
 			// Fetch selected project
-			filtered := m.getFilteredProjects()
-			if len(filtered) > 0 && m.selectedProject < len(filtered) {
-				// Find the actual index in m.projects
-				selectedProj := filtered[m.selectedProject]
-				actualIndex := -1
-				for i, p := range m.projects {
-					if p.Project.Path == selectedProj.Project.Path {
-						actualIndex = i
-						break
-					}
+			if actualIndex := m.selectedProjectIndex(); actualIndex != -1 {
+				m.fetchingProject = actualIndex
+				return m, fetchProjectCmd(m.ctx, &m.projects[actualIndex], actualIndex, m.checkOptions)
+			}
+
+		case "F":
+			// Fetch every listed project, the footer showing the progress
+			if m.fetchAll == nil && !m.loading {
+				if indexes := m.fetchableIndexes(); len(indexes) > 0 {
+					m.fetchAll = &fetchAllState{total: len(indexes)}
+					return m, fetchAllCmd(m.ctx, m.projects, indexes, m.config.FetchConcurrency, m.checkOptions)
 				}
+				return m, m.showToast("Nothing to fetch", false)
+			}
 
-				if actualIndex != -1 {
+		case "u":
+			// Fetch the full history of a shallow clone
+			if actualIndex := m.selectedProjectIndex(); actualIndex != -1 && m.fetchingProject == -1 {
+				if status := m.projects[actualIndex].Status; status != nil && status.Shallow {
 					m.fetchingProject = actualIndex
-					return m, fetchProjectCmd(&m.projects[actualIndex], actualIndex)
+					return m, unshallowProjectCmd(m.ctx, &m.projects[actualIndex], actualIndex, m.checkOptions)
+				}
+			}
+
+		case "p":
+			// Pull selected project, fast-forward only unless pull_rebase is set
+			if actualIndex := m.selectedProjectIndex(); actualIndex != -1 && m.pullingProject == -1 {
+				m.pullingProject = actualIndex
+				opts := git.PullOptions{Rebase: m.config.PullRebase}
+				return m, pullProjectCmd(m.ctx, &m.projects[actualIndex], actualIndex, opts, m.checkOptions)
+			}
+
+		case "P":
+			// Push selected project, once confirmed (the footer shows the branch and remote,
+			// resolved first)
+			if actualIndex := m.selectedProjectIndex(); actualIndex != -1 && m.pushingProject == -1 && m.resolvingPush == -1 {
+				m.resolvingPush = actualIndex
+				return m, pushTargetCmd(m.ctx, m.projects[actualIndex].Project, actualIndex, m.checkOptions)
+			}
+
+		case "T":
+			// Push the tags the remote does not have, once confirmed
+			if actualIndex := m.selectedProjectIndex(); actualIndex != -1 && m.pushingProject == -1 {
+				if status := m.projects[actualIndex].Status; status != nil && len(status.UnpushedTags) > 0 && status.Origin != nil {
+					m.confirmTags = &tagsConfirm{projectIndex: actualIndex, remote: status.Origin.Name, tags: status.UnpushedTags}
+				}
+			}
+
+		case "L":
+			// Remove a lock file left behind, once confirmed: a recent one may be held by git
+			if actualIndex := m.selectedProjectIndex(); actualIndex != -1 {
+				if status := m.projects[actualIndex].Status; status != nil && status.Type == git.StatusLocked {
+					if time.Since(status.LockedAt) < git.StaleLockAge {
+						return m, m.showToast("The lock is recent: a git process may still be running", true)
+					}
+					m.confirmLock = &lockConfirm{projectIndex: actualIndex, lockFile: status.LockFile, lockedAt: status.LockedAt}
+				}
+			}
+
+		case "d":
+			// Delete a branch merged into the default one, once confirmed
+			if actualIndex := m.selectedProjectIndex(); actualIndex != -1 {
+				if status := m.projects[actualIndex].Status; status != nil && len(status.MergedBranches) > 0 {
+					m.confirmDelete = &deleteConfirm{projectIndex: actualIndex, branches: status.MergedBranches, base: status.MergedInto}
 				}
 			}
 
@@ -78,6 +188,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case "ctrl+s":
+			// Export the current data to a file in the current directory
+			if !m.loading {
+				now := time.Now()
+				return m, exportFileCmd(m.exportText(now), now)
+			}
+
+		case "ctrl+y":
+			// Export the current data to the clipboard
+			// (terminals send ctrl+shift+s as ctrl+s)
+			if !m.loading {
+				return m, exportClipboardCmd(m.exportText(time.Now()))
+			}
+
+		case "t":
+			// Toggle the stale bucket: only stale projects, or every status
+			if m.staleOnly || m.hasStale() {
+				m.staleOnly = !m.staleOnly
+				m.selectedProject = 0
+				m.detailsScroll = 0
+			}
+
 		case "enter":
 			// Toggle focus between panels
 			m.focusedPanel = !m.focusedPanel
@@ -190,51 +322,198 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.errorMsg = msg.err.Error()
 		} else {
 			m.projects = msg.projects
+			m.statuses = msg.statuses
+			m.lastCommitsSet = msg.lastCommits
+			m.deadEntries = msg.dead
 			m.errorMsg = ""
+			m.reselect(-1)
+			cmds = append(cmds, waitForStatus(msg.statuses), m.spinner.Tick)
 
+			// Sorting by age was selected during the scan
+			if m.sortOrder == reporter.SortAge && !m.lastCommitsSet {
+				cmds = append(cmds, loadLastCommitsCmd(m.ctx, m.projects, m.checkOptions))
+			}
+			if msg.revalidate != nil {
+				cmds = append(cmds, revalidateCmd(m.ctx, msg.revalidate, m.projects, m.checkOptions, m.lastCommitsSet))
+			}
+			if m.watcher != nil {
+				cmds = append(cmds, watchProjectsCmd(m.watcher, m.config, m.projects))
+			}
+		}
+
+	case statusMsg:
+		// Results of a previous scan are still read, so that its checks can end
+		if msg.statuses == m.statuses {
+			m.setStatus(msg.result)
+		}
+		cmds = append(cmds, waitForStatus(msg.statuses))
+
+	case checksCompleteMsg:
+		if msg.statuses == m.statuses {
+			m.statuses = nil
 			// Ensure selected category is visible when hideClean is enabled
-			if m.hideClean && len(m.categories) > 0 {
-				// Check if current selected category has changes
-				currentCat := ""
-				if m.selectedCategory < len(m.categories) {
-					currentCat = m.categories[m.selectedCategory]
-				}
+			m.showChangedCategory()
+			m.reselect(-1)
+		}
 
-				// If current category has no changes, select first visible category
-				if currentCat != "" && !m.categoryHasChanges(currentCat) {
-					visibleCategories := m.getVisibleCategories()
-					if len(visibleCategories) > 0 {
-						// Find first visible category in full list
-						for i, cat := range m.categories {
-							if cat == visibleCategories[0] {
-								m.selectedCategory = i
-								m.selectedProject = 0
-								break
-							}
-						}
-					}
+	case rescanCompleteMsg:
+		if msg.err == nil {
+			m.mergeRescan(msg)
+			if m.watcher != nil {
+				cmds = append(cmds, watchProjectsCmd(m.watcher, m.config, m.projects))
+			}
+		}
+
+	case changeMsg:
+		cmds = append(cmds, waitForChange(m.changes))
+		switch {
+		case msg.change.Rescan && !m.loading:
+			// A directory appeared under a root, or a project went away
+			m.loading = true
+			cmds = append(cmds, m.spinner.Tick, scanProjectsCmd(m.ctx, m.config, m.checkOptions, m.sortOrder == reporter.SortAge, true))
+		case !msg.change.Rescan:
+			for i, p := range m.projects {
+				if slices.Contains(msg.change.Projects, p.Project.Path) {
+					cmds = append(cmds, recheckCmd(m.ctx, p, i, m.checkOptions))
 				}
 			}
 		}
 
+	case watchTickMsg:
+		cmds = append(cmds, watchTickCmd(m.watchInterval))
+		if !m.loading {
+			m.loading = true
+			cmds = append(cmds, m.spinner.Tick, scanProjectsCmd(m.ctx, m.config, m.checkOptions, m.sortOrder == reporter.SortAge, true))
+		}
+
+	case watchedMsg:
+		m.watchedPaths = msg.paths
+		if msg.err != nil {
+			cmds = append(cmds, m.showToast(msg.err.Error(), true))
+		}
+
+	case recheckedMsg:
+		// Projects may have been reordered by a refresh meanwhile
+		if msg.projectIndex < len(m.projects) && m.projects[msg.projectIndex].Project.Path == msg.path {
+			m.projects[msg.projectIndex].Status = msg.status
+		}
+		// A project clean once committed leaves the list when clean ones are hidden
+		if filtered := m.getFilteredProjects(); m.selectedProject >= len(filtered) {
+			m.selectedProject = max(len(filtered)-1, 0)
+			m.detailsScroll = 0
+		}
+
 	case fetchingMsg:
 		// Mark project as being fetched
 		m.fetchingProject = msg.projectIndex
 
+	case lastCommitsMsg:
+		for i := range m.projects {
+			m.projects[i].LastCommit = msg.lastCommits[m.projects[i].Project.Path]
+		}
+		m.lastCommitsSet = true
+
+	case exportDoneMsg:
+		if msg.err != nil {
+			return m, m.showToast(fmt.Sprintf("Export failed: %v", msg.err), true)
+		}
+		if msg.target == "clipboard" {
+			return m, m.showToast("Copied to the clipboard", false)
+		}
+		return m, m.showToast("Exported to "+msg.target, false)
+
+	case clearToastMsg:
+		if msg.id == m.toastID {
+			m.toast = ""
+		}
+
 	case fetchCompleteMsg:
 		// Clear fetching state
 		m.fetchingProject = -1
 
 		if msg.err != nil {
-			// Show error briefly (could be improved with a status bar)
-			m.errorMsg = fmt.Sprintf("Fetch failed: %v", msg.err)
+			cmds = append(cmds, m.showToast(fetchFailure(msg), true))
 		} else {
-			// Clear any error
-			m.errorMsg = ""
+			cmds = append(cmds, m.showToast(msg.summary, false))
+		}
+
+	case fetchAllProgressMsg:
+		if m.fetchAll != nil {
+			m.fetchAll.done = msg.done
+			if msg.err != nil {
+				m.fetchAll.failed = append(m.fetchAll.failed, msg.name)
+			}
+		}
+		cmds = append(cmds, waitForFetchAll(msg.progress))
+		if msg.projectIndex < len(m.projects) && m.projects[msg.projectIndex].Project.Path == msg.path {
+			cmds = append(cmds, refreshFetchedCmd(m.ctx, m.projects[msg.projectIndex], msg, m.checkOptions))
+		}
+
+	case fetchAllStatusMsg:
+		// Projects may have been reordered by a refresh meanwhile
+		if msg.projectIndex < len(m.projects) && m.projects[msg.projectIndex].Project.Path == msg.path {
+			m.projects[msg.projectIndex].Status = msg.status
+		}
+
+	case fetchAllDoneMsg:
+		if m.fetchAll != nil {
+			state := m.fetchAll
+			m.fetchAll = nil
+			cmds = append(cmds, m.showToast(fetchAllSummary(state), len(state.failed) > 0))
+		}
+
+	case pullCompleteMsg:
+		m.pullingProject = -1
+		if msg.projectIndex < len(m.projects) {
+			m.projects[msg.projectIndex].Action = msg.summary
+			m.projects[msg.projectIndex].ActionFailed = msg.failed
+			m.projects[msg.projectIndex].ActionOutput = ""
+		}
+		cmds = append(cmds, m.showToast(msg.summary, msg.failed))
+
+	case pushTargetMsg:
+		m.resolvingPush = -1
+		switch {
+		case msg.err != nil:
+			cmds = append(cmds, m.showToast(pushFailure(msg.err), true))
+		case m.selectedProjectIndex() != msg.projectIndex || m.projects[msg.projectIndex].Project.Path != msg.path:
+			// The selection moved meanwhile: the prompt would not name the project pushed
+		case m.pushingProject == -1 && m.confirmTags == nil && m.confirmLock == nil && m.confirmDelete == nil:
+			m.confirmPush = &pushConfirm{projectIndex: msg.projectIndex, target: msg.target}
+		}
+
+	case pushCompleteMsg:
+		m.pushingProject = -1
+		if msg.projectIndex < len(m.projects) {
+			m.projects[msg.projectIndex].Action = msg.summary
+			m.projects[msg.projectIndex].ActionFailed = msg.failed
+			m.projects[msg.projectIndex].ActionOutput = msg.output
+		}
+		// A project clean once pushed leaves the list when clean ones are hidden
+		if filtered := m.getFilteredProjects(); m.selectedProject >= len(filtered) {
+			m.selectedProject = max(len(filtered)-1, 0)
+			m.detailsScroll = 0
+		}
+		cmds = append(cmds, m.showToast(msg.summary, msg.failed))
+
+	case lockRemovedMsg:
+		if msg.projectIndex < len(m.projects) {
+			m.projects[msg.projectIndex].Action = msg.summary
+			m.projects[msg.projectIndex].ActionFailed = msg.failed
+			m.projects[msg.projectIndex].ActionOutput = ""
+		}
+		cmds = append(cmds, m.showToast(msg.summary, msg.failed))
+
+	case deleteBranchCompleteMsg:
+		if msg.projectIndex < len(m.projects) {
+			m.projects[msg.projectIndex].Action = msg.summary
+			m.projects[msg.projectIndex].ActionFailed = msg.failed
+			m.projects[msg.projectIndex].ActionOutput = ""
 		}
+		cmds = append(cmds, m.showToast(msg.summary, msg.failed))
 
 	case spinner.TickMsg:
-		if m.loading {
+		if m.loading || m.statuses != nil {
 			m.spinner, cmd = m.spinner.Update(msg)
 			cmds = append(cmds, cmd)
 		}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/gittest"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/pkg/checkprojects"
+)
+
+// pushModel returns a model listing two projects ahead of their upstream, the first one selected
+func pushModel(t *testing.T) Model {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Categories = []config.Category{{Name: "dev"}}
+	m := NewModel(context.Background(), cfg, "test", checkprojects.Options{})
+	m.loading = false
+	for _, name := range []string{"api", "web"} {
+		r := gittest.NewRepo(t).Commit("README.md").WithBareRemote().PushAll().Ahead(1)
+		m.projects = append(m.projects, ProjectWithStatus{
+			Project: scanner.Project{Name: name, Category: "dev", Path: r.Path, Repository: r.Repository()},
+			Status:  &git.Status{Type: git.StatusUnsync, Ahead: 1},
+		})
+	}
+	return m
+}
+
+// press sends a key to the model
+func press(t *testing.T, m Model, key string) (Model, tea.Cmd) {
+	t.Helper()
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return updated.(Model), cmd
+}
+
+// deliver runs cmd and sends the message it returns to the model
+func deliver(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	updated, _ := m.Update(cmd())
+	return updated.(Model)
+}
+
+func TestPushConfirmationAfterTargetResolved(t *testing.T) {
+	m, cmd := press(t, pushModel(t), "P")
+	if cmd == nil {
+		t.Fatal("no command resolving the push target")
+	}
+	if m.confirmPush != nil {
+		t.Fatal("confirmation shown before the push target is resolved")
+	}
+	if _, again := press(t, m, "P"); again != nil {
+		t.Error("the push target is resolved again while pending")
+	}
+
+	m = deliver(t, m, cmd)
+	if m.confirmPush == nil {
+		t.Fatal("no confirmation once the push target is resolved")
+	}
+	if target := m.confirmPush.target; m.confirmPush.projectIndex != 0 || target.Branch != gittest.DefaultBranch || target.Remote != "origin" {
+		t.Errorf("confirming a push of %+v for project %d", target, m.confirmPush.projectIndex)
+	}
+	if m.resolvingPush != -1 {
+		t.Errorf("still resolving the push target of project %d", m.resolvingPush)
+	}
+}
+
+func TestPushConfirmationDroppedWhenSelectionMoved(t *testing.T) {
+	m, cmd := press(t, pushModel(t), "P")
+	m.selectedProject = 1
+	m = deliver(t, m, cmd)
+	if m.confirmPush != nil {
+		t.Errorf("confirming a push of project %d, no longer selected", m.confirmPush.projectIndex)
+	}
+	if _, cmd := press(t, m, "P"); cmd == nil {
+		t.Error("the selected project cannot be pushed after that")
+	}
+}
+
+func TestPushTargetFailure(t *testing.T) {
+	m := pushModel(t)
+	m.projects[0].Project.Repository = nil // Not a git repository
+	m, cmd := press(t, m, "P")
+	m = deliver(t, m, cmd)
+	if m.confirmPush != nil || !m.toastIsError {
+		t.Errorf("got confirmation %+v and toast %q, want an error toast", m.confirmPush, m.toast)
+	}
+}
@@ -1,11 +1,21 @@
 package tui
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/uralys/check-projects/internal/checker"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/reporter"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/vcs"
 )
 
 // Theme colors - centralized color definitions
@@ -14,6 +24,7 @@ var (
 	colorStatusClean  = lipgloss.Color("2") // Green for clean/success
 	colorStatusError  = lipgloss.Color("1") // Dark red for errors/modifications
 	colorStatusUnsync = lipgloss.Color("1") // Dark red for unsync
+	colorStatusStale  = lipgloss.Color("3") // Dark yellow for stale
 
 	// UI colors
 	colorTitle       = lipgloss.Color("86")  // Cyan for titles
@@ -53,6 +64,9 @@ var (
 	statusErrorStyle = lipgloss.NewStyle().
 				Foreground(colorStatusError)
 
+	statusStaleStyle = lipgloss.NewStyle().
+				Foreground(colorStatusStale)
+
 	helpStyle = lipgloss.NewStyle().
 			Foreground(colorHelp).
 			MarginTop(1)
@@ -164,6 +178,9 @@ func renderSplitView(m Model) string {
 	// Height calculation - use fixed reserved space like width
 	// Reserve: top margin (1) + header box (~4-5) + blank line (1) + footer (2) = ~9 lines
 	reservedHeight := 9
+	if len(m.deadEntries) > 0 {
+		reservedHeight++ // Notice above the footer
+	}
 
 	// Remaining height for panels (including their borders)
 	panelTotalHeight := m.height - reservedHeight
@@ -281,13 +298,13 @@ func renderProjectsList(m Model, width, height int) string {
 		var renderedStatus string
 
 		if p.Status != nil {
-			statusSymbol = p.Status.Symbol
+			statusSymbol = reporter.CountedSymbol(p.Status)
 			switch p.Status.Type {
 			case "sync":
 				renderedStatus = statusCleanStyle.Render(statusSymbol)
 			case "unsync":
 				// Special case: if symbol is ⬆ (ahead of remote), use green
-				if statusSymbol == "⬆" {
+				if p.Status.Symbol == "⬆" {
 					renderedStatus = statusCleanStyle.Render(statusSymbol)
 				} else if strings.HasPrefix(statusSymbol, "✱ ") {
 					// Staged changes: ✱ (red) + letter (green)
@@ -300,9 +317,16 @@ func renderProjectsList(m Model, width, height int) string {
 				} else {
 					renderedStatus = statusUnsyncStyle.Render(statusSymbol)
 				}
-			case "error", "broken_symlink":
+			case "error", "broken_symlink", "timeout", "locked":
 				renderedStatus = statusErrorStyle.Render(statusSymbol)
+			case "stale":
+				renderedStatus = statusStaleStyle.Render(statusSymbol)
+			case "empty", "no_remote", "unversioned":
+				renderedStatus = statusUnsyncStyle.Render(statusSymbol)
 			}
+		} else if m.statuses != nil {
+			// Still being checked
+			renderedStatus = strings.TrimSpace(m.spinner.View())
 		} else {
 			renderedStatus = statusSymbol
 		}
@@ -311,15 +335,36 @@ func renderProjectsList(m Model, width, height int) string {
 		if p.Project.IsSymlink && p.Project.SymlinkTarget != "" {
 			projectLabel = fmt.Sprintf("%s -> %s", p.Project.Name, p.Project.SymlinkTarget)
 		}
+		if vcs := p.Project.VCS(); vcs != "" && vcs != "git" {
+			projectLabel += fmt.Sprintf(" (%s)", vcs)
+		}
 
 		line := fmt.Sprintf("%s%s %s", prefix, renderedStatus, style.Render(projectLabel))
+		if p.Status != nil {
+			if counts := reporter.CompactChangeCounts(p.Status); counts != "" {
+				line += lipgloss.NewStyle().Foreground(colorBorder).Render(" (" + counts + ")")
+			}
+		}
+		if p.Status != nil && p.Status.OffDefaultBranch {
+			line += lipgloss.NewStyle().Foreground(colorBorder).Render(" ⎇ " + p.Status.Branch)
+		}
+		if m.fetchIsStale(p.Status) {
+			line += statusStaleStyle.Render(" ⏳")
+		}
 
-		// Add fetching indicator if this project is being fetched
+		// Add fetching or pulling indicator if this project is being fetched or pulled
 		for j, fullProj := range m.projects {
-			if fullProj.Project.Path == p.Project.Path && j == m.fetchingProject {
+			if fullProj.Project.Path != p.Project.Path {
+				continue
+			}
+			if j == m.fetchingProject {
 				line += lipgloss.NewStyle().Foreground(colorVersion).Render(" (fetching...)")
-				break
+			} else if j == m.pullingProject {
+				line += lipgloss.NewStyle().Foreground(colorVersion).Render(" (pulling...)")
+			} else if j == m.pushingProject {
+				line += lipgloss.NewStyle().Foreground(colorVersion).Render(" (pushing...)")
 			}
+			break
 		}
 
 		lines = append(lines, line)
@@ -366,11 +411,13 @@ func renderDetailsPanel(m Model, width, height int) string {
 
 	selectedProj := filtered[m.selectedProject]
 
-	// Check if this project is being fetched
-	isFetching := false
+	// Check if this project is being fetched or pulled
+	isFetching, isPulling, isPushing := false, false, false
 	for i, p := range m.projects {
-		if p.Project.Path == selectedProj.Project.Path && i == m.fetchingProject {
-			isFetching = true
+		if p.Project.Path == selectedProj.Project.Path {
+			isFetching = i == m.fetchingProject
+			isPulling = i == m.pullingProject
+			isPushing = i == m.pushingProject
 			break
 		}
 	}
@@ -378,8 +425,28 @@ func renderDetailsPanel(m Model, width, height int) string {
 	// Build content lines
 	var contentLines []string
 
-	// Path
+	// Path, where it pushes to, then how recent the last commit is
 	contentLines = append(contentLines, labelStyle.Render(selectedProj.Project.Path))
+	if selectedProj.Status != nil && selectedProj.Project.VCS() == vcs.KindGit {
+		if origin := reporter.OriginLabel(selectedProj.Status); origin != "" {
+			contentLines = append(contentLines, labelStyle.Render("→ "+origin))
+		}
+	}
+	if selectedProj.Status != nil && selectedProj.Status.LastCommit != nil {
+		contentLines = append(contentLines, labelStyle.Render("Last commit "+reporter.CommitLabel(selectedProj.Status.LastCommit, time.Now())))
+	}
+	if selectedProj.Status != nil && !selectedProj.Status.LastFetched.IsZero() {
+		fetched := reporter.FetchedLabel(selectedProj.Status, time.Now())
+		if m.fetchIsStale(selectedProj.Status) {
+			contentLines = append(contentLines, statusStaleStyle.Render("⏳ "+fetched))
+		} else {
+			contentLines = append(contentLines, labelStyle.Render(fetched))
+		}
+	}
+
+	if selectedProj.Status != nil && selectedProj.Status.IdentityMismatch {
+		contentLines = append(contentLines, statusUnsyncStyle.Render(git.IdentitySymbol+" "+reporter.IdentityLabel(selectedProj.Status)))
+	}
 
 	// Broken symlink - show target info and return early
 	if selectedProj.Status != nil && selectedProj.Status.Type == "broken_symlink" {
@@ -391,6 +458,43 @@ func renderDetailsPanel(m Model, width, height int) string {
 		return renderDetailsPanelContent(contentLines, width, height, 0, false)
 	}
 
+	// Not a repository - nothing to run git on
+	if selectedProj.Status != nil && selectedProj.Status.Type == git.StatusUnversioned {
+		contentLines = append(contentLines, "")
+		contentLines = append(contentLines, statusUnsyncStyle.Render(git.UnversionedSymbol+" Not under version control"))
+		contentLines = append(contentLines, labelStyle.Render("git init to start tracking it, or add it to the ignore patterns"))
+		return renderDetailsPanelContent(contentLines, width, height, 0, false)
+	}
+
+	// Bare repository - nothing to run git status on
+	if selectedProj.Status != nil && selectedProj.Status.Bare {
+		contentLines = append(contentLines, "")
+		contentLines = append(contentLines, labelStyle.Render("bare repository"))
+		if selectedProj.Status.Type != git.StatusBare {
+			contentLines = append(contentLines, statusUnsyncStyle.Render(selectedProj.Status.Message))
+		}
+		return renderDetailsPanelContent(contentLines, width, height, 0, false)
+	}
+
+	// Empty repository - no commit to compare with anything yet
+	if selectedProj.Status != nil && selectedProj.Status.Type == git.StatusEmpty {
+		contentLines = append(contentLines, "")
+		contentLines = append(contentLines, statusUnsyncStyle.Render(fmt.Sprintf("∅ No commits yet on %s", selectedProj.Status.Branch)))
+		if changes := reporter.ChangeSummary(selectedProj.Status); changes != "" {
+			contentLines = append(contentLines, labelStyle.Render(changes+", waiting for the first commit"))
+		} else {
+			contentLines = append(contentLines, labelStyle.Render("Add files and commit them to start tracking the project"))
+		}
+		return renderDetailsPanelContent(contentLines, width, height, 0, false)
+	}
+
+	// Not checked yet
+	if selectedProj.Status == nil && m.statuses != nil {
+		contentLines = append(contentLines, "")
+		contentLines = append(contentLines, lipgloss.NewStyle().Foreground(colorVersion).Render(m.spinner.View()+"Checking..."))
+		return renderDetailsPanelContent(contentLines, width, height, 0, false)
+	}
+
 	// If fetching, show loader and return early
 	if isFetching {
 		contentLines = append(contentLines, "")
@@ -400,20 +504,127 @@ func renderDetailsPanel(m Model, width, height int) string {
 		// Pad and return early
 		return renderDetailsPanelContent(contentLines, width, height, 0, false)
 	}
+	if isPulling || isPushing {
+		action := "⟳ Pulling from remote..."
+		if isPushing {
+			action = "⟳ Pushing to remote..."
+		}
+		contentLines = append(contentLines, "")
+		contentLines = append(contentLines, lipgloss.NewStyle().Foreground(colorVersion).Render(action))
+		contentLines = append(contentLines, "")
+		return renderDetailsPanelContent(contentLines, width, height, 0, false)
+	}
 
-	// Always check remote status first
-	remoteStatus := getRemoteStatus(selectedProj.Project.Path)
+	// The git commands below run while rendering: bound them so that a repository on
+	// a hung mount cannot freeze the TUI
+	ctx, cancel := checker.WithTimeout(m.ctx, detailTimeout(m.checkOptions.Timeout))
+	defer cancel()
 
-	// Show git status --short output for non-clean projects
-	if selectedProj.Status != nil && selectedProj.Status.Type != "sync" {
-		// Get branch name
-		branchName := getGitBranch(selectedProj.Project.Path)
-		if branchName != "" {
-			contentLines = append(contentLines, labelStyle.Render(fmt.Sprintf("[%s]", branchName)))
+	// Sync with the upstream, as checked
+	remote := upstreamSync(selectedProj.Status)
+
+	branchLine := getBranchLine(ctx, selectedProj.Project.Path)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		contentLines = append(contentLines, "")
+		contentLines = append(contentLines, statusErrorStyle.Render("⌛ git did not answer in time"))
+		return renderDetailsPanelContent(contentLines, width, height, 0, false)
+	}
+
+	// How many lines the changes to tracked files insert and delete
+	if status := selectedProj.Status; status != nil && status.Type == git.StatusUnsync && status.Changes > status.UntrackedCount {
+		if repo, ok := selectedProj.Project.Repository.(*git.Repository); ok {
+			if stat, err := repo.DiffStat(ctx); err == nil && !stat.Empty() {
+				contentLines = append(contentLines, statusUnsyncStyle.Render(reporter.DiffStatLabel(stat)))
+				contentLines = append(contentLines, "") // Empty line
+			}
+		}
+	}
+
+	// The outcome of the last pull or push, with what git printed, until the next refresh
+	if selectedProj.Action != "" {
+		if selectedProj.ActionFailed {
+			contentLines = append(contentLines, statusErrorStyle.Render("✗ "+selectedProj.Action))
+		} else {
+			contentLines = append(contentLines, statusCleanStyle.Render("✔ "+selectedProj.Action))
+		}
+		if selectedProj.ActionOutput != "" {
+			for _, line := range strings.Split(selectedProj.ActionOutput, "\n") {
+				contentLines = append(contentLines, labelStyle.Render("  "+line))
+			}
+		}
+		contentLines = append(contentLines, "") // Empty line
+	}
+
+	// A lock file blocking git, which L removes once it is old enough
+	if status := selectedProj.Status; status != nil && status.Type == git.StatusLocked {
+		contentLines = append(contentLines, statusErrorStyle.Render(git.LockSymbol+" "+reporter.LockHint(status, time.Now())))
+		if time.Since(status.LockedAt) >= git.StaleLockAge {
+			contentLines = append(contentLines, labelStyle.Render("L: remove the lock file"))
+		} else {
+			contentLines = append(contentLines, labelStyle.Render("Recent lock: a git process may still be running"))
+		}
+		contentLines = append(contentLines, "") // Empty line
+	}
+
+	// A repository without remote only has its local state, and its last commit above
+	if status := selectedProj.Status; status != nil && status.Type == git.StatusNoRemote {
+		contentLines = append(contentLines, statusUnsyncStyle.Render("⌂ No remote configured"))
+		contentLines = append(contentLines, labelStyle.Render("git remote add origin <url> to publish it"))
+		contentLines = append(contentLines, "") // Empty line
+	}
+
+	// Then an operation left in progress or conflicts, with the files left unmerged
+	if selectedProj.Status != nil && (selectedProj.Status.Operation != "" || selectedProj.Status.Conflicts > 0) {
+		contentLines = append(contentLines, statusErrorStyle.Render(selectedProj.Status.Symbol+" "+selectedProj.Status.Message))
+		if unmerged := getUnmergedPaths(ctx, selectedProj.Project.Path); len(unmerged) > 0 {
+			contentLines = append(contentLines, labelStyle.Render("Unmerged paths:"))
+			for _, path := range unmerged {
+				contentLines = append(contentLines, statusErrorStyle.Render("  U")+" "+path)
+			}
+		}
+		contentLines = append(contentLines, "") // Empty line
+	}
+
+	if branchLine != "" {
+		contentLines = append(contentLines, labelStyle.Render(branchLine))
+	}
+	if selectedProj.Status != nil && selectedProj.Status.Shallow {
+		contentLines = append(contentLines, statusUnsyncStyle.Render("⚠ "+reporter.ShallowNote+" (u: fetch the full history)"))
+	}
+	if status := selectedProj.Status; status != nil && status.GoneUpstream != "" {
+		contentLines = append(contentLines, statusErrorStyle.Render(git.GoneSymbol+" "+reporter.GoneLabel(status)))
+	}
+	if status := selectedProj.Status; status != nil && status.OffDefaultBranch && status.GoneUpstream == "" {
+		offDefault := "⎇ " + reporter.OffDefaultLabel(status)
+		if status.Origin != nil {
+			offDefault += getDivergence(ctx, selectedProj.Project.Path, status.Origin.Name+"/"+status.DefaultBranch)
 		}
+		contentLines = append(contentLines, statusUnsyncStyle.Render(offDefault))
+	}
+	if status := selectedProj.Status; status != nil && len(status.MergedBranches) > 0 {
+		contentLines = append(contentLines, labelStyle.Render(fmt.Sprintf("Merged into %s, safe to delete (d):", status.MergedInto)))
+		for i, branch := range status.MergedBranches {
+			marker := "  ✂ "
+			if confirm := m.confirmDelete; confirm != nil && confirm.selected == i && m.projects[confirm.projectIndex].Project.Path == selectedProj.Project.Path {
+				marker = "› ✂ "
+			}
+			contentLines = append(contentLines, statusCleanStyle.Render(marker)+branch)
+		}
+	}
+	if status := selectedProj.Status; status != nil && len(status.UnpushedTags) > 0 {
+		contentLines = append(contentLines, labelStyle.Render(reporter.TagsLabel(len(status.UnpushedTags))+" (T: push):"))
+		for _, tag := range status.UnpushedTags {
+			contentLines = append(contentLines, statusUnsyncStyle.Render("  🏷 ")+tag)
+		}
+	}
 
-		gitOutput := getGitStatusShort(selectedProj.Project.Path)
+	// Show git status --short output for non-clean projects
+	if selectedProj.Status != nil && selectedProj.Status.Type != "sync" {
+		gitOutput := getGitStatusShort(ctx, selectedProj.Project.Path)
 		if gitOutput != "" {
+			if changes := reporter.ChangeSummary(selectedProj.Status); changes != "" {
+				contentLines = append(contentLines, labelStyle.Render(changes))
+			}
 			// Split git output into lines
 			gitLines := strings.Split(colorizeGitStatus(gitOutput), "\n")
 			contentLines = append(contentLines, gitLines...)
@@ -421,44 +632,35 @@ func renderDetailsPanel(m Model, width, height int) string {
 			// No local changes but status is unsync - likely ahead of remote
 			contentLines = append(contentLines, statusCleanStyle.Render("✔")+" No local changes")
 
-			// Check remote status
-			if remoteStatus.HasRemote {
-				if remoteStatus.AheadCount > 0 {
-					contentLines = append(contentLines, statusCleanStyle.Render("⬆")+" Ready to be pushed")
-				} else if remoteStatus.RemoteAhead {
-					contentLines = append(contentLines, statusErrorStyle.Render("↓")+" Remote is ahead: Requires pull")
+			if remote.tracked {
+				if remote.ahead > 0 {
+					contentLines = append(contentLines, statusCleanStyle.Render("⬆")+fmt.Sprintf(" %d commit(s) ready to be pushed", remote.ahead))
+				} else if remote.behind > 0 {
+					contentLines = append(contentLines, statusErrorStyle.Render("↓")+fmt.Sprintf(" Remote is ahead by %d commit(s): Requires pull", remote.behind))
 				}
 			}
 		}
 
 		// Always show remote status check for modified files too
-		if remoteStatus.HasRemote && gitOutput != "" {
+		if remote.tracked && gitOutput != "" {
 			contentLines = append(contentLines, "") // Empty line
-			if remoteStatus.RemoteAhead {
-				contentLines = append(contentLines, statusErrorStyle.Render("↓")+" Remote is ahead: Requires pull")
-			} else if remoteStatus.AheadCount > 0 {
-				contentLines = append(contentLines, statusCleanStyle.Render("⬆")+" Also ahead of remote")
+			if remote.behind > 0 {
+				contentLines = append(contentLines, statusErrorStyle.Render("↓")+fmt.Sprintf(" Remote is ahead by %d commit(s): Requires pull", remote.behind))
+			} else if remote.ahead > 0 {
+				contentLines = append(contentLines, statusCleanStyle.Render("⬆")+fmt.Sprintf(" Also ahead of remote by %d commit(s)", remote.ahead))
 			}
 		}
 	} else {
-		// Project is clean - show remote status
-		// Get and show branch name
-		branchName := getGitBranch(selectedProj.Project.Path)
-		if branchName != "" {
-			contentLines = append(contentLines, labelStyle.Render(fmt.Sprintf("[%s]", branchName)))
-		}
-
-		// Show local status
+		// Project is clean - show local status, then remote status
 		contentLines = append(contentLines, statusCleanStyle.Render("✔")+" No local changes")
 
-		// Show remote status if available
-		if remoteStatus.HasRemote {
-			if remoteStatus.IsUpToDate {
+		if remote.tracked {
+			if remote.ahead == 0 && remote.behind == 0 {
 				contentLines = append(contentLines, statusCleanStyle.Render("✔")+" Up to date with remote")
-			} else if remoteStatus.RemoteAhead {
-				contentLines = append(contentLines, statusErrorStyle.Render("↓")+" Remote is ahead: Requires pull")
-			} else if remoteStatus.AheadCount > 0 {
-				contentLines = append(contentLines, statusCleanStyle.Render("⬆")+" Ready to be pushed")
+			} else if remote.behind > 0 {
+				contentLines = append(contentLines, statusErrorStyle.Render("↓")+fmt.Sprintf(" Remote is ahead by %d commit(s): Requires pull", remote.behind))
+			} else {
+				contentLines = append(contentLines, statusCleanStyle.Render("⬆")+fmt.Sprintf(" %d commit(s) ready to be pushed", remote.ahead))
 			}
 		}
 	}
@@ -472,6 +674,58 @@ func renderDetailsPanel(m Model, width, height int) string {
 		}
 	}
 
+	// Show one line per compared remote
+	if selectedProj.Status != nil && len(selectedProj.Status.RemoteComparisons) > 0 {
+		contentLines = append(contentLines, "") // Empty line
+		contentLines = append(contentLines, labelStyle.Render("Remotes:"))
+		for _, comparison := range selectedProj.Status.RemoteComparisons {
+			symbol := statusCleanStyle.Render("  ✔")
+			if comparison.Behind > 0 {
+				symbol = statusErrorStyle.Render("  ↓")
+			} else if comparison.Ahead > 0 {
+				symbol = statusCleanStyle.Render("  ⬆")
+			}
+			contentLines = append(contentLines, symbol+" "+reporter.ComparisonLabel(comparison))
+		}
+	}
+
+	// Show local-only branches if any
+	if selectedProj.Status != nil && len(selectedProj.Status.LocalOnlyBranches) > 0 {
+		contentLines = append(contentLines, "") // Empty line
+		contentLines = append(contentLines, labelStyle.Render("Local-only branches:"))
+		for _, branch := range selectedProj.Status.LocalOnlyBranches {
+			contentLines = append(contentLines, statusStaleStyle.Render("  ⬆")+" "+branch.Branch+": "+branch.Message)
+		}
+	}
+
+	// Show the dirty submodules with their own changes
+	if selectedProj.Status != nil {
+		for _, submodule := range selectedProj.Status.DirtySubmodules {
+			contentLines = append(contentLines, "") // Empty line
+			contentLines = append(contentLines, labelStyle.Render(fmt.Sprintf("Submodule %s:", submodule)))
+			gitOutput := getGitStatusShort(ctx, filepath.Join(selectedProj.Project.Path, submodule))
+			if gitOutput == "" {
+				contentLines = append(contentLines, statusErrorStyle.Render("  *")+" New commit, not recorded in the parent")
+				continue
+			}
+			for _, line := range strings.Split(colorizeGitStatus(gitOutput), "\n") {
+				contentLines = append(contentLines, "  "+line)
+			}
+		}
+	}
+
+	// Show the stash entries if any
+	if selectedProj.Status != nil && selectedProj.Status.StashCount > 0 {
+		stashes, err := git.NewRepository(selectedProj.Project.Path, selectedProj.Project.Name).StashList(ctx)
+		if err == nil && len(stashes) > 0 {
+			contentLines = append(contentLines, "") // Empty line
+			contentLines = append(contentLines, labelStyle.Render("Stashes:"))
+			for _, stash := range stashes {
+				contentLines = append(contentLines, statusStaleStyle.Render("  ⚑")+" "+stash.Ref+": "+stash.Message)
+			}
+		}
+	}
+
 	// Render the content with scrolling
 	return renderDetailsPanelContent(contentLines, width, height, m.detailsScroll, true)
 }
@@ -679,6 +933,12 @@ func renderCategoryHorizontalScrollbar(m Model, width int) string {
 func renderFooter(m Model) string {
 	var footer strings.Builder
 
+	// Config entries listing no repository stay noticed until the config is fixed
+	if len(m.deadEntries) > 0 {
+		footer.WriteString(helpStyle.Foreground(colorVersion).Render(truncateLine(deadNotice(m.deadEntries), m.width)))
+		footer.WriteString("\n")
+	}
+
 	// Title and version line
 	if m.version != "" {
 		titleStyle := lipgloss.NewStyle().
@@ -690,29 +950,102 @@ func renderFooter(m Model) string {
 		titleLine := titleStyle.Render("check-projects") + " | " + versionStyle.Render(m.version) + " | " + linkStyle.Render("https://github.com/uralys/check-projects")
 		footer.WriteString(titleLine)
 	}
+	if m.watcher != nil {
+		footer.WriteString(" | " + lipgloss.NewStyle().Foreground(colorHelp).Render(fmt.Sprintf("watching %d paths", m.watchedPaths)))
+	}
 
-	// Help bar on same line
+	// Help bar on same line, replaced by the toast while one is shown
 	footer.WriteString("  ")
-	footer.WriteString(renderHelpBar(m))
+	switch {
+	case m.confirmPush != nil:
+		footer.WriteString(helpStyle.Foreground(colorVersion).Render(pushPrompt(m.confirmPush.target)))
+	case m.confirmTags != nil:
+		footer.WriteString(helpStyle.Foreground(colorVersion).Render(tagsPrompt(m.confirmTags)))
+	case m.confirmLock != nil:
+		footer.WriteString(helpStyle.Foreground(colorVersion).Render(lockPrompt(m.confirmLock)))
+	case m.confirmDelete != nil:
+		footer.WriteString(helpStyle.Foreground(colorVersion).Render(deletePrompt(m.confirmDelete)))
+	case m.fetchAll != nil:
+		footer.WriteString(helpStyle.Foreground(colorVersion).Render(fetchAllProgress(m.fetchAll)))
+	case m.toast != "" && m.toastIsError:
+		footer.WriteString(helpStyle.Foreground(colorStatusError).Render(m.toast))
+	case m.toast != "":
+		footer.WriteString(helpStyle.Foreground(colorStatusClean).Render(m.toast))
+	default:
+		footer.WriteString(renderHelpBar(m))
+	}
 
 	return footer.String()
 }
 
+// deadNotice lists the config entries listing no repository, on one line
+func deadNotice(dead []scanner.Warning) string {
+	entries := make([]string, len(dead))
+	for i, warning := range dead {
+		entries[i] = fmt.Sprintf("%s (%s)", warning.Path, warning.Message)
+	}
+	return fmt.Sprintf("⚠ Config entries listing no repository: %s — see check-projects prune", strings.Join(entries, ", "))
+}
+
+// fetchAllProgress tells how many fetches of fetch all completed
+func fetchAllProgress(state *fetchAllState) string {
+	progress := fmt.Sprintf("Fetching all: %d/%d", state.done, state.total)
+	if len(state.failed) > 0 {
+		progress += fmt.Sprintf(" (%d failed)", len(state.failed))
+	}
+	return progress
+}
+
 func renderHelpBar(m Model) string {
-	help := "q/esc: quit | ↑↓: scroll | ←→: categories | enter: switch panel | h: toggle clean | f: fetch | r: refresh"
+	help := "q/esc: quit | ↑↓: scroll | ←→: categories | enter: switch panel | h: toggle clean | s: sort (" + m.sortOrder + ") | f: fetch | F: fetch all | p: pull | P: push | r: refresh | ctrl+s: export"
 	if m.hideClean {
 		help = strings.Replace(help, "toggle clean", "show clean", 1)
 	} else {
 		help = strings.Replace(help, "toggle clean", "hide clean", 1)
 	}
+	if i := m.selectedProjectIndex(); i != -1 && m.projects[i].Status != nil && m.projects[i].Status.Shallow {
+		help += " | u: unshallow"
+	}
+	if i := m.selectedProjectIndex(); i != -1 && m.projects[i].Status != nil && len(m.projects[i].Status.MergedBranches) > 0 {
+		help += " | d: delete merged branch"
+	}
+	if i := m.selectedProjectIndex(); i != -1 && m.projects[i].Status != nil && len(m.projects[i].Status.UnpushedTags) > 0 {
+		help += " | T: push tags"
+	}
+	if i := m.selectedProjectIndex(); i != -1 && m.projects[i].Status != nil && m.projects[i].Status.Type == git.StatusLocked {
+		help += " | L: remove lock"
+	}
+	if m.staleOnly {
+		help += " | t: all statuses"
+	} else if m.hasStale() {
+		help += " | t: stale only"
+	}
 
 	return helpStyle.Render(help)
 }
 
+// maxDetailWait is the longest the details panel waits for git
+const maxDetailWait = 2 * time.Second
+
+// detailTimeout bounds the git commands of the details panel: the configured
+// timeout when shorter than maxDetailWait
+func detailTimeout(timeout time.Duration) time.Duration {
+	if timeout > 0 && timeout < maxDetailWait {
+		return timeout
+	}
+	return maxDetailWait
+}
+
+// gitCommand builds a git command for the details panel, bound to ctx
+func gitCommand(ctx context.Context, projectPath string, args ...string) *exec.Cmd {
+	cmd := git.Command(ctx, projectPath, args...)
+	cmd.WaitDelay = 100 * time.Millisecond
+	return cmd
+}
+
 // getGitBranch returns the current git branch name
-func getGitBranch(projectPath string) string {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = projectPath
+func getGitBranch(ctx context.Context, projectPath string) string {
+	cmd := gitCommand(ctx, projectPath, "rev-parse", "--abbrev-ref", "HEAD")
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -722,75 +1055,48 @@ func getGitBranch(projectPath string) string {
 	return strings.TrimSpace(string(output))
 }
 
-// RemoteStatus represents the status of the local branch relative to remote
-type RemoteStatus struct {
-	HasRemote     bool
-	IsUpToDate    bool
-	RemoteAhead   bool
-	AheadCount    int
-	BehindCount   int
-	HasLocalDiffs bool
-}
-
-// getRemoteStatus checks if local branch is ahead/behind remote
-func getRemoteStatus(projectPath string) RemoteStatus {
-	status := RemoteStatus{
-		HasRemote:     false,
-		IsUpToDate:    false,
-		RemoteAhead:   false,
-		HasLocalDiffs: false,
-	}
-
-	// Check if there are local uncommitted changes
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = projectPath
-	output, err := cmd.CombinedOutput()
-	if err == nil && len(strings.TrimSpace(string(output))) > 0 {
-		status.HasLocalDiffs = true
+// getBranchLine returns "[branch]", or for a detached HEAD "[detached at <commit>]"
+// with the nearest tag when there is one
+func getBranchLine(ctx context.Context, projectPath string) string {
+	branchName := getGitBranch(ctx, projectPath)
+	if branchName != "HEAD" {
+		if branchName == "" {
+			return ""
+		}
+		return fmt.Sprintf("[%s]", branchName)
 	}
 
-	// Check if branch has an upstream
-	cmd = exec.Command("git", "rev-parse", "--abbrev-ref", "@{u}")
-	cmd.Dir = projectPath
-	_, err = cmd.CombinedOutput()
+	output, err := gitCommand(ctx, projectPath, "rev-parse", "--short", "HEAD").Output()
 	if err != nil {
-		// No upstream configured
-		return status
+		return "[detached]"
 	}
-	status.HasRemote = true
-
-	// Get ahead/behind counts
-	// Commits ahead: local commits not in remote
-	cmd = exec.Command("git", "rev-list", "--count", "@{u}..HEAD")
-	cmd.Dir = projectPath
-	output, err = cmd.CombinedOutput()
-	if err == nil {
-		_, _ = fmt.Sscanf(string(output), "%d", &status.AheadCount)
+	commit := strings.TrimSpace(string(output))
+	line := "detached at " + commit
+	// describe --always falls back to the commit itself without tags
+	output, err = gitCommand(ctx, projectPath, "describe", "--tags", "--always").Output()
+	if described := strings.TrimSpace(string(output)); err == nil && described != commit {
+		line += fmt.Sprintf(" (%s)", described)
 	}
+	return "[" + line + "]"
+}
 
-	// Commits behind: remote commits not in local
-	cmd = exec.Command("git", "rev-list", "--count", "HEAD..@{u}")
-	cmd.Dir = projectPath
-	output, err = cmd.CombinedOutput()
-	if err == nil {
-		_, _ = fmt.Sscanf(string(output), "%d", &status.BehindCount)
-	}
+// upstreamState is how the current branch compares with its upstream
+type upstreamState struct {
+	tracked       bool // The branch has an upstream (false when detached)
+	ahead, behind int
+}
 
-	// Determine overall status
-	if status.BehindCount > 0 {
-		status.RemoteAhead = true
+// upstreamSync reads the upstream state of a status, as checked
+func upstreamSync(status *git.Status) upstreamState {
+	if status == nil || status.Type == git.StatusNoUpstream || status.Type == git.StatusNoRemote || status.DetachedAt != "" || status.Bare {
+		return upstreamState{}
 	}
-	if status.AheadCount == 0 && status.BehindCount == 0 {
-		status.IsUpToDate = true
-	}
-
-	return status
+	return upstreamState{tracked: true, ahead: status.Ahead, behind: status.Behind}
 }
 
 // getGitStatusShort returns the output of git status --short
-func getGitStatusShort(projectPath string) string {
-	cmd := exec.Command("git", "status", "--short")
-	cmd.Dir = projectPath
+func getGitStatusShort(ctx context.Context, projectPath string) string {
+	cmd := gitCommand(ctx, projectPath, "status", "--short")
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -800,6 +1106,33 @@ func getGitStatusShort(projectPath string) string {
 	return strings.TrimSpace(string(output))
 }
 
+// getDivergence tells how far HEAD is from ref, e.g. " (3 ahead, 12 behind origin/main)",
+// or "" when it cannot be counted
+func getDivergence(ctx context.Context, projectPath, ref string) string {
+	output, err := gitCommand(ctx, projectPath, "rev-list", "--left-right", "--count", "HEAD..."+ref).Output()
+	if err != nil {
+		return ""
+	}
+	var ahead, behind int
+	if _, err := fmt.Sscanf(string(output), "%d %d", &ahead, &behind); err != nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%d ahead, %d behind %s)", ahead, behind, ref)
+}
+
+// getUnmergedPaths returns the files left unmerged by a merge, rebase, cherry-pick
+// or stash pop
+func getUnmergedPaths(ctx context.Context, projectPath string) []string {
+	cmd := gitCommand(ctx, projectPath, "diff", "--name-only", "--diff-filter=U")
+
+	output, err := cmd.Output()
+	if err != nil || len(bytes.TrimSpace(output)) == 0 {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
+
 // truncateLine truncates a line to maxWidth, preserving ANSI codes
 func truncateLine(line string, maxWidth int) string {
 	if lipgloss.Width(line) <= maxWidth {
@@ -0,0 +1,62 @@
+package updater
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// cachedRelease is the last successful answer from the release check
+type cachedRelease struct {
+	LatestVersion string    `json:"latest_version"`
+	CheckedAt     time.Time `json:"checked_at"`
+}
+
+// cacheFile returns where the last release check is stored
+func cacheFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "check-projects", "latest-release.json"), nil
+}
+
+// saveLatestVersion records the latest release so later commands can use it offline.
+// Failures are ignored: the cache is only a convenience.
+func saveLatestVersion(version string) {
+	path, err := cacheFile()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cachedRelease{
+		LatestVersion: strings.TrimPrefix(version, "v"),
+		CheckedAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0644)
+}
+
+// CachedLatestVersion returns the latest release seen by a previous update check,
+// without any network call. ok is false when no check has been recorded yet.
+func CachedLatestVersion() (version string, checkedAt time.Time, ok bool) {
+	path, err := cacheFile()
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	var cached cachedRelease
+	if err := json.Unmarshal(data, &cached); err != nil || cached.LatestVersion == "" {
+		return "", time.Time{}, false
+	}
+	return cached.LatestVersion, cached.CheckedAt, true
+}
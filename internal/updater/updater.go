@@ -74,17 +74,36 @@ func CheckForUpdatesAsync(currentVersion string) <-chan *UpdateResult {
 	return ch
 }
 
-// PrintUpdateNotice prints an update notice if available
-func PrintUpdateNotice(result *UpdateResult) {
+// WaitForUpdate waits up to timeout for the async update check to complete.
+// It returns nil when the channel is nil or the check did not finish in time.
+func WaitForUpdate(ch <-chan *UpdateResult, timeout time.Duration) *UpdateResult {
+	if ch == nil {
+		return nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-ch:
+		return result
+	case <-timer.C:
+		// Update check still in progress, skip notification
+		return nil
+	}
+}
+
+// PrintUpdateNotice prints an update notice to w if available
+func PrintUpdateNotice(w io.Writer, result *UpdateResult) {
 	if result == nil || !result.Available {
 		return
 	}
 
-	fmt.Printf("\n%s %s → %s\n",
+	fmt.Fprintf(w, "\n%s %s → %s\n",
 		yellow("⚠ New version available:"),
 		cyan(result.CurrentVersion),
 		green(result.LatestVersion))
-	fmt.Printf("Run %s to update\n", cyan("check-projects --update"))
+	fmt.Fprintf(w, "Run %s to update\n", cyan("check-projects --update"))
 }
 
 // CheckForUpdates checks if a new version is available (blocking, with prompt)
@@ -147,6 +166,7 @@ func getLatestVersion() (string, error) {
 		return "", err
 	}
 
+	saveLatestVersion(release.TagName)
 	return release.TagName, nil
 }
 
@@ -0,0 +1,51 @@
+package updater
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitForUpdate(t *testing.T) {
+	if got := WaitForUpdate(nil, time.Second); got != nil {
+		t.Errorf("no check: got %+v", got)
+	}
+
+	blocked := make(chan *UpdateResult)
+	start := time.Now()
+	if got := WaitForUpdate(blocked, 50*time.Millisecond); got != nil {
+		t.Errorf("check past the timeout: got %+v", got)
+	}
+	if took := time.Since(start); took > 2*time.Second {
+		t.Errorf("waited %s for a 50ms timeout", took)
+	}
+
+	done := make(chan *UpdateResult, 1)
+	done <- &UpdateResult{Available: true, CurrentVersion: "1.0.0", LatestVersion: "1.1.0"}
+	if got := WaitForUpdate(done, time.Second); got == nil || got.LatestVersion != "1.1.0" {
+		t.Errorf("finished check: got %+v", got)
+	}
+}
+
+func TestCheckForUpdatesAsyncSkipsDevBuilds(t *testing.T) {
+	for _, version := range []string{"", "dev", "v1.2.0-3-gabcdef-dirty"} {
+		if got := WaitForUpdate(CheckForUpdatesAsync(version), time.Second); got != nil {
+			t.Errorf("%q: got %+v, want no check", version, got)
+		}
+	}
+}
+
+func TestPrintUpdateNotice(t *testing.T) {
+	var out bytes.Buffer
+	PrintUpdateNotice(&out, nil)
+	PrintUpdateNotice(&out, &UpdateResult{Available: false})
+	if out.Len() != 0 {
+		t.Errorf("notice without update: %q", out.String())
+	}
+
+	PrintUpdateNotice(&out, &UpdateResult{Available: true, CurrentVersion: "1.0.0", LatestVersion: "1.1.0"})
+	if !strings.Contains(out.String(), "1.0.0") || !strings.Contains(out.String(), "1.1.0") || !strings.Contains(out.String(), "--update") {
+		t.Errorf("got %q", out.String())
+	}
+}
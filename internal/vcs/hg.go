@@ -0,0 +1,286 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// HgRepository is a Mercurial working copy.
+// Incoming and outgoing changesets are compared with the "default" path; other
+// branches and upstream setup have no equivalent and are never reported.
+type HgRepository struct {
+	Path string
+	Name string
+}
+
+// NewHgRepository creates a new HgRepository instance
+func NewHgRepository(path, name string) *HgRepository {
+	return &HgRepository{Path: path, Name: name}
+}
+
+// Kind returns KindHg
+func (r *HgRepository) Kind() string {
+	return KindHg
+}
+
+// run runs an hg command in the working copy with stable, untranslated output.
+// It returns the exit code of hg along with its output, and an error only when
+// hg could not run (or ctx ended).
+func (r *HgRepository) run(ctx context.Context, args ...string) (stdout string, stderr string, code int, err error) {
+	cmd := exec.CommandContext(ctx, "hg", args...)
+	cmd.Dir = r.Path
+	cmd.Env = append(os.Environ(), "HGPLAIN=1")
+	cmd.WaitDelay = 500 * time.Millisecond
+
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+
+	runErr := cmd.Run()
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "", "", 0, &git.TimeoutError{Operation: "hg " + args[0]}
+	case ctx.Err() != nil:
+		return "", "", 0, ctx.Err()
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		return out.String(), strings.TrimSpace(errOut.String()), exitErr.ExitCode(), nil
+	}
+	if runErr != nil {
+		return "", "", 0, runErr
+	}
+	return out.String(), "", 0, nil
+}
+
+// hgChanges summarizes `hg status` by kind of change
+type hgChanges struct {
+	Added     bool
+	Modified  bool
+	Removed   bool // Removed or missing files
+	Untracked bool
+}
+
+func (c hgChanges) any() bool {
+	return c.Added || c.Modified || c.Removed || c.Untracked
+}
+
+// parseHgStatus parses the output of `hg status`: one "<code> <path>" line per file
+func parseHgStatus(output string) hgChanges {
+	var changes hgChanges
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case 'A':
+			changes.Added = true
+		case 'M':
+			changes.Modified = true
+		case 'R', '!':
+			changes.Removed = true
+		case '?':
+			changes.Untracked = true
+		}
+	}
+	return changes
+}
+
+func (r *HgRepository) changes(ctx context.Context) (hgChanges, error) {
+	out, stderr, code, err := r.run(ctx, "status")
+	if err != nil {
+		return hgChanges{}, err
+	}
+	if code != 0 {
+		return hgChanges{}, fmt.Errorf("hg status failed: %s", stderr)
+	}
+	return parseHgStatus(out), nil
+}
+
+// countChangesets runs `hg incoming` or `hg outgoing` against the default path and
+// returns the number of changesets listed (hg exits with 1 when there are none)
+func (r *HgRepository) countChangesets(ctx context.Context, direction string) (int, error) {
+	out, stderr, code, err := r.run(ctx, direction, "--quiet")
+	if err != nil {
+		return 0, err
+	}
+	switch code {
+	case 0:
+		return len(strings.Fields(out)), nil
+	case 1:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("hg %s failed: %s", direction, stderr)
+	}
+}
+
+// hasDefaultPath reports whether the working copy has a "default" path to sync with
+func (r *HgRepository) hasDefaultPath(ctx context.Context) (bool, error) {
+	_, _, code, err := r.run(ctx, "paths", "default")
+	return err == nil && code == 0, err
+}
+
+// GetStatus reports local changes first, then incoming and outgoing changesets
+// (only compared with the default path when the working copy is clean)
+func (r *HgRepository) GetStatus(ctx context.Context) (*git.Status, error) {
+	branch, err := r.GetCurrentBranch(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return r.errorStatus(err, ""), nil
+	}
+	status := func(statusType git.StatusType, message, symbol string) *git.Status {
+		return &git.Status{Type: statusType, Message: message, Symbol: symbol, Branch: branch}
+	}
+
+	changes, err := r.changes(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return r.errorStatus(err, branch), nil
+	}
+
+	hasDefault, err := r.hasDefaultPath(ctx)
+	switch {
+	case err != nil:
+		return nil, err
+	case !hasDefault:
+		return status(git.StatusNoRemote, "No default path configured", git.NoRemoteSymbol), nil
+	case changes.Added:
+		return status(git.StatusUnsync, "Added files", "✱ +"), nil
+	case changes.Modified:
+		return status(git.StatusUnsync, "Modified files", "* M"), nil
+	case changes.Removed:
+		return status(git.StatusUnsync, "Deleted files", "* D"), nil
+	case changes.Untracked:
+		return status(git.StatusUnsync, "Untracked files", "✱ ✚"), nil
+	}
+
+	ahead, err := r.countChangesets(ctx, "outgoing")
+	if err == nil {
+		var behind int
+		if behind, err = r.countChangesets(ctx, "incoming"); err == nil {
+			s := syncStatus(ahead, behind)
+			s.Branch = branch
+			return s, nil
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, err
+	}
+	return r.errorStatus(err, branch), nil
+}
+
+// errorStatus is the status of a working copy hg failed on: permission denied
+// when a path could not be read, error otherwise
+func (r *HgRepository) errorStatus(err error, branch string) *git.Status {
+	if status := git.PermissionStatus(r.Path, err.Error(), err); status != nil {
+		status.Branch = branch
+		return status
+	}
+	return &git.Status{Type: git.StatusError, Message: fmt.Sprintf("Error: %v", err), Symbol: "❌", Branch: branch}
+}
+
+// syncStatus classifies a clean working copy by its changesets to push and pull
+func syncStatus(ahead, behind int) *git.Status {
+	s := &git.Status{Type: git.StatusUnsync, Ahead: ahead, Behind: behind}
+	switch {
+	case ahead > 0 && behind > 0:
+		s.Message, s.Symbol = "Diverged from remote", "⬆⬆"
+	case ahead > 0:
+		s.Message, s.Symbol = "Ahead of remote", "⬆"
+	case behind > 0:
+		s.Message, s.Symbol = "Behind remote", "↓"
+	default:
+		s.Type, s.Message, s.Symbol = git.StatusSync, "Clean", "✔"
+	}
+	return s
+}
+
+// Fetch pulls changesets from the default path without updating the working copy
+// (changesets are not counted: the result stays empty)
+func (r *HgRepository) Fetch(ctx context.Context, opts git.FetchOptions) (git.FetchResult, error) {
+	_, stderr, code, err := r.run(ctx, "pull")
+	if err != nil {
+		return git.FetchResult{}, err
+	}
+	if code != 0 {
+		return git.FetchResult{}, &git.FetchError{Operation: "pull", Stderr: stderr}
+	}
+	return git.FetchResult{}, nil
+}
+
+// GetCurrentBranch returns the name of the working copy's branch
+func (r *HgRepository) GetCurrentBranch(ctx context.Context) (string, error) {
+	out, stderr, code, err := r.run(ctx, "branch")
+	if err != nil {
+		return "", err
+	}
+	if code != 0 {
+		return "", fmt.Errorf("failed to get current branch: %s", stderr)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// LastCommitTime returns the date of the working copy's parent changeset.
+// It returns an error for repositories without changesets.
+func (r *HgRepository) LastCommitTime(ctx context.Context) (time.Time, error) {
+	out, stderr, code, err := r.run(ctx, "log", "-r", ".", "--template", "{date|hgdate}")
+	if err != nil {
+		return time.Time{}, err
+	}
+	if code != 0 {
+		return time.Time{}, fmt.Errorf("failed to read last commit: %s", stderr)
+	}
+
+	// "<unix seconds> <offset>", with seconds 0 when there is no changeset yet
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return time.Time{}, fmt.Errorf("unexpected hg log output: %q", out)
+	}
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected hg log output: %q", out)
+	}
+	if seconds == 0 {
+		return time.Time{}, fmt.Errorf("no changeset yet")
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// IsDirty reports whether the working copy has added, modified, removed or untracked files
+func (r *HgRepository) IsDirty(ctx context.Context) (bool, error) {
+	changes, err := r.changes(ctx)
+	return changes.any(), err
+}
+
+// Remotes returns the names of the configured paths
+func (r *HgRepository) Remotes(ctx context.Context) ([]string, error) {
+	out, stderr, code, err := r.run(ctx, "paths")
+	if err != nil {
+		return nil, err
+	}
+	if code != 0 {
+		return nil, fmt.Errorf("failed to list paths: %s", stderr)
+	}
+
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if name, _, ok := strings.Cut(line, " = "); ok {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	return names, nil
+}
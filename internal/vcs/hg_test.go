@@ -0,0 +1,235 @@
+package vcs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// commitDate is the date of every changeset of the fixtures
+var commitDate = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// hgFixture is a Mercurial working copy, cloned from an upstream one when it has a default path
+type hgFixture struct {
+	t        *testing.T
+	Path     string
+	Upstream string
+	commits  int
+}
+
+// hgEnv isolates hg from the user's configuration. It skips the test when hg is
+// not installed.
+func hgEnv(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("hg"); err != nil {
+		t.Skip("hg is not installed")
+	}
+	t.Setenv("HGRCPATH", "") // Only the hgrc of the repository
+	t.Setenv("HGUSER", "check-projects test <test@example.com>")
+	t.Setenv("HGPLAIN", "1")
+}
+
+// runHg runs hg in dir and returns its output
+func runHg(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("hg", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("hg %v in %s: %v: %s", args, dir, err, out)
+	}
+	return string(out)
+}
+
+// newHgFixture creates a working copy with one changeset, without default path
+func newHgFixture(t *testing.T) *hgFixture {
+	t.Helper()
+	hgEnv(t)
+	f := &hgFixture{t: t, Path: filepath.Join(t.TempDir(), "repo")}
+	runHg(t, filepath.Dir(f.Path), "init", f.Path)
+	return f.commit(f.Path, "README.md")
+}
+
+// clonedHgFixture returns a working copy cloned from an upstream one with one changeset
+func clonedHgFixture(t *testing.T) *hgFixture {
+	t.Helper()
+	upstream := newHgFixture(t)
+	f := &hgFixture{t: t, Path: filepath.Join(t.TempDir(), "repo"), Upstream: upstream.Path, commits: upstream.commits}
+	runHg(t, filepath.Dir(f.Path), "clone", "--quiet", upstream.Path, f.Path)
+	return f
+}
+
+// write writes a file of the working copy
+func (f *hgFixture) write(name, content string) *hgFixture {
+	f.t.Helper()
+	if err := os.WriteFile(filepath.Join(f.Path, name), []byte(content), 0644); err != nil {
+		f.t.Fatal(err)
+	}
+	return f
+}
+
+// commit adds and commits a file, with new content, in the working copy at dir
+func (f *hgFixture) commit(dir, name string) *hgFixture {
+	f.t.Helper()
+	f.commits++
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(name+" "+string(rune('a'+f.commits))), 0644); err != nil {
+		f.t.Fatal(err)
+	}
+	runHg(f.t, dir, "commit", "--quiet", "--addremove", "--date", commitDate.Format("2006-01-02 15:04:05 -0700"), "-m", "Update "+name)
+	return f
+}
+
+func (f *hgFixture) repository() *HgRepository {
+	return NewHgRepository(f.Path, filepath.Base(f.Path))
+}
+
+func TestHgGetStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture func(t *testing.T) *hgFixture
+		want    git.Status
+	}{
+		{"clean", clonedHgFixture,
+			git.Status{Type: git.StatusSync, Message: "Clean", Symbol: "✔", Branch: "default"}},
+		{"no default path", newHgFixture,
+			git.Status{Type: git.StatusNoRemote, Message: "No default path configured", Symbol: git.NoRemoteSymbol, Branch: "default"}},
+		{"modified", func(t *testing.T) *hgFixture { return clonedHgFixture(t).write("README.md", "changed") },
+			git.Status{Type: git.StatusUnsync, Message: "Modified files", Symbol: "* M", Branch: "default"}},
+		{"added", func(t *testing.T) *hgFixture {
+			f := clonedHgFixture(t).write("new.txt", "new")
+			runHg(t, f.Path, "add", "--quiet", "new.txt")
+			return f
+		}, git.Status{Type: git.StatusUnsync, Message: "Added files", Symbol: "✱ +", Branch: "default"}},
+		{"removed", func(t *testing.T) *hgFixture {
+			f := clonedHgFixture(t)
+			runHg(t, f.Path, "remove", "--quiet", "README.md")
+			return f
+		}, git.Status{Type: git.StatusUnsync, Message: "Deleted files", Symbol: "* D", Branch: "default"}},
+		{"missing", func(t *testing.T) *hgFixture {
+			f := clonedHgFixture(t)
+			if err := os.Remove(filepath.Join(f.Path, "README.md")); err != nil {
+				t.Fatal(err)
+			}
+			return f
+		}, git.Status{Type: git.StatusUnsync, Message: "Deleted files", Symbol: "* D", Branch: "default"}},
+		{"untracked", func(t *testing.T) *hgFixture { return clonedHgFixture(t).write("notes.txt", "notes") },
+			git.Status{Type: git.StatusUnsync, Message: "Untracked files", Symbol: "✱ ✚", Branch: "default"}},
+		{"ahead", func(t *testing.T) *hgFixture {
+			f := clonedHgFixture(t)
+			return f.commit(f.Path, "main.go").commit(f.Path, "main.go")
+		}, git.Status{Type: git.StatusUnsync, Message: "Ahead of remote", Symbol: "⬆", Branch: "default", Ahead: 2}},
+		{"behind", func(t *testing.T) *hgFixture {
+			f := clonedHgFixture(t)
+			return f.commit(f.Upstream, "remote.txt")
+		}, git.Status{Type: git.StatusUnsync, Message: "Behind remote", Symbol: "↓", Branch: "default", Behind: 1}},
+		{"diverged", func(t *testing.T) *hgFixture {
+			f := clonedHgFixture(t)
+			return f.commit(f.Upstream, "remote.txt").commit(f.Path, "local.txt")
+		}, git.Status{Type: git.StatusUnsync, Message: "Diverged from remote", Symbol: "⬆⬆", Branch: "default", Ahead: 1, Behind: 1}},
+		{"named branch", func(t *testing.T) *hgFixture {
+			f := clonedHgFixture(t)
+			runHg(t, f.Path, "branch", "--quiet", "feature")
+			return f.commit(f.Path, "feature.txt")
+		}, git.Status{Type: git.StatusUnsync, Message: "Ahead of remote", Symbol: "⬆", Branch: "feature", Ahead: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := tt.fixture(t).repository().GetStatus(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*status, tt.want) {
+				t.Errorf("got %+v, want %+v", *status, tt.want)
+			}
+		})
+	}
+}
+
+func TestHgGetStatusUnreachableDefault(t *testing.T) {
+	f := clonedHgFixture(t)
+	if err := os.RemoveAll(f.Upstream); err != nil {
+		t.Fatal(err)
+	}
+	status, err := f.repository().GetStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Type != git.StatusError || status.Branch != "default" {
+		t.Errorf("got %s on %q (%s), want %s", status.Type, status.Branch, status.Message, git.StatusError)
+	}
+}
+
+func TestHgFetch(t *testing.T) {
+	f := clonedHgFixture(t)
+	f.commit(f.Upstream, "remote.txt")
+	repo := f.repository()
+	if _, err := repo.Fetch(context.Background(), git.FetchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if out := runHg(t, f.Path, "log", "--template", "{desc}\n", "-r", "tip"); out != "Update remote.txt\n" {
+		t.Errorf("tip is %q after the pull", out)
+	}
+	if dirty, err := repo.IsDirty(context.Background()); err != nil || dirty {
+		t.Errorf("dirty %v (%v) after the pull, which must not update the working copy", dirty, err)
+	}
+
+	if err := os.RemoveAll(f.Upstream); err != nil {
+		t.Fatal(err)
+	}
+	_, err := repo.Fetch(context.Background(), git.FetchOptions{})
+	if fetchErr, ok := err.(*git.FetchError); !ok || fetchErr.Operation != "pull" {
+		t.Errorf("got %v, want a *git.FetchError of pull", err)
+	}
+}
+
+func TestHgRepositoryDetails(t *testing.T) {
+	f := clonedHgFixture(t)
+	repo := f.repository()
+	ctx := context.Background()
+
+	if remotes, err := repo.Remotes(ctx); err != nil || !reflect.DeepEqual(remotes, []string{"default"}) {
+		t.Errorf("remotes %q (%v), want default", remotes, err)
+	}
+	if remotes, err := newHgFixture(t).repository().Remotes(ctx); err != nil || len(remotes) != 0 {
+		t.Errorf("remotes %q (%v) without default path", remotes, err)
+	}
+	if date, err := repo.LastCommitTime(ctx); err != nil || !date.Equal(commitDate) {
+		t.Errorf("last commit %s (%v), want %s", date, err, commitDate)
+	}
+	if dirty, err := repo.IsDirty(ctx); err != nil || dirty {
+		t.Errorf("dirty %v (%v), want clean", dirty, err)
+	}
+	if dirty, err := f.write("notes.txt", "notes").repository().IsDirty(ctx); err != nil || !dirty {
+		t.Errorf("dirty %v (%v) with an untracked file", dirty, err)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	runHg(t, filepath.Dir(empty), "init", empty)
+	if _, err := NewHgRepository(empty, "empty").LastCommitTime(ctx); err == nil {
+		t.Error("no error for the last commit of a repository without changeset")
+	}
+}
+
+func TestParseHgStatus(t *testing.T) {
+	tests := []struct {
+		output string
+		want   hgChanges
+	}{
+		{"", hgChanges{}},
+		{"M main.go\n", hgChanges{Modified: true}},
+		{"A new.go\nR old.go\n", hgChanges{Added: true, Removed: true}},
+		{"! missing.go\n? notes.txt\n", hgChanges{Removed: true, Untracked: true}},
+		{"C clean.go\nI ignored.log\n", hgChanges{}},
+	}
+	for _, tt := range tests {
+		if got := parseHgStatus(tt.output); got != tt.want {
+			t.Errorf("%q: got %+v, want %+v", tt.output, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package vcs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+)
+
+// Version control systems detected by the scanner
+const (
+	KindGit = "git"
+	KindHg  = "hg"
+)
+
+// Repository is a working copy checked by check-projects, whatever its VCS.
+// Statuses use the git vocabulary: features a backend lacks (other branches
+// behind their remote, upstream setup) are simply never reported by it.
+type Repository interface {
+	// Kind returns the VCS of the repository (KindGit, KindHg)
+	Kind() string
+	// GetStatus returns the status of the working copy and its sync with the remote
+	GetStatus(ctx context.Context) (*git.Status, error)
+	// Fetch updates the knowledge of the remote without touching the working copy
+	Fetch(ctx context.Context, opts git.FetchOptions) (git.FetchResult, error)
+	// GetCurrentBranch returns the name of the current branch
+	GetCurrentBranch(ctx context.Context) (string, error)
+	// LastCommitTime returns the date of the working copy's parent commit
+	LastCommitTime(ctx context.Context) (time.Time, error)
+	// IsDirty reports whether the working copy has changes
+	IsDirty(ctx context.Context) (bool, error)
+	// Remotes lists the names of the configured remotes
+	Remotes(ctx context.Context) ([]string, error)
+}
+
+// UpstreamSetter is implemented by repositories whose branches can track a remote one
+type UpstreamSetter interface {
+	// SetUpstream tracks the remote branch of the same name, failing with a
+	// *git.BranchNotOnRemoteError when the remote does not have it
+	SetUpstream(ctx context.Context) error
+	// Push pushes the current branch, setting up its upstream with setUpstream
+	Push(ctx context.Context, setUpstream bool) (string, error)
+}
+
+// Detect returns the VCS of the working copy at path, or "" when it is none
+func Detect(path string) string {
+	if git.IsGitRepository(path) || git.IsBareRepository(path) {
+		return KindGit
+	}
+	if info, err := os.Stat(filepath.Join(path, ".hg")); err == nil && info.IsDir() {
+		return KindHg
+	}
+	return ""
+}
+
+// IsBare reports whether path is a bare git repository, without working tree
+func IsBare(path string) bool {
+	return !git.IsGitRepository(path) && git.IsBareRepository(path)
+}
+
+// IsRepository reports whether path is a working copy of a supported VCS
+func IsRepository(path string) bool {
+	return Detect(path) != ""
+}
+
+// gitInstalled reports whether the git binary can be found, looked up once
+var gitInstalled = sync.OnceValue(func() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+})
+
+// Open returns the repository at path for the given kind, as returned by Detect
+// (nil for an unknown kind). Git repositories are read with the given backend
+// (config.BackendExec or config.BackendGoGit); by default, with the git binary
+// when it is installed and with go-git otherwise.
+func Open(kind, path, name, backend string) Repository {
+	switch kind {
+	case KindGit:
+		if backend == config.BackendGoGit || (backend == "" && !gitInstalled()) {
+			return git.NewGoGitRepository(path, name)
+		}
+		return git.NewRepository(path, name)
+	case KindHg:
+		return NewHgRepository(path, name)
+	default:
+		return nil
+	}
+}
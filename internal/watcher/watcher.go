@@ -0,0 +1,325 @@
+// Package watcher follows projects on disk with filesystem notifications, so that
+// watch modes re-check a project as soon as it changes instead of on a timer.
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/scanner"
+	"github.com/uralys/check-projects/internal/vcs"
+)
+
+// DefaultDebounce is how long a project must stay quiet before it is reported as changed,
+// so that a commit or a checkout (bursts of events) is re-checked once
+const DefaultDebounce = 500 * time.Millisecond
+
+// Change is a batch of changes seen on disk
+type Change struct {
+	Projects []string // Paths of the projects whose files or metadata changed
+	Rescan   bool     // A directory appeared under a root, or a project went away
+}
+
+// role is what a watched directory stands for
+type role struct {
+	project string // Path of the project the directory belongs to, if any
+	holder  bool   // Holds projects: a new directory in it calls for a rescan
+}
+
+// Watcher follows the projects on disk: their working tree and metadata (.git, refs,
+// .hg), and the directories holding them under category roots
+type Watcher struct {
+	fs       *fsnotify.Watcher
+	debounce time.Duration
+
+	mu      sync.Mutex
+	watched map[string]role
+}
+
+// New returns a watcher reporting changes once they settled for debounce
+func New(debounce time.Duration) (*Watcher, error) {
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{fs: fs, debounce: debounce, watched: make(map[string]role)}, nil
+}
+
+// Set watches the roots of cfg and the given projects (as scanned), and stops watching
+// what they no longer cover. Paths that could not be watched (e.g. past the system limit
+// on watches) are left out and reported in the error.
+func (w *Watcher) Set(cfg *config.Config, projects []scanner.Project) error {
+	wanted := make(map[string]role)
+	add := func(dir string, r role) {
+		current := wanted[dir]
+		if r.project != "" {
+			current.project = r.project
+		}
+		current.holder = current.holder || r.holder
+		wanted[dir] = current
+	}
+	for _, category := range cfg.Categories {
+		if category.Root != "" && len(category.Projects) == 0 {
+			add(filepath.Clean(category.GetRootPath()), role{holder: true})
+		}
+	}
+	for _, project := range projects {
+		if project.Origin == scanner.OriginScanned {
+			add(filepath.Dir(project.Path), role{holder: true})
+		}
+		if project.Unversioned {
+			add(project.Path, role{holder: true})
+			continue
+		}
+		if project.Repository == nil {
+			continue // Broken symlink: nothing to watch until it is scanned again
+		}
+		for _, dir := range metadataDirs(project, cfg.SkipDirsFor(project.Category)) {
+			add(dir, role{project: project.Path})
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for dir := range w.watched {
+		if _, keep := wanted[dir]; !keep {
+			_ = w.fs.Remove(dir) // Fails when the directory is already gone
+			delete(w.watched, dir)
+		}
+	}
+	var failed []string
+	var firstErr error
+	for dir, r := range wanted {
+		if _, ok := w.watched[dir]; !ok {
+			if err := w.fs.Add(dir); errors.Is(err, os.ErrNotExist) {
+				continue // Gone since it was scanned
+			} else if err != nil {
+				failed = append(failed, dir)
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+		}
+		w.watched[dir] = r
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("%d paths not watched (e.g. %s): %w", len(failed), failed[0], firstErr)
+	}
+	return nil
+}
+
+// Paths returns how many directories are watched
+func (w *Watcher) Paths() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.watched)
+}
+
+// Changes reports the changes seen on disk until ctx is done or the watcher is closed.
+// Each project is reported once its events settled for the debounce delay, and changes
+// not yet received are merged together.
+func (w *Watcher) Changes(ctx context.Context) <-chan Change {
+	changes := make(chan Change)
+	go w.run(ctx, changes)
+	return changes
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+func (w *Watcher) run(ctx context.Context, changes chan<- Change) {
+	defer close(changes)
+
+	tick := time.NewTicker(max(w.debounce/5, 10*time.Millisecond))
+	defer tick.Stop()
+
+	pending := make(map[string]time.Time) // Last event per project
+	var rescanAt time.Time                // Last event calling for a rescan
+
+	var ready Change
+	var out chan<- Change // Set when ready holds a change to send
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case out <- ready:
+			ready, out = Change{}, nil
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			project, rescan := w.classify(event)
+			if rescan {
+				rescanAt = time.Now()
+			} else if project != "" {
+				pending[project] = time.Now()
+			}
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				rescanAt = time.Now() // Events were lost: start over
+			}
+		case now := <-tick.C:
+			if !rescanAt.IsZero() && now.Sub(rescanAt) >= w.debounce {
+				ready.Rescan = true
+				rescanAt = time.Time{}
+			}
+			for project, last := range pending {
+				if now.Sub(last) >= w.debounce {
+					ready.Projects = appendUnique(ready.Projects, project)
+					delete(pending, project)
+				}
+			}
+			if ready.Rescan || len(ready.Projects) > 0 {
+				out = changes
+			}
+		}
+	}
+}
+
+// classify returns the project an event belongs to, or whether it calls for a rescan:
+// a directory created in one holding projects, or a watched directory going away
+func (w *Watcher) classify(event fsnotify.Event) (string, bool) {
+	if event.Op == fsnotify.Chmod {
+		return "", false // Touched by indexers and backups, never a change of status
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if gone, ok := w.watched[event.Name]; ok && event.Has(fsnotify.Remove|fsnotify.Rename) {
+		if gone.holder || gone.project == event.Name {
+			return "", true
+		}
+	}
+
+	parent := w.watched[filepath.Dir(event.Name)]
+	if parent.holder && event.Has(fsnotify.Create) {
+		if _, known := w.watched[event.Name]; !known {
+			if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+				return "", true
+			}
+		}
+	}
+	return parent.project, false
+}
+
+// metadataDirs returns the directories whose changes may change the status of a
+// project: its working tree (see treeDirs), and its metadata where commits, checkouts
+// and fetches land (see refDirs). Linked worktrees and submodules (.git files) are only watched
+// through their working tree.
+func metadataDirs(project scanner.Project, skip []string) []string {
+	var dirs []string
+	switch project.VCS() {
+	case vcs.KindGit:
+		meta := project.Path
+		if !vcs.IsBare(project.Path) {
+			dirs = treeDirs(project.Path, skip)
+			meta = filepath.Join(project.Path, ".git")
+		}
+		if info, err := os.Stat(meta); err != nil || !info.IsDir() {
+			return dirs
+		}
+		dirs = append(dirs, meta)
+		dirs = append(dirs, refDirs(filepath.Join(meta, "refs", "heads"))...)
+		dirs = append(dirs, refDirs(filepath.Join(meta, "refs", "remotes"))...)
+	case vcs.KindHg:
+		dirs = append(treeDirs(project.Path, skip), filepath.Join(project.Path, ".hg"))
+	default:
+		dirs = []string{project.Path}
+	}
+	return dirs
+}
+
+// maxTreeDirs bounds the directories watched in a working tree, so that a huge one
+// does not use up the watches of the system: past it, deeper edits are only seen once
+// they are staged or committed
+const maxTreeDirs = 1000
+
+// treeDirs returns the directories of the working tree at root, breadth first, leaving
+// out hidden ones (metadata included), those matching skip (scan.skip_dirs, e.g. build
+// outputs) and nested repositories, which are projects of their own
+func treeDirs(root string, skip []string) []string {
+	dirs := []string{root}
+	for i := 0; i < len(dirs) && len(dirs) < maxTreeDirs; i++ {
+		entries, err := os.ReadDir(dirs[i])
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || skips(entry.Name(), skip) {
+				continue
+			}
+			dir := filepath.Join(dirs[i], entry.Name())
+			if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+				continue
+			}
+			if dirs = append(dirs, dir); len(dirs) == maxTreeDirs {
+				break
+			}
+		}
+	}
+	return dirs
+}
+
+// refDirs returns dir and the directories under it, breadth first and up to maxTreeDirs:
+// branches named like feature/x are loose refs in subdirectories of refs/heads and
+// refs/remotes/<remote>
+func refDirs(dir string) []string {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil
+	}
+	dirs := []string{dir}
+	for i := 0; i < len(dirs) && len(dirs) < maxTreeDirs; i++ {
+		entries, err := os.ReadDir(dirs[i])
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if dirs = append(dirs, filepath.Join(dirs[i], entry.Name())); len(dirs) == maxTreeDirs {
+				break
+			}
+		}
+	}
+	return dirs
+}
+
+// skips reports whether a directory name matches one of the skip patterns
+func skips(name string, skip []string) bool {
+	for _, pattern := range skip {
+		if matched, err := filepath.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(paths []string, path string) []string {
+	for _, p := range paths {
+		if p == path {
+			return paths
+		}
+	}
+	return append(paths, path)
+}
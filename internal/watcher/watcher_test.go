@@ -0,0 +1,144 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/gittest"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+const testDebounce = 50 * time.Millisecond
+
+// watchRepo watches a new repository scanned under its parent directory, and returns
+// them with the changes reported
+func watchRepo(t *testing.T) (*gittest.Repo, string, <-chan Change) {
+	t.Helper()
+	repo := gittest.NewRepo(t).Commit("README.md")
+	root := filepath.Dir(repo.Path)
+
+	w, err := New(testDebounce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+
+	cfg := &config.Config{Categories: []config.Category{{Name: "dev", Root: root}}}
+	project := scanner.Project{
+		Name:       repo.Name,
+		Path:       repo.Path,
+		Category:   "dev",
+		Repository: repo.Repository(),
+		Origin:     scanner.OriginScanned,
+	}
+	if err := w.Set(cfg, []scanner.Project{project}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return repo, root, w.Changes(ctx)
+}
+
+// nextChange returns the next change reported within timeout, if any
+func nextChange(changes <-chan Change, timeout time.Duration) (Change, bool) {
+	select {
+	case change := <-changes:
+		return change, true
+	case <-time.After(timeout):
+		return Change{}, false
+	}
+}
+
+func TestChangesReportsABurstOnce(t *testing.T) {
+	repo, _, changes := watchRepo(t)
+
+	for i := 0; i < 5; i++ {
+		repo.WriteFile("notes.txt", string(rune('a'+i)))
+		repo.WriteFile(filepath.Join("src", "main.go"), string(rune('a'+i)))
+		time.Sleep(testDebounce / 10)
+	}
+
+	change, ok := nextChange(changes, 2*time.Second)
+	if !ok {
+		t.Fatal("no change reported")
+	}
+	if change.Rescan || !slices.Equal(change.Projects, []string{repo.Path}) {
+		t.Fatalf("got %+v, want the project only", change)
+	}
+	if extra, ok := nextChange(changes, 5*testDebounce); ok {
+		t.Fatalf("got a second change %+v for the same burst", extra)
+	}
+}
+
+func TestChangesReportsCommits(t *testing.T) {
+	repo, _, changes := watchRepo(t)
+
+	repo.Commit("CHANGELOG.md")
+
+	change, ok := nextChange(changes, 2*time.Second)
+	if !ok {
+		t.Fatal("no change reported")
+	}
+	if !slices.Contains(change.Projects, repo.Path) {
+		t.Fatalf("got %+v, want the project", change)
+	}
+}
+
+func TestChangesRescansOnNewDirectoryUnderRoot(t *testing.T) {
+	_, root, changes := watchRepo(t)
+
+	if err := os.Mkdir(filepath.Join(root, "clone"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	change, ok := nextChange(changes, 2*time.Second)
+	if !ok {
+		t.Fatal("no change reported")
+	}
+	if !change.Rescan {
+		t.Fatalf("got %+v, want a rescan", change)
+	}
+}
+
+func TestMetadataDirsWalksNestedRefs(t *testing.T) {
+	repo := gittest.NewRepo(t).Commit("README.md").WithBareRemote()
+	repo.Branch("feature/deep/x").Checkout(gittest.DefaultBranch)
+	repo.Git("push", "--quiet", "origin", "feature/deep/x")
+
+	project := scanner.Project{Path: repo.Path, Repository: repo.Repository()}
+	dirs := metadataDirs(project, nil)
+
+	refs := filepath.Join(repo.Path, ".git", "refs")
+	for _, want := range []string{
+		filepath.Join(refs, "heads"),
+		filepath.Join(refs, "heads", "feature", "deep"),
+		filepath.Join(refs, "remotes", "origin"),
+		filepath.Join(refs, "remotes", "origin", "feature", "deep"),
+	} {
+		if !slices.Contains(dirs, want) {
+			t.Errorf("%s not watched in %v", want, dirs)
+		}
+	}
+}
+
+func TestRefDirsIsBounded(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < maxTreeDirs+10; i++ {
+		if err := os.Mkdir(filepath.Join(dir, fmt.Sprintf("branch-%04d", i)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := refDirs(dir); len(got) > maxTreeDirs {
+		t.Fatalf("got %d directories, want at most %d", len(got), maxTreeDirs)
+	}
+	if got := refDirs(filepath.Join(dir, "missing")); got != nil {
+		t.Fatalf("got %v for a missing directory", got)
+	}
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// stateFile returns where the digest of the last document delivered to each
+// webhook is stored, for only_on: change
+func stateFile() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "check-projects", "webhooks.json"), nil
+}
+
+// digest hashes s, so that neither URLs (which may hold secrets) nor reports are stored
+func digest(s []byte) string {
+	sum := sha256.Sum256(s)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadState returns the digests of the last delivered documents, by URL digest
+func loadState() map[string]string {
+	state := make(map[string]string)
+	path, err := stateFile()
+	if err != nil {
+		return state
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state
+	}
+	_ = json.Unmarshal(data, &state)
+	return state
+}
+
+// Changed reports whether state differs from the one recorded at the last delivery
+// to url. Without any recorded delivery, it is considered changed.
+func Changed(url string, state []byte) bool {
+	return loadState()[digest([]byte(url))] != digest(state)
+}
+
+// Remember records state as the one of the last delivery to url.
+// Failures are ignored: at worst an unchanged document is delivered again.
+func Remember(url string, state []byte) {
+	path, err := stateFile()
+	if err != nil {
+		return
+	}
+	digests := loadState()
+	digests[digest([]byte(url))] = digest(state)
+	data, err := json.Marshal(digests)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+)
+
+// Default delivery settings of a Sender
+const (
+	DefaultTimeout    = 10 * time.Second
+	DefaultRetries    = 3
+	DefaultBackoff    = time.Second
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Sender posts JSON documents to webhooks, retrying failed deliveries
+type Sender struct {
+	Client     *http.Client
+	Retries    int           // Attempts after the first one
+	Backoff    time.Duration // Delay before the first retry, doubled for each next one
+	MaxBackoff time.Duration // Longest delay between two attempts (0 = unbounded)
+
+	after func(d time.Duration) <-chan time.Time // Waits between attempts (nil = time.After)
+}
+
+// NewSender returns a Sender with the default timeout, retries and backoff
+func NewSender() *Sender {
+	return &Sender{
+		Client:     &http.Client{Timeout: DefaultTimeout},
+		Retries:    DefaultRetries,
+		Backoff:    DefaultBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+}
+
+// Validate returns an error when hook cannot be delivered to
+func Validate(hook config.Webhook) error {
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL '%s': expected http(s)://host/...", hook.URL)
+	}
+	switch hook.OnlyOn {
+	case "", config.WebhookAlways, config.WebhookDirty, config.WebhookChange:
+		return nil
+	default:
+		return fmt.Errorf("invalid only_on '%s' for webhook %s: expected %s, %s or %s",
+			hook.OnlyOn, Host(hook.URL), config.WebhookDirty, config.WebhookChange, config.WebhookAlways)
+	}
+}
+
+// Host returns the host of a webhook URL, safe to print when its path holds a secret
+func Host(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "webhook"
+}
+
+// Send posts body to hook. Network errors, 429 and 5xx responses are retried with
+// exponential backoff, up to MaxBackoff; other responses fail at once. It gives up
+// when ctx is done.
+func (s *Sender) Send(ctx context.Context, hook config.Webhook, body []byte) error {
+	after := s.after
+	if after == nil {
+		after = time.After
+	}
+	delay := s.Backoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = s.post(ctx, hook, body)
+		if err == nil || !retry || attempt == s.Retries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-after(s.capped(delay)):
+		}
+		delay = s.capped(2 * delay)
+	}
+	if err != nil && s.Retries > 0 {
+		return fmt.Errorf("%w (after %d attempts)", err, s.Retries+1)
+	}
+	return err
+}
+
+// capped returns delay, bounded by MaxBackoff
+func (s *Sender) capped(delay time.Duration) time.Duration {
+	if s.MaxBackoff > 0 && delay > s.MaxBackoff {
+		return s.MaxBackoff
+	}
+	return delay
+}
+
+// post makes a single delivery attempt, telling whether a failure is worth retrying
+func (s *Sender) post(ctx context.Context, hook config.Webhook, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "check-projects")
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		// Drop the URL, which may hold a secret, from the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("server answered %s", resp.Status)
+	default:
+		return false, fmt.Errorf("server answered %s", resp.Status)
+	}
+}
@@ -0,0 +1,152 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/uralys/check-projects/internal/config"
+)
+
+// failingServer answers the first failures requests with status, then 204, and
+// counts the requests it got
+func failingServer(t *testing.T, failures int, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(requests.Add(1)) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// recordingSender returns a Sender that does not wait between attempts, and the
+// delays it was asked to wait
+func recordingSender(retries int, backoff, maxBackoff time.Duration) (*Sender, *[]time.Duration) {
+	var delays []time.Duration
+	s := &Sender{
+		Client:     &http.Client{Timeout: 5 * time.Second},
+		Retries:    retries,
+		Backoff:    backoff,
+		MaxBackoff: maxBackoff,
+		after: func(d time.Duration) <-chan time.Time {
+			delays = append(delays, d)
+			ch := make(chan time.Time, 1)
+			ch <- time.Now()
+			return ch
+		},
+	}
+	return s, &delays
+}
+
+func TestSendRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		status   int
+		retries  int
+		requests int
+		fails    bool
+	}{
+		{"success", 0, 0, 3, 1, false},
+		{"server errors then success", 2, http.StatusBadGateway, 3, 3, false},
+		{"rate limited then success", 1, http.StatusTooManyRequests, 3, 2, false},
+		{"server errors past the retries", 10, http.StatusServiceUnavailable, 3, 4, true},
+		{"without retries", 10, http.StatusInternalServerError, 0, 1, true},
+		{"client error, not retried", 10, http.StatusUnauthorized, 3, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := failingServer(t, tt.failures, tt.status)
+			s, _ := recordingSender(tt.retries, time.Millisecond, 0)
+
+			err := s.Send(context.Background(), config.Webhook{URL: server.URL}, []byte(`{}`))
+			if (err != nil) != tt.fails {
+				t.Errorf("got %v, want failure %v", err, tt.fails)
+			}
+			if got := int(requests.Load()); got != tt.requests {
+				t.Errorf("%d requests, want %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestSendBackoffIsCapped(t *testing.T) {
+	server, requests := failingServer(t, 100, http.StatusInternalServerError)
+	s, delays := recordingSender(6, time.Second, 5*time.Second)
+
+	err := s.Send(context.Background(), config.Webhook{URL: server.URL}, []byte(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "after 7 attempts") {
+		t.Errorf("got %v, want a failure after 7 attempts", err)
+	}
+	if got := requests.Load(); got != 7 {
+		t.Errorf("%d requests, want 7", got)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second, 5 * time.Second}
+	if !reflect.DeepEqual(*delays, want) {
+		t.Errorf("waited %v, want %v", *delays, want)
+	}
+}
+
+func TestSendStopsWithContext(t *testing.T) {
+	server, requests := failingServer(t, 100, http.StatusInternalServerError)
+	s := &Sender{Client: http.DefaultClient, Retries: 5, Backoff: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := s.Send(ctx, config.Webhook{URL: server.URL}, []byte(`{}`)); err != context.DeadlineExceeded {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if took := time.Since(start); took > 5*time.Second {
+		t.Errorf("gave up after %s", took)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("%d requests, want 1", got)
+	}
+}
+
+func TestSendRequest(t *testing.T) {
+	var got *http.Request
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		got, body = r, string(data)
+	}))
+	defer server.Close()
+
+	hook := config.Webhook{URL: server.URL + "/hooks/secret", Headers: map[string]string{"Authorization": "Bearer token"}}
+	if err := NewSender().Send(context.Background(), hook, []byte(`{"projects":[]}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got.Method != http.MethodPost || got.URL.Path != "/hooks/secret" || body != `{"projects":[]}` {
+		t.Errorf("got %s %s with %q", got.Method, got.URL.Path, body)
+	}
+	for name, want := range map[string]string{"Content-Type": "application/json", "User-Agent": "check-projects", "Authorization": "Bearer token"} {
+		if value := got.Header.Get(name); value != want {
+			t.Errorf("%s: %q, want %q", name, value, want)
+		}
+	}
+}
+
+func TestSendErrorHidesURL(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL + "/hooks/secret"
+	server.Close() // Connections are refused
+
+	s, _ := recordingSender(1, time.Millisecond, 0)
+	err := s.Send(context.Background(), config.Webhook{URL: url}, []byte(`{}`))
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("got %v, want an error without the URL", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package checkprojects is the library behind the check-projects command: it loads
+// a config, discovers the git repositories it describes and checks their status.
+//
+// The command line and the interactive TUI are built on these functions, so a
+// dashboard embedding them sees exactly what check-projects reports:
+//
+//	cfg, err := checkprojects.Load("") // ~/.config/check-projects/config.yml
+//	if err != nil {
+//		return err
+//	}
+//	projects, warnings, err := checkprojects.Discover(ctx, cfg)
+//	if err != nil {
+//		return err
+//	}
+//	for _, w := range warnings {
+//		log.Printf("warning: %s", w)
+//	}
+//	results, err := checkprojects.Check(ctx, projects, checkprojects.Options{Fetch: true})
+//	if err != nil {
+//		return err // ctx was cancelled: results only hold what was checked
+//	}
+//	for _, r := range results {
+//		if r.Status.Type != checkprojects.StatusSync {
+//			fmt.Printf("%s/%s: %s\n", r.Project.Category, r.Project.Name, r.Status.Message)
+//		}
+//	}
+//
+// Rendering (text, JSON, markdown...) stays internal to the command.
+package checkprojects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uralys/check-projects/internal/checker"
+	"github.com/uralys/check-projects/internal/config"
+	"github.com/uralys/check-projects/internal/git"
+	"github.com/uralys/check-projects/internal/scanner"
+)
+
+// Config is a loaded configuration: categories of projects and display options
+type Config = config.Config
+
+// Category is a named group of projects, listed explicitly or found under a root
+type Category = config.Category
+
+// Project is a discovered repository (Repository is nil for a broken symlink)
+type Project = scanner.Project
+
+// Warning is a configured project or directory that Discover left out
+type Warning = scanner.Warning
+
+// Status is the git status of a repository, as shown in reports
+type Status = git.Status
+
+// StatusType classifies a Status
+type StatusType = git.StatusType
+
+// Status types
+const (
+	StatusSync          = git.StatusSync          // Clean and in sync with its upstream
+	StatusUnsync        = git.StatusUnsync        // Local changes, or ahead of or behind its upstream
+	StatusError         = git.StatusError         // Git failed: see Status.Message
+	StatusIgnored       = git.StatusIgnored       // Ignored by the config
+	StatusNoUpstream    = git.StatusNoUpstream    // The current branch tracks nothing
+	StatusBrokenSymlink = git.StatusBrokenSymlink // A symlink whose target is missing
+	StatusTimeout       = git.StatusTimeout       // A git operation exceeded Options.Timeout
+	StatusStale         = git.StatusStale         // Clean, without commits for longer than Options.StaleAfter
+	StatusPermission    = git.StatusPermission    // The current user cannot read the repository: see Status.Path
+	StatusUnchecked     = git.StatusUnchecked     // Only set by the check-projects command (--max-duration)
+	StatusBare          = git.StatusBare          // Bare repository without unpushed branches
+	StatusEmpty         = git.StatusEmpty         // Repository without any commit yet
+	StatusLocked        = git.StatusLocked        // A lock file blocks git: see Status.LockFile
+	StatusNoRemote      = git.StatusNoRemote      // The repository has no remote at all
+	StatusUnversioned   = git.StatusUnversioned   // A directory holding files but no repository (Project.Unversioned)
+
+	StatusRemoteUnreachable = git.StatusRemoteUnreachable // Only set by the check-projects command (--check-remotes)
+	StatusAuthRequired      = git.StatusAuthRequired      // The remote refused the credentials while fetching (Options.Fetch)
+)
+
+// Options controls how Check and Stream check projects:
+//
+//   - Concurrency: maximum number of repositories checked at once
+//     (0 = adapted to each disk's latency; prefer a fixed value with Fetch)
+//   - Timeout: per-repository limit for each git operation (0 = none)
+//   - Fetch: fetch each repository before checking it
+//   - LastCommits: also read the date of each repository's last commit
+//   - MergedBranches: per project, whether to list the local branches merged into
+//     the default branch of its remote (e.g. Config.MergedBranchesFor of its category)
+//   - OffDefaultBranch: per project, whether to flag a current branch that is not
+//     the default branch of its remote (e.g. Config.DefaultBranchFor of its category)
+//   - StaleAfter: age of the last commit past which a clean project is StatusStale,
+//     per project (e.g. Config.StaleAfterFor of its category)
+//   - Logf: receives debug messages, such as concurrency changes
+type Options = checker.Options
+
+// Result is the outcome of checking the project at Index in the slice given to
+// Check or Stream. Its Status is never nil once checked.
+type Result = checker.Result
+
+// Load reads the config from the given files, merged in order (later files override
+// the options of earlier ones). Without any path, or with an empty one, the default
+// ~/.config/check-projects/config.yml is used.
+func Load(configPaths ...string) (*Config, error) {
+	var paths []string
+	for _, path := range configPaths {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return config.LoadConfig(paths)
+}
+
+// Discover finds the projects of every category of cfg, in config order, leaving out
+// ignored ones. Missing or unreadable projects and directories do not fail the
+// discovery: they are returned as warnings. The error is ctx's when it was cancelled.
+func Discover(ctx context.Context, cfg *Config) ([]Project, []Warning, error) {
+	s := scanner.NewScanner(cfg)
+	projects, err := s.ScanAll(ctx)
+	return projects, s.Warnings, err
+}
+
+// Stream checks projects concurrently and sends each result as soon as it is ready.
+// The channel is closed once every project has been checked. Once ctx is cancelled,
+// no new check is started and interrupted checks send no result.
+func Stream(ctx context.Context, projects []Project, opts Options) <-chan Result {
+	return checker.Stream(ctx, projects, opts)
+}
+
+// Check returns one result per project, in the same order. When ctx is cancelled,
+// it returns ctx's error along with the results of the projects checked so far
+// (the others have a nil Status).
+func Check(ctx context.Context, projects []Project, opts Options) ([]Result, error) {
+	results := make([]Result, len(projects))
+	for i, project := range projects {
+		results[i] = Result{Index: i, Project: project}
+	}
+	for result := range Stream(ctx, projects, opts) {
+		results[result.Index] = result
+	}
+	return results, ctx.Err()
+}
+
+// FetchProgress is told about each fetch of FetchAll as it completes: how many are
+// done out of total, and the project with its error (nil when it succeeded)
+type FetchProgress = checker.FetchProgress
+
+// FetchAll fetches every project, at most concurrency at once, with the timeout,
+// retries and fetch options of opts. A failed fetch never stops the others: failures
+// are returned by project index. progress, when set, is called once per project.
+func FetchAll(ctx context.Context, projects []Project, concurrency int, opts Options, progress FetchProgress) map[int]error {
+	return checker.FetchAll(ctx, projects, concurrency, opts, progress)
+}
+
+// LastCommits returns the date of the last commit of every project, in the same order.
+// Repositories without commits, broken symlinks and failures are left as the zero time.
+func LastCommits(ctx context.Context, projects []Project, opts Options) []time.Time {
+	return checker.LastCommits(ctx, projects, opts)
+}